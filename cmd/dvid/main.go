@@ -19,6 +19,7 @@ import (
 
 	"github.com/janelia-flyem/dvid/datastore"
 	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/rpc"
 	"github.com/janelia-flyem/dvid/server"
 	"github.com/janelia-flyem/dvid/storage"
 	"github.com/janelia-flyem/go/profiler"
@@ -55,6 +56,11 @@ var (
 
 	rpcAddress = flag.String("rpc", server.DefaultRPCAddress, "")
 
+	// TLS files for RPC to a server requiring encryption.
+	tlsCert = flag.String("tlscert", "", "")
+	tlsKey  = flag.String("tlskey", "", "")
+	tlsCA   = flag.String("tlsca", "", "")
+
 	// msgAddress = flag.String("message", message.DefaultAddress, "")
 
 	// Profile CPU usage using standard gotest system.
@@ -78,6 +84,9 @@ Usage: dvid [options] <command>
 
       -readonly   (flag)    HTTP API ignores anything but GET and HEAD requests.
       -rpc        =string   Address for RPC communication.
+      -tlsca      =string   Use TLS for RPC, verifying the server with CAs in this PEM file.
+      -tlscert    =string   Client certificate PEM file for RPC to servers requiring mutual TLS.
+      -tlskey     =string   Client key PEM file for RPC to servers requiring mutual TLS.
       -cpuprofile =string   Write CPU profile to this file.
       -memprofile =string   Write memory profile to this file on ctrl-C.
       -numcpu     =number   Number of logical CPUs to use for DVID.
@@ -224,6 +233,14 @@ func DoCommand(cmd dvid.Command) error {
 				return fmt.Errorf("Error in reading from standard input: %v", err)
 			}
 		}
+		if *tlsCA != "" || *tlsCert != "" || *tlsKey != "" {
+			tlsConfig := dvid.TLSConfig{CertFile: *tlsCert, KeyFile: *tlsKey, CAFile: *tlsCA}
+			clientTLS, err := tlsConfig.ClientConfig()
+			if err != nil {
+				return err
+			}
+			rpc.SetClientTLS(clientTLS)
+		}
 		return server.SendRPC(*rpcAddress, request)
 	}
 	return nil
@@ -245,10 +262,17 @@ func DoRepair(cmd dvid.Command) error {
 
 // DoServe opens a datastore then creates both web and rpc servers for the datastore
 func DoServe(cmd dvid.Command) error {
-	// Capture ctrl+c and other interrupts.  Then handle graceful shutdown.
-	stopSig := make(chan os.Signal)
+	// Capture ctrl+c and other interrupts.  Then handle graceful shutdown.  A second
+	// interrupt during shutdown exits immediately.
+	stopSig := make(chan os.Signal, 1)
 	go func() {
+		var shuttingDown bool
 		for sig := range stopSig {
+			if shuttingDown {
+				log.Printf("Stop signal captured again: %q.  Exiting without waiting for shutdown...\n", sig)
+				os.Exit(1)
+			}
+			shuttingDown = true
 			log.Printf("Stop signal captured: %q.  Shutting down...\n", sig)
 			if *memprofile != "" {
 				log.Printf("Storing memory profiling to %s...\n", *memprofile)
@@ -263,9 +287,11 @@ func DoServe(cmd dvid.Command) error {
 				log.Printf("Stopping CPU profiling to %s...\n", *cpuprofile)
 				pprof.StopCPUProfile()
 			}
-			server.Shutdown()
-			time.Sleep(1 * time.Second)
-			os.Exit(0)
+			go func() {
+				server.Shutdown()
+				time.Sleep(1 * time.Second)
+				os.Exit(0)
+			}()
 		}
 	}()
 	signal.Notify(stopSig, os.Interrupt, os.Kill, syscall.SIGTERM)
@@ -316,6 +342,21 @@ func DoServe(cmd dvid.Command) error {
 		transdb.UnlockKey(key)
 	}
 
+	// Reload the configuration file on SIGHUP.
+	reloadSig := make(chan os.Signal, 1)
+	go func() {
+		for range reloadSig {
+			result, err := server.ReloadConfig()
+			if err != nil {
+				dvid.Errorf("SIGHUP reload of configuration failed: %v\n", err)
+				continue
+			}
+			dvid.Infof("SIGHUP reloaded configuration, applied %v, restart required for %v\n",
+				result.Applied, result.RestartRequired)
+		}
+	}()
+	signal.Notify(reloadSig, syscall.SIGHUP)
+
 	// add handlers to help us track memory usage - they don't track memory until they're told to
 	profiler.AddMemoryProfilingHandlers()
 
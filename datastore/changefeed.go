@@ -0,0 +1,215 @@
+/*
+   This file provides change feeds that let external clients follow the sync events
+   published by a data instance.
+*/
+
+package datastore
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+const (
+	// number of recent events retained per data instance so clients can resume a feed.
+	changeFeedHistory = 4096
+
+	// number of events buffered per subscriber before the subscriber is dropped.
+	changeFeedBuffer = 256
+)
+
+// ChangeEvent is a compact description of a sync event suitable for delivery to
+// external clients.  Bulky deltas like block data are never included.
+type ChangeEvent struct {
+	Seq     uint64 // monotonically increasing per data instance
+	Event   string
+	Version dvid.UUID
+	Time    time.Time
+	Labels  []uint64 `json:",omitempty"` // labels affected by the change, if known
+	Block   string   `json:",omitempty"` // block coordinate "x,y,z" for block-level changes
+	Key     string   `json:",omitempty"` // key for key-value changes
+}
+
+// ChangeDescriber is implemented by SyncMessage deltas that can summarize themselves
+// for change feeds.  Events with other deltas only report their event type and version.
+type ChangeDescriber interface {
+	DescribeChange(*ChangeEvent)
+}
+
+// ChangeFilter restricts the events sent to a change feed subscriber.  Empty fields
+// do not restrict events.
+type ChangeFilter struct {
+	Events  map[string]struct{}
+	Labels  map[uint64]struct{}
+	Version dvid.UUID
+}
+
+func (f ChangeFilter) matches(e *ChangeEvent) bool {
+	if f.Version != "" && f.Version != e.Version {
+		return false
+	}
+	if len(f.Events) != 0 {
+		if _, found := f.Events[e.Event]; !found {
+			return false
+		}
+	}
+	if len(f.Labels) != 0 {
+		for _, label := range e.Labels {
+			if _, found := f.Labels[label]; found {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ChangeSubscription receives events from a data instance's change feed.  The channel
+// is closed if the subscriber falls too far behind, in which case the client should
+// resubscribe from its last received sequence number.
+type ChangeSubscription struct {
+	Ch <-chan ChangeEvent
+
+	ch     chan ChangeEvent
+	filter ChangeFilter
+	feed   *changeFeed
+}
+
+// Close stops delivery of events to the subscription.
+func (s *ChangeSubscription) Close() {
+	s.feed.Lock()
+	s.feed.drop(s)
+	s.feed.Unlock()
+}
+
+// changeFeed holds the recent events and subscribers for a data instance.  A feed is
+// only created when first subscribed, so data never followed incurs no overhead.
+type changeFeed struct {
+	sync.Mutex
+	lastSeq uint64
+	history []ChangeEvent
+	subs    map[*ChangeSubscription]struct{}
+}
+
+// must be called with feed locked.
+func (feed *changeFeed) drop(s *ChangeSubscription) {
+	if _, found := feed.subs[s]; found {
+		delete(feed.subs, s)
+		close(s.ch)
+	}
+}
+
+func (feed *changeFeed) publish(e ChangeEvent) {
+	feed.Lock()
+	defer feed.Unlock()
+	feed.lastSeq++
+	e.Seq = feed.lastSeq
+	if len(feed.history) >= 2*changeFeedHistory {
+		n := copy(feed.history, feed.history[len(feed.history)-changeFeedHistory+1:])
+		feed.history = feed.history[:n]
+	}
+	feed.history = append(feed.history, e)
+	for s := range feed.subs {
+		if !s.filter.matches(&e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			dvid.Infof("Dropping change feed subscriber that fell behind at event %d\n", e.Seq)
+			feed.drop(s)
+		}
+	}
+}
+
+// returns retained events with sequence numbers after the given one.  The bool is false
+// if events after the given sequence number are no longer retained.
+func (feed *changeFeed) since(seq uint64) ([]ChangeEvent, bool) {
+	if seq == feed.lastSeq {
+		return nil, true
+	}
+	if seq > feed.lastSeq || len(feed.history) == 0 { // sequence from before a restart
+		return feed.history, false
+	}
+	first := feed.history[0].Seq
+	if seq+1 < first {
+		return feed.history, false
+	}
+	return feed.history[seq+1-first:], true
+}
+
+var changeFeeds struct {
+	sync.RWMutex
+	byData map[dvid.UUID]*changeFeed
+}
+
+// SubscribeChanges subscribes to changes in the given data instance.  If resume is true,
+// retained events after the given sequence number that pass the filter are returned
+// for replay, and complete is false if some of those events are no longer retained.
+// The caller must Close the subscription when done.
+func SubscribeChanges(d dvid.Data, filter ChangeFilter, resume bool, since uint64) (sub *ChangeSubscription, replay []ChangeEvent, complete bool, err error) {
+	if manager == nil {
+		err = ErrManagerNotInitialized
+		return
+	}
+	if d == nil {
+		err = fmt.Errorf("can't subscribe to changes of nil data")
+		return
+	}
+	changeFeeds.Lock()
+	if changeFeeds.byData == nil {
+		changeFeeds.byData = make(map[dvid.UUID]*changeFeed)
+	}
+	feed, found := changeFeeds.byData[d.DataUUID()]
+	if !found {
+		feed = &changeFeed{subs: make(map[*ChangeSubscription]struct{})}
+		changeFeeds.byData[d.DataUUID()] = feed
+	}
+	changeFeeds.Unlock()
+
+	ch := make(chan ChangeEvent, changeFeedBuffer)
+	sub = &ChangeSubscription{Ch: ch, ch: ch, filter: filter, feed: feed}
+
+	feed.Lock()
+	defer feed.Unlock()
+	complete = true
+	if resume {
+		var retained []ChangeEvent
+		retained, complete = feed.since(since)
+		for i := range retained {
+			if filter.matches(&retained[i]) {
+				replay = append(replay, retained[i])
+			}
+		}
+	}
+	feed.subs[sub] = struct{}{}
+	return
+}
+
+// publishChange adds a sync event to the change feed of the notifying data instance,
+// if any client has subscribed to it.
+func publishChange(e SyncEvent, m SyncMessage) {
+	changeFeeds.RLock()
+	feed, found := changeFeeds.byData[e.Data]
+	changeFeeds.RUnlock()
+	if !found {
+		return
+	}
+	uuid, err := manager.uuidFromVersion(m.Version)
+	if err != nil {
+		dvid.Errorf("unable to publish change feed event %q: %v\n", m.Event, err)
+		return
+	}
+	ce := ChangeEvent{
+		Event:   m.Event,
+		Version: uuid,
+		Time:    time.Now(),
+	}
+	if describer, ok := m.Delta.(ChangeDescriber); ok {
+		describer.DescribeChange(&ce)
+	}
+	feed.publish(ce)
+}
@@ -0,0 +1,67 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+func TestChangeFeedResume(t *testing.T) {
+	feed := &changeFeed{subs: make(map[*ChangeSubscription]struct{})}
+	for i := 0; i < 2*changeFeedHistory+10; i++ {
+		feed.publish(ChangeEvent{Event: "MERGE_END", Version: dvid.UUID("abc"), Labels: []uint64{uint64(i)}})
+	}
+	if feed.lastSeq != 2*changeFeedHistory+10 {
+		t.Fatalf("expected last sequence %d, got %d\n", 2*changeFeedHistory+10, feed.lastSeq)
+	}
+	if len(feed.history) > 2*changeFeedHistory {
+		t.Errorf("history not bounded: %d events retained\n", len(feed.history))
+	}
+	events, complete := feed.since(feed.lastSeq - 5)
+	if !complete || len(events) != 5 || events[0].Seq != feed.lastSeq-4 {
+		t.Errorf("bad resume: complete %t, %d events\n", complete, len(events))
+	}
+	if _, complete = feed.since(1); complete {
+		t.Errorf("expected resume from discarded sequence to be incomplete\n")
+	}
+	if events, complete = feed.since(feed.lastSeq); !complete || len(events) != 0 {
+		t.Errorf("expected no events when resuming from last sequence\n")
+	}
+	if _, complete = feed.since(feed.lastSeq + 100); complete {
+		t.Errorf("expected resume from unknown sequence to be incomplete\n")
+	}
+}
+
+func TestChangeFeedFilter(t *testing.T) {
+	feed := &changeFeed{subs: make(map[*ChangeSubscription]struct{})}
+	filter := ChangeFilter{
+		Events:  map[string]struct{}{"SPLIT_END": {}},
+		Labels:  map[uint64]struct{}{7: {}},
+		Version: dvid.UUID("abc"),
+	}
+	ch := make(chan ChangeEvent, 2)
+	sub := &ChangeSubscription{Ch: ch, ch: ch, filter: filter, feed: feed}
+	feed.subs[sub] = struct{}{}
+
+	feed.publish(ChangeEvent{Event: "SPLIT_END", Version: dvid.UUID("abc"), Labels: []uint64{3, 7}})
+	feed.publish(ChangeEvent{Event: "SPLIT_END", Version: dvid.UUID("def"), Labels: []uint64{7}})
+	feed.publish(ChangeEvent{Event: "MERGE_END", Version: dvid.UUID("abc"), Labels: []uint64{7}})
+	feed.publish(ChangeEvent{Event: "SPLIT_END", Version: dvid.UUID("abc"), Labels: []uint64{8}})
+	if len(ch) != 1 {
+		t.Fatalf("expected 1 filtered event, got %d\n", len(ch))
+	}
+	if e := <-ch; e.Seq != 1 {
+		t.Errorf("expected event with sequence 1, got %v\n", e)
+	}
+
+	// Subscriber that falls behind should be dropped and its channel closed.
+	for i := 0; i < 3; i++ {
+		feed.publish(ChangeEvent{Event: "SPLIT_END", Version: dvid.UUID("abc"), Labels: []uint64{7}})
+	}
+	if len(feed.subs) != 0 {
+		t.Errorf("expected slow subscriber to be dropped\n")
+	}
+	for range ch {
+	}
+	sub.Close() // closing a dropped subscription should be harmless
+}
@@ -383,8 +383,9 @@ type Data struct {
 	deleted bool
 
 	// handle waiting based on operation ID.
-	opWG    map[uint64]*sync.WaitGroup
-	opWG_mu sync.RWMutex
+	opWG      map[uint64]*sync.WaitGroup
+	opWG_mu   sync.RWMutex
+	opPending int // number of MutAdd without matching MutDone
 }
 
 // IsDeleted returns true if data has been deleted or is deleting.
@@ -598,6 +599,20 @@ func (d *Data) NewMutationID() uint64 {
 	return repo.newMutationID()
 }
 
+// NewMutationIDs reserves a contiguous range of num mutation IDs and returns the first.
+func (d *Data) NewMutationIDs(num uint64) uint64 {
+	if manager == nil {
+		dvid.Criticalf("New mutation IDs requested for data %q but manager not initialized!\n", d.DataName())
+		return 0
+	}
+	repo, err := manager.repoFromUUID(d.RootUUID())
+	if err != nil {
+		dvid.Criticalf("New mutation IDs requested for data %q but no repo associated with root %s\n", d.DataName(), d.RootUUID())
+		return 0
+	}
+	return repo.newMutationIDs(num)
+}
+
 // ---- dvid.DataSetter implementation ----
 
 func (d *Data) SetInstanceID(id dvid.InstanceID) {
@@ -917,6 +932,7 @@ func (d *Data) MutAdd(mutID uint64) (newOp bool) {
 		newOp = true
 	}
 	wg.Add(1)
+	d.opPending++
 	d.opWG_mu.Unlock()
 	return
 }
@@ -932,9 +948,17 @@ func (d *Data) MutDone(mutID uint64) {
 	if !found || wg == nil {
 		return
 	}
+	d.opPending--
 	wg.Done()
 }
 
+// MutPending returns true if any operation added via MutAdd has not been marked done.
+func (d *Data) MutPending() bool {
+	d.opWG_mu.RLock()
+	defer d.opWG_mu.RUnlock()
+	return d.opPending > 0
+}
+
 // MutWait blocks until all operations with the given ID are completed.
 func (d *Data) MutWait(mutID uint64) {
 	d.opWG_mu.RLock()
@@ -1082,6 +1106,11 @@ func (d *Data) ProduceKafkaMsg(b []byte) error {
 		topic += "-" + suffix
 	}
 
+	// queue message for any event sinks handling this data
+	if err := storage.PublishEvent(dvid.GetDataSpecifier(d.DataName(), rootuuid), topic, b); err != nil {
+		dvid.Errorf("unable to queue message for event sinks on topic %q: %v\n", topic, err)
+	}
+
 	// send message if kafka initialized
 	return storage.KafkaProduceMsg(b, topic)
 }
@@ -135,6 +135,15 @@ func GetRepoJSON(uuid dvid.UUID) (string, error) {
 	return manager.getRepoJSON(uuid)
 }
 
+// GetRepoUsageJSON returns the storage usage of all data instances in a repo along with
+// any configured quotas.
+func GetRepoUsageJSON(uuid dvid.UUID) (string, error) {
+	if manager == nil {
+		return "", ErrManagerNotInitialized
+	}
+	return manager.getRepoUsageJSON(uuid)
+}
+
 func GetBranchVersionsJSON(uuid dvid.UUID, name string) (string, error) {
 	if manager == nil {
 		return "", ErrManagerNotInitialized
@@ -783,6 +792,11 @@ func LogRepoOpToKafka(uuid dvid.UUID, b []byte) error {
 	}
 	topic := storage.KafkaTopicPrefix + "dvidrepo-" + string(rootuuid) + "-repo-ops"
 
+	// queue message for any event sinks handling all data
+	if err := storage.PublishEvent("", topic, b); err != nil {
+		dvid.Errorf("unable to queue repo op for event sinks on topic %q: %v\n", topic, err)
+	}
+
 	// send message if kafka initialized
 	return storage.KafkaProduceMsg(b, topic)
 }
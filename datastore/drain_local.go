@@ -0,0 +1,70 @@
+// +build !clustered,!gcloud
+
+package datastore
+
+import (
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// scaleUpdater is implemented by data that compute lower-resolution scales asynchronously.
+type scaleUpdater interface {
+	AnyScaleUpdating() bool
+}
+
+// mutationPender is implemented by data that track mutations via MutAdd and MutDone.
+type mutationPender interface {
+	MutPending() bool
+}
+
+// busyData returns the names of data instances with in-flight mutations, pending sync
+// events, or down-resolution computations.
+func (m *repoManager) busyData() []dvid.InstanceName {
+	m.idMutex.RLock()
+	dataservices := make([]DataService, 0, len(m.iids))
+	for _, data := range m.iids {
+		dataservices = append(dataservices, data)
+	}
+	m.idMutex.RUnlock()
+
+	var busy []dvid.InstanceName
+	for _, data := range dataservices {
+		if d, ok := data.(dataUpdater); ok && d.Updating() {
+			busy = append(busy, data.DataName())
+		} else if d, ok := data.(mutationPender); ok && d.MutPending() {
+			busy = append(busy, data.DataName())
+		} else if d, ok := data.(Syncer); ok && d.SyncPending() {
+			busy = append(busy, data.DataName())
+		} else if d, ok := data.(scaleUpdater); ok && d.AnyScaleUpdating() {
+			busy = append(busy, data.DataName())
+		}
+	}
+	return busy
+}
+
+// WaitForMutations blocks until no data instance is mutating, processing sync events, or
+// computing lower-resolution scales, or until the timeout elapses.  Requests should be
+// denied before calling so no new mutations start.  Returns the names of data instances
+// that were still busy at the timeout.
+func WaitForMutations(timeout time.Duration) []dvid.InstanceName {
+	if manager == nil {
+		return nil
+	}
+	deadline := time.Now().Add(timeout)
+	lastLog := time.Now()
+	for {
+		busy := manager.busyData()
+		if len(busy) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return busy
+		}
+		if time.Since(lastLog) >= 5*time.Second {
+			dvid.Infof("Waiting for in-flight mutations to finish in data %v...\n", busy)
+			lastLog = time.Now()
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
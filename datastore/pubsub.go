@@ -24,6 +24,17 @@ type SyncMessage struct {
 	Event   string
 	Version dvid.VersionID
 	Delta   interface{}
+	Span    *dvid.TraceSpan // trace span of the operation causing the change, if any
+}
+
+// StartSpan starts a span for the handling of the message by the given data instance
+// within the trace of the operation that caused it.  Returns nil if the operation was
+// not traced.
+func (m SyncMessage) StartSpan(d dvid.Data) *dvid.TraceSpan {
+	span := dvid.StartSpan(m.Span, "sync "+m.Event)
+	span.SetAttribute("data", string(d.DataName()))
+	span.SetAttribute("version", uint64(m.Version))
+	return span
 }
 
 // SyncSub is a subscription request from an instance to be notified via a channel when
@@ -161,7 +172,28 @@ func SetSyncData(data dvid.Data, syncs dvid.UUIDSet, replace bool) error {
 	return manager.setSync(data, syncs, replace)
 }
 
-// NotifySubscribers sends a message to any data instances subscribed to the event.
+// HasSubscribers returns true if any data instance in the repo of the given version is
+// synced to events of the given data.
+func HasSubscribers(v dvid.VersionID, data dvid.UUID) (bool, error) {
+	if manager == nil {
+		return false, ErrManagerNotInitialized
+	}
+	repo, err := manager.repoFromVersion(v)
+	if err != nil {
+		return false, err
+	}
+	repo.RLock()
+	defer repo.RUnlock()
+	for e, subs := range repo.subs {
+		if e.Data == data && len(subs) != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NotifySubscribers sends a message to any data instances subscribed to the event
+// as well as any clients following the data instance's change feed.
 func NotifySubscribers(e SyncEvent, m SyncMessage) error {
 	if manager == nil {
 		return ErrManagerNotInitialized
@@ -173,6 +205,9 @@ func NotifySubscribers(e SyncEvent, m SyncMessage) error {
 		return err
 	}
 
+	// Publish to any external change feed before notifying internal subscribers.
+	publishChange(e, m)
+
 	// Use the repo notification system to notify internal subscribers.
 	return repo.notifySubscribers(e, m)
 }
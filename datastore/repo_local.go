@@ -61,6 +61,7 @@ type Config struct {
 	InstanceGen   string
 	InstanceStart dvid.InstanceID
 	MutationStart uint64
+	Quotas        map[dvid.UUID]RepoQuota // storage quotas keyed by a UUID within each repo
 }
 
 // Initialize creates a repositories manager that is handled through package functions.
@@ -137,6 +138,9 @@ func Initialize(initMetadata bool, iconfig Config) error {
 			go d.Initialize()
 		}
 	}
+	if err = m.setQuotas(iconfig.Quotas); err != nil {
+		return err
+	}
 	// Set the package variable.  We are good to go...
 	manager = m
 
@@ -267,6 +271,9 @@ type repoManager struct {
 
 	// Verified metadata storage for ease of use.
 	store storage.OrderedKeyValueDB
+
+	// storage quotas by repo root
+	quotas quotasT
 }
 
 func (m *repoManager) Shutdown() {
@@ -883,6 +890,9 @@ func (m *repoManager) addRepo(r *repoT) error {
 		m.uuidToVersion[node.uuid] = v
 	}
 	m.idMutex.Unlock()
+	for _, dataservice := range r.data {
+		m.addQuotaInstance(dataservice)
+	}
 
 	// Persist the changes
 	if err := m.putCaches(); err != nil {
@@ -1808,6 +1818,7 @@ func (m *repoManager) newData(uuid dvid.UUID, t TypeService, name dvid.InstanceN
 	m.iids[id] = dataservice
 	m.dataByUUID[dataservice.DataUUID()] = dataservice
 	m.idMutex.Unlock()
+	m.addQuotaInstance(dataservice)
 
 	r.Lock()
 	r.data[name] = dataservice
@@ -2412,6 +2423,11 @@ func (r *repoT) getMutationID() (mutID uint64) {
 }
 
 func (r *repoT) newMutationID() (mutID uint64) {
+	return r.newMutationIDs(1)
+}
+
+// newMutationIDs reserves a contiguous range of mutation IDs and returns the first.
+func (r *repoT) newMutationIDs(num uint64) (mutID uint64) {
 	if manager == nil || manager.store == nil {
 		dvid.Criticalf("Bad new mutation ID request.  Manager or store nil.\n")
 		return
@@ -2419,9 +2435,11 @@ func (r *repoT) newMutationID() (mutID uint64) {
 	var ctx storage.MetadataContext
 	r.mutMu.Lock()
 	mutID = r.mutCurID
-	r.mutCurID++
+	r.mutCurID += num
 	if r.mutCurID >= r.mutSavedID {
-		r.mutSavedID += StrideMutationID
+		for r.mutCurID >= r.mutSavedID {
+			r.mutSavedID += StrideMutationID
+		}
 		mutdata := make([]byte, 8)
 		binary.LittleEndian.PutUint64(mutdata, r.mutSavedID)
 		tk := storage.NewTKey(mutidKey, r.id.Bytes())
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/janelia-flyem/dvid/dvid"
 )
@@ -238,3 +239,29 @@ func TestUUIDAssignment(t *testing.T) {
 		t.Errorf("Error getting back correct UUID %s from %s\n", myuuid, uuid)
 	}
 }
+
+func TestWaitForMutations(t *testing.T) {
+	saved := manager
+	defer func() { manager = saved }()
+
+	data := &TestData{&Data{name: "busy"}}
+	manager = &repoManager{
+		repos: make(map[dvid.UUID]*repoT),
+		iids:  map[dvid.InstanceID]DataService{1: data},
+	}
+	if busy := WaitForMutations(0); len(busy) != 0 {
+		t.Fatalf("expected no busy data, got %v\n", busy)
+	}
+
+	data.MutAdd(23)
+	if busy := WaitForMutations(10 * time.Millisecond); len(busy) != 1 || busy[0] != "busy" {
+		t.Fatalf("expected data %q to be busy, got %v\n", "busy", busy)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		data.MutDone(23)
+	}()
+	if busy := WaitForMutations(5 * time.Second); len(busy) != 0 {
+		t.Fatalf("expected mutation to finish, got busy data %v\n", busy)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+   This file provides a registry of data instance HTTP endpoints so the server can
+   produce machine-readable API descriptions.
+*/
+
+package datastore
+
+import (
+	"sync"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// RouteParam describes a path or query-string parameter of an HTTP endpoint.
+type RouteParam struct {
+	Name        string
+	Type        string // "string", "integer", "number", or "boolean".  Defaults to "string".
+	Description string
+	Required    bool // path parameters are always required
+}
+
+// Route describes an HTTP endpoint of a data instance.  The Path is relative to
+// /api/node/{uuid}/{dataname} and uses {name} for path parameters, e.g., "/key/{key}".
+type Route struct {
+	Method     string // "GET", "POST", "DELETE", or "HEAD"
+	Path       string
+	Summary    string
+	PathParams []RouteParam
+	Query      []RouteParam
+	Request    []string // content types accepted in the request body, if any
+	Response   []string // content types of a successful response, if any
+}
+
+// CommonRoutes are the endpoints supported by data instances of all types.
+var CommonRoutes = []Route{
+	{
+		Method:  "GET",
+		Path:    "/blobstore/{reference}",
+		Summary: "Returns the blob with the given reference from the data instance's blobstore.",
+		PathParams: []RouteParam{
+			{Name: "reference", Description: "URL-friendly content hash of the blob."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:   "POST",
+		Path:     "/blobstore",
+		Summary:  "Stores the request body in the data instance's blobstore and returns its reference.",
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/events",
+		Summary: "Streams changes to the data instance as Server-Sent Events.",
+		Query: []RouteParam{
+			{Name: "types", Description: "Comma-separated event types to receive."},
+			{Name: "labels", Description: "Comma-separated labels; only events affecting these labels are sent."},
+			{Name: "since", Type: "integer", Description: "Resume after the given sequence number."},
+			{Name: "allversions", Type: "boolean", Description: "Send events from all versions, not just the given UUID."},
+		},
+		Response: []string{"text/event-stream"},
+	},
+}
+
+var routes struct {
+	sync.RWMutex
+	byType map[dvid.TypeString][]Route
+}
+
+// RegisterRoutes adds descriptions of the HTTP endpoints for a datatype.  It is typically
+// called from a datatype's init() alongside Register.
+func RegisterRoutes(typename dvid.TypeString, rts ...Route) {
+	routes.Lock()
+	if routes.byType == nil {
+		routes.byType = make(map[dvid.TypeString][]Route)
+	}
+	routes.byType[typename] = append(routes.byType[typename], rts...)
+	routes.Unlock()
+}
+
+// TypeRoutes returns the registered HTTP endpoints for a datatype, not including
+// CommonRoutes.  Returns nil if the datatype has not registered its routes.
+func TypeRoutes(typename dvid.TypeString) []Route {
+	routes.RLock()
+	defer routes.RUnlock()
+	return routes.byType[typename]
+}
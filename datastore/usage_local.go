@@ -0,0 +1,262 @@
+// +build !clustered,!gcloud
+
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+// RepoQuota sets limits on the storage used by all data instances within a repo.
+// A zero limit is not enforced.
+type RepoQuota struct {
+	SoftGB float64 // Writes past this limit succeed but are logged and flagged in usage reports.
+	HardGB float64 // Writes that would increase usage past this limit fail.
+}
+
+func (q RepoQuota) softBytes() int64 {
+	return int64(q.SoftGB * float64(dvid.Giga))
+}
+
+func (q RepoQuota) hardBytes() int64 {
+	return int64(q.HardGB * float64(dvid.Giga))
+}
+
+// minimum time between soft quota warnings for a repo.
+const softQuotaWarnInterval = 5 * time.Minute
+
+type quotasT struct {
+	sync.RWMutex
+	byRoot   map[dvid.UUID]RepoQuota
+	lastWarn map[dvid.UUID]time.Time
+
+	// Bytes used per repo are maintained incrementally from storage usage changes.
+	// The repo of each instance is resolved when quotas are set or the instance is
+	// added, so writes never look up repos.  Changes for instances without a resolved
+	// repo are held in unassigned.
+	rootOf     map[dvid.InstanceID]dvid.UUID
+	data       map[dvid.InstanceID]dvid.Data
+	used       map[dvid.UUID]int64
+	unassigned map[dvid.InstanceID]int64
+}
+
+// setQuotas resolves the configured UUIDs, which can be any node or a unique prefix
+// within a repo, to repo roots and installs the quota check for writes.  Quotas for
+// UUIDs that can't be resolved are logged and skipped.
+func (m *repoManager) setQuotas(quotas map[dvid.UUID]RepoQuota) error {
+	if len(quotas) == 0 {
+		return nil
+	}
+	if !storage.UsageTracked() {
+		return fmt.Errorf("repo quotas require storage usage tracking to be enabled via [usage] track = true")
+	}
+	byRoot := make(map[dvid.UUID]RepoQuota, len(quotas))
+	for uuidStr, quota := range quotas {
+		if quota.SoftGB < 0 || quota.HardGB < 0 {
+			return fmt.Errorf("bad quota configuration for repo %s: limits must be non-negative", uuidStr)
+		}
+		uuid, _, err := m.matchingUUID(string(uuidStr))
+		if err != nil {
+			dvid.Errorf("Skipping storage quota for repo %s: %v\n", uuidStr, err)
+			continue
+		}
+		root, err := m.getRepoRoot(uuid)
+		if err != nil {
+			dvid.Errorf("Skipping storage quota for repo %s: %v\n", uuidStr, err)
+			continue
+		}
+		byRoot[root] = quota
+		dvid.Infof("Repo %s storage quota: soft %.1f GB, hard %.1f GB\n", root, quota.SoftGB, quota.HardGB)
+	}
+
+	m.quotas.Lock()
+	m.quotas.byRoot = byRoot
+	m.quotas.lastWarn = make(map[dvid.UUID]time.Time, len(byRoot))
+	m.quotas.rootOf = make(map[dvid.InstanceID]dvid.UUID)
+	m.quotas.data = make(map[dvid.InstanceID]dvid.Data)
+	m.quotas.used = make(map[dvid.UUID]int64, len(byRoot))
+	m.quotas.unassigned = make(map[dvid.InstanceID]int64)
+	m.quotas.Unlock()
+
+	// The observer can be called before we add the baseline, which is fine since
+	// both are just summed.
+	baseline := storage.ObserveUsage(m.observeUsage)
+	m.quotas.Lock()
+	for id, bytes := range baseline {
+		m.quotas.unassigned[id] += bytes
+	}
+	m.quotas.Unlock()
+	m.idMutex.RLock()
+	instances := make([]dvid.Data, 0, len(m.iids))
+	for _, d := range m.iids {
+		instances = append(instances, d)
+	}
+	m.idMutex.RUnlock()
+	m.quotas.Lock()
+	for _, d := range instances {
+		m.quotas.assign(d)
+	}
+	m.quotas.Unlock()
+	storage.SetUsageLimiter(m.checkQuota)
+	return nil
+}
+
+// addQuotaInstance resolves the repo of a new or added data instance for quota checks.
+func (m *repoManager) addQuotaInstance(d dvid.Data) {
+	m.quotas.Lock()
+	if m.quotas.rootOf != nil {
+		m.quotas.assign(d)
+	}
+	m.quotas.Unlock()
+}
+
+// assign records the repo of an instance and adds any of its unassigned usage to the
+// repo's total.  Caller must hold lock.
+func (q *quotasT) assign(d dvid.Data) {
+	id := d.InstanceID()
+	if _, found := q.rootOf[id]; found {
+		return
+	}
+	root := d.RootUUID()
+	q.rootOf[id] = root
+	q.data[id] = d
+	q.used[root] += q.unassigned[id]
+	delete(q.unassigned, id)
+}
+
+// observeUsage is notified by storage of every change in the bytes used by an instance.
+func (m *repoManager) observeUsage(id dvid.InstanceID, deltaBytes int64) {
+	m.quotas.Lock()
+	if root, found := m.quotas.rootOf[id]; found {
+		m.quotas.used[root] += deltaBytes
+	} else {
+		m.quotas.unassigned[id] += deltaBytes
+	}
+	m.quotas.Unlock()
+}
+
+// repoInstanceIDs returns the instance ids of all data in the repo with the given root.
+func (m *repoManager) repoInstanceIDs(root dvid.UUID) ([]dvid.InstanceID, map[dvid.InstanceID]dvid.InstanceName, error) {
+	m.repoMutex.RLock()
+	r, found := m.repos[root]
+	m.repoMutex.RUnlock()
+	if !found {
+		return nil, nil, ErrInvalidUUID
+	}
+	r.RLock()
+	ids := make([]dvid.InstanceID, 0, len(r.data))
+	names := make(map[dvid.InstanceID]dvid.InstanceName, len(r.data))
+	for name, d := range r.data {
+		ids = append(ids, d.InstanceID())
+		names[d.InstanceID()] = name
+	}
+	r.RUnlock()
+	return ids, names, nil
+}
+
+// checkQuota is used as the storage usage limiter and fails writes that would exceed
+// the hard quota of the data instance's repo.  It only uses the repos resolved for
+// instances when they were added, so it takes no repo manager locks.
+func (m *repoManager) checkQuota(id dvid.InstanceID, addBytes int64) error {
+	m.quotas.RLock()
+	root, found := m.quotas.rootOf[id]
+	if !found {
+		m.quotas.RUnlock()
+		return nil // data without repo, e.g., being deleted, is not subject to quotas
+	}
+	d := m.quotas.data[id]
+	quota, found := m.quotas.byRoot[root]
+	used := m.quotas.used[root]
+	m.quotas.RUnlock()
+	if !found {
+		return nil
+	}
+	if hard := quota.hardBytes(); hard > 0 && used+addBytes > hard {
+		name := d.DataName()
+		return fmt.Errorf("write to data %q refused: repo %s would exceed its hard storage quota of %.1f GB (%d bytes used, %d bytes requested)", name, root, quota.HardGB, used, addBytes)
+	}
+	if soft := quota.softBytes(); soft > 0 && used+addBytes > soft {
+		m.quotas.Lock()
+		if time.Since(m.quotas.lastWarn[root]) > softQuotaWarnInterval {
+			m.quotas.lastWarn[root] = time.Now()
+			dvid.Errorf("Repo %s has exceeded its soft storage quota of %.1f GB (%d bytes used)\n", root, quota.SoftGB, used)
+		}
+		m.quotas.Unlock()
+	}
+	return nil
+}
+
+type usageJSON struct {
+	Keys     int64
+	Bytes    int64
+	Versions map[dvid.UUID]storage.UsageCounts `json:",omitempty"`
+}
+
+type repoUsageJSON struct {
+	Root              dvid.UUID
+	Keys              int64
+	Bytes             int64
+	SoftQuotaBytes    int64 `json:",omitempty"`
+	HardQuotaBytes    int64 `json:",omitempty"`
+	SoftQuotaExceeded bool
+	Counting          bool // true if initial usage counts are still being computed
+	Instances         map[dvid.InstanceName]usageJSON
+}
+
+func (m *repoManager) getRepoUsageJSON(uuid dvid.UUID) (string, error) {
+	if !storage.UsageTracked() {
+		return "", fmt.Errorf("storage usage tracking is not enabled in server configuration")
+	}
+	root, err := m.getRepoRoot(uuid)
+	if err != nil {
+		return "", err
+	}
+	ids, names, err := m.repoInstanceIDs(root)
+	if err != nil {
+		return "", err
+	}
+	usage, err := storage.GetInstanceUsage(ids...)
+	if err != nil {
+		return "", err
+	}
+	ru := repoUsageJSON{
+		Root:      root,
+		Instances: make(map[dvid.InstanceName]usageJSON, len(usage)),
+	}
+	for id, iu := range usage {
+		iuJSON := usageJSON{
+			Keys:     iu.Keys,
+			Bytes:    iu.Bytes,
+			Versions: make(map[dvid.UUID]storage.UsageCounts, len(iu.Versions)),
+		}
+		for v, counts := range iu.Versions {
+			vuuid, err := m.uuidFromVersion(v)
+			if err != nil {
+				continue
+			}
+			iuJSON.Versions[vuuid] = counts
+		}
+		ru.Instances[names[id]] = iuJSON
+		ru.Keys += iu.Keys
+		ru.Bytes += iu.Bytes
+		ru.Counting = ru.Counting || iu.Counting
+	}
+	m.quotas.RLock()
+	quota, found := m.quotas.byRoot[root]
+	m.quotas.RUnlock()
+	if found {
+		ru.SoftQuotaBytes = quota.softBytes()
+		ru.HardQuotaBytes = quota.hardBytes()
+		ru.SoftQuotaExceeded = ru.SoftQuotaBytes > 0 && ru.Bytes > ru.SoftQuotaBytes
+	}
+	jsonBytes, err := json.Marshal(ru)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
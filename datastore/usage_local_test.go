@@ -0,0 +1,85 @@
+// +build !clustered,!gcloud
+
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+func TestRepoQuota(t *testing.T) {
+	engines, backend, err := storage.GetTestableBackend(nil, nil)
+	if err != nil {
+		t.Fatalf("can't get testable backend: %v\n", err)
+	}
+	backend.Usage = storage.UsageConfig{Track: true}
+	testStore.Lock()
+	testStore.engines, testStore.backend = engines, backend
+	openStores(false)
+	defer CloseTest()
+	defer storage.SetUsageLimiter(nil)
+
+	uuid, v := NewTestRepo()
+	id, err := manager.newInstanceID()
+	if err != nil {
+		t.Fatalf("can't get new instance id: %v\n", err)
+	}
+	data := &TestData{&Data{typename: "testtype", id: id, name: "quotadata", rootUUID: uuid}}
+	manager.idMutex.Lock()
+	manager.iids[id] = data
+	manager.idMutex.Unlock()
+	manager.repos[uuid].data[data.name] = data
+
+	// wait for initial usage counts of the new store.
+	for i := 0; i < 100; i++ {
+		usage, err := storage.GetInstanceUsage(id)
+		if err != nil {
+			t.Fatalf("can't get usage: %v\n", err)
+		}
+		if iu, found := usage[id]; !found || !iu.Counting {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	quotas := map[dvid.UUID]RepoQuota{
+		uuid:       {HardGB: 1000.0 / float64(dvid.Giga)},
+		"deadbeef": {HardGB: 1},
+	}
+	if err := manager.setQuotas(quotas); err != nil {
+		t.Fatalf("expected unknown repo in quotas to be skipped, got error: %v\n", err)
+	}
+
+	store, err := storage.DefaultKVDB()
+	if err != nil {
+		t.Fatalf("can't get default store: %v\n", err)
+	}
+	ctx := NewVersionedCtx(data, v)
+	tk1 := storage.NewTKey(23, []byte("a"))
+	tk2 := storage.NewTKey(23, []byte("b"))
+	if err := store.Put(ctx, tk1, make([]byte, 500)); err != nil {
+		t.Fatalf("expected write under quota to succeed: %v\n", err)
+	}
+	if err := store.Put(ctx, tk2, make([]byte, 600)); err == nil {
+		t.Fatalf("expected write over hard quota to fail\n")
+	}
+	if err := store.Delete(ctx, tk1); err != nil {
+		t.Fatalf("unable to delete: %v\n", err)
+	}
+	if err := store.Put(ctx, tk2, make([]byte, 600)); err != nil {
+		t.Fatalf("expected write under quota after delete to succeed: %v\n", err)
+	}
+
+	// quota checks use the repo resolved when quotas were set, not repo manager lookups.
+	manager.repoMutex.Lock()
+	manager.idMutex.Lock()
+	err = store.Put(ctx, tk1, make([]byte, 600))
+	manager.idMutex.Unlock()
+	manager.repoMutex.Unlock()
+	if err == nil {
+		t.Fatalf("expected write over hard quota to fail while repo locks are held\n")
+	}
+}
@@ -298,6 +298,100 @@ The "Tags" property will be indexed and so can be costly if used for very large
 The "Prop" property is an arbitrary object with string values.  The "Prop" object's key are not indexed.
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Appends to list of data instances with which the annotations are synced.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/label/{label}",
+		Summary:    "Returns all point annotations within the given label as an array of elements.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label in a synced label data instance."}},
+		Query: []datastore.RouteParam{
+			{Name: "relationships", Type: "boolean", Description: "If true, return all relationships for each annotation."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/tag/{tag}",
+		Summary:    "Returns all point annotations with the given tag as an array of elements.",
+		PathParams: []datastore.RouteParam{{Name: "tag", Description: "A tag of point annotations."}},
+		Query: []datastore.RouteParam{
+			{Name: "relationships", Type: "boolean", Description: "If true, return all relationships for each annotation."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "DELETE",
+		Path:       "/element/{coord}",
+		Summary:    "Deletes a point annotation given its location.",
+		PathParams: []datastore.RouteParam{{Name: "coord", Description: "Coordinate of the point annotation, e.g., \"23_41_52\"."}},
+		Query: []datastore.RouteParam{
+			{Name: "kafkalog", Description: "Set to \"off\" if the mutation should not be logged to kafka."},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/roi/{roi}",
+		Summary: "Returns all point annotations within the ROI as an array of elements.",
+		PathParams: []datastore.RouteParam{
+			{Name: "roi", Description: "ROI specification of the form \"roiname,uuid\" or \"roiname\" for the request's UUID."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/elements/{size}/{offset}",
+		Summary: "Returns all point annotations within the subvolume of given size and offset.",
+		PathParams: []datastore.RouteParam{
+			{Name: "size", Description: "Size in voxels, e.g., \"400_300_200\"."},
+			{Name: "offset", Description: "Voxel coordinate of the subvolume's upper left corner, e.g., \"400_300_200\"."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/elements",
+		Summary: "Adds or modifies point annotations given in the request body as an array of elements.",
+		Query: []datastore.RouteParam{
+			{Name: "kafkalog", Description: "Set to \"off\" if the mutation should not be logged to kafka."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/blocks/{size}/{offset}",
+		Summary: "Returns all point annotations within blocks intersecting the subvolume, keyed by block coordinate.",
+		PathParams: []datastore.RouteParam{
+			{Name: "size", Description: "Size in voxels, e.g., \"400_300_200\"."},
+			{Name: "offset", Description: "Voxel coordinate of the subvolume's upper left corner, e.g., \"400_300_200\"."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/move/{from_coord}/{to_coord}",
+		Summary: "Moves the point annotation at one coordinate to another.",
+		PathParams: []datastore.RouteParam{
+			{Name: "from_coord", Description: "Current coordinate of the point annotation, e.g., \"23_41_52\"."},
+			{Name: "to_coord", Description: "New coordinate of the point annotation, e.g., \"24_41_52\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "kafkalog", Description: "Set to \"off\" if the mutation should not be logged to kafka."},
+		},
+	},
+}
+
 var (
 	dtype *Type
 
@@ -320,6 +414,7 @@ func init() {
 	// See doc for package on why channels are segregated instead of interleaved.
 	// Data types must be registered with the datastore to be used.
 	datastore.Register(dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
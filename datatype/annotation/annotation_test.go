@@ -1609,6 +1609,27 @@ func TestMappedLabels(t *testing.T) {
 	testMappedLabels(t, uuid, "mylabelmap", "mylabelmap")
 }
 
+func TestSyncedAgglomerate(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, _ := initTestRepo()
+	var config dvid.Config
+	config.Set("BlockSize", "32,32,32")
+	server.CreateTestInstance(t, uuid, "labelmap", "mylabelmap", config)
+	_ = createLabelTestVolume(t, uuid, "mylabelmap")
+
+	// agglomeration can't be described to synced annotations, so it's refused.
+	server.CreateTestInstance(t, uuid, "annotation", "mysynapses", config)
+	server.CreateTestSync(t, uuid, "mysynapses", "mylabelmap")
+	reqStr := fmt.Sprintf("%snode/%s/mylabelmap/agglomerate?threshold=0.5", server.WebAPIPath, uuid)
+	if _, err := server.TestHTTPError(t, "POST", reqStr, nil); err == nil {
+		t.Errorf("expected error agglomerating labelmap with synced annotations\n")
+	}
+}
+
 func TestSupervoxelSplit(t *testing.T) {
 	if err := server.OpenTest(); err != nil {
 		t.Fatalf("can't open test server: %v\n", err)
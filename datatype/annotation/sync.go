@@ -37,6 +37,30 @@ type DeltaSetElements struct {
 	Set []ElementPos
 }
 
+func describeElementLabels(e *datastore.ChangeEvent, elemSets ...[]ElementPos) {
+	lbls := make(labels.Set)
+	for _, elems := range elemSets {
+		for _, elem := range elems {
+			if elem.Label != 0 {
+				lbls[elem.Label] = struct{}{}
+			}
+		}
+	}
+	for label := range lbls {
+		e.Labels = append(e.Labels, label)
+	}
+}
+
+// DescribeChange adds the labels of added and deleted elements to a change feed event.
+func (delta DeltaModifyElements) DescribeChange(e *datastore.ChangeEvent) {
+	describeElementLabels(e, delta.Add, delta.Del)
+}
+
+// DescribeChange adds the labels of the set elements to a change feed event.
+func (delta DeltaSetElements) DescribeChange(e *datastore.ChangeEvent) {
+	describeElementLabels(e, delta.Set)
+}
+
 // Annotation number change event identifiers.
 const (
 	ModifyElementsEvent = "ANNOTATION_MOD_ELEMENTS"
@@ -246,7 +270,10 @@ func (d *Data) processEvents() {
 			}
 		case msg := <-d.syncCh:
 			ctx := datastore.NewVersionedCtx(d, msg.Version)
+			span := msg.StartSpan(d)
+			ctx.SetSpan(span)
 			d.handleSyncMessage(ctx, msg, batcher)
+			span.End()
 
 			if stop && len(d.syncCh) == 0 {
 				dvid.Infof("Shutting down sync even handler for instance %q after draining sync events.\n", d.DataName())
@@ -346,7 +373,7 @@ func (d *Data) handleSyncMessage(ctx *datastore.VersionedCtx, msg datastore.Sync
 		successful = false
 	}
 
-	if server.KafkaAvailable() {
+	if server.KafkaAvailable() || storage.EventSinksAvailable() {
 		t := time.Since(t0)
 		activity := map[string]interface{}{
 			"time":       t0.Unix(),
@@ -60,6 +60,7 @@ type Mutation struct {
 	v          dvid.VersionID
 	mutID      uint64
 	hiresCache BlockMap
+	span       *dvid.TraceSpan // trace span of the operation causing the mutation, if any
 
 	sync.RWMutex
 }
@@ -86,6 +87,15 @@ func (m *Mutation) MutationID() uint64 {
 	return m.mutID
 }
 
+// SetSpan sets the trace span of the operation causing the mutation so the computation
+// of lower-res scales is recorded within its trace.
+func (m *Mutation) SetSpan(span *dvid.TraceSpan) {
+	if m == nil {
+		return
+	}
+	m.span = span
+}
+
 // BlockMutated caches mutations at the highest resolution (scale 0)
 func (m *Mutation) BlockMutated(bcoord dvid.IZYXString, block interface{}) error {
 	if m.hiresCache == nil {
@@ -102,10 +112,23 @@ func (m *Mutation) Execute() error {
 	timedLog := dvid.NewTimeLog()
 	m.Lock()
 	bm := m.hiresCache
+	span := dvid.StartSpan(m.span, "downres")
+	span.SetAttribute("data", string(m.d.DataName()))
+	span.SetAttribute("mutation_id", m.mutID)
+	span.SetAttribute("blocks", len(bm))
+	span.SetAttribute("scales", int(m.d.GetMaxDownresLevel()))
+	defer span.End()
 	var err error
 	for scale := uint8(0); scale < m.d.GetMaxDownresLevel(); scale++ {
 		bm, err = m.d.StoreDownres(m.v, scale, bm)
 		if err != nil {
+			span.SetError(err)
+			// release the remaining scales so shutdown and syncs don't wait on them.
+			for s := scale + 1; s <= m.d.GetMaxDownresLevel(); s++ {
+				m.d.StopScaleUpdate(s)
+			}
+			m.hiresCache = nil
+			m.Unlock()
 			return fmt.Errorf("mutation %d for data %q: %v", m.mutID, m.d.DataName(), err)
 		}
 		m.d.StopScaleUpdate(scale + 1)
@@ -3,6 +3,7 @@ package labels
 import (
 	"fmt"
 
+	"github.com/janelia-flyem/dvid/datastore"
 	"github.com/janelia-flyem/dvid/datatype/common/proto"
 	"github.com/janelia-flyem/dvid/datatype/imageblk"
 	"github.com/janelia-flyem/dvid/dvid"
@@ -196,3 +197,65 @@ const (
 	SupervoxelSplitEvent      = "SV_SPLIT"
 	SupervoxelSplitEndEvent   = "SV_SPLIT_END"
 )
+
+// -- Change feed descriptions of label deltas.
+
+// DescribeChange adds the target and merged labels to a change feed event.
+func (op MergeOp) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, op.Target)
+	for label := range op.Merged {
+		e.Labels = append(e.Labels, label)
+	}
+}
+
+// DescribeChange adds the target and cleaved labels to a change feed event.
+func (op CleaveOp) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, op.Target, op.CleavedLabel)
+}
+
+// DescribeChange adds the split supervoxel and its new supervoxels to a change feed event.
+func (op SplitSupervoxelOp) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, op.Supervoxel, op.SplitSupervoxel, op.RemainSupervoxel)
+}
+
+// DescribeChange adds the old and new labels to a change feed event.
+func (d DeltaSplit) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, d.OldLabel, d.NewLabel)
+}
+
+// DescribeChange adds the old and new labels to a change feed event.
+func (d DeltaSplitStart) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, d.OldLabel, d.NewLabel)
+}
+
+// DescribeChange adds the old and new labels to a change feed event.
+func (d DeltaSplitEnd) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, d.OldLabel, d.NewLabel)
+}
+
+// DescribeChange adds the modified label to a change feed event.
+func (d DeltaSparsevol) DescribeChange(e *datastore.ChangeEvent) {
+	e.Labels = append(e.Labels, d.Label)
+}
+
+// DescribeBlockChange adds the block coordinate and the labels within the given
+// blocks to a change feed event.  Nil blocks are ignored.
+func DescribeBlockChange(e *datastore.ChangeEvent, bcoord dvid.IZYXString, blocks ...*Block) {
+	if x, y, z, err := bcoord.Unpack(); err == nil {
+		e.Block = fmt.Sprintf("%d,%d,%d", x, y, z)
+	}
+	lbls := make(Set)
+	for _, block := range blocks {
+		if block == nil {
+			continue
+		}
+		for _, label := range block.Labels {
+			if label != 0 {
+				lbls[label] = struct{}{}
+			}
+		}
+	}
+	for label := range lbls {
+		e.Labels = append(e.Labels, label)
+	}
+}
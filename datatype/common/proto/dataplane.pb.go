@@ -0,0 +1,5605 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: dataplane.proto
+
+package proto
+
+import (
+	bytes "bytes"
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type Block struct {
+	X    int32  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y    int32  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Z    int32  `protobuf:"varint,3,opt,name=z,proto3" json:"z,omitempty"`
+	Data []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *Block) Reset()      { *m = Block{} }
+func (*Block) ProtoMessage() {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{0}
+}
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(m, src)
+}
+func (m *Block) XXX_Size() int {
+	return m.Size()
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetX() int32 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *Block) GetY() int32 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+func (m *Block) GetZ() int32 {
+	if m != nil {
+		return m.Z
+	}
+	return 0
+}
+
+func (m *Block) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// Blocks can be requested as a list of block coordinates or an inclusive range
+// of block coordinates, which can hold at most 1,048,576 blocks.  Missing labelmap
+// blocks are not sent.
+type GetBlocksRequest struct {
+	Uuid        string  `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname    string  `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Scale       uint32  `protobuf:"varint,3,opt,name=scale,proto3" json:"scale,omitempty"`
+	Supervoxels bool    `protobuf:"varint,4,opt,name=supervoxels,proto3" json:"supervoxels,omitempty"`
+	Compression string  `protobuf:"bytes,5,opt,name=compression,proto3" json:"compression,omitempty"`
+	Blocks      []int32 `protobuf:"varint,6,rep,packed,name=blocks,proto3" json:"blocks,omitempty"`
+	MinBlock    []int32 `protobuf:"varint,7,rep,packed,name=min_block,json=minBlock,proto3" json:"min_block,omitempty"`
+	MaxBlock    []int32 `protobuf:"varint,8,rep,packed,name=max_block,json=maxBlock,proto3" json:"max_block,omitempty"`
+}
+
+func (m *GetBlocksRequest) Reset()      { *m = GetBlocksRequest{} }
+func (*GetBlocksRequest) ProtoMessage() {}
+func (*GetBlocksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{1}
+}
+func (m *GetBlocksRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetBlocksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetBlocksRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetBlocksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlocksRequest.Merge(m, src)
+}
+func (m *GetBlocksRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetBlocksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlocksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlocksRequest proto.InternalMessageInfo
+
+func (m *GetBlocksRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *GetBlocksRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *GetBlocksRequest) GetScale() uint32 {
+	if m != nil {
+		return m.Scale
+	}
+	return 0
+}
+
+func (m *GetBlocksRequest) GetSupervoxels() bool {
+	if m != nil {
+		return m.Supervoxels
+	}
+	return false
+}
+
+func (m *GetBlocksRequest) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
+func (m *GetBlocksRequest) GetBlocks() []int32 {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *GetBlocksRequest) GetMinBlock() []int32 {
+	if m != nil {
+		return m.MinBlock
+	}
+	return nil
+}
+
+func (m *GetBlocksRequest) GetMaxBlock() []int32 {
+	if m != nil {
+		return m.MaxBlock
+	}
+	return nil
+}
+
+// The instance and options are taken from the first message of the stream.
+type PutBlocksRequest struct {
+	Uuid      string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname  string `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Scale     uint32 `protobuf:"varint,3,opt,name=scale,proto3" json:"scale,omitempty"`
+	Downscale bool   `protobuf:"varint,4,opt,name=downscale,proto3" json:"downscale,omitempty"`
+	Mutate    bool   `protobuf:"varint,5,opt,name=mutate,proto3" json:"mutate,omitempty"`
+	User      string `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	App       string `protobuf:"bytes,7,opt,name=app,proto3" json:"app,omitempty"`
+	Block     *Block `protobuf:"bytes,8,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+func (m *PutBlocksRequest) Reset()      { *m = PutBlocksRequest{} }
+func (*PutBlocksRequest) ProtoMessage() {}
+func (*PutBlocksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{2}
+}
+func (m *PutBlocksRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PutBlocksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PutBlocksRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PutBlocksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PutBlocksRequest.Merge(m, src)
+}
+func (m *PutBlocksRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *PutBlocksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PutBlocksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PutBlocksRequest proto.InternalMessageInfo
+
+func (m *PutBlocksRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *PutBlocksRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *PutBlocksRequest) GetScale() uint32 {
+	if m != nil {
+		return m.Scale
+	}
+	return 0
+}
+
+func (m *PutBlocksRequest) GetDownscale() bool {
+	if m != nil {
+		return m.Downscale
+	}
+	return false
+}
+
+func (m *PutBlocksRequest) GetMutate() bool {
+	if m != nil {
+		return m.Mutate
+	}
+	return false
+}
+
+func (m *PutBlocksRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *PutBlocksRequest) GetApp() string {
+	if m != nil {
+		return m.App
+	}
+	return ""
+}
+
+func (m *PutBlocksRequest) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+type PutBlocksResponse struct {
+	NumBlocks uint64 `protobuf:"varint,1,opt,name=num_blocks,json=numBlocks,proto3" json:"num_blocks,omitempty"`
+}
+
+func (m *PutBlocksResponse) Reset()      { *m = PutBlocksResponse{} }
+func (*PutBlocksResponse) ProtoMessage() {}
+func (*PutBlocksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{3}
+}
+func (m *PutBlocksResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PutBlocksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PutBlocksResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PutBlocksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PutBlocksResponse.Merge(m, src)
+}
+func (m *PutBlocksResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *PutBlocksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PutBlocksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PutBlocksResponse proto.InternalMessageInfo
+
+func (m *PutBlocksResponse) GetNumBlocks() uint64 {
+	if m != nil {
+		return m.NumBlocks
+	}
+	return 0
+}
+
+// The subvolume's little-endian voxel values are streamed in ZYX order as slabs of
+// the subvolume along z are read.
+type SubvolumeRequest struct {
+	Uuid        string  `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname    string  `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Offset      []int32 `protobuf:"varint,3,rep,packed,name=offset,proto3" json:"offset,omitempty"`
+	Size_       []int32 `protobuf:"varint,4,rep,packed,name=size,proto3" json:"size,omitempty"`
+	Scale       uint32  `protobuf:"varint,5,opt,name=scale,proto3" json:"scale,omitempty"`
+	Supervoxels bool    `protobuf:"varint,6,opt,name=supervoxels,proto3" json:"supervoxels,omitempty"`
+	ChunkBytes  uint32  `protobuf:"varint,7,opt,name=chunk_bytes,json=chunkBytes,proto3" json:"chunk_bytes,omitempty"`
+}
+
+func (m *SubvolumeRequest) Reset()      { *m = SubvolumeRequest{} }
+func (*SubvolumeRequest) ProtoMessage() {}
+func (*SubvolumeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{4}
+}
+func (m *SubvolumeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubvolumeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubvolumeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SubvolumeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubvolumeRequest.Merge(m, src)
+}
+func (m *SubvolumeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubvolumeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubvolumeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubvolumeRequest proto.InternalMessageInfo
+
+func (m *SubvolumeRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *SubvolumeRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *SubvolumeRequest) GetOffset() []int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
+func (m *SubvolumeRequest) GetSize_() []int32 {
+	if m != nil {
+		return m.Size_
+	}
+	return nil
+}
+
+func (m *SubvolumeRequest) GetScale() uint32 {
+	if m != nil {
+		return m.Scale
+	}
+	return 0
+}
+
+func (m *SubvolumeRequest) GetSupervoxels() bool {
+	if m != nil {
+		return m.Supervoxels
+	}
+	return false
+}
+
+func (m *SubvolumeRequest) GetChunkBytes() uint32 {
+	if m != nil {
+		return m.ChunkBytes
+	}
+	return 0
+}
+
+type SparsevolRequest struct {
+	Uuid        string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname    string `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Label       uint64 `protobuf:"varint,3,opt,name=label,proto3" json:"label,omitempty"`
+	Scale       uint32 `protobuf:"varint,4,opt,name=scale,proto3" json:"scale,omitempty"`
+	Supervoxels bool   `protobuf:"varint,5,opt,name=supervoxels,proto3" json:"supervoxels,omitempty"`
+	Format      string `protobuf:"bytes,6,opt,name=format,proto3" json:"format,omitempty"`
+}
+
+func (m *SparsevolRequest) Reset()      { *m = SparsevolRequest{} }
+func (*SparsevolRequest) ProtoMessage() {}
+func (*SparsevolRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{5}
+}
+func (m *SparsevolRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SparsevolRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SparsevolRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SparsevolRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SparsevolRequest.Merge(m, src)
+}
+func (m *SparsevolRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SparsevolRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SparsevolRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SparsevolRequest proto.InternalMessageInfo
+
+func (m *SparsevolRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *SparsevolRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *SparsevolRequest) GetLabel() uint64 {
+	if m != nil {
+		return m.Label
+	}
+	return 0
+}
+
+func (m *SparsevolRequest) GetScale() uint32 {
+	if m != nil {
+		return m.Scale
+	}
+	return 0
+}
+
+func (m *SparsevolRequest) GetSupervoxels() bool {
+	if m != nil {
+		return m.Supervoxels
+	}
+	return false
+}
+
+func (m *SparsevolRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+// DataChunk is a piece of a larger response.  Concatenate the data of all chunks
+// in a stream to get the response.
+type DataChunk struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *DataChunk) Reset()      { *m = DataChunk{} }
+func (*DataChunk) ProtoMessage() {}
+func (*DataChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{6}
+}
+func (m *DataChunk) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DataChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DataChunk.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DataChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DataChunk.Merge(m, src)
+}
+func (m *DataChunk) XXX_Size() int {
+	return m.Size()
+}
+func (m *DataChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_DataChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DataChunk proto.InternalMessageInfo
+
+func (m *DataChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type MergeRequest struct {
+	Uuid     string   `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname string   `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Target   uint64   `protobuf:"varint,3,opt,name=target,proto3" json:"target,omitempty"`
+	Merged   []uint64 `protobuf:"varint,4,rep,packed,name=merged,proto3" json:"merged,omitempty"`
+	User     string   `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	App      string   `protobuf:"bytes,6,opt,name=app,proto3" json:"app,omitempty"`
+}
+
+func (m *MergeRequest) Reset()      { *m = MergeRequest{} }
+func (*MergeRequest) ProtoMessage() {}
+func (*MergeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{7}
+}
+func (m *MergeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MergeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MergeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MergeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MergeRequest.Merge(m, src)
+}
+func (m *MergeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *MergeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MergeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MergeRequest proto.InternalMessageInfo
+
+func (m *MergeRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *MergeRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *MergeRequest) GetTarget() uint64 {
+	if m != nil {
+		return m.Target
+	}
+	return 0
+}
+
+func (m *MergeRequest) GetMerged() []uint64 {
+	if m != nil {
+		return m.Merged
+	}
+	return nil
+}
+
+func (m *MergeRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *MergeRequest) GetApp() string {
+	if m != nil {
+		return m.App
+	}
+	return ""
+}
+
+type CleaveRequest struct {
+	Uuid        string   `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname    string   `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Target      uint64   `protobuf:"varint,3,opt,name=target,proto3" json:"target,omitempty"`
+	Supervoxels []uint64 `protobuf:"varint,4,rep,packed,name=supervoxels,proto3" json:"supervoxels,omitempty"`
+	User        string   `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	App         string   `protobuf:"bytes,6,opt,name=app,proto3" json:"app,omitempty"`
+}
+
+func (m *CleaveRequest) Reset()      { *m = CleaveRequest{} }
+func (*CleaveRequest) ProtoMessage() {}
+func (*CleaveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{8}
+}
+func (m *CleaveRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CleaveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CleaveRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CleaveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CleaveRequest.Merge(m, src)
+}
+func (m *CleaveRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CleaveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CleaveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CleaveRequest proto.InternalMessageInfo
+
+func (m *CleaveRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *CleaveRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *CleaveRequest) GetTarget() uint64 {
+	if m != nil {
+		return m.Target
+	}
+	return 0
+}
+
+func (m *CleaveRequest) GetSupervoxels() []uint64 {
+	if m != nil {
+		return m.Supervoxels
+	}
+	return nil
+}
+
+func (m *CleaveRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *CleaveRequest) GetApp() string {
+	if m != nil {
+		return m.App
+	}
+	return ""
+}
+
+type SplitRequest struct {
+	Uuid     string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Dataname string `protobuf:"bytes,2,opt,name=dataname,proto3" json:"dataname,omitempty"`
+	Label    uint64 `protobuf:"varint,3,opt,name=label,proto3" json:"label,omitempty"`
+	Rles     []byte `protobuf:"bytes,4,opt,name=rles,proto3" json:"rles,omitempty"`
+	User     string `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	App      string `protobuf:"bytes,6,opt,name=app,proto3" json:"app,omitempty"`
+}
+
+func (m *SplitRequest) Reset()      { *m = SplitRequest{} }
+func (*SplitRequest) ProtoMessage() {}
+func (*SplitRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{9}
+}
+func (m *SplitRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SplitRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SplitRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SplitRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SplitRequest.Merge(m, src)
+}
+func (m *SplitRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SplitRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SplitRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SplitRequest proto.InternalMessageInfo
+
+func (m *SplitRequest) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *SplitRequest) GetDataname() string {
+	if m != nil {
+		return m.Dataname
+	}
+	return ""
+}
+
+func (m *SplitRequest) GetLabel() uint64 {
+	if m != nil {
+		return m.Label
+	}
+	return 0
+}
+
+func (m *SplitRequest) GetRles() []byte {
+	if m != nil {
+		return m.Rles
+	}
+	return nil
+}
+
+func (m *SplitRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *SplitRequest) GetApp() string {
+	if m != nil {
+		return m.App
+	}
+	return ""
+}
+
+type MutationResponse struct {
+	Mutid uint64 `protobuf:"varint,1,opt,name=mutid,proto3" json:"mutid,omitempty"`
+	Label uint64 `protobuf:"varint,2,opt,name=label,proto3" json:"label,omitempty"`
+}
+
+func (m *MutationResponse) Reset()      { *m = MutationResponse{} }
+func (*MutationResponse) ProtoMessage() {}
+func (*MutationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_617387e490a04ffa, []int{10}
+}
+func (m *MutationResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MutationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MutationResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MutationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MutationResponse.Merge(m, src)
+}
+func (m *MutationResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *MutationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MutationResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MutationResponse proto.InternalMessageInfo
+
+func (m *MutationResponse) GetMutid() uint64 {
+	if m != nil {
+		return m.Mutid
+	}
+	return 0
+}
+
+func (m *MutationResponse) GetLabel() uint64 {
+	if m != nil {
+		return m.Label
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Block)(nil), "proto.Block")
+	proto.RegisterType((*GetBlocksRequest)(nil), "proto.GetBlocksRequest")
+	proto.RegisterType((*PutBlocksRequest)(nil), "proto.PutBlocksRequest")
+	proto.RegisterType((*PutBlocksResponse)(nil), "proto.PutBlocksResponse")
+	proto.RegisterType((*SubvolumeRequest)(nil), "proto.SubvolumeRequest")
+	proto.RegisterType((*SparsevolRequest)(nil), "proto.SparsevolRequest")
+	proto.RegisterType((*DataChunk)(nil), "proto.DataChunk")
+	proto.RegisterType((*MergeRequest)(nil), "proto.MergeRequest")
+	proto.RegisterType((*CleaveRequest)(nil), "proto.CleaveRequest")
+	proto.RegisterType((*SplitRequest)(nil), "proto.SplitRequest")
+	proto.RegisterType((*MutationResponse)(nil), "proto.MutationResponse")
+}
+
+func init() { proto.RegisterFile("dataplane.proto", fileDescriptor_617387e490a04ffa) }
+
+var fileDescriptor_617387e490a04ffa = []byte{
+	// 742 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x4f, 0x4f, 0x13, 0x41,
+	0x14, 0xef, 0xd0, 0xdd, 0xa5, 0xfb, 0x28, 0xb1, 0x8e, 0x04, 0x37, 0x55, 0x97, 0x66, 0x4f, 0x3d,
+	0x11, 0x82, 0x10, 0x0f, 0x26, 0xc6, 0x80, 0x86, 0x13, 0x09, 0x19, 0x3e, 0x00, 0x99, 0xb6, 0x03,
+	0x36, 0xec, 0x3f, 0x77, 0x66, 0x6b, 0xe1, 0xe4, 0x27, 0x30, 0x1e, 0xbc, 0x7b, 0xf1, 0xc0, 0x37,
+	0xd1, 0x23, 0x47, 0x8e, 0x52, 0x2e, 0x9e, 0x0c, 0x1f, 0xc1, 0xcc, 0x9f, 0x6e, 0x4b, 0x2b, 0x84,
+	0x08, 0x9e, 0x3a, 0xbf, 0xf7, 0xf6, 0xfd, 0xff, 0xbd, 0x57, 0x78, 0xd0, 0xa1, 0x82, 0xa6, 0x21,
+	0x8d, 0xd9, 0x72, 0x9a, 0x25, 0x22, 0xc1, 0xb6, 0xfa, 0x09, 0xde, 0x82, 0xbd, 0x11, 0x26, 0xed,
+	0x43, 0x5c, 0x05, 0xd4, 0xf7, 0x50, 0x03, 0x35, 0x6d, 0x82, 0xfa, 0x12, 0x1d, 0x79, 0x33, 0x1a,
+	0x1d, 0x49, 0x74, 0xec, 0x95, 0x35, 0x3a, 0xc6, 0x18, 0x2c, 0xe9, 0xcc, 0xb3, 0x1a, 0xa8, 0x59,
+	0x25, 0xea, 0x1d, 0xfc, 0x46, 0x50, 0xdb, 0x62, 0x42, 0xb9, 0xe2, 0x84, 0xbd, 0xcf, 0x19, 0x17,
+	0xf2, 0xc3, 0x3c, 0xef, 0x76, 0x94, 0x57, 0x97, 0xa8, 0x37, 0xae, 0x43, 0x45, 0x1a, 0xc4, 0x34,
+	0x62, 0xca, 0xbf, 0x4b, 0x0a, 0x8c, 0x17, 0xc0, 0xe6, 0x6d, 0x1a, 0x32, 0x15, 0x6a, 0x9e, 0x68,
+	0x80, 0x1b, 0x30, 0xc7, 0xf3, 0x94, 0x65, 0xbd, 0xa4, 0xcf, 0x42, 0xae, 0xa2, 0x56, 0xc8, 0xb8,
+	0x48, 0x7e, 0xd1, 0x4e, 0xa2, 0x34, 0x63, 0x9c, 0x77, 0x93, 0xd8, 0xb3, 0x95, 0xdb, 0x71, 0x11,
+	0x5e, 0x04, 0xa7, 0xa5, 0x52, 0xf3, 0x9c, 0x46, 0xb9, 0x69, 0x13, 0x83, 0xf0, 0x13, 0x70, 0xa3,
+	0x6e, 0xbc, 0xa7, 0x90, 0x37, 0xab, 0x54, 0x95, 0xa8, 0x1b, 0xeb, 0x8e, 0x48, 0x25, 0xed, 0x1b,
+	0x65, 0xc5, 0x28, 0x69, 0x5f, 0x29, 0x83, 0x33, 0x04, 0xb5, 0x9d, 0xfc, 0xbf, 0x14, 0xfc, 0x14,
+	0xdc, 0x4e, 0xf2, 0x21, 0xd6, 0x1a, 0x5d, 0xee, 0x48, 0x20, 0x4b, 0x89, 0x72, 0x41, 0x05, 0x53,
+	0x75, 0x56, 0x88, 0x41, 0x2a, 0x36, 0x67, 0x99, 0xe7, 0x98, 0xd8, 0x9c, 0x65, 0xb8, 0x06, 0x65,
+	0x9a, 0xa6, 0xde, 0xac, 0x12, 0xc9, 0x27, 0x0e, 0xc0, 0x1e, 0xd6, 0x83, 0x9a, 0x73, 0xab, 0x55,
+	0x4d, 0x86, 0x65, 0x55, 0x06, 0xd1, 0xaa, 0x60, 0x15, 0x1e, 0x8e, 0x55, 0xc6, 0xd3, 0x24, 0xe6,
+	0x0c, 0x3f, 0x03, 0x88, 0xf3, 0x68, 0xcf, 0x74, 0x51, 0x16, 0x68, 0x11, 0x37, 0xce, 0x23, 0xfd,
+	0x59, 0xf0, 0x1d, 0x41, 0x6d, 0x37, 0x6f, 0xf5, 0x92, 0x30, 0x8f, 0xd8, 0xbf, 0xb6, 0x63, 0x11,
+	0x9c, 0x64, 0x7f, 0x9f, 0x33, 0xe1, 0x95, 0xf5, 0x94, 0x34, 0x92, 0x7e, 0x78, 0xf7, 0x58, 0xf6,
+	0x42, 0x4a, 0xd5, 0x7b, 0xd4, 0x3a, 0xfb, 0x06, 0xae, 0x38, 0xd3, 0x5c, 0x59, 0x82, 0xb9, 0xf6,
+	0xbb, 0x3c, 0x3e, 0xdc, 0x6b, 0x1d, 0x09, 0xc6, 0x55, 0x6b, 0xe6, 0x09, 0x28, 0xd1, 0x86, 0x94,
+	0x04, 0x27, 0xb2, 0x92, 0x94, 0x66, 0x9c, 0xf5, 0x92, 0xf0, 0x0e, 0x83, 0x0d, 0x69, 0x8b, 0x85,
+	0x6a, 0xb0, 0x16, 0xd1, 0x60, 0x94, 0xb3, 0x75, 0x43, 0xce, 0xf6, 0x74, 0xce, 0x8b, 0xe0, 0xec,
+	0x27, 0x59, 0x44, 0x85, 0x19, 0xae, 0x41, 0xc1, 0x12, 0xb8, 0x6f, 0xa8, 0xa0, 0x9b, 0x32, 0xf9,
+	0x62, 0x2b, 0xd1, 0xd8, 0x56, 0x7e, 0x41, 0x50, 0xdd, 0x66, 0xd9, 0xc1, 0x5d, 0x26, 0x22, 0x68,
+	0x76, 0xc0, 0x84, 0x29, 0xc4, 0x20, 0x45, 0x42, 0xe9, 0xb7, 0xa3, 0x66, 0x62, 0x11, 0x83, 0x0a,
+	0x12, 0xda, 0xd3, 0x24, 0x74, 0x0a, 0x12, 0x06, 0x5f, 0x11, 0xcc, 0x6f, 0x86, 0x8c, 0xf6, 0xee,
+	0x3d, 0xaf, 0xa9, 0x5b, 0x21, 0x93, 0xbb, 0xd2, 0xcb, 0xdb, 0x65, 0xf8, 0x09, 0x41, 0x75, 0x37,
+	0x0d, 0xbb, 0xe2, 0x7e, 0x09, 0x80, 0xc1, 0xca, 0x42, 0xc6, 0x87, 0x97, 0x53, 0xbe, 0x6f, 0x99,
+	0xd0, 0x2b, 0xa8, 0x6d, 0xcb, 0x3d, 0xef, 0x26, 0x71, 0xb1, 0x92, 0x0b, 0x60, 0x47, 0xb9, 0x30,
+	0x49, 0x59, 0x44, 0x83, 0x51, 0xe4, 0x99, 0xb1, 0xc8, 0xab, 0xdf, 0xca, 0x9a, 0x2b, 0x3b, 0xf2,
+	0x1f, 0x00, 0xaf, 0x81, 0x5b, 0x1c, 0x6b, 0xfc, 0xd8, 0xdc, 0x80, 0xc9, 0xf3, 0x5d, 0xbf, 0x72,
+	0x1c, 0x56, 0x10, 0x7e, 0x0d, 0xee, 0x4e, 0x3e, 0x69, 0x35, 0x79, 0x03, 0xeb, 0xde, 0xb4, 0x42,
+	0xe7, 0xdb, 0x44, 0xf8, 0x25, 0x54, 0xb7, 0x98, 0x28, 0xee, 0x44, 0xe1, 0x64, 0xf2, 0x72, 0xd4,
+	0x6b, 0x46, 0x51, 0xd0, 0x7b, 0xa5, 0x30, 0x1e, 0xae, 0xe6, 0xc8, 0x78, 0x62, 0x59, 0xff, 0x6a,
+	0xbc, 0x0e, 0xb6, 0x5a, 0x04, 0xfc, 0xc8, 0x28, 0xc7, 0xd7, 0xa2, 0x3e, 0x74, 0x35, 0xd5, 0xe2,
+	0x17, 0xe0, 0x68, 0xa2, 0xe2, 0x05, 0xf3, 0xc9, 0x15, 0xde, 0x5e, 0x6f, 0xb8, 0x0e, 0xb6, 0xe2,
+	0x4f, 0x11, 0x6f, 0x9c, 0x4d, 0xd7, 0x9a, 0x6d, 0xac, 0x9d, 0x9e, 0xfb, 0xa5, 0xb3, 0x73, 0xbf,
+	0x74, 0x79, 0xee, 0xa3, 0x8f, 0x03, 0x1f, 0x9d, 0x0c, 0x7c, 0xf4, 0x63, 0xe0, 0xa3, 0xd3, 0x81,
+	0x8f, 0x7e, 0x0e, 0x7c, 0xf4, 0x6b, 0xe0, 0x97, 0x2e, 0x07, 0x3e, 0xfa, 0x7c, 0xe1, 0x97, 0x4e,
+	0x2f, 0xfc, 0xd2, 0xd9, 0x85, 0x5f, 0x6a, 0x39, 0xca, 0xdb, 0xf3, 0x3f, 0x03, 0x00, 0x0c, 0x37,
+	0xe6, 0x70, 0xe4, 0x07, 0x00, 0x00,
+}
+
+func (this *Block) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Block)
+	if !ok {
+		that2, ok := that.(Block)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.X != that1.X {
+		return false
+	}
+	if this.Y != that1.Y {
+		return false
+	}
+	if this.Z != that1.Z {
+		return false
+	}
+	if !bytes.Equal(this.Data, that1.Data) {
+		return false
+	}
+	return true
+}
+func (this *GetBlocksRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*GetBlocksRequest)
+	if !ok {
+		that2, ok := that.(GetBlocksRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if this.Scale != that1.Scale {
+		return false
+	}
+	if this.Supervoxels != that1.Supervoxels {
+		return false
+	}
+	if this.Compression != that1.Compression {
+		return false
+	}
+	if len(this.Blocks) != len(that1.Blocks) {
+		return false
+	}
+	for i := range this.Blocks {
+		if this.Blocks[i] != that1.Blocks[i] {
+			return false
+		}
+	}
+	if len(this.MinBlock) != len(that1.MinBlock) {
+		return false
+	}
+	for i := range this.MinBlock {
+		if this.MinBlock[i] != that1.MinBlock[i] {
+			return false
+		}
+	}
+	if len(this.MaxBlock) != len(that1.MaxBlock) {
+		return false
+	}
+	for i := range this.MaxBlock {
+		if this.MaxBlock[i] != that1.MaxBlock[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *PutBlocksRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*PutBlocksRequest)
+	if !ok {
+		that2, ok := that.(PutBlocksRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if this.Scale != that1.Scale {
+		return false
+	}
+	if this.Downscale != that1.Downscale {
+		return false
+	}
+	if this.Mutate != that1.Mutate {
+		return false
+	}
+	if this.User != that1.User {
+		return false
+	}
+	if this.App != that1.App {
+		return false
+	}
+	if !this.Block.Equal(that1.Block) {
+		return false
+	}
+	return true
+}
+func (this *PutBlocksResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*PutBlocksResponse)
+	if !ok {
+		that2, ok := that.(PutBlocksResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.NumBlocks != that1.NumBlocks {
+		return false
+	}
+	return true
+}
+func (this *SubvolumeRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*SubvolumeRequest)
+	if !ok {
+		that2, ok := that.(SubvolumeRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if len(this.Offset) != len(that1.Offset) {
+		return false
+	}
+	for i := range this.Offset {
+		if this.Offset[i] != that1.Offset[i] {
+			return false
+		}
+	}
+	if len(this.Size_) != len(that1.Size_) {
+		return false
+	}
+	for i := range this.Size_ {
+		if this.Size_[i] != that1.Size_[i] {
+			return false
+		}
+	}
+	if this.Scale != that1.Scale {
+		return false
+	}
+	if this.Supervoxels != that1.Supervoxels {
+		return false
+	}
+	if this.ChunkBytes != that1.ChunkBytes {
+		return false
+	}
+	return true
+}
+func (this *SparsevolRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*SparsevolRequest)
+	if !ok {
+		that2, ok := that.(SparsevolRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if this.Label != that1.Label {
+		return false
+	}
+	if this.Scale != that1.Scale {
+		return false
+	}
+	if this.Supervoxels != that1.Supervoxels {
+		return false
+	}
+	if this.Format != that1.Format {
+		return false
+	}
+	return true
+}
+func (this *DataChunk) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DataChunk)
+	if !ok {
+		that2, ok := that.(DataChunk)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Data, that1.Data) {
+		return false
+	}
+	return true
+}
+func (this *MergeRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MergeRequest)
+	if !ok {
+		that2, ok := that.(MergeRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if this.Target != that1.Target {
+		return false
+	}
+	if len(this.Merged) != len(that1.Merged) {
+		return false
+	}
+	for i := range this.Merged {
+		if this.Merged[i] != that1.Merged[i] {
+			return false
+		}
+	}
+	if this.User != that1.User {
+		return false
+	}
+	if this.App != that1.App {
+		return false
+	}
+	return true
+}
+func (this *CleaveRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*CleaveRequest)
+	if !ok {
+		that2, ok := that.(CleaveRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if this.Target != that1.Target {
+		return false
+	}
+	if len(this.Supervoxels) != len(that1.Supervoxels) {
+		return false
+	}
+	for i := range this.Supervoxels {
+		if this.Supervoxels[i] != that1.Supervoxels[i] {
+			return false
+		}
+	}
+	if this.User != that1.User {
+		return false
+	}
+	if this.App != that1.App {
+		return false
+	}
+	return true
+}
+func (this *SplitRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*SplitRequest)
+	if !ok {
+		that2, ok := that.(SplitRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Uuid != that1.Uuid {
+		return false
+	}
+	if this.Dataname != that1.Dataname {
+		return false
+	}
+	if this.Label != that1.Label {
+		return false
+	}
+	if !bytes.Equal(this.Rles, that1.Rles) {
+		return false
+	}
+	if this.User != that1.User {
+		return false
+	}
+	if this.App != that1.App {
+		return false
+	}
+	return true
+}
+func (this *MutationResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MutationResponse)
+	if !ok {
+		that2, ok := that.(MutationResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Mutid != that1.Mutid {
+		return false
+	}
+	if this.Label != that1.Label {
+		return false
+	}
+	return true
+}
+func (this *Block) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&proto.Block{")
+	s = append(s, "X: "+fmt.Sprintf("%#v", this.X)+",\n")
+	s = append(s, "Y: "+fmt.Sprintf("%#v", this.Y)+",\n")
+	s = append(s, "Z: "+fmt.Sprintf("%#v", this.Z)+",\n")
+	s = append(s, "Data: "+fmt.Sprintf("%#v", this.Data)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *GetBlocksRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&proto.GetBlocksRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Scale: "+fmt.Sprintf("%#v", this.Scale)+",\n")
+	s = append(s, "Supervoxels: "+fmt.Sprintf("%#v", this.Supervoxels)+",\n")
+	s = append(s, "Compression: "+fmt.Sprintf("%#v", this.Compression)+",\n")
+	s = append(s, "Blocks: "+fmt.Sprintf("%#v", this.Blocks)+",\n")
+	s = append(s, "MinBlock: "+fmt.Sprintf("%#v", this.MinBlock)+",\n")
+	s = append(s, "MaxBlock: "+fmt.Sprintf("%#v", this.MaxBlock)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *PutBlocksRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&proto.PutBlocksRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Scale: "+fmt.Sprintf("%#v", this.Scale)+",\n")
+	s = append(s, "Downscale: "+fmt.Sprintf("%#v", this.Downscale)+",\n")
+	s = append(s, "Mutate: "+fmt.Sprintf("%#v", this.Mutate)+",\n")
+	s = append(s, "User: "+fmt.Sprintf("%#v", this.User)+",\n")
+	s = append(s, "App: "+fmt.Sprintf("%#v", this.App)+",\n")
+	if this.Block != nil {
+		s = append(s, "Block: "+fmt.Sprintf("%#v", this.Block)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *PutBlocksResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&proto.PutBlocksResponse{")
+	s = append(s, "NumBlocks: "+fmt.Sprintf("%#v", this.NumBlocks)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *SubvolumeRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 11)
+	s = append(s, "&proto.SubvolumeRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Offset: "+fmt.Sprintf("%#v", this.Offset)+",\n")
+	s = append(s, "Size_: "+fmt.Sprintf("%#v", this.Size_)+",\n")
+	s = append(s, "Scale: "+fmt.Sprintf("%#v", this.Scale)+",\n")
+	s = append(s, "Supervoxels: "+fmt.Sprintf("%#v", this.Supervoxels)+",\n")
+	s = append(s, "ChunkBytes: "+fmt.Sprintf("%#v", this.ChunkBytes)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *SparsevolRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&proto.SparsevolRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Label: "+fmt.Sprintf("%#v", this.Label)+",\n")
+	s = append(s, "Scale: "+fmt.Sprintf("%#v", this.Scale)+",\n")
+	s = append(s, "Supervoxels: "+fmt.Sprintf("%#v", this.Supervoxels)+",\n")
+	s = append(s, "Format: "+fmt.Sprintf("%#v", this.Format)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DataChunk) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&proto.DataChunk{")
+	s = append(s, "Data: "+fmt.Sprintf("%#v", this.Data)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MergeRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&proto.MergeRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Target: "+fmt.Sprintf("%#v", this.Target)+",\n")
+	s = append(s, "Merged: "+fmt.Sprintf("%#v", this.Merged)+",\n")
+	s = append(s, "User: "+fmt.Sprintf("%#v", this.User)+",\n")
+	s = append(s, "App: "+fmt.Sprintf("%#v", this.App)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *CleaveRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&proto.CleaveRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Target: "+fmt.Sprintf("%#v", this.Target)+",\n")
+	s = append(s, "Supervoxels: "+fmt.Sprintf("%#v", this.Supervoxels)+",\n")
+	s = append(s, "User: "+fmt.Sprintf("%#v", this.User)+",\n")
+	s = append(s, "App: "+fmt.Sprintf("%#v", this.App)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *SplitRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&proto.SplitRequest{")
+	s = append(s, "Uuid: "+fmt.Sprintf("%#v", this.Uuid)+",\n")
+	s = append(s, "Dataname: "+fmt.Sprintf("%#v", this.Dataname)+",\n")
+	s = append(s, "Label: "+fmt.Sprintf("%#v", this.Label)+",\n")
+	s = append(s, "Rles: "+fmt.Sprintf("%#v", this.Rles)+",\n")
+	s = append(s, "User: "+fmt.Sprintf("%#v", this.User)+",\n")
+	s = append(s, "App: "+fmt.Sprintf("%#v", this.App)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MutationResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&proto.MutationResponse{")
+	s = append(s, "Mutid: "+fmt.Sprintf("%#v", this.Mutid)+",\n")
+	s = append(s, "Label: "+fmt.Sprintf("%#v", this.Label)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringDataplane(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DataPlaneClient is the client API for DataPlane service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DataPlaneClient interface {
+	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (DataPlane_GetBlocksClient, error)
+	PutBlocks(ctx context.Context, opts ...grpc.CallOption) (DataPlane_PutBlocksClient, error)
+	GetSubvolume(ctx context.Context, in *SubvolumeRequest, opts ...grpc.CallOption) (DataPlane_GetSubvolumeClient, error)
+	GetSparsevol(ctx context.Context, in *SparsevolRequest, opts ...grpc.CallOption) (DataPlane_GetSparsevolClient, error)
+	Merge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*MutationResponse, error)
+	Cleave(ctx context.Context, in *CleaveRequest, opts ...grpc.CallOption) (*MutationResponse, error)
+	Split(ctx context.Context, in *SplitRequest, opts ...grpc.CallOption) (*MutationResponse, error)
+}
+
+type dataPlaneClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDataPlaneClient(cc *grpc.ClientConn) DataPlaneClient {
+	return &dataPlaneClient{cc}
+}
+
+func (c *dataPlaneClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (DataPlane_GetBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DataPlane_serviceDesc.Streams[0], "/proto.DataPlane/GetBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataPlaneGetBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataPlane_GetBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type dataPlaneGetBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataPlaneGetBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataPlaneClient) PutBlocks(ctx context.Context, opts ...grpc.CallOption) (DataPlane_PutBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DataPlane_serviceDesc.Streams[1], "/proto.DataPlane/PutBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataPlanePutBlocksClient{stream}
+	return x, nil
+}
+
+type DataPlane_PutBlocksClient interface {
+	Send(*PutBlocksRequest) error
+	CloseAndRecv() (*PutBlocksResponse, error)
+	grpc.ClientStream
+}
+
+type dataPlanePutBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataPlanePutBlocksClient) Send(m *PutBlocksRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dataPlanePutBlocksClient) CloseAndRecv() (*PutBlocksResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PutBlocksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataPlaneClient) GetSubvolume(ctx context.Context, in *SubvolumeRequest, opts ...grpc.CallOption) (DataPlane_GetSubvolumeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DataPlane_serviceDesc.Streams[2], "/proto.DataPlane/GetSubvolume", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataPlaneGetSubvolumeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataPlane_GetSubvolumeClient interface {
+	Recv() (*DataChunk, error)
+	grpc.ClientStream
+}
+
+type dataPlaneGetSubvolumeClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataPlaneGetSubvolumeClient) Recv() (*DataChunk, error) {
+	m := new(DataChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataPlaneClient) GetSparsevol(ctx context.Context, in *SparsevolRequest, opts ...grpc.CallOption) (DataPlane_GetSparsevolClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DataPlane_serviceDesc.Streams[3], "/proto.DataPlane/GetSparsevol", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataPlaneGetSparsevolClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataPlane_GetSparsevolClient interface {
+	Recv() (*DataChunk, error)
+	grpc.ClientStream
+}
+
+type dataPlaneGetSparsevolClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataPlaneGetSparsevolClient) Recv() (*DataChunk, error) {
+	m := new(DataChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataPlaneClient) Merge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*MutationResponse, error) {
+	out := new(MutationResponse)
+	err := c.cc.Invoke(ctx, "/proto.DataPlane/Merge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataPlaneClient) Cleave(ctx context.Context, in *CleaveRequest, opts ...grpc.CallOption) (*MutationResponse, error) {
+	out := new(MutationResponse)
+	err := c.cc.Invoke(ctx, "/proto.DataPlane/Cleave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataPlaneClient) Split(ctx context.Context, in *SplitRequest, opts ...grpc.CallOption) (*MutationResponse, error) {
+	out := new(MutationResponse)
+	err := c.cc.Invoke(ctx, "/proto.DataPlane/Split", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataPlaneServer is the server API for DataPlane service.
+type DataPlaneServer interface {
+	GetBlocks(*GetBlocksRequest, DataPlane_GetBlocksServer) error
+	PutBlocks(DataPlane_PutBlocksServer) error
+	GetSubvolume(*SubvolumeRequest, DataPlane_GetSubvolumeServer) error
+	GetSparsevol(*SparsevolRequest, DataPlane_GetSparsevolServer) error
+	Merge(context.Context, *MergeRequest) (*MutationResponse, error)
+	Cleave(context.Context, *CleaveRequest) (*MutationResponse, error)
+	Split(context.Context, *SplitRequest) (*MutationResponse, error)
+}
+
+// UnimplementedDataPlaneServer can be embedded to have forward compatible implementations.
+type UnimplementedDataPlaneServer struct {
+}
+
+func (*UnimplementedDataPlaneServer) GetBlocks(req *GetBlocksRequest, srv DataPlane_GetBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (*UnimplementedDataPlaneServer) PutBlocks(srv DataPlane_PutBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method PutBlocks not implemented")
+}
+func (*UnimplementedDataPlaneServer) GetSubvolume(req *SubvolumeRequest, srv DataPlane_GetSubvolumeServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetSubvolume not implemented")
+}
+func (*UnimplementedDataPlaneServer) GetSparsevol(req *SparsevolRequest, srv DataPlane_GetSparsevolServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetSparsevol not implemented")
+}
+func (*UnimplementedDataPlaneServer) Merge(ctx context.Context, req *MergeRequest) (*MutationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Merge not implemented")
+}
+func (*UnimplementedDataPlaneServer) Cleave(ctx context.Context, req *CleaveRequest) (*MutationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cleave not implemented")
+}
+func (*UnimplementedDataPlaneServer) Split(ctx context.Context, req *SplitRequest) (*MutationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Split not implemented")
+}
+
+func RegisterDataPlaneServer(s *grpc.Server, srv DataPlaneServer) {
+	s.RegisterService(&_DataPlane_serviceDesc, srv)
+}
+
+func _DataPlane_GetBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataPlaneServer).GetBlocks(m, &dataPlaneGetBlocksServer{stream})
+}
+
+type DataPlane_GetBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type dataPlaneGetBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataPlaneGetBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataPlane_PutBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DataPlaneServer).PutBlocks(&dataPlanePutBlocksServer{stream})
+}
+
+type DataPlane_PutBlocksServer interface {
+	SendAndClose(*PutBlocksResponse) error
+	Recv() (*PutBlocksRequest, error)
+	grpc.ServerStream
+}
+
+type dataPlanePutBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataPlanePutBlocksServer) SendAndClose(m *PutBlocksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dataPlanePutBlocksServer) Recv() (*PutBlocksRequest, error) {
+	m := new(PutBlocksRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DataPlane_GetSubvolume_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubvolumeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataPlaneServer).GetSubvolume(m, &dataPlaneGetSubvolumeServer{stream})
+}
+
+type DataPlane_GetSubvolumeServer interface {
+	Send(*DataChunk) error
+	grpc.ServerStream
+}
+
+type dataPlaneGetSubvolumeServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataPlaneGetSubvolumeServer) Send(m *DataChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataPlane_GetSparsevol_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SparsevolRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataPlaneServer).GetSparsevol(m, &dataPlaneGetSparsevolServer{stream})
+}
+
+type DataPlane_GetSparsevolServer interface {
+	Send(*DataChunk) error
+	grpc.ServerStream
+}
+
+type dataPlaneGetSparsevolServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataPlaneGetSparsevolServer) Send(m *DataChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataPlane_Merge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataPlaneServer).Merge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.DataPlane/Merge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataPlaneServer).Merge(ctx, req.(*MergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataPlane_Cleave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CleaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataPlaneServer).Cleave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.DataPlane/Cleave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataPlaneServer).Cleave(ctx, req.(*CleaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataPlane_Split_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SplitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataPlaneServer).Split(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.DataPlane/Split",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataPlaneServer).Split(ctx, req.(*SplitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DataPlane_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.DataPlane",
+	HandlerType: (*DataPlaneServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Merge",
+			Handler:    _DataPlane_Merge_Handler,
+		},
+		{
+			MethodName: "Cleave",
+			Handler:    _DataPlane_Cleave_Handler,
+		},
+		{
+			MethodName: "Split",
+			Handler:    _DataPlane_Split_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetBlocks",
+			Handler:       _DataPlane_GetBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PutBlocks",
+			Handler:       _DataPlane_PutBlocks_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetSubvolume",
+			Handler:       _DataPlane_GetSubvolume_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetSparsevol",
+			Handler:       _DataPlane_GetSparsevol_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dataplane.proto",
+}
+
+func (m *Block) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Block) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Block) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Z != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Z))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Y != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Y))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.X != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.X))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GetBlocksRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetBlocksRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetBlocksRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.MaxBlock) > 0 {
+		dAtA2 := make([]byte, len(m.MaxBlock)*10)
+		var j1 int
+		for _, num1 := range m.MaxBlock {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		i -= j1
+		copy(dAtA[i:], dAtA2[:j1])
+		i = encodeVarintDataplane(dAtA, i, uint64(j1))
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.MinBlock) > 0 {
+		dAtA4 := make([]byte, len(m.MinBlock)*10)
+		var j3 int
+		for _, num1 := range m.MinBlock {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA4[j3] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j3++
+			}
+			dAtA4[j3] = uint8(num)
+			j3++
+		}
+		i -= j3
+		copy(dAtA[i:], dAtA4[:j3])
+		i = encodeVarintDataplane(dAtA, i, uint64(j3))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.Blocks) > 0 {
+		dAtA6 := make([]byte, len(m.Blocks)*10)
+		var j5 int
+		for _, num1 := range m.Blocks {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA6[j5] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j5++
+			}
+			dAtA6[j5] = uint8(num)
+			j5++
+		}
+		i -= j5
+		copy(dAtA[i:], dAtA6[:j5])
+		i = encodeVarintDataplane(dAtA, i, uint64(j5))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Compression) > 0 {
+		i -= len(m.Compression)
+		copy(dAtA[i:], m.Compression)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Compression)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Supervoxels {
+		i--
+		if m.Supervoxels {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Scale != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Scale))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *PutBlocksRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PutBlocksRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PutBlocksRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Block != nil {
+		{
+			size, err := m.Block.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintDataplane(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.App) > 0 {
+		i -= len(m.App)
+		copy(dAtA[i:], m.App)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.App)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.User) > 0 {
+		i -= len(m.User)
+		copy(dAtA[i:], m.User)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.User)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Mutate {
+		i--
+		if m.Mutate {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Downscale {
+		i--
+		if m.Downscale {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Scale != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Scale))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *PutBlocksResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PutBlocksResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PutBlocksResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.NumBlocks != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.NumBlocks))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SubvolumeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubvolumeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SubvolumeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.ChunkBytes != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.ChunkBytes))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Supervoxels {
+		i--
+		if m.Supervoxels {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Scale != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Scale))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Size_) > 0 {
+		dAtA9 := make([]byte, len(m.Size_)*10)
+		var j8 int
+		for _, num1 := range m.Size_ {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA9[j8] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j8++
+			}
+			dAtA9[j8] = uint8(num)
+			j8++
+		}
+		i -= j8
+		copy(dAtA[i:], dAtA9[:j8])
+		i = encodeVarintDataplane(dAtA, i, uint64(j8))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Offset) > 0 {
+		dAtA11 := make([]byte, len(m.Offset)*10)
+		var j10 int
+		for _, num1 := range m.Offset {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA11[j10] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j10++
+			}
+			dAtA11[j10] = uint8(num)
+			j10++
+		}
+		i -= j10
+		copy(dAtA[i:], dAtA11[:j10])
+		i = encodeVarintDataplane(dAtA, i, uint64(j10))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SparsevolRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SparsevolRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SparsevolRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Format) > 0 {
+		i -= len(m.Format)
+		copy(dAtA[i:], m.Format)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Format)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Supervoxels {
+		i--
+		if m.Supervoxels {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Scale != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Scale))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Label != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Label))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DataChunk) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DataChunk) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DataChunk) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MergeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MergeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MergeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.App) > 0 {
+		i -= len(m.App)
+		copy(dAtA[i:], m.App)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.App)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.User) > 0 {
+		i -= len(m.User)
+		copy(dAtA[i:], m.User)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.User)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Merged) > 0 {
+		dAtA13 := make([]byte, len(m.Merged)*10)
+		var j12 int
+		for _, num := range m.Merged {
+			for num >= 1<<7 {
+				dAtA13[j12] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j12++
+			}
+			dAtA13[j12] = uint8(num)
+			j12++
+		}
+		i -= j12
+		copy(dAtA[i:], dAtA13[:j12])
+		i = encodeVarintDataplane(dAtA, i, uint64(j12))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Target != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Target))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CleaveRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CleaveRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CleaveRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.App) > 0 {
+		i -= len(m.App)
+		copy(dAtA[i:], m.App)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.App)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.User) > 0 {
+		i -= len(m.User)
+		copy(dAtA[i:], m.User)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.User)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Supervoxels) > 0 {
+		dAtA15 := make([]byte, len(m.Supervoxels)*10)
+		var j14 int
+		for _, num := range m.Supervoxels {
+			for num >= 1<<7 {
+				dAtA15[j14] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j14++
+			}
+			dAtA15[j14] = uint8(num)
+			j14++
+		}
+		i -= j14
+		copy(dAtA[i:], dAtA15[:j14])
+		i = encodeVarintDataplane(dAtA, i, uint64(j14))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Target != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Target))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SplitRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SplitRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SplitRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.App) > 0 {
+		i -= len(m.App)
+		copy(dAtA[i:], m.App)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.App)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.User) > 0 {
+		i -= len(m.User)
+		copy(dAtA[i:], m.User)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.User)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Rles) > 0 {
+		i -= len(m.Rles)
+		copy(dAtA[i:], m.Rles)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Rles)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Label != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Label))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Dataname) > 0 {
+		i -= len(m.Dataname)
+		copy(dAtA[i:], m.Dataname)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Dataname)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Uuid) > 0 {
+		i -= len(m.Uuid)
+		copy(dAtA[i:], m.Uuid)
+		i = encodeVarintDataplane(dAtA, i, uint64(len(m.Uuid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MutationResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MutationResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MutationResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Label != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Label))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Mutid != 0 {
+		i = encodeVarintDataplane(dAtA, i, uint64(m.Mutid))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintDataplane(dAtA []byte, offset int, v uint64) int {
+	offset -= sovDataplane(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Block) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.X != 0 {
+		n += 1 + sovDataplane(uint64(m.X))
+	}
+	if m.Y != 0 {
+		n += 1 + sovDataplane(uint64(m.Y))
+	}
+	if m.Z != 0 {
+		n += 1 + sovDataplane(uint64(m.Z))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *GetBlocksRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Scale != 0 {
+		n += 1 + sovDataplane(uint64(m.Scale))
+	}
+	if m.Supervoxels {
+		n += 2
+	}
+	l = len(m.Compression)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if len(m.Blocks) > 0 {
+		l = 0
+		for _, e := range m.Blocks {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	if len(m.MinBlock) > 0 {
+		l = 0
+		for _, e := range m.MinBlock {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	if len(m.MaxBlock) > 0 {
+		l = 0
+		for _, e := range m.MaxBlock {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	return n
+}
+
+func (m *PutBlocksRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Scale != 0 {
+		n += 1 + sovDataplane(uint64(m.Scale))
+	}
+	if m.Downscale {
+		n += 2
+	}
+	if m.Mutate {
+		n += 2
+	}
+	l = len(m.User)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.App)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Block != nil {
+		l = m.Block.Size()
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *PutBlocksResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.NumBlocks != 0 {
+		n += 1 + sovDataplane(uint64(m.NumBlocks))
+	}
+	return n
+}
+
+func (m *SubvolumeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if len(m.Offset) > 0 {
+		l = 0
+		for _, e := range m.Offset {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	if len(m.Size_) > 0 {
+		l = 0
+		for _, e := range m.Size_ {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	if m.Scale != 0 {
+		n += 1 + sovDataplane(uint64(m.Scale))
+	}
+	if m.Supervoxels {
+		n += 2
+	}
+	if m.ChunkBytes != 0 {
+		n += 1 + sovDataplane(uint64(m.ChunkBytes))
+	}
+	return n
+}
+
+func (m *SparsevolRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Label != 0 {
+		n += 1 + sovDataplane(uint64(m.Label))
+	}
+	if m.Scale != 0 {
+		n += 1 + sovDataplane(uint64(m.Scale))
+	}
+	if m.Supervoxels {
+		n += 2
+	}
+	l = len(m.Format)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *DataChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *MergeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Target != 0 {
+		n += 1 + sovDataplane(uint64(m.Target))
+	}
+	if len(m.Merged) > 0 {
+		l = 0
+		for _, e := range m.Merged {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	l = len(m.User)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.App)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *CleaveRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Target != 0 {
+		n += 1 + sovDataplane(uint64(m.Target))
+	}
+	if len(m.Supervoxels) > 0 {
+		l = 0
+		for _, e := range m.Supervoxels {
+			l += sovDataplane(uint64(e))
+		}
+		n += 1 + sovDataplane(uint64(l)) + l
+	}
+	l = len(m.User)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.App)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *SplitRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Uuid)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.Dataname)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	if m.Label != 0 {
+		n += 1 + sovDataplane(uint64(m.Label))
+	}
+	l = len(m.Rles)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.User)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	l = len(m.App)
+	if l > 0 {
+		n += 1 + l + sovDataplane(uint64(l))
+	}
+	return n
+}
+
+func (m *MutationResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Mutid != 0 {
+		n += 1 + sovDataplane(uint64(m.Mutid))
+	}
+	if m.Label != 0 {
+		n += 1 + sovDataplane(uint64(m.Label))
+	}
+	return n
+}
+
+func sovDataplane(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozDataplane(x uint64) (n int) {
+	return sovDataplane(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Block) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Block{`,
+		`X:` + fmt.Sprintf("%v", this.X) + `,`,
+		`Y:` + fmt.Sprintf("%v", this.Y) + `,`,
+		`Z:` + fmt.Sprintf("%v", this.Z) + `,`,
+		`Data:` + fmt.Sprintf("%v", this.Data) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GetBlocksRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&GetBlocksRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Scale:` + fmt.Sprintf("%v", this.Scale) + `,`,
+		`Supervoxels:` + fmt.Sprintf("%v", this.Supervoxels) + `,`,
+		`Compression:` + fmt.Sprintf("%v", this.Compression) + `,`,
+		`Blocks:` + fmt.Sprintf("%v", this.Blocks) + `,`,
+		`MinBlock:` + fmt.Sprintf("%v", this.MinBlock) + `,`,
+		`MaxBlock:` + fmt.Sprintf("%v", this.MaxBlock) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PutBlocksRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PutBlocksRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Scale:` + fmt.Sprintf("%v", this.Scale) + `,`,
+		`Downscale:` + fmt.Sprintf("%v", this.Downscale) + `,`,
+		`Mutate:` + fmt.Sprintf("%v", this.Mutate) + `,`,
+		`User:` + fmt.Sprintf("%v", this.User) + `,`,
+		`App:` + fmt.Sprintf("%v", this.App) + `,`,
+		`Block:` + strings.Replace(this.Block.String(), "Block", "Block", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PutBlocksResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PutBlocksResponse{`,
+		`NumBlocks:` + fmt.Sprintf("%v", this.NumBlocks) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *SubvolumeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SubvolumeRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Offset:` + fmt.Sprintf("%v", this.Offset) + `,`,
+		`Size_:` + fmt.Sprintf("%v", this.Size_) + `,`,
+		`Scale:` + fmt.Sprintf("%v", this.Scale) + `,`,
+		`Supervoxels:` + fmt.Sprintf("%v", this.Supervoxels) + `,`,
+		`ChunkBytes:` + fmt.Sprintf("%v", this.ChunkBytes) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *SparsevolRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SparsevolRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Label:` + fmt.Sprintf("%v", this.Label) + `,`,
+		`Scale:` + fmt.Sprintf("%v", this.Scale) + `,`,
+		`Supervoxels:` + fmt.Sprintf("%v", this.Supervoxels) + `,`,
+		`Format:` + fmt.Sprintf("%v", this.Format) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DataChunk) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DataChunk{`,
+		`Data:` + fmt.Sprintf("%v", this.Data) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MergeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MergeRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Target:` + fmt.Sprintf("%v", this.Target) + `,`,
+		`Merged:` + fmt.Sprintf("%v", this.Merged) + `,`,
+		`User:` + fmt.Sprintf("%v", this.User) + `,`,
+		`App:` + fmt.Sprintf("%v", this.App) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CleaveRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CleaveRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Target:` + fmt.Sprintf("%v", this.Target) + `,`,
+		`Supervoxels:` + fmt.Sprintf("%v", this.Supervoxels) + `,`,
+		`User:` + fmt.Sprintf("%v", this.User) + `,`,
+		`App:` + fmt.Sprintf("%v", this.App) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *SplitRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SplitRequest{`,
+		`Uuid:` + fmt.Sprintf("%v", this.Uuid) + `,`,
+		`Dataname:` + fmt.Sprintf("%v", this.Dataname) + `,`,
+		`Label:` + fmt.Sprintf("%v", this.Label) + `,`,
+		`Rles:` + fmt.Sprintf("%v", this.Rles) + `,`,
+		`User:` + fmt.Sprintf("%v", this.User) + `,`,
+		`App:` + fmt.Sprintf("%v", this.App) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MutationResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MutationResponse{`,
+		`Mutid:` + fmt.Sprintf("%v", this.Mutid) + `,`,
+		`Label:` + fmt.Sprintf("%v", this.Label) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringDataplane(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Block) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Block: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Block: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field X", wireType)
+			}
+			m.X = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.X |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Y", wireType)
+			}
+			m.Y = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Y |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Z", wireType)
+			}
+			m.Z = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Z |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetBlocksRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetBlocksRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetBlocksRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scale", wireType)
+			}
+			m.Scale = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Scale |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Supervoxels", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Supervoxels = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Compression = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Blocks = append(m.Blocks, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Blocks) == 0 {
+					m.Blocks = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Blocks = append(m.Blocks, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Blocks", wireType)
+			}
+		case 7:
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.MinBlock = append(m.MinBlock, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.MinBlock) == 0 {
+					m.MinBlock = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.MinBlock = append(m.MinBlock, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinBlock", wireType)
+			}
+		case 8:
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.MaxBlock = append(m.MaxBlock, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.MaxBlock) == 0 {
+					m.MaxBlock = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.MaxBlock = append(m.MaxBlock, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBlock", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PutBlocksRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PutBlocksRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PutBlocksRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scale", wireType)
+			}
+			m.Scale = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Scale |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Downscale", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Downscale = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mutate", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Mutate = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field User", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.User = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field App", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.App = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Block", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Block == nil {
+				m.Block = &Block{}
+			}
+			if err := m.Block.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PutBlocksResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PutBlocksResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PutBlocksResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumBlocks", wireType)
+			}
+			m.NumBlocks = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NumBlocks |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SubvolumeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SubvolumeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SubvolumeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Offset = append(m.Offset, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Offset) == 0 {
+					m.Offset = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Offset = append(m.Offset, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+		case 4:
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Size_ = append(m.Size_, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Size_) == 0 {
+					m.Size_ = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Size_ = append(m.Size_, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Size_", wireType)
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scale", wireType)
+			}
+			m.Scale = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Scale |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Supervoxels", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Supervoxels = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkBytes", wireType)
+			}
+			m.ChunkBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkBytes |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SparsevolRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SparsevolRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SparsevolRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Label", wireType)
+			}
+			m.Label = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Label |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scale", wireType)
+			}
+			m.Scale = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Scale |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Supervoxels", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Supervoxels = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Format", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Format = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DataChunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DataChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DataChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MergeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MergeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MergeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			m.Target = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Target |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Merged = append(m.Merged, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Merged) == 0 {
+					m.Merged = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Merged = append(m.Merged, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Merged", wireType)
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field User", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.User = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field App", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.App = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CleaveRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CleaveRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CleaveRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			m.Target = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Target |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Supervoxels = append(m.Supervoxels, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowDataplane
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthDataplane
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Supervoxels) == 0 {
+					m.Supervoxels = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowDataplane
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Supervoxels = append(m.Supervoxels, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Supervoxels", wireType)
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field User", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.User = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field App", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.App = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SplitRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SplitRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SplitRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uuid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uuid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dataname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dataname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Label", wireType)
+			}
+			m.Label = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Label |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rles", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Rles = append(m.Rles[:0], dAtA[iNdEx:postIndex]...)
+			if m.Rles == nil {
+				m.Rles = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field User", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.User = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field App", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.App = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MutationResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MutationResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MutationResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mutid", wireType)
+			}
+			m.Mutid = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Mutid |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Label", wireType)
+			}
+			m.Label = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Label |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDataplane(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDataplane
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipDataplane(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowDataplane
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDataplane
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthDataplane
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupDataplane
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthDataplane
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthDataplane        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowDataplane          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupDataplane = fmt.Errorf("proto: unexpected end of group")
+)
@@ -178,8 +178,64 @@ GET  <api URL>/node/<UUID>/<data name>/raw/<dims>/<size>/<offset>[/<format>][?qu
     				call right away, a 503 (Service Unavailable) status code is returned.
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns characteristics of this data.", Response: []string{"application/json"}},
+	{
+		Method:  "GET",
+		Path:    "/tile/{dims}/{scaling}/{tile_coord}",
+		Summary: "Returns a tile image of the data.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the tile, e.g., \"0_1\" or \"xy\"."},
+			{Name: "scaling", Type: "integer", Description: "Scale from 0 (original resolution) where each step is downres by 2."},
+			{Name: "tile_coord", Description: "Tile coordinate in \"x_y_z\" format."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "tilesize", Type: "integer", Description: "Size in pixels along one dimension of square tile."},
+			{Name: "noblanks", Type: "boolean", Description: "If true, tiles outside the stored extents return a placeholder."},
+			{Name: "format", Description: "\"png\" (default) or \"jpeg\", optionally with quality or compression level, e.g., \"jpeg:80\"."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image or 3d array of voxel values in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "compression", Description: "Compression of 3d data: \"snappy\" (default) or \"lz4\"."},
+			{Name: "scale", Type: "integer", Description: "For scale N, returns data down-sampled by a factor of 2^N."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image or 3d array of voxel values in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "compression", Description: "Compression of 3d data: \"snappy\" (default) or \"lz4\"."},
+			{Name: "scale", Type: "integer", Description: "For scale N, returns data down-sampled by a factor of 2^N."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+}
+
 func init() {
 	datastore.Register(NewType())
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
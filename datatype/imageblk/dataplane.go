@@ -0,0 +1,63 @@
+/*
+	This file supports the gRPC data plane by implementing the server's streaming interfaces
+	on top of the imageblk HTTP endpoint methods.
+*/
+
+package imageblk
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+// GetDataPlaneBlock returns the uncompressed voxels of a block as in GET /blocks.
+// Blocks that are not stored are returned filled with the background value.
+func (d *Data) GetDataPlaneBlock(ctx *datastore.VersionedCtx, bcoord dvid.ChunkPoint3d, scale uint8, supervoxels bool, compression string) ([]byte, error) {
+	if scale != 0 {
+		return nil, fmt.Errorf("data %q does not support multiple scales", d.DataName())
+	}
+	switch compression {
+	case "", "uncompressed":
+	default:
+		return nil, fmt.Errorf("data %q only supports uncompressed blocks, not %q", d.DataName(), compression)
+	}
+	return d.GetBlocks(ctx.VersionID(), bcoord, 1)
+}
+
+// PutDataPlaneBlocks stores uncompressed blocks of voxels as in POST /blocks.
+func (d *Data) PutDataPlaneBlocks(ctx *datastore.VersionedCtx, opts server.DataPlanePutOptions, blocks <-chan server.DataPlaneBlock) error {
+	if opts.Scale != 0 {
+		return fmt.Errorf("data %q does not support multiple scales", d.DataName())
+	}
+	if opts.Downscale {
+		return fmt.Errorf("data %q does not support downscaling", d.DataName())
+	}
+	numBlockBytes := int(d.BlockSize().Prod()) // block size expected by PutBlocks
+	mutID := d.NewMutationID()
+	for block := range blocks {
+		if len(block.Data) != numBlockBytes {
+			return fmt.Errorf("block %s has %d bytes, expected %d bytes", block.BCoord, len(block.Data), numBlockBytes)
+		}
+		if err := d.PutBlocks(ctx.VersionID(), mutID, block.BCoord, 1, ioutil.NopCloser(bytes.NewReader(block.Data)), opts.Mutate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDataPlaneSubvolume returns the voxels of a subvolume as in GET /raw.
+func (d *Data) GetDataPlaneSubvolume(ctx *datastore.VersionedCtx, offset, size dvid.Point3d, scale uint8, supervoxels bool) ([]byte, error) {
+	if scale != 0 {
+		return nil, fmt.Errorf("data %q does not support multiple scales", d.DataName())
+	}
+	vox, err := d.NewVoxels(dvid.NewSubvolume(offset, size), nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetVolume(ctx.VersionID(), vox, "")
+}
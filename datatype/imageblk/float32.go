@@ -26,4 +26,5 @@ func init() {
 	floatimg.Type.Version = "0.1"
 
 	datastore.Register(&floatimg)
+	datastore.RegisterRoutes(floatimg.Type.Name, routes...)
 }
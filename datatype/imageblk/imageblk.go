@@ -461,6 +461,183 @@ POST <api URL>/node/<UUID>/<data name>/blocks/<block coord>/<spanX>
                   the block size for a data type.
 `
 
+// machine-readable description of the HTTP API above, shared by all imageblk types.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings including extents.", Response: []string{"application/json"}},
+	{Method: "GET", Path: "/metadata", Summary: "Returns a JSON schema describing the layout of bytes returned for n-d images.", Response: []string{"application/vnd.dvid-nd-data+json"}},
+	{Method: "POST", Path: "/extents", Summary: "Sets the extents for the image volume.", Request: []string{"application/json"}},
+	{Method: "POST", Path: "/resolution", Summary: "Sets the resolution for the image volume.", Request: []string{"application/json"}},
+	{
+		Method:  "GET",
+		Path:    "/rawkey",
+		Summary: "Returns the hex-encoded binary key used to store the block at the given block coordinate.",
+		Query: []datastore.RouteParam{
+			{Name: "x", Type: "integer", Required: true, Description: "Block x coordinate."},
+			{Name: "y", Type: "integer", Required: true, Description: "Block y coordinate."},
+			{Name: "z", Type: "integer", Required: true, Description: "Block z coordinate."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/specificblocks",
+		Summary: "Returns the blocks given in the query string, each preceded by its block coordinate and size.",
+		Query: []datastore.RouteParam{
+			{Name: "blocks", Required: true, Description: "Comma-separated block coordinates, e.g., \"x1,y1,z1,x2,y2,z2\"."},
+			{Name: "compression", Description: "\"uncompressed\" or default storage compression."},
+			{Name: "prefetch", Type: "boolean", Description: "If true, only prefetches blocks without sending data."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/subvolblocks/{size}/{offset}",
+		Summary: "Returns the blocks within a block-aligned subvolume, each preceded by its block coordinate and size.",
+		PathParams: []datastore.RouteParam{
+			{Name: "size", Description: "Size in voxels, e.g., \"64_64_64\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_0\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "compression", Description: "\"jpeg\" (default) or \"uncompressed\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image or 3d array of voxel values in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "attenuation", Type: "integer", Description: "For n in 1 to 7, reduces the intensity of voxels outside the roi by 2^n instead of zeroing them."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image or 3d array of voxel values in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "attenuation", Type: "integer", Description: "For n in 1 to 7, reduces the intensity of voxels outside the roi by 2^n instead of zeroing them."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of voxel values in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of voxel values in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Stores block-aligned voxel data in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Must be \"0_1_2\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "mutate", Type: "boolean", Description: "If true, the POST is a mutation of prior data so synced data can be updated."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/arb/{top_left}/{top_right}/{bottom_left}/{res}",
+		Summary: "Returns an arbitrarily oriented planar image given real world coordinates of its corners.",
+		PathParams: []datastore.RouteParam{
+			{Name: "top_left", Description: "Real world coordinate of the top left pixel, e.g., \"20.3_11.8_109.4\"."},
+			{Name: "top_right", Description: "Real world coordinate of the top right pixel."},
+			{Name: "bottom_left", Description: "Real world coordinate of the bottom left pixel."},
+			{Name: "res", Type: "number", Description: "Resolution per pixel used to calculate the image size."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/arb/{top_left}/{top_right}/{bottom_left}/{res}/{format}",
+		Summary: "Returns an arbitrarily oriented planar image given real world coordinates of its corners in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "top_left", Description: "Real world coordinate of the top left pixel, e.g., \"20.3_11.8_109.4\"."},
+			{Name: "top_right", Description: "Real world coordinate of the top right pixel."},
+			{Name: "bottom_left", Description: "Real world coordinate of the bottom left pixel."},
+			{Name: "res", Type: "number", Description: "Resolution per pixel used to calculate the image size."},
+			{Name: "format", Description: "\"png\" or \"jpg\", which allows a quality setting, e.g., \"jpg:80\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/blocks/{block_coord}/{span_x}",
+		Summary: "Returns a run of uncompressed blocks along x starting at the given block coordinate.",
+		PathParams: []datastore.RouteParam{
+			{Name: "block_coord", Description: "Block coordinate of the first block, e.g., \"10_20_30\"."},
+			{Name: "span_x", Type: "integer", Description: "Number of blocks along x."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/blocks/{block_coord}/{span_x}",
+		Summary: "Stores a run of uncompressed blocks along x starting at the given block coordinate.",
+		PathParams: []datastore.RouteParam{
+			{Name: "block_coord", Description: "Block coordinate of the first block, e.g., \"10_20_30\"."},
+			{Name: "span_x", Type: "integer", Description: "Number of blocks along x."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "mutate", Type: "boolean", Description: "If true, the POST is a mutation of prior data so synced data can be updated."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+}
+
 var (
 	// DefaultBlockSize specifies the default size for each block of this data type.
 	DefaultBlockSize int32 = 32
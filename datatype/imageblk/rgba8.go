@@ -38,4 +38,5 @@ func init() {
 	rgba.Type.Version = "0.2"
 
 	datastore.Register(&rgba)
+	datastore.RegisterRoutes(rgba.Type.Name, routes...)
 }
@@ -1,6 +1,11 @@
 package imageblk
 
-import "github.com/janelia-flyem/dvid/dvid"
+import (
+	"fmt"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+)
 
 // Events for imageblk
 const (
@@ -25,3 +30,19 @@ type MutatedBlock struct {
 	Data  []byte
 	MutID  uint64
 }
+
+func describeBlock(e *datastore.ChangeEvent, index *dvid.IndexZYX) {
+	if index != nil {
+		e.Block = fmt.Sprintf("%d,%d,%d", index[0], index[1], index[2])
+	}
+}
+
+// DescribeChange adds the block coordinate to a change feed event.
+func (b Block) DescribeChange(e *datastore.ChangeEvent) {
+	describeBlock(e, b.Index)
+}
+
+// DescribeChange adds the block coordinate to a change feed event.
+func (b MutatedBlock) DescribeChange(e *datastore.ChangeEvent) {
+	describeBlock(e, b.Index)
+}
@@ -21,4 +21,5 @@ func init() {
 	imgtype.Type.Version = "0.1"
 
 	datastore.Register(&imgtype)
+	datastore.RegisterRoutes(imgtype.Type.Name, routes...)
 }
@@ -21,4 +21,5 @@ func init() {
 	grayscale.Type.Version = "0.2"
 
 	datastore.Register(&grayscale)
+	datastore.RegisterRoutes(grayscale.Type.Name, routes...)
 }
@@ -337,7 +337,7 @@ func (d *Data) PutBlocks(v dvid.VersionID, mutID uint64, start dvid.ChunkPoint3d
 			delta = Block{&zyx, buf, mutID}
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), event}
-		msg := datastore.SyncMessage{event, v, delta}
+		msg := datastore.SyncMessage{Event: event, Version: v, Delta: delta}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			return err
 		}
@@ -470,7 +470,7 @@ func (d *Data) putChunk(chunk *storage.Chunk, hasbuffer bool, patchgeo *patchGeo
 			delta = Block{&op.indexZYX, block.V, op.mutID}
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), event}
-		msg := datastore.SyncMessage{event, op.version, delta}
+		msg := datastore.SyncMessage{Event: event, Version: op.version, Delta: delta}
 		if err = datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Errorf("Unable to notify subscribers of event %s in %s\n", event, d.DataName())
 		}
@@ -611,7 +611,7 @@ func (d *Data) writeBlocks(v dvid.VersionID, b storage.TKeyValues, wg1, wg2 *syn
 				dvid.Errorf("Unable to recover index from block key: %v\n", block.K)
 				return
 			}
-			msg := datastore.SyncMessage{IngestBlockEvent, v, Block{indexZYX, block.V, mutID}}
+			msg := datastore.SyncMessage{Event: IngestBlockEvent, Version: v, Delta: Block{indexZYX, block.V, mutID}}
 			if err := datastore.NotifySubscribers(evt, msg); err != nil {
 				dvid.Errorf("Unable to notify subscribers of ChangeBlockEvent in %s\n", d.DataName())
 				return
@@ -306,12 +306,103 @@ GET  <api URL>/node/<UUID>/<data name>/isotropic/<dims>/<size>/<offset>[/<format
 
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns characteristics of this tile data like the tile size and number of scales.", Response: []string{"application/json"}},
+	{Method: "GET", Path: "/metadata", Summary: "Returns the resolution and expected tile sizes for stored tiles.", Response: []string{"application/json"}},
+	{Method: "POST", Path: "/metadata", Summary: "Sets the resolution and expected tile sizes for stored tiles.", Request: []string{"application/json"}},
+	{
+		Method:  "GET",
+		Path:    "/tile/{dims}/{scaling}/{tile_coord}",
+		Summary: "Returns a precomputed tile in the format dictated by the imagetile encoding.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the tile, e.g., \"0_1\" or \"xy\"."},
+			{Name: "scaling", Type: "integer", Description: "Scale from 0 (original resolution) where each step is downres by 2."},
+			{Name: "tile_coord", Description: "Tile coordinate in \"x_y_z\" format."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "noblanks", Type: "boolean", Description: "If true, tiles outside the stored extents return a blank image."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/tile/{dims}/{scaling}/{tile_coord}",
+		Summary: "Stores a tile that matches the data instance's compression and tile sizes.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the tile, e.g., \"0_1\" or \"xy\"."},
+			{Name: "scaling", Type: "integer", Description: "Scale from 0 (original resolution) where each step is downres by 2."},
+			{Name: "tile_coord", Description: "Tile coordinate in \"x_y_z\" format."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/tilekey/{dims}/{scaling}/{tile_coord}",
+		Summary: "Returns the internal key for a tile as a hexadecimal string.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the tile, e.g., \"0_1\" or \"xy\"."},
+			{Name: "scaling", Type: "integer", Description: "Scale from 0 (original resolution) where each step is downres by 2."},
+			{Name: "tile_coord", Description: "Tile coordinate in \"x_y_z\" format."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Returns an image stitched from tiles without adjusting for voxel resolution.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns an image stitched from tiles without adjusting for voxel resolution in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\", which allows a quality setting, e.g., \"jpg:80\"."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}",
+		Summary: "Returns an image stitched from tiles and scaled to have isotropic pixels.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns an image stitched from tiles and scaled to have isotropic pixels in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\", which allows a quality setting, e.g., \"jpg:80\"."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+}
+
 var (
 	ErrNoMetadataSet = errors.New("Tile metadata has not been POSTed yet.  GET requests require metadata to be POST.")
 )
 
 func init() {
 	datastore.Register(NewType())
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -182,8 +182,62 @@ POST <api URL>/node/<UUID>/<data name>/keyvalues
 				and the returned data will be a tarfile with keys as file names.
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{Method: "GET", Path: "/keys", Summary: "Returns all keys as a JSON array.", Response: []string{"application/json"}},
+	{
+		Method:  "GET",
+		Path:    "/keyrange/{key1}/{key2}",
+		Summary: "Returns all keys between key1 and key2 as a JSON array.",
+		PathParams: []datastore.RouteParam{
+			{Name: "key1", Description: "Lexicographically lowest key in range."},
+			{Name: "key2", Description: "Lexicographically highest key in range."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/key/{key}",
+		Summary:    "Returns the value of a key.",
+		PathParams: []datastore.RouteParam{{Name: "key", Description: "An alphanumeric key."}},
+		Response:   []string{"application/octet-stream"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/key/{key}",
+		Summary:    "Stores the request body as the value of a key.",
+		PathParams: []datastore.RouteParam{{Name: "key", Description: "An alphanumeric key."}},
+		Request:    []string{"application/octet-stream"},
+	},
+	{
+		Method:     "DELETE",
+		Path:       "/key/{key}",
+		Summary:    "Deletes a key.",
+		PathParams: []datastore.RouteParam{{Name: "key", Description: "An alphanumeric key."}},
+	},
+	{
+		Method:  "GET",
+		Path:    "/keyvalues",
+		Summary: "Returns the values for keys given in a Keys protobuf or, if jsontar is set, a JSON array.",
+		Query: []datastore.RouteParam{
+			{Name: "jsontar", Type: "boolean", Description: "If set, request body is a JSON array of keys and a tarfile is returned."},
+		},
+		Request:  []string{"application/octet-stream", "application/json"},
+		Response: []string{"application/octet-stream", "application/tar"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/keyvalues",
+		Summary: "Stores the keys and values in a KeyValues protobuf request body.",
+		Request: []string{"application/octet-stream"},
+	},
+}
+
 func init() {
 	datastore.Register(NewType())
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -371,7 +425,11 @@ func (d *Data) PutData(ctx storage.Context, keyStr string, value []byte) error {
 	if err != nil {
 		return err
 	}
-	return db.Put(ctx, tk, serialization)
+	if err := db.Put(ctx, tk, serialization); err != nil {
+		return err
+	}
+	d.notifyKeyChange(ctx, PutEvent, keyStr)
+	return nil
 }
 
 // DeleteData deletes a key-value pair
@@ -384,7 +442,35 @@ func (d *Data) DeleteData(ctx storage.Context, keyStr string) error {
 	if err != nil {
 		return err
 	}
-	return db.Delete(ctx, tk)
+	if err := db.Delete(ctx, tk); err != nil {
+		return err
+	}
+	d.notifyKeyChange(ctx, DeleteEvent, keyStr)
+	return nil
+}
+
+// Key-value change event identifiers.
+const (
+	PutEvent    = "KEYVALUE_PUT"
+	DeleteEvent = "KEYVALUE_DELETE"
+)
+
+// DeltaKey is the unit of delta for key-value change events.
+type DeltaKey struct {
+	Key string
+}
+
+// DescribeChange adds the modified key to a change feed event.
+func (delta DeltaKey) DescribeChange(e *datastore.ChangeEvent) {
+	e.Key = delta.Key
+}
+
+func (d *Data) notifyKeyChange(ctx storage.Context, event, keyStr string) {
+	evt := datastore.SyncEvent{Data: d.DataUUID(), Event: event}
+	msg := datastore.SyncMessage{Event: event, Version: ctx.VersionID(), Delta: DeltaKey{keyStr}}
+	if err := datastore.NotifySubscribers(evt, msg); err != nil {
+		dvid.Errorf("unable to notify subscribers of %s on key %q in data %q: %v\n", event, keyStr, d.DataName(), err)
+	}
 }
 
 // put handles a PUT command-line request.
@@ -897,6 +897,299 @@ POST <api URL>/node/<UUID>/<data name>/split-coarse/<label>[?splitlabel=X]
 	The Notes for "split" endpoint above are applicable to this "split-coarse" endpoint.
 `
 
+// optional bounds on the sparse volume endpoints.
+var routeBounds = []datastore.RouteParam{
+	{Name: "minx", Type: "integer", Description: "Spans must be equal to or larger than this minimum x voxel coordinate."},
+	{Name: "maxx", Type: "integer", Description: "Spans must be equal to or smaller than this maximum x voxel coordinate."},
+	{Name: "miny", Type: "integer", Description: "Spans must be equal to or larger than this minimum y voxel coordinate."},
+	{Name: "maxy", Type: "integer", Description: "Spans must be equal to or smaller than this maximum y voxel coordinate."},
+	{Name: "minz", Type: "integer", Description: "Spans must be equal to or larger than this minimum z voxel coordinate."},
+	{Name: "maxz", Type: "integer", Description: "Spans must be equal to or smaller than this maximum z voxel coordinate."},
+}
+
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings including extents.", Response: []string{"application/json"}},
+	{Method: "POST", Path: "/resolution", Summary: "Sets the resolution for the image volume.", Request: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Appends data instances with which this labelarray is synced.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:   "GET",
+		Path:     "/metadata",
+		Summary:  "Returns a JSON schema describing the layout of bytes returned for n-d images.",
+		Response: []string{"application/vnd.dvid-nd-data+json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/specificblocks",
+		Summary: "Returns the blocks at the given block coordinates, each preceded by its block coordinate and size.",
+		Query: []datastore.RouteParam{
+			{Name: "blocks", Required: true, Description: "Comma-separated block coordinates, e.g., \"x1,y1,z1,x2,y2,z2\"."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of labels in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image or 3d array of labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image or 3d array of labels in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Stores block-aligned labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Must be \"0_1_2\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "mutate", Type: "boolean", Description: "If true, the POST is a mutation of prior data so synced data can be updated."},
+			{Name: "compression", Description: "Compression of the request body: \"lz4\" or \"gzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/pseudocolor/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d PNG image where each label is hashed to a different color.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"image/png"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/label/{coord}",
+		Summary: "Returns the label at the given voxel coordinate.",
+		PathParams: []datastore.RouteParam{
+			{Name: "coord", Description: "Voxel coordinate, e.g., \"10_20_30\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/labels",
+		Summary: "Returns the labels at each voxel coordinate in the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "hash", Description: "MD5 hash of request body content in hexadecimal string format."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/blocks/{size}/{offset}",
+		Summary: "Returns the blocks within a block-aligned subvolume, each preceded by its block coordinate and size.",
+		PathParams: []datastore.RouteParam{
+			{Name: "size", Description: "Size in voxels, e.g., \"64_64_64\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_0\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "compression", Description: "\"lz4\" (default), \"gzip\", \"blocks\" for native DVID label blocks, or \"uncompressed\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/blocks",
+		Summary: "Stores the blocks in the request body, each preceded by its block coordinate and size.",
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "downres", Type: "boolean", Description: "If true, the blocks are also down-sampled to lower resolutions. Requires scale 0."},
+			{Name: "compression", Description: "Compression of the request body: \"blocks\" (default)."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{Method: "GET", Path: "/maxlabel", Summary: "Returns the maximum label for the version.", Response: []string{"application/json"}},
+	{
+		Method:     "GET",
+		Path:       "/sparsevol-size/{label}",
+		Summary:    "Returns the number of blocks and the block-accurate voxel bounding box of a label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevol/{label}",
+		Summary: "Returns a sparse volume with voxels of the given label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: append(append([]datastore.RouteParam{}, routeBounds...),
+			datastore.RouteParam{Name: "format", Description: "\"rles\" (default), \"srles\" for streaming RLEs, or \"blocks\" for a binary block stream."},
+			datastore.RouteParam{Name: "exact", Type: "boolean", Description: "If false, RLEs can extend outside the bounds within border blocks."},
+			datastore.RouteParam{Name: "compression", Description: "\"lz4\" or \"gzip\" compression of the returned \"rles\" data."},
+			datastore.RouteParam{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+		),
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "HEAD",
+		Path:    "/sparsevol/{label}",
+		Summary: "Returns 200 if the label has voxels within the optional block-aligned bounds and 204 if not.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: routeBounds,
+	},
+	{
+		Method:     "GET",
+		Path:       "/sparsevol-by-point/{coord}",
+		Summary:    "Returns a sparse volume in encoded RLE format of the label at the given voxel.",
+		PathParams: []datastore.RouteParam{{Name: "coord", Description: "Voxel coordinate, e.g., \"10_20_30\"."}},
+		Response:   []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevol-coarse/{label}",
+		Summary: "Returns a sparse volume with blocks of the given label in encoded RLE format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query:    routeBounds,
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevols-coarse/{start}/{end}",
+		Summary: "Returns a stream of coarse sparse volumes for labels in the given range.",
+		PathParams: []datastore.RouteParam{
+			{Name: "start", Type: "integer", Description: "First label of the range."},
+			{Name: "end", Type: "integer", Description: "Last label of the range."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{Method: "GET", Path: "/nextlabel", Summary: "Returns the next label for the version.", Response: []string{"application/json"}},
+	{
+		Method:   "POST",
+		Path:     "/nextlabel",
+		Summary:  "Reserves the number of labels given in the JSON request body and returns the reserved range.",
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/merge",
+		Summary: "Merges labels given as a JSON array [toLabel, fromLabel1, fromLabel2, ...].",
+		Request: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/split/{label}",
+		Summary: "Splits the voxels in the binary sparse volume request body from a label and returns the new label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "Label to split."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "splitlabel", Type: "integer", Description: "Label to use for the split voxels instead of a new label."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/split-coarse/{label}",
+		Summary: "Splits the blocks in the coarse sparse volume request body from a label and returns the new label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "Label to split."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "splitlabel", Type: "integer", Description: "Label to use for the split blocks instead of a new label."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+}
+
 var (
 	dtype        Type
 	encodeFormat dvid.DataValues
@@ -950,6 +1243,7 @@ func init() {
 	// See doc for package on why channels are segregated instead of interleaved.
 	// Data types must be registered with the datastore to be used.
 	datastore.Register(&dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -2120,7 +2414,7 @@ func (d *Data) ReceiveBlocks(ctx *datastore.VersionedCtx, r io.ReadCloser, scale
 		}
 
 		evt := datastore.SyncEvent{d.DataUUID(), event}
-		msg := datastore.SyncMessage{event, ctx.VersionID(), ingestBlock}
+		msg := datastore.SyncMessage{Event: event, Version: ctx.VersionID(), Delta: ingestBlock}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Errorf("Unable to notify subscribers of event %s in %s\n", event, d.DataName())
 		}
@@ -90,7 +90,7 @@ func (d *Data) MergeLabels(v dvid.VersionID, op labels.MergeOp) error {
 
 	// Signal that we are starting a merge.
 	evt := datastore.SyncEvent{d.DataUUID(), labels.MergeStartEvent}
-	msg := datastore.SyncMessage{labels.MergeStartEvent, v, labels.DeltaMergeStart{op}}
+	msg := datastore.SyncMessage{Event: labels.MergeStartEvent, Version: v, Delta: labels.DeltaMergeStart{op}}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		d.StopUpdate()
 		return err
@@ -119,7 +119,7 @@ func (d *Data) processMerge(v dvid.VersionID, mutID uint64, delta labels.DeltaMe
 	timedLog := dvid.NewTimeLog()
 
 	evt := datastore.SyncEvent{d.DataUUID(), labels.MergeBlockEvent}
-	msg := datastore.SyncMessage{labels.MergeBlockEvent, v, delta}
+	msg := datastore.SyncMessage{Event: labels.MergeBlockEvent, Version: v, Delta: delta}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return fmt.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
@@ -156,13 +156,13 @@ func (d *Data) processMerge(v dvid.VersionID, mutID uint64, delta labels.DeltaMe
 		NewSize: delta.TargetVoxels + delta.MergedVoxels,
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-	msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, deltaRep}
+	msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: deltaRep}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Criticalf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
 
 	evt = datastore.SyncEvent{d.DataUUID(), labels.MergeEndEvent}
-	msg = datastore.SyncMessage{labels.MergeEndEvent, v, labels.DeltaMergeEnd{delta.MergeOp}}
+	msg = datastore.SyncMessage{Event: labels.MergeEndEvent, Version: v, Delta: labels.DeltaMergeEnd{delta.MergeOp}}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Criticalf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
@@ -272,7 +272,7 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel, splitLabel uint64, r io.
 	}()
 
 	// Signal that we are starting a split.
-	msg := datastore.SyncMessage{labels.SplitStartEvent, v, splitOpStart}
+	msg := datastore.SyncMessage{Event: labels.SplitStartEvent, Version: v, Delta: splitOpStart}
 	if err = datastore.NotifySubscribers(evt, msg); err != nil {
 		return
 	}
@@ -397,7 +397,7 @@ func (d *Data) SplitCoarseLabels(v dvid.VersionID, fromLabel, splitLabel uint64,
 	defer labels.SplitStop(iv, splitOpEnd)
 
 	// Signal that we are starting a split.
-	msg := datastore.SyncMessage{labels.SplitStartEvent, v, splitOpStart}
+	msg := datastore.SyncMessage{Event: labels.SplitStartEvent, Version: v, Delta: splitOpStart}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -427,7 +427,7 @@ func (d *Data) SplitCoarseLabels(v dvid.VersionID, fromLabel, splitLabel uint64,
 		return
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.SplitLabelEvent}
-	msg = datastore.SyncMessage{labels.SplitLabelEvent, v, deltaSplit}
+	msg = datastore.SyncMessage{Event: labels.SplitLabelEvent, Version: v, Delta: deltaSplit}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -509,7 +509,7 @@ func (d *Data) processSplit(v dvid.VersionID, mutID uint64, delta labels.DeltaSp
 			Size:  delta.SplitVoxels,
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-		msg := datastore.SyncMessage{labels.ChangeSizeEvent, v, deltaNewSize}
+		msg := datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: deltaNewSize}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 		}
@@ -519,7 +519,7 @@ func (d *Data) processSplit(v dvid.VersionID, mutID uint64, delta labels.DeltaSp
 			SizeChange: int64(-delta.SplitVoxels),
 		}
 		evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-		msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, deltaModSize}
+		msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: deltaModSize}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 		}
@@ -542,14 +542,14 @@ func (d *Data) processSplit(v dvid.VersionID, mutID uint64, delta labels.DeltaSp
 
 	// Publish split event
 	evt := datastore.SyncEvent{d.DataUUID(), labels.SplitLabelEvent}
-	msg := datastore.SyncMessage{labels.SplitLabelEvent, v, delta}
+	msg := datastore.SyncMessage{Event: labels.SplitLabelEvent, Version: v, Delta: delta}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
 
 	// Publish split end
 	evt = datastore.SyncEvent{d.DataUUID(), labels.SplitEndEvent}
-	msg = datastore.SyncMessage{labels.SplitEndEvent, v, labels.DeltaSplitEnd{delta.OldLabel, delta.NewLabel}}
+	msg = datastore.SyncMessage{Event: labels.SplitEndEvent, Version: v, Delta: labels.DeltaSplitEnd{delta.OldLabel, delta.NewLabel}}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return fmt.Errorf("Unable to notify subscribers to data %q for evt %v\n", d.DataName(), evt)
 	}
@@ -700,7 +700,7 @@ func (d *Data) splitBlock(ctx *datastore.VersionedCtx, op splitOp) {
 			Size:  toLabelSize,
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-		msg := datastore.SyncMessage{labels.ChangeSizeEvent, ctx.VersionID(), delta}
+		msg := datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: ctx.VersionID(), Delta: delta}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Criticalf("Unable to notify subscribers to data %q for evt %v\n", d.DataName(), evt)
 		}
@@ -710,7 +710,7 @@ func (d *Data) splitBlock(ctx *datastore.VersionedCtx, op splitOp) {
 			SizeChange: int64(-toLabelSize),
 		}
 		evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-		msg = datastore.SyncMessage{labels.ChangeSizeEvent, ctx.VersionID(), delta2}
+		msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: ctx.VersionID(), Delta: delta2}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Criticalf("Unable to notify subscribers to data %q for evt %v\n", d.DataName(), evt)
 		}
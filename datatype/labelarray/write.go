@@ -224,7 +224,7 @@ func (d *Data) putChunk(op *putOperation, wg *sync.WaitGroup, putbuffer storage.
 			dvid.Errorf("data %q publishing downres: %v\n", d.DataName(), err)
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), event}
-		msg := datastore.SyncMessage{event, op.version, delta}
+		msg := datastore.SyncMessage{Event: event, Version: op.version, Delta: delta}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Errorf("Unable to notify subscribers of event %s in %s\n", event, d.DataName())
 		}
@@ -355,7 +355,7 @@ func (d *Data) writeBlocks(v dvid.VersionID, b storage.TKeyValues, wg1, wg2 *syn
 			block := IngestedBlock{mutID, indexZYX.ToIZYXString(), lblBlock}
 			d.handleBlockIndexing(v, blockCh, block)
 
-			msg := datastore.SyncMessage{labels.IngestBlockEvent, v, block}
+			msg := datastore.SyncMessage{Event: labels.IngestBlockEvent, Version: v, Delta: block}
 			if err := datastore.NotifySubscribers(evt, msg); err != nil {
 				dvid.Errorf("Unable to notify subscribers of ChangeBlockEvent in %s\n", d.DataName())
 				return
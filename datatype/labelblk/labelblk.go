@@ -402,6 +402,157 @@ GET  <api URL>/node/<UUID>/<data name>/blocks/<size>/<offset>[?queryopts]
                   (Service Unavailable) status code is returned.
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings including extents.", Response: []string{"application/json"}},
+	{Method: "POST", Path: "/resolution", Summary: "Sets the resolution for the image volume.", Request: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Appends data instances with which this labelblk is synced.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:   "GET",
+		Path:     "/metadata",
+		Summary:  "Returns a JSON schema describing the layout of bytes returned for n-d images.",
+		Response: []string{"application/vnd.dvid-nd-data+json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image or 3d array of labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image or 3d array of labels in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of labels in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Stores block-aligned labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Must be \"0_1_2\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "mutate", Type: "boolean", Description: "If true, the POST is a mutation of prior data so synced data can be updated."},
+			{Name: "compression", Description: "Compression of the request body: \"lz4\" or \"gzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/pseudocolor/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d PNG image where each label is hashed to a different color.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"image/png"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/label/{coord}",
+		Summary:    "Returns the label at the given voxel coordinate.",
+		PathParams: []datastore.RouteParam{{Name: "coord", Description: "Voxel coordinate, e.g., \"10_20_30\"."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/labels",
+		Summary: "Returns the labels at each voxel coordinate in the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "hash", Description: "MD5 hash of request body content in hexadecimal string format."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/blocks/{size}/{offset}",
+		Summary: "Returns the blocks within a block-aligned subvolume, each preceded by its block coordinate and size.",
+		PathParams: []datastore.RouteParam{
+			{Name: "size", Description: "Size in voxels, e.g., \"64_64_64\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_0\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "compression", Description: "\"lz4\" (default) or \"uncompressed\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+}
+
 var (
 	dtype        Type
 	encodeFormat dvid.DataValues
@@ -429,6 +580,7 @@ func init() {
 	// See doc for package on why channels are segregated instead of interleaved.
 	// Data types must be registered with the datastore to be used.
 	datastore.Register(&dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -455,7 +455,7 @@ func (d *Data) publishBlockChange(v dvid.VersionID, mutID uint64, block dvid.IZY
 		bcoord: block,
 		data:   blockData,
 	}
-	msg := datastore.SyncMessage{DownsizeBlockEvent, v, delta}
+	msg := datastore.SyncMessage{Event: DownsizeBlockEvent, Version: v, Delta: delta}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Criticalf("unable to notify subscribers of event %s: %v\n", evt, err)
 	}
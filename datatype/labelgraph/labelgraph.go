@@ -294,8 +294,145 @@ TODO:
 * Implement better atomicity at storage level to prevent weirdness (all writes should be in a batch -- most currently are)
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:  "GET",
+		Path:    "/subgraph",
+		Summary: "Returns the graph or the subgraph of the vertices given in an optional JSON request body.",
+		Query: []datastore.RouteParam{
+			{Name: "unsafe", Type: "boolean", Description: "If true, skips schema validation of the request JSON."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/subgraph",
+		Summary: "Adds the subgraph in the JSON request body without changing existing connections.",
+		Query: []datastore.RouteParam{
+			{Name: "unsafe", Type: "boolean", Description: "If true, skips schema validation of the request JSON."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/subgraph",
+		Summary: "Deletes the whole graph or the vertices or edges given in an optional JSON request body.",
+		Query: []datastore.RouteParam{
+			{Name: "unsafe", Type: "boolean", Description: "If true, skips schema validation of the request JSON."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/merge",
+		Summary: "Merges the vertices given in the JSON request body into the last vertex, summing weights.",
+		Request: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/neighbors/{vertex}",
+		Summary:    "Returns the vertices and edges connected to the given vertex.",
+		PathParams: []datastore.RouteParam{{Name: "vertex", Type: "integer", Description: "ID of vertex."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/weight",
+		Summary: "Increments the weights of the vertices and edges given in the JSON request body.",
+		Request: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/propertytransaction/{elements}/{property}",
+		Summary: "Locks the vertices given in the binary request body and returns a property for each vertex or edge.",
+		PathParams: []datastore.RouteParam{
+			{Name: "elements", Description: "\"edges\" or \"vertices\"."},
+			{Name: "property", Description: "Name of the property."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/propertytransaction/{elements}/{property}",
+		Summary: "Sets a property for the vertices or edges given in the binary request body using their transaction ids.",
+		PathParams: []datastore.RouteParam{
+			{Name: "elements", Description: "\"edges\" or \"vertices\"."},
+			{Name: "property", Description: "Name of the property."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/property/{vertex}/{key}",
+		Summary: "Returns a vertex property.",
+		PathParams: []datastore.RouteParam{
+			{Name: "vertex", Type: "integer", Description: "ID of vertex."},
+			{Name: "key", Description: "Name of the property."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/property/{vertex}/{key}",
+		Summary: "Sets a vertex property to the request body.",
+		PathParams: []datastore.RouteParam{
+			{Name: "vertex", Type: "integer", Description: "ID of vertex."},
+			{Name: "key", Description: "Name of the property."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/property/{vertex}/{key}",
+		Summary: "Deletes a vertex property.",
+		PathParams: []datastore.RouteParam{
+			{Name: "vertex", Type: "integer", Description: "ID of vertex."},
+			{Name: "key", Description: "Name of the property."},
+		},
+	},
+	{
+		Method:  "GET",
+		Path:    "/property/{vertex1}/{vertex2}/{key}",
+		Summary: "Returns an edge property.",
+		PathParams: []datastore.RouteParam{
+			{Name: "vertex1", Type: "integer", Description: "ID of first vertex of edge."},
+			{Name: "vertex2", Type: "integer", Description: "ID of second vertex of edge."},
+			{Name: "key", Description: "Name of the property."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/property/{vertex1}/{vertex2}/{key}",
+		Summary: "Sets an edge property to the request body.",
+		PathParams: []datastore.RouteParam{
+			{Name: "vertex1", Type: "integer", Description: "ID of first vertex of edge."},
+			{Name: "vertex2", Type: "integer", Description: "ID of second vertex of edge."},
+			{Name: "key", Description: "Name of the property."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/property/{vertex1}/{vertex2}/{key}",
+		Summary: "Deletes an edge property.",
+		PathParams: []datastore.RouteParam{
+			{Name: "vertex1", Type: "integer", Description: "ID of first vertex of edge."},
+			{Name: "vertex2", Type: "integer", Description: "ID of second vertex of edge."},
+			{Name: "key", Description: "Name of the property."},
+		},
+	},
+}
+
 func init() {
 	datastore.Register(NewType())
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -0,0 +1,532 @@
+/*
+	This file supports a versioned graph of supervoxel affinities and agglomeration of
+	supervoxels into bodies by thresholding the affinities.
+*/
+
+package labelmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/datatype/roi"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+// serializes changes to affinities.
+var affinityMu sync.Mutex
+
+// SupervoxelAffinity is the affinity of a supervoxel with a neighboring supervoxel.
+type SupervoxelAffinity struct {
+	Label    uint64  `json:"label"`
+	Affinity float32 `json:"affinity"`
+}
+
+// AgglomerateResult describes the changes made by an agglomeration.
+type AgglomerateResult struct {
+	MutationID  uint64
+	Bodies      int // number of body labels created, changed, or removed
+	Supervoxels int // number of supervoxels mapped to a different body
+}
+
+// returns the affinities of a supervoxel sorted by neighbor label.
+func getAffinities(ctx *datastore.VersionedCtx, supervoxel uint64) (*proto.Affinities, error) {
+	store, err := datastore.GetKeyValueDB(ctx.Data())
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(ctx, NewAffinitiesTKey(supervoxel))
+	if err != nil {
+		return nil, err
+	}
+	affs := new(proto.Affinities)
+	if len(data) == 0 {
+		return affs, nil
+	}
+	if err := affs.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("bad affinities for supervoxel %d in data %q: %v", supervoxel, ctx.Data().DataName(), err)
+	}
+	return affs, nil
+}
+
+// GetAffinities returns the affinities of a supervoxel with its neighbors in order of
+// neighbor label.
+func (d *Data) GetAffinities(v dvid.VersionID, supervoxel uint64) ([]SupervoxelAffinity, error) {
+	affs, err := getAffinities(datastore.NewVersionedCtx(d, v), supervoxel)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SupervoxelAffinity, len(affs.Labels))
+	for i, label := range affs.Labels {
+		out[i] = SupervoxelAffinity{Label: label, Affinity: affs.Affinities[i]}
+	}
+	return out, nil
+}
+
+// PutAffinities stores the affinities between supervoxels given in the table, replacing
+// any previous affinity between the same supervoxels.  Affinities are symmetric so an
+// edge given for either supervoxel applies to both.
+func (d *Data) PutAffinities(v dvid.VersionID, table *proto.AffinityTable) error {
+	updates := make(map[uint64]map[uint64]float32)
+	addUpdate := func(sv1, sv2 uint64, value float32) {
+		svUpdates, found := updates[sv1]
+		if !found {
+			svUpdates = make(map[uint64]float32)
+			updates[sv1] = svUpdates
+		}
+		svUpdates[sv2] = value
+	}
+	for sv1, affs := range table.Table {
+		if affs == nil {
+			continue
+		}
+		if len(affs.Labels) != len(affs.Affinities) {
+			return fmt.Errorf("supervoxel %d has %d neighbors but %d affinities", sv1, len(affs.Labels), len(affs.Affinities))
+		}
+		for i, sv2 := range affs.Labels {
+			if sv1 == 0 || sv2 == 0 || sv1 == sv2 {
+				return fmt.Errorf("bad affinity between supervoxels %d and %d", sv1, sv2)
+			}
+			addUpdate(sv1, sv2, affs.Affinities[i])
+			addUpdate(sv2, sv1, affs.Affinities[i])
+		}
+	}
+
+	affinityMu.Lock()
+	defer affinityMu.Unlock()
+
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		return err
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	for supervoxel, svUpdates := range updates {
+		affs, err := getAffinities(ctx, supervoxel)
+		if err != nil {
+			return err
+		}
+		for i, label := range affs.Labels {
+			if _, found := svUpdates[label]; !found {
+				svUpdates[label] = affs.Affinities[i]
+			}
+		}
+		nbrs := make([]uint64, 0, len(svUpdates))
+		for label := range svUpdates {
+			nbrs = append(nbrs, label)
+		}
+		sort.Slice(nbrs, func(i, j int) bool { return nbrs[i] < nbrs[j] })
+		affs.Labels = nbrs
+		affs.Affinities = make([]float32, len(nbrs))
+		for i, label := range nbrs {
+			affs.Affinities[i] = svUpdates[label]
+		}
+		data, err := affs.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := store.Put(ctx, NewAffinitiesTKey(supervoxel), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// union-find of supervoxels with path compression.
+type svUnionFind map[uint64]uint64
+
+func (uf svUnionFind) find(sv uint64) uint64 {
+	root := sv
+	for {
+		parent, found := uf[root]
+		if !found || parent == root {
+			break
+		}
+		root = parent
+	}
+	for sv != root {
+		next := uf[sv]
+		uf[sv] = root
+		sv = next
+	}
+	return root
+}
+
+// union keeps the smaller supervoxel as root so each set's root is its minimum.
+func (uf svUnionFind) union(sv1, sv2 uint64) {
+	root1, root2 := uf.find(sv1), uf.find(sv2)
+	if root1 == root2 {
+		return
+	}
+	if root1 < root2 {
+		uf[root2] = root1
+	} else {
+		uf[root1] = root2
+	}
+}
+
+// roiFilter checks if label blocks intersect the blocks of an ROI.
+type roiFilter struct {
+	blockSize    dvid.Point3d
+	roiBlockSize dvid.Point3d
+	roiBlocks    map[dvid.ChunkPoint3d]struct{}
+}
+
+func (d *Data) newROIFilter(v dvid.VersionID, roiname dvid.InstanceName) (*roiFilter, error) {
+	uuid, err := datastore.UUIDFromVersion(v)
+	if err != nil {
+		return nil, err
+	}
+	roiData, err := roi.GetByUUIDName(uuid, roiname)
+	if err != nil {
+		return nil, err
+	}
+	spans, err := roiData.GetSpans(v)
+	if err != nil {
+		return nil, err
+	}
+	blockSize, ok := d.BlockSize().(dvid.Point3d)
+	if !ok {
+		return nil, fmt.Errorf("block size for data %q should be 3d, not: %s", d.DataName(), d.BlockSize())
+	}
+	f := &roiFilter{
+		blockSize:    blockSize,
+		roiBlockSize: roiData.BlockSize,
+		roiBlocks:    make(map[dvid.ChunkPoint3d]struct{}),
+	}
+	for _, span := range spans {
+		for x := span[2]; x <= span[3]; x++ {
+			f.roiBlocks[dvid.ChunkPoint3d{x, span[1], span[0]}] = struct{}{}
+		}
+	}
+	return f, nil
+}
+
+// blockWithin returns true if any ROI block intersects the given label block.
+func (f *roiFilter) blockWithin(zyx uint64) bool {
+	x, y, z := labels.DecodeBlockIndex(zyx)
+	bcoord := dvid.Point3d{x, y, z}
+	var beg, end dvid.ChunkPoint3d
+	for i := 0; i < 3; i++ {
+		minVoxel := bcoord[i] * f.blockSize[i]
+		maxVoxel := minVoxel + f.blockSize[i] - 1
+		beg[i] = int32(math.Floor(float64(minVoxel) / float64(f.roiBlockSize[i])))
+		end[i] = int32(math.Floor(float64(maxVoxel) / float64(f.roiBlockSize[i])))
+	}
+	for rz := beg[2]; rz <= end[2]; rz++ {
+		for ry := beg[1]; ry <= end[1]; ry++ {
+			for rx := beg[0]; rx <= end[0]; rx++ {
+				if _, found := f.roiBlocks[dvid.ChunkPoint3d{rx, ry, rz}]; found {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+type affinityEdge struct {
+	sv1, sv2 uint64
+}
+
+// Agglomerate sets the mapping of all supervoxels with stored affinities so supervoxels
+// joined by affinities at or above the threshold are in the same body, labeled by its
+// smallest supervoxel unless that label remains in use by another body.  Supervoxels
+// without affinities above the threshold become their own bodies, and supervoxels without
+// any stored affinities keep their current bodies.  If roiname is given, only supervoxels
+// with voxels in blocks within the ROI are agglomerated.  Label indices of affected bodies
+// are rebuilt and, if storing them fails, the changes are rolled back.  Since the body
+// changes can't be described by merge or cleave events, agglomeration is refused if any
+// data instance is synced to this one.
+func (d *Data) Agglomerate(v dvid.VersionID, threshold float32, roiname dvid.InstanceName, info dvid.ModInfo) (result AgglomerateResult, err error) {
+	timedLog := dvid.NewTimeLog()
+
+	var filter *roiFilter
+	if roiname != "" {
+		if filter, err = d.newROIFilter(v, roiname); err != nil {
+			return
+		}
+	}
+
+	// Exclude all other mutations while the bodies are rebuilt.
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+
+	var synced bool
+	if synced, err = datastore.HasSubscribers(v, d.DataUUID()); err != nil {
+		return
+	}
+	if synced {
+		err = fmt.Errorf("cannot agglomerate data %q while other data instances are synced to it", d.DataName())
+		return
+	}
+
+	d.StartUpdate()
+	defer d.StopUpdate()
+
+	// read the affinity graph, keeping each edge once.
+	store, err := datastore.GetOrderedKeyValueDB(d)
+	if err != nil {
+		return
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	graphSVs := make(labels.Set)
+	var edges []affinityEdge
+	begTKey := NewAffinitiesTKey(0)
+	endTKey := NewAffinitiesTKey(math.MaxUint64)
+	err = store.ProcessRange(ctx, begTKey, endTKey, &storage.ChunkOp{}, func(c *storage.Chunk) error {
+		if c == nil || c.TKeyValue == nil || len(c.TKeyValue.V) == 0 {
+			return nil
+		}
+		sv1, err := DecodeAffinitiesTKey(c.TKeyValue.K)
+		if err != nil {
+			return err
+		}
+		var affs proto.Affinities
+		if err := affs.Unmarshal(c.TKeyValue.V); err != nil {
+			return err
+		}
+		graphSVs[sv1] = struct{}{}
+		for i, sv2 := range affs.Labels {
+			if sv1 < sv2 && affs.Affinities[i] >= threshold {
+				edges = append(edges, affinityEdge{sv1, sv2})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// get the current bodies and indices of the graph's supervoxels.
+	mapping, err := getMapping(d, v)
+	if err != nil {
+		return
+	}
+	bodyIndices := make(map[uint64]*labels.Index)
+	for sv := range graphSVs {
+		body := sv
+		if mapped, found := mapping.MappedLabel(v, sv); found {
+			body = mapped
+		}
+		if body == 0 {
+			delete(graphSVs, sv) // supervoxel has been split.
+			continue
+		}
+		if _, found := bodyIndices[body]; found {
+			continue
+		}
+		var idx *labels.Index
+		if idx, err = GetLabelIndex(d, v, body, false); err != nil {
+			return
+		}
+		bodyIndices[body] = idx
+	}
+
+	// only keep supervoxels in the body indices and, if given, the ROI.
+	svBody := make(map[uint64]uint64)
+	inROI := make(labels.Set)
+	for body, idx := range bodyIndices {
+		if idx == nil {
+			continue
+		}
+		for zyx, svc := range idx.Blocks {
+			for sv := range svc.Counts {
+				svBody[sv] = body
+				if filter != nil && filter.blockWithin(zyx) {
+					inROI[sv] = struct{}{}
+				}
+			}
+		}
+	}
+	for sv := range graphSVs {
+		_, found := svBody[sv]
+		if _, within := inROI[sv]; !found || (filter != nil && !within) {
+			delete(graphSVs, sv)
+		}
+	}
+
+	uf := make(svUnionFind, len(graphSVs))
+	for _, edge := range edges {
+		_, found1 := graphSVs[edge.sv1]
+		_, found2 := graphSVs[edge.sv2]
+		if found1 && found2 {
+			uf.union(edge.sv1, edge.sv2)
+		}
+	}
+
+	// affected bodies that keep supervoxels outside the graph.
+	keptBodies := make(labels.Set)
+	for sv, body := range svBody {
+		if _, found := graphSVs[sv]; !found {
+			keptBodies[body] = struct{}{}
+		}
+	}
+
+	// journal the changes so any failure rolls back new labels, indices and mappings.
+	b := newMutationBatch(d, v, d.NewMutationID(), 1)
+	defer func() {
+		if err != nil {
+			b.rollback()
+		}
+	}()
+	newLabel := func() (label uint64, err error) {
+		err = b.reserveLabels(func() (err error) {
+			label, err = d.newLabel(v)
+			return
+		})
+		return
+	}
+
+	// new body for each supervoxel in the affected bodies.  If a component's label is
+	// already used by a body that's unaffected or that keeps supervoxels outside the
+	// graph, a new label is used.
+	newLabels := make(map[uint64]uint64)
+	newBody := make(map[uint64]uint64, len(svBody))
+	for sv, body := range svBody {
+		if _, found := graphSVs[sv]; !found {
+			newBody[sv] = body
+			continue
+		}
+		root := uf.find(sv)
+		label, found := newLabels[root]
+		if !found {
+			label = root
+			if _, kept := keptBodies[root]; kept {
+				if label, err = newLabel(); err != nil {
+					return
+				}
+			} else if _, affected := bodyIndices[root]; !affected {
+				var idx *labels.Index
+				if idx, err = GetLabelIndex(d, v, root, false); err != nil {
+					return
+				}
+				if idx != nil {
+					if label, err = newLabel(); err != nil {
+						return
+					}
+				}
+			}
+			newLabels[root] = label
+		}
+		newBody[sv] = label
+	}
+
+	// build the new indices and find the changed bodies and supervoxels.
+	newIndices := make(map[uint64]*labels.Index)
+	for _, idx := range bodyIndices {
+		if idx == nil {
+			continue
+		}
+		for zyx, svc := range idx.Blocks {
+			for sv, count := range svc.Counts {
+				body := newBody[sv]
+				nidx, found := newIndices[body]
+				if !found {
+					nidx = new(labels.Index)
+					nidx.Label = body
+					nidx.Blocks = make(map[uint64]*proto.SVCount)
+					newIndices[body] = nidx
+				}
+				nsvc, found := nidx.Blocks[zyx]
+				if !found {
+					nsvc = new(proto.SVCount)
+					nsvc.Counts = make(map[uint64]uint32)
+					nidx.Blocks[zyx] = nsvc
+				}
+				nsvc.Counts[sv] = count
+			}
+		}
+	}
+	changedBodies := make(labels.Set)
+	remapped := make(map[uint64]labels.Set)
+	for sv, body := range newBody {
+		if body != svBody[sv] {
+			changedBodies[body] = struct{}{}
+			changedBodies[svBody[sv]] = struct{}{}
+			svs, found := remapped[body]
+			if !found {
+				svs = make(labels.Set)
+				remapped[body] = svs
+			}
+			svs[sv] = struct{}{}
+			result.Supervoxels++
+		}
+	}
+	result.Bodies = len(changedBodies)
+	if len(changedBodies) == 0 {
+		timedLog.Infof("Agglomeration of data %q at threshold %f made no changes", d.DataName(), threshold)
+		return
+	}
+
+	var lbls []uint64
+	for body := range changedBodies {
+		lbls = append(lbls, body)
+	}
+	if err = d.checkCheckouts(v, info.User, lbls...); err != nil {
+		return
+	}
+
+	// store the changed indices and mappings.
+	result.MutationID = b.firstMutID
+	for body := range changedBodies {
+		if err = b.saveIndex(body); err != nil {
+			return
+		}
+		idx, found := newIndices[body]
+		if !found {
+			if err = DeleteLabelIndex(d, v, body); err != nil {
+				return
+			}
+			continue
+		}
+		idx.LastMutId = result.MutationID
+		idx.LastModUser = info.User
+		idx.LastModTime = info.Time
+		idx.LastModApp = info.App
+		if err = PutLabelIndex(d, v, body, idx); err != nil {
+			return
+		}
+	}
+	var mappings proto.MappingOps
+	for body, svs := range remapped {
+		mapOp := &proto.MappingOp{Mutid: result.MutationID, Mapped: body}
+		for sv := range svs {
+			mapOp.Original = append(mapOp.Original, sv)
+		}
+		mappings.Mappings = append(mappings.Mappings, mapOp)
+	}
+	if err = d.ingestMappings(ctx, mappings, b); err != nil {
+		return
+	}
+	if err := b.runDeferred(); err != nil {
+		dvid.Errorf("agglomeration of data %q applied but not fully logged: %v\n", d.DataName(), err)
+	}
+
+	// send kafka agglomerate event to instance-uuid topic
+	sort.Slice(lbls, func(i, j int) bool { return lbls[i] < lbls[j] })
+	versionuuid, _ := datastore.UUIDFromVersion(v)
+	msginfo := map[string]interface{}{
+		"Action":     "agglomerate",
+		"Threshold":  threshold,
+		"ROI":        roiname,
+		"Labels":     lbls,
+		"MutationID": result.MutationID,
+		"UUID":       string(versionuuid),
+		"Timestamp":  time.Now().String(),
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	if err := d.ProduceKafkaMsg(jsonmsg); err != nil {
+		dvid.Errorf("error on sending agglomerate op to kafka: %v", err)
+	}
+
+	timedLog.Infof("Agglomerated data %q at threshold %f: %d supervoxels remapped, %d bodies changed", d.DataName(), threshold, result.Supervoxels, result.Bodies)
+	return
+}
@@ -0,0 +1,148 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func TestAffinityAgglomeration(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	for i := int32(0); i < 4; i++ {
+		vol.addSubvol(dvid.Point3d{32 * i, 0, 0}, dvid.Point3d{32, 64, 64}, uint64(i+1))
+	}
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	const slabSize = 32 * 64 * 64
+
+	table := proto.AffinityTable{
+		Table: map[uint64]*proto.Affinities{
+			1: {Labels: []uint64{2}, Affinities: []float32{0.9}},
+			3: {Labels: []uint64{2, 4}, Affinities: []float32{0.3, 0.8}},
+		},
+	}
+	serialization, err := table.Marshal()
+	if err != nil {
+		t.Fatalf("couldn't serialize affinities: %v\n", err)
+	}
+	server.TestHTTP(t, "POST", fmt.Sprintf("%snode/%s/labels/affinities", server.WebAPIPath, uuid), bytes.NewBuffer(serialization))
+
+	reqStr := fmt.Sprintf("%snode/%s/labels/affinities/2", server.WebAPIPath, uuid)
+	var affs []SupervoxelAffinity
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &affs); err != nil {
+		t.Fatalf("couldn't unmarshal affinities: %v\n", err)
+	}
+	if len(affs) != 2 || affs[0].Label != 1 || affs[0].Affinity != 0.9 || affs[1].Label != 3 || affs[1].Affinity != 0.3 {
+		t.Fatalf("unexpected affinities for supervoxel 2: %v\n", affs)
+	}
+
+	agglomerate := func(threshold string, expected []uint64) {
+		reqStr := fmt.Sprintf("%snode/%s/labels/agglomerate?threshold=%s", server.WebAPIPath, uuid, threshold)
+		var result AgglomerateResult
+		if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, nil), &result); err != nil {
+			t.Fatalf("couldn't unmarshal agglomerate result: %v\n", err)
+		}
+		mapped, _, err := d.GetMappedLabels(v, []uint64{1, 2, 3, 4})
+		if err != nil {
+			t.Fatalf("couldn't get mapped labels: %v\n", err)
+		}
+		bodySizes := make(map[uint64]uint64)
+		for i, label := range mapped {
+			if label != expected[i] {
+				t.Fatalf("expected mapping %v after agglomerating at %s, got %v\n", expected, threshold, mapped)
+			}
+			bodySizes[label] += slabSize
+		}
+		for body, size := range bodySizes {
+			checkLabelSize(t, d, v, body, false, size)
+		}
+	}
+	agglomerate("0.5", []uint64{1, 1, 3, 3})
+	agglomerate("0.2", []uint64{1, 1, 1, 1})
+	agglomerate("0.95", []uint64{1, 2, 3, 4})
+
+	if _, err := server.TestHTTPError(t, "POST", fmt.Sprintf("%snode/%s/labels/agglomerate", server.WebAPIPath, uuid), nil); err == nil {
+		t.Errorf("expected error on agglomerate without threshold\n")
+	}
+}
+
+func TestAgglomerateKeptBody(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	for i := int32(0); i < 4; i++ {
+		vol.addSubvol(dvid.Point3d{32 * i, 0, 0}, dvid.Point3d{32, 64, 64}, uint64(i+1))
+	}
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	const slabSize = 32 * 64 * 64
+
+	// body 1 has supervoxel 2, which has no affinities.
+	testMerge := mergeJSON(`[1, 2]`)
+	testMerge.send(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	table := proto.AffinityTable{
+		Table: map[uint64]*proto.Affinities{
+			1: {Labels: []uint64{3}, Affinities: []float32{0.9}},
+		},
+	}
+	serialization, err := table.Marshal()
+	if err != nil {
+		t.Fatalf("couldn't serialize affinities: %v\n", err)
+	}
+	server.TestHTTP(t, "POST", fmt.Sprintf("%snode/%s/labels/affinities", server.WebAPIPath, uuid), bytes.NewBuffer(serialization))
+	server.TestHTTP(t, "POST", fmt.Sprintf("%snode/%s/labels/agglomerate?threshold=0.5", server.WebAPIPath, uuid), nil)
+
+	// supervoxel 2 keeps body 1 while the component of supervoxels 1 and 3 gets a new body.
+	mapped, _, err := d.GetMappedLabels(v, []uint64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("couldn't get mapped labels: %v\n", err)
+	}
+	component := mapped[0]
+	if component == 1 || component == 2 || component == 3 || component == 4 {
+		t.Fatalf("expected new body for component kept apart from body 1, got mapping %v\n", mapped)
+	}
+	if mapped[1] != 1 || mapped[2] != component || mapped[3] != 4 {
+		t.Fatalf("unexpected mapping after agglomeration: %v\n", mapped)
+	}
+	checkLabelSize(t, d, v, 1, false, slabSize)
+	checkLabelSize(t, d, v, component, false, 2*slabSize)
+	checkLabelSize(t, d, v, 4, false, slabSize)
+}
@@ -0,0 +1,528 @@
+/*
+	This file supports batches of label mutations that are applied all-or-nothing under a
+	contiguous range of mutation IDs.  Undo information is journaled as each op is applied
+	and side effects visible outside the instance are deferred until the batch commits.
+*/
+
+package labelmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// MutationOp is a single op within a batch of mutations.  Exactly one of the op fields
+// should be set.  Any mutation ID in the op is ignored since the batch assigns them.
+type MutationOp struct {
+	Merge           *proto.MergeOp           `json:"merge,omitempty"`
+	Cleave          *proto.CleaveOp          `json:"cleave,omitempty"`
+	SplitSupervoxel *proto.SupervoxelSplitOp `json:"split-supervoxel,omitempty"`
+	Mapping         *proto.MappingOp         `json:"mapping,omitempty"`
+
+	// RLEs holds the split voxels of a split-supervoxel op using the same encoding as the
+	// body of a split-supervoxel POST.
+	RLEs []byte `json:"rles,omitempty"`
+}
+
+// MutationResult gives the mutation ID of a batched op and any labels it created.
+type MutationResult struct {
+	MutationID       uint64
+	CleavedLabel     uint64 `json:",omitempty"`
+	SplitSupervoxel  uint64 `json:",omitempty"`
+	RemainSupervoxel uint64 `json:",omitempty"`
+}
+
+// mutationBatch journals the state changed by the ops of a batch so they can be rolled
+// back, and holds the logging, notifications, and kafka messages of the ops until commit.
+// All methods used by the mutation code are no-ops or run immediately on a nil batch.
+type mutationBatch struct {
+	d          *Data
+	v          dvid.VersionID
+	firstMutID uint64
+	numOps     int
+
+	// state before the batch: serialized label indices (nil if none), supervoxel
+	// mappings, number of supervoxel split records per version, and scale 0 blocks
+	// (nil if none).
+	indices  map[uint64][]byte
+	mappings map[uint64]vmap
+	splits   map[uint8]int
+	blocks   map[dvid.IZYXString]*labels.PositionedBlock
+
+	// number of down-res mutations started by the ops, whose scale updates are only
+	// stopped when the down-res executes on commit.
+	numDownres int
+
+	// max label of the version and repo before the batch and after its last allocation of
+	// labels.  Rollback only restores them if no other request has changed them since.
+	maxSaved                       bool
+	maxFound                       bool
+	maxLabel, maxRepoLabel         uint64
+	lastMaxLabel, lastMaxRepoLabel uint64
+
+	deferred []func() error
+	msgs     []map[string]interface{}
+}
+
+func newMutationBatch(d *Data, v dvid.VersionID, firstMutID uint64, numOps int) *mutationBatch {
+	return &mutationBatch{
+		d:          d,
+		v:          v,
+		firstMutID: firstMutID,
+		numOps:     numOps,
+		indices:    make(map[uint64][]byte),
+		mappings:   make(map[uint64]vmap),
+		splits:     make(map[uint8]int),
+		blocks:     make(map[dvid.IZYXString]*labels.PositionedBlock),
+	}
+}
+
+// afterCommit runs fn immediately if there is no batch, otherwise when the batch commits.
+func (b *mutationBatch) afterCommit(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	b.deferred = append(b.deferred, fn)
+	return nil
+}
+
+// notify sends a sync message to subscribers immediately if there is no batch, otherwise
+// when the batch commits.
+func (b *mutationBatch) notify(evt datastore.SyncEvent, msg datastore.SyncMessage) error {
+	return b.afterCommit(func() error {
+		return datastore.NotifySubscribers(evt, msg)
+	})
+}
+
+// saveIndex journals the label's index if it hasn't been saved already in this batch.
+func (b *mutationBatch) saveIndex(label uint64) error {
+	if _, saved := b.indices[label]; saved {
+		return nil
+	}
+	idx, err := GetLabelIndex(b.d, b.v, label, false)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if idx != nil {
+		if data, err = idx.Marshal(); err != nil {
+			return err
+		}
+	}
+	b.indices[label] = data
+	return nil
+}
+
+// saveMapping journals the mapping of a supervoxel.  The SVMap lock must be held.
+func (b *mutationBatch) saveMapping(m *SVMap, supervoxel uint64) {
+	if b == nil {
+		return
+	}
+	if _, saved := b.mappings[supervoxel]; !saved {
+		b.mappings[supervoxel] = m.fm[supervoxel]
+	}
+}
+
+// saveSplits journals the supervoxel split records of a version.  The SVMap lock must be held.
+func (b *mutationBatch) saveSplits(m *SVMap, vid uint8) {
+	if b == nil {
+		return
+	}
+	if _, saved := b.splits[vid]; !saved {
+		b.splits[vid] = len(m.splits[vid])
+	}
+}
+
+// saveDownres journals a down-res mutation started by an op so its scale updates can be
+// stopped if the batch is rolled back.
+func (b *mutationBatch) saveDownres() {
+	if b == nil {
+		return
+	}
+	b.numDownres++
+}
+
+// reserveLabels journals the max labels around fn, which allocates or updates labels.
+func (b *mutationBatch) reserveLabels(fn func() error) error {
+	d := b.d
+	if !b.maxSaved {
+		d.mlMu.RLock()
+		b.maxLabel, b.maxFound = d.MaxLabel[b.v]
+		b.maxRepoLabel = d.MaxRepoLabel
+		d.mlMu.RUnlock()
+		b.maxSaved = true
+	}
+	err := fn()
+	d.mlMu.RLock()
+	b.lastMaxLabel = d.MaxLabel[b.v]
+	b.lastMaxRepoLabel = d.MaxRepoLabel
+	d.mlMu.RUnlock()
+	return err
+}
+
+// saveBlocks journals unmodified scale 0 blocks.
+func (b *mutationBatch) saveBlocks(blocks []*labels.PositionedBlock) {
+	if b == nil {
+		return
+	}
+	for _, pb := range blocks {
+		if _, saved := b.blocks[pb.BCoord]; !saved {
+			b.blocks[pb.BCoord] = pb
+		}
+	}
+}
+
+// saveNewBlock journals a scale 0 block coordinate that had no block before the batch.
+func (b *mutationBatch) saveNewBlock(bcoord dvid.IZYXString) {
+	if b == nil {
+		return
+	}
+	if _, saved := b.blocks[bcoord]; !saved {
+		b.blocks[bcoord] = nil
+	}
+}
+
+// produceMutationMsg sends a kafka message for a mutation or, if part of a batch, adds it
+// to the single message sent when the batch commits.
+func (d *Data) produceMutationMsg(b *mutationBatch, msginfo map[string]interface{}) error {
+	if b != nil {
+		b.msgs = append(b.msgs, msginfo)
+		return nil
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	return d.ProduceKafkaMsg(jsonmsg)
+}
+
+// ApplyMutations applies an ordered batch of mutations all-or-nothing under a contiguous
+// range of mutation IDs.  If an op fails, the earlier ops of the batch are rolled back.
+// Mutation logging, sync notifications, checkout changes and down-res of the ops are done
+// only after all ops succeed, and a single kafka message describes the whole batch.
+// Other label mutations on the instance wait until the batch is done.
+func (d *Data) ApplyMutations(v dvid.VersionID, ops []MutationOp, info dvid.ModInfo, downscale bool) ([]MutationResult, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no mutations given in batch")
+	}
+	for i, op := range ops {
+		if err := op.check(); err != nil {
+			return nil, fmt.Errorf("mutation %d of batch: %v", i, err)
+		}
+	}
+
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+
+	timedLog := dvid.NewTimeLog()
+	b := newMutationBatch(d, v, d.NewMutationIDs(uint64(len(ops))), len(ops))
+	results := make([]MutationResult, len(ops))
+	for i, op := range ops {
+		results[i].MutationID = b.firstMutID + uint64(i)
+		if err := b.apply(op, info, downscale, &results[i]); err != nil {
+			b.rollback()
+			if _, conflict := err.(CheckoutError); conflict {
+				return nil, err
+			}
+			return nil, fmt.Errorf("mutation %d of batch rolled back: %v", i, err)
+		}
+	}
+	if err := b.commit(); err != nil {
+		return results, err
+	}
+	timedLog.Infof("Applied batch of %d mutations (ids %d-%d) to data %q", len(ops), b.firstMutID, b.firstMutID+uint64(len(ops))-1, d.DataName())
+	return results, nil
+}
+
+// check makes sure exactly one op is given along with any required data.
+func (op MutationOp) check() error {
+	var numOps int
+	if op.Merge != nil {
+		numOps++
+	}
+	if op.Cleave != nil {
+		numOps++
+	}
+	if op.SplitSupervoxel != nil {
+		numOps++
+		if len(op.RLEs) == 0 {
+			return fmt.Errorf("split-supervoxel op requires split RLEs")
+		}
+	}
+	if op.Mapping != nil {
+		numOps++
+	}
+	if numOps != 1 {
+		return fmt.Errorf("each mutation must have exactly one op, not %d", numOps)
+	}
+	return nil
+}
+
+func (b *mutationBatch) apply(op MutationOp, info dvid.ModInfo, downscale bool, result *MutationResult) error {
+	d, v, mutID := b.d, b.v, result.MutationID
+	switch {
+	case op.Merge != nil:
+		tuple := append(labels.MergeTuple{op.Merge.Target}, op.Merge.Merged...)
+		mergeOp, err := tuple.Op()
+		if err != nil {
+			return err
+		}
+		mergeOp.MutID = mutID
+		if err := b.saveIndex(mergeOp.Target); err != nil {
+			return err
+		}
+		for label := range mergeOp.Merged {
+			if err := b.saveIndex(label); err != nil {
+				return err
+			}
+		}
+		return d.mergeLabels(v, mergeOp, info, mutID, b)
+
+	case op.Cleave != nil:
+		target := op.Cleave.Target
+		if target == 0 {
+			return fmt.Errorf("label 0 is protected background value and cannot be used as cleave target")
+		}
+		if len(op.Cleave.Cleaved) == 0 {
+			return fmt.Errorf("no supervoxels given for cleave of label %d", target)
+		}
+		if err := d.checkCheckouts(v, info.User, target); err != nil {
+			return err
+		}
+		cleavedLabel := op.Cleave.Cleavedlabel
+		if cleavedLabel != 0 {
+			idx, err := GetLabelIndex(d, v, cleavedLabel, false)
+			if err != nil {
+				return err
+			}
+			if idx != nil {
+				return fmt.Errorf("cannot cleave into existing label %d", cleavedLabel)
+			}
+		}
+		err := b.reserveLabels(func() (err error) {
+			if cleavedLabel == 0 {
+				cleavedLabel, err = d.newLabel(v)
+			} else {
+				_, err = d.updateMaxLabel(v, cleavedLabel)
+			}
+			return
+		})
+		if err != nil {
+			return err
+		}
+		result.CleavedLabel = cleavedLabel
+		if err := b.saveIndex(target); err != nil {
+			return err
+		}
+		if err := b.saveIndex(cleavedLabel); err != nil {
+			return err
+		}
+		cleaveOp := labels.CleaveOp{
+			MutID:              mutID,
+			Target:             target,
+			CleavedLabel:       cleavedLabel,
+			CleavedSupervoxels: op.Cleave.Cleaved,
+		}
+		return d.cleaveLabel(v, cleaveOp, info, b)
+
+	case op.SplitSupervoxel != nil:
+		svlabel := op.SplitSupervoxel.Supervoxel
+		if svlabel == 0 {
+			return fmt.Errorf("label 0 is protected background value and cannot be used as split target")
+		}
+		split, err := dvid.ReadRLEs(bytes.NewReader(op.RLEs))
+		if err != nil {
+			return err
+		}
+		var splitSupervoxel, remainSupervoxel uint64
+		err = b.reserveLabels(func() (err error) {
+			splitSupervoxel, remainSupervoxel, err = d.splitSupervoxelLabels(v, op.SplitSupervoxel.Splitlabel, op.SplitSupervoxel.Remainlabel)
+			return
+		})
+		if err != nil {
+			return err
+		}
+		result.SplitSupervoxel = splitSupervoxel
+		result.RemainSupervoxel = remainSupervoxel
+
+		mapping, err := getMapping(d, v)
+		if err != nil {
+			return err
+		}
+		label := svlabel
+		if mapped, found := mapping.MappedLabel(v, svlabel); found {
+			label = mapped
+		}
+		if err := b.saveIndex(label); err != nil {
+			return err
+		}
+		svOp := labels.SplitSupervoxelOp{
+			MutID:            mutID,
+			Supervoxel:       svlabel,
+			SplitSupervoxel:  splitSupervoxel,
+			RemainSupervoxel: remainSupervoxel,
+		}
+		return d.splitSupervoxel(v, svOp, split, info, downscale, b)
+
+	default:
+		mapOp := *op.Mapping
+		mapOp.Mutid = mutID
+		mappings := proto.MappingOps{Mappings: []*proto.MappingOp{&mapOp}}
+		if err := d.checkMappingCheckouts(v, info.User, mappings); err != nil {
+			return err
+		}
+		versionuuid, _ := datastore.UUIDFromVersion(v)
+		msginfo := map[string]interface{}{
+			"Action":     "mapping",
+			"Mapped":     mapOp.Mapped,
+			"Original":   mapOp.Original,
+			"MutationID": mutID,
+			"UUID":       string(versionuuid),
+			"Timestamp":  time.Now().String(),
+		}
+		d.produceMutationMsg(b, msginfo)
+		ctx := datastore.NewVersionedCtx(d, v)
+		return d.ingestMappings(ctx, mappings, b)
+	}
+}
+
+// rollback restores the blocks, label indices, mappings and max labels changed by the batch
+// and ends the scale updates of down-res mutations that will not be executed.
+func (b *mutationBatch) rollback() {
+	d, v := b.d, b.v
+	dvid.Infof("Rolling back batch of mutations %d-%d for data %q\n", b.firstMutID, b.firstMutID+uint64(b.numOps)-1, d.DataName())
+
+	for i := 0; i < b.numDownres; i++ {
+		for scale := uint8(1); scale <= d.MaxDownresLevel; scale++ {
+			d.StopScaleUpdate(scale)
+		}
+	}
+
+	b.restoreBlocks()
+	for label, data := range b.indices {
+		var err error
+		if data == nil {
+			err = DeleteLabelIndex(d, v, label)
+		} else {
+			idx := new(labels.Index)
+			if err = idx.Unmarshal(data); err == nil {
+				err = PutLabelIndex(d, v, label, idx)
+			}
+		}
+		if err != nil {
+			dvid.Criticalf("unable to restore label %d index, data %q after failed mutation batch: %v\n", label, d.DataName(), err)
+		}
+	}
+	b.restoreMaxLabel()
+	m, err := getMapping(d, v)
+	if err != nil {
+		dvid.Criticalf("unable to restore mappings, data %q after failed mutation batch: %v\n", d.DataName(), err)
+		return
+	}
+	m.Lock()
+	for supervoxel, vm := range b.mappings {
+		if vm == nil {
+			delete(m.fm, supervoxel)
+		} else {
+			m.fm[supervoxel] = vm
+		}
+	}
+	for vid, numSplits := range b.splits {
+		m.splits[vid] = m.splits[vid][:numSplits]
+	}
+	m.Unlock()
+}
+
+// restoreBlocks puts back the journaled scale 0 blocks and deletes blocks the batch created.
+func (b *mutationBatch) restoreBlocks() {
+	d := b.d
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		dvid.Criticalf("unable to restore blocks, data %q after failed mutation batch: %v\n", d.DataName(), err)
+		return
+	}
+	ctx := datastore.NewVersionedCtx(d, b.v)
+	var scale uint8
+	for bcoord, pb := range b.blocks {
+		if pb == nil {
+			err = store.Delete(ctx, NewBlockTKeyByCoord(scale, bcoord))
+		} else {
+			err = d.putLabelBlock(ctx, scale, pb)
+		}
+		if err != nil {
+			dvid.Criticalf("unable to restore block %s, data %q after failed mutation batch: %v\n", bcoord, d.DataName(), err)
+		}
+	}
+}
+
+// restoreMaxLabel returns the max labels to their values before the batch unless another
+// request has allocated labels since the batch last did, in which case the labels taken by
+// the batch are left unused.
+func (b *mutationBatch) restoreMaxLabel() {
+	if !b.maxSaved {
+		return
+	}
+	d, v := b.d, b.v
+	d.mlMu.Lock()
+	defer d.mlMu.Unlock()
+	if d.MaxLabel[v] != b.lastMaxLabel || d.MaxRepoLabel != b.lastMaxRepoLabel {
+		dvid.Infof("Labels up to %d allocated by rolled back batch of data %q are left unused\n", b.lastMaxRepoLabel, d.DataName())
+		return
+	}
+	if b.maxFound {
+		d.MaxLabel[v] = b.maxLabel
+		if err := d.persistMaxLabel(v); err != nil {
+			dvid.Criticalf("unable to restore max label, data %q after failed mutation batch: %v\n", d.DataName(), err)
+		}
+	} else {
+		delete(d.MaxLabel, v)
+		if err := d.deleteMaxLabel(v); err != nil {
+			dvid.Criticalf("unable to restore max label, data %q after failed mutation batch: %v\n", d.DataName(), err)
+		}
+	}
+	d.MaxRepoLabel = b.maxRepoLabel
+	if err := d.persistMaxRepoLabel(); err != nil {
+		dvid.Criticalf("unable to restore repo max label, data %q after failed mutation batch: %v\n", d.DataName(), err)
+	}
+}
+
+// runDeferred does the deferred work of all ops in order, returning the last error.
+func (b *mutationBatch) runDeferred() error {
+	var err error
+	for _, fn := range b.deferred {
+		if fnErr := fn(); fnErr != nil {
+			dvid.Errorf("error completing mutation batch %d-%d, data %q: %v\n", b.firstMutID, b.firstMutID+uint64(b.numOps)-1, b.d.DataName(), fnErr)
+			err = fnErr
+		}
+	}
+	return err
+}
+
+// commit does the deferred work of all ops in order and sends the batch kafka message.
+// Since the ops have been applied, errors are returned but cannot be rolled back.
+func (b *mutationBatch) commit() error {
+	err := b.runDeferred()
+
+	// send kafka batch event to instance-uuid topic
+	versionuuid, _ := datastore.UUIDFromVersion(b.v)
+	msginfo := map[string]interface{}{
+		"Action":          "batch",
+		"FirstMutationID": b.firstMutID,
+		"LastMutationID":  b.firstMutID + uint64(b.numOps) - 1,
+		"Mutations":       b.msgs,
+		"UUID":            string(versionuuid),
+		"Timestamp":       time.Now().String(),
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	if kafkaErr := b.d.ProduceKafkaMsg(jsonmsg); kafkaErr != nil {
+		dvid.Errorf("error on sending mutation batch to kafka: %v", kafkaErr)
+	}
+	if err != nil {
+		return fmt.Errorf("mutation batch applied but not fully logged or synced: %v", err)
+	}
+	return nil
+}
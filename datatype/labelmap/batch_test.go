@@ -0,0 +1,179 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+// returns the binary sparse volume for a box of voxels.
+func boxSparseVol(t *testing.T, origin, size dvid.Point3d) []byte {
+	var rles dvid.RLEs
+	for z := origin[2]; z < origin[2]+size[2]; z++ {
+		for y := origin[1]; y < origin[1]+size[1]; y++ {
+			rles = append(rles, dvid.NewRLE(dvid.Point3d{origin[0], y, z}, size[0]))
+		}
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteByte(dvid.EncodingBinary)
+	binary.Write(buf, binary.LittleEndian, uint8(3))
+	binary.Write(buf, binary.LittleEndian, byte(0))
+	buf.WriteByte(byte(0))
+	binary.Write(buf, binary.LittleEndian, uint32(0))
+	binary.Write(buf, binary.LittleEndian, uint32(len(rles)))
+	rleBytes, err := rles.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unable to serialize RLEs: %v\n", err)
+	}
+	buf.Write(rleBytes)
+	return buf.Bytes()
+}
+
+func checkLabelSize(t *testing.T, d *Data, v dvid.VersionID, label uint64, supervoxel bool, expected uint64) {
+	size, err := GetLabelSize(d, v, label, supervoxel)
+	if err != nil {
+		t.Fatalf("couldn't get size of label %d: %v\n", label, err)
+	}
+	if size != expected {
+		t.Errorf("expected label %d (supervoxel %t) to have %d voxels, got %d\n", label, supervoxel, expected, size)
+	}
+}
+
+func TestMutationBatch(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	config.Set("MaxDownresLevel", "2")
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	for i := int32(0); i < 4; i++ {
+		vol.addSubvol(dvid.Point3d{32 * i, 0, 0}, dvid.Point3d{32, 64, 64}, uint64(i+1))
+	}
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	const slabSize = 32 * 64 * 64
+
+	ops := []MutationOp{
+		{Merge: &proto.MergeOp{Target: 1, Merged: []uint64{2}}},
+		{Merge: &proto.MergeOp{Target: 3, Merged: []uint64{4}}},
+		{Cleave: &proto.CleaveOp{Target: 1, Cleaved: []uint64{2}}},
+		{
+			SplitSupervoxel: &proto.SupervoxelSplitOp{Supervoxel: 3},
+			RLEs:            boxSparseVol(t, dvid.Point3d{64, 0, 0}, dvid.Point3d{16, 64, 64}),
+		},
+	}
+	opsJSON, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("couldn't marshal ops: %v\n", err)
+	}
+	reqStr := fmt.Sprintf("%snode/%s/labels/mutations", server.WebAPIPath, uuid)
+	r := server.TestHTTP(t, "POST", reqStr, bytes.NewBuffer(opsJSON))
+	var results []MutationResult
+	if err := json.Unmarshal(r, &results); err != nil {
+		t.Fatalf("couldn't unmarshal batch results %q: %v\n", string(r), err)
+	}
+	if len(results) != len(ops) {
+		t.Fatalf("expected %d results, got %s\n", len(ops), string(r))
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].MutationID != results[0].MutationID+uint64(i) {
+			t.Errorf("expected consecutive mutation ids, got %s\n", string(r))
+		}
+	}
+	cleaved := results[2].CleavedLabel
+	splitSV, remainSV := results[3].SplitSupervoxel, results[3].RemainSupervoxel
+	if cleaved == 0 || splitSV == 0 || remainSV == 0 {
+		t.Fatalf("expected new labels from cleave and supervoxel split, got %s\n", string(r))
+	}
+	checkLabelSize(t, d, v, 1, false, slabSize)
+	checkLabelSize(t, d, v, cleaved, false, slabSize)
+	checkLabelSize(t, d, v, 3, false, 2*slabSize)
+	checkLabelSize(t, d, v, splitSV, true, slabSize/2)
+	mapped, _, err := d.GetMappedLabels(v, []uint64{2, 3, 4, splitSV, remainSV})
+	if err != nil {
+		t.Fatalf("couldn't get mapped labels: %v\n", err)
+	}
+	expected := []uint64{cleaved, 0, 3, 3, 3}
+	for i := range expected {
+		if mapped[i] != expected[i] {
+			t.Fatalf("expected mapping %v after batch, got %v\n", expected, mapped)
+		}
+	}
+
+	// a failing op rolls back the earlier merge and supervoxel split.
+	ops = []MutationOp{
+		{Merge: &proto.MergeOp{Target: 1, Merged: []uint64{3}}},
+		{
+			SplitSupervoxel: &proto.SupervoxelSplitOp{Supervoxel: 4},
+			RLEs:            boxSparseVol(t, dvid.Point3d{96, 0, 0}, dvid.Point3d{16, 64, 64}),
+		},
+		{Cleave: &proto.CleaveOp{Target: 1, Cleaved: []uint64{999}}},
+	}
+	if opsJSON, err = json.Marshal(ops); err != nil {
+		t.Fatalf("couldn't marshal ops: %v\n", err)
+	}
+	maxRepoLabel := d.MaxRepoLabel
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBuffer(opsJSON)); err == nil {
+		t.Fatalf("expected error on batch with bad cleave\n")
+	}
+	if d.MaxRepoLabel != maxRepoLabel {
+		t.Errorf("expected max label %d restored after rollback, got %d\n", maxRepoLabel, d.MaxRepoLabel)
+	}
+	checkLabelSize(t, d, v, 1, false, slabSize)
+	checkLabelSize(t, d, v, 3, false, 2*slabSize)
+	checkLabelSize(t, d, v, 4, true, slabSize)
+	if mapped, _, err = d.GetMappedLabels(v, []uint64{splitSV, remainSV, 4}); err != nil {
+		t.Fatalf("couldn't get mapped labels: %v\n", err)
+	}
+	for i, label := range mapped {
+		if label != 3 {
+			t.Fatalf("expected supervoxels mapped to 3 after rollback, got %v (index %d)\n", mapped, i)
+		}
+	}
+	sv, err := d.GetLabelAtScaledPoint(v, dvid.Point3d{100, 10, 10}, 0, true)
+	if err != nil {
+		t.Fatalf("couldn't get supervoxel at point: %v\n", err)
+	}
+	if sv != 4 {
+		t.Errorf("expected supervoxel 4 voxels restored after rollback, got %d\n", sv)
+	}
+	for scale := uint8(1); scale <= d.MaxDownresLevel; scale++ {
+		if d.ScaleUpdating(scale) {
+			t.Errorf("expected no update of scale %d after rollback of supervoxel split\n", scale)
+		}
+	}
+	if d.AnyScaleUpdating() {
+		t.Errorf("expected no scale updates after rollback\n")
+	}
+
+	// checkouts by other users reject the whole batch.
+	if _, err := d.CheckoutLabel(v, 3, "alice", DefaultCheckoutTTL); err != nil {
+		t.Fatalf("couldn't checkout label 3: %v\n", err)
+	}
+	ops = []MutationOp{{Merge: &proto.MergeOp{Target: 1, Merged: []uint64{3}}}}
+	if opsJSON, err = json.Marshal(ops); err != nil {
+		t.Fatalf("couldn't marshal ops: %v\n", err)
+	}
+	if resp := server.TestHTTPResponse(t, "POST", reqStr+"?u=bob", bytes.NewBuffer(opsJSON)); resp.Code != http.StatusConflict {
+		t.Errorf("expected conflict for batch with checked out label, got status %d\n", resp.Code)
+	}
+}
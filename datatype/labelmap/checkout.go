@@ -0,0 +1,283 @@
+/*
+	This file supports checkout of labels by users so concurrent proofreaders don't mutate
+	the same label.  Checkouts are stored per version and expire after a given TTL.
+*/
+
+package labelmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+// DefaultCheckoutTTL is the duration of a label checkout if no TTL is given.
+const DefaultCheckoutTTL = time.Hour
+
+// LabelCheckout records a user's exclusive right to mutate a label until it expires.
+type LabelCheckout struct {
+	Label   uint64
+	User    string
+	Created time.Time
+	Expires time.Time
+}
+
+// CheckoutError is returned when a label is checked out by another user.
+type CheckoutError struct {
+	LabelCheckout
+}
+
+func (e CheckoutError) Error() string {
+	return fmt.Sprintf("label %d is checked out by user %q until %s", e.Label, e.User, e.Expires.Format(time.RFC3339))
+}
+
+// returns nil if the label has no checkout or it has expired.
+func getCheckout(ctx *datastore.VersionedCtx, label uint64) (*LabelCheckout, error) {
+	store, err := datastore.GetKeyValueDB(ctx.Data())
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(ctx, NewCheckoutTKey(label))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	co := new(LabelCheckout)
+	if err := json.Unmarshal(data, co); err != nil {
+		return nil, fmt.Errorf("bad checkout for label %d in data %q: %v", label, ctx.Data().DataName(), err)
+	}
+	if time.Now().After(co.Expires) {
+		return nil, nil
+	}
+	return co, nil
+}
+
+func putCheckout(ctx *datastore.VersionedCtx, co *LabelCheckout) error {
+	store, err := datastore.GetKeyValueDB(ctx.Data())
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(co)
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, NewCheckoutTKey(co.Label), data)
+}
+
+func deleteCheckout(ctx *datastore.VersionedCtx, label uint64) error {
+	store, err := datastore.GetKeyValueDB(ctx.Data())
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, NewCheckoutTKey(label))
+}
+
+// CheckoutLabel gives the user exclusive rights to mutate a label for the given TTL.
+// A user may renew their own checkout, but a CheckoutError is returned if the label is
+// checked out by another user.
+func (d *Data) CheckoutLabel(v dvid.VersionID, label uint64, user string, ttl time.Duration) (*LabelCheckout, error) {
+	if user == "" {
+		return nil, fmt.Errorf("a user must be specified to checkout label %d", label)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("checkout TTL must be positive, not %s", ttl)
+	}
+	if d.IndexedLabels {
+		idx, err := GetLabelIndex(d, v, label, false)
+		if err != nil {
+			return nil, err
+		}
+		if idx == nil {
+			return nil, fmt.Errorf("can't checkout non-existent label %d", label)
+		}
+	}
+	// Mutations check checkouts and mutate while holding batchMu, so taking it exclusively
+	// keeps a new checkout from landing between another user's check and mutation.
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+	d.checkoutMu.Lock()
+	defer d.checkoutMu.Unlock()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	co, err := getCheckout(ctx, label)
+	if err != nil {
+		return nil, err
+	}
+	if co != nil && co.User != user {
+		return nil, CheckoutError{*co}
+	}
+	now := time.Now()
+	if co == nil {
+		co = &LabelCheckout{Label: label, User: user, Created: now}
+	}
+	co.Expires = now.Add(ttl)
+	if err := putCheckout(ctx, co); err != nil {
+		return nil, err
+	}
+	return co, nil
+}
+
+// ReleaseLabel removes a user's checkout of a label.  A CheckoutError is returned if the
+// label is checked out by another user unless force is true.
+func (d *Data) ReleaseLabel(v dvid.VersionID, label uint64, user string, force bool) error {
+	d.checkoutMu.Lock()
+	defer d.checkoutMu.Unlock()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	co, err := getCheckout(ctx, label)
+	if err != nil {
+		return err
+	}
+	if co != nil && co.User != user && !force {
+		return CheckoutError{*co}
+	}
+	return deleteCheckout(ctx, label)
+}
+
+// GetCheckout returns the current checkout of a label or nil if it is not checked out.
+func (d *Data) GetCheckout(v dvid.VersionID, label uint64) (*LabelCheckout, error) {
+	return getCheckout(datastore.NewVersionedCtx(d, v), label)
+}
+
+// GetCheckouts returns all unexpired checkouts in order of label.
+func (d *Data) GetCheckouts(v dvid.VersionID) ([]LabelCheckout, error) {
+	store, err := datastore.GetOrderedKeyValueDB(d)
+	if err != nil {
+		return nil, err
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	now := time.Now()
+	checkouts := []LabelCheckout{}
+	begTKey := NewCheckoutTKey(0)
+	endTKey := NewCheckoutTKey(math.MaxUint64)
+	err = store.ProcessRange(ctx, begTKey, endTKey, &storage.ChunkOp{}, func(c *storage.Chunk) error {
+		if c == nil || c.TKeyValue == nil || len(c.TKeyValue.V) == 0 {
+			return nil
+		}
+		var co LabelCheckout
+		if err := json.Unmarshal(c.TKeyValue.V, &co); err != nil {
+			return err
+		}
+		if now.Before(co.Expires) {
+			checkouts = append(checkouts, co)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(checkouts, func(i, j int) bool { return checkouts[i].Label < checkouts[j].Label })
+	return checkouts, nil
+}
+
+// checkCheckouts returns a CheckoutError if any of the given labels is checked out by a
+// user other than the given one.  Callers must hold batchMu through the guarded mutation.
+func (d *Data) checkCheckouts(v dvid.VersionID, user string, lbls ...uint64) error {
+	d.checkoutMu.Lock()
+	defer d.checkoutMu.Unlock()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	for _, label := range lbls {
+		co, err := getCheckout(ctx, label)
+		if err != nil {
+			return err
+		}
+		if co != nil && co.User != user {
+			return CheckoutError{*co}
+		}
+	}
+	return nil
+}
+
+// checkMappingCheckouts returns a CheckoutError if any label that would lose or gain
+// supervoxels through the given mappings is checked out by a user other than the given one.
+func (d *Data) checkMappingCheckouts(v dvid.VersionID, user string, mappings proto.MappingOps) error {
+	m, err := getMapping(d, v)
+	if err != nil {
+		return err
+	}
+	lblSet := make(map[uint64]struct{})
+	for _, mapOp := range mappings.Mappings {
+		lblSet[mapOp.Mapped] = struct{}{}
+		for _, supervoxel := range mapOp.Original {
+			label, _ := m.MappedLabel(v, supervoxel)
+			lblSet[label] = struct{}{}
+		}
+	}
+	lbls := make([]uint64, 0, len(lblSet))
+	for label := range lblSet {
+		lbls = append(lbls, label)
+	}
+	return d.checkCheckouts(v, user, lbls...)
+}
+
+// mergeCheckouts moves any checkouts of merged labels to the merge target, keeping the
+// latest expiration.
+func (d *Data) mergeCheckouts(v dvid.VersionID, op labels.MergeOp) {
+	d.checkoutMu.Lock()
+	defer d.checkoutMu.Unlock()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	target, err := getCheckout(ctx, op.Target)
+	if err != nil {
+		dvid.Errorf("unable to get checkout of label %d in data %q: %v\n", op.Target, d.DataName(), err)
+		return
+	}
+	changed := false
+	for merged := range op.Merged {
+		co, err := getCheckout(ctx, merged)
+		if err != nil {
+			dvid.Errorf("unable to get checkout of label %d in data %q: %v\n", merged, d.DataName(), err)
+			continue
+		}
+		if co == nil {
+			continue
+		}
+		if target == nil {
+			target = co
+			target.Label = op.Target
+			changed = true
+		} else if co.User == target.User && co.Expires.After(target.Expires) {
+			target.Expires = co.Expires
+			changed = true
+		}
+		if err := deleteCheckout(ctx, merged); err != nil {
+			dvid.Errorf("unable to delete checkout of merged label %d in data %q: %v\n", merged, d.DataName(), err)
+		}
+	}
+	if changed {
+		if err := putCheckout(ctx, target); err != nil {
+			dvid.Errorf("unable to move checkouts to merged label %d in data %q: %v\n", op.Target, d.DataName(), err)
+		}
+	}
+}
+
+// copyCheckout gives a new label created from a checked out label the same checkout.
+func (d *Data) copyCheckout(v dvid.VersionID, from, to uint64) {
+	d.checkoutMu.Lock()
+	defer d.checkoutMu.Unlock()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	co, err := getCheckout(ctx, from)
+	if err != nil {
+		dvid.Errorf("unable to get checkout of label %d in data %q: %v\n", from, d.DataName(), err)
+		return
+	}
+	if co == nil {
+		return
+	}
+	co.Label = to
+	if err := putCheckout(ctx, co); err != nil {
+		dvid.Errorf("unable to copy checkout of label %d to label %d in data %q: %v\n", from, to, d.DataName(), err)
+	}
+}
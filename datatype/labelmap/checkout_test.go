@@ -0,0 +1,139 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func getCheckoutJSON(t *testing.T, uuid dvid.UUID, label uint64) (co LabelCheckout, found bool) {
+	reqStr := fmt.Sprintf("%snode/%s/labels/checkout/%d", server.WebAPIPath, uuid, label)
+	resp := server.TestHTTPResponse(t, "GET", reqStr, nil)
+	if resp.Code == http.StatusNotFound {
+		return
+	}
+	if resp.Code != http.StatusOK {
+		t.Fatalf("bad status %d getting checkout of label %d: %s\n", resp.Code, label, resp.Body.String())
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &co); err != nil {
+		t.Fatalf("couldn't unmarshal checkout %q: %v\n", resp.Body.String(), err)
+	}
+	return co, true
+}
+
+func TestLabelCheckout(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(64, 64, 64)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{20, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{20, 0, 0}, dvid.Point3d{20, 64, 64}, 2)
+	vol.addSubvol(dvid.Point3d{40, 0, 0}, dvid.Point3d{24, 64, 64}, 3)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	checkoutURL := fmt.Sprintf("%snode/%s/labels/checkout/1", server.WebAPIPath, uuid)
+	server.TestHTTP(t, "POST", checkoutURL+"?u=alice&ttl=60", nil)
+	if resp := server.TestHTTPResponse(t, "POST", checkoutURL+"?u=bob", nil); resp.Code != http.StatusConflict {
+		t.Fatalf("expected conflict checking out label held by another user, got status %d\n", resp.Code)
+	}
+	if _, err := server.TestHTTPError(t, "POST", fmt.Sprintf("%snode/%s/labels/checkout/99?u=bob", server.WebAPIPath, uuid), nil); err == nil {
+		t.Errorf("expected error checking out non-existent label\n")
+	}
+
+	// only the checkout's user can merge the label, and the checkout moves to the target.
+	mergeURL := fmt.Sprintf("%snode/%s/labels/merge", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "POST", mergeURL+"?u=bob", bytes.NewBufferString("[2, 1]")); resp.Code != http.StatusConflict {
+		t.Fatalf("expected conflict merging label checked out by another user, got status %d\n", resp.Code)
+	}
+	server.TestHTTP(t, "POST", mergeURL+"?u=alice", bytes.NewBufferString("[2, 1]"))
+	if _, found := getCheckoutJSON(t, uuid, 1); found {
+		t.Errorf("expected checkout of merged label 1 to be removed\n")
+	}
+	co, found := getCheckoutJSON(t, uuid, 2)
+	if !found || co.User != "alice" {
+		t.Fatalf("expected checkout of merged label to move to target 2, got %v\n", co)
+	}
+
+	reqStr := fmt.Sprintf("%snode/%s/labels/checkouts", server.WebAPIPath, uuid)
+	var checkouts []LabelCheckout
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &checkouts); err != nil {
+		t.Fatalf("couldn't unmarshal checkouts: %v\n", err)
+	}
+	if len(checkouts) != 1 || checkouts[0].Label != 2 {
+		t.Errorf("expected only label 2 to be checked out, got %v\n", checkouts)
+	}
+
+	releaseURL := fmt.Sprintf("%snode/%s/labels/release/2", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "POST", releaseURL+"?u=bob", nil); resp.Code != http.StatusConflict {
+		t.Fatalf("expected conflict releasing label checked out by another user, got status %d\n", resp.Code)
+	}
+	server.TestHTTP(t, "POST", releaseURL+"?u=bob&force=true", nil)
+	if _, found := getCheckoutJSON(t, uuid, 2); found {
+		t.Errorf("expected label 2 checkout to be released\n")
+	}
+
+	// expired checkouts don't block other users.
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	if _, err := d.CheckoutLabel(v, 3, "alice", time.Millisecond); err != nil {
+		t.Fatalf("couldn't checkout label 3: %v\n", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := d.checkCheckouts(v, "bob", 3); err != nil {
+		t.Errorf("expected expired checkout to be ignored, got %v\n", err)
+	}
+	if _, err := d.CheckoutLabel(v, 3, "bob", time.Minute); err != nil {
+		t.Errorf("expected checkout of label with expired checkout to succeed: %v\n", err)
+	}
+
+	// mappings can't move supervoxels out of a label checked out by another user.
+	mappings := proto.MappingOps{Mappings: []*proto.MappingOp{{Mapped: 2, Original: []uint64{3}}}}
+	serialization, err := mappings.Marshal()
+	if err != nil {
+		t.Fatalf("couldn't serialize mappings: %v\n", err)
+	}
+	mappingsURL := fmt.Sprintf("%snode/%s/labels/mappings", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "POST", mappingsURL+"?u=alice", bytes.NewBuffer(serialization)); resp.Code != http.StatusConflict {
+		t.Fatalf("expected conflict mapping supervoxel of label checked out by another user, got status %d\n", resp.Code)
+	}
+	server.TestHTTP(t, "POST", mappingsURL+"?u=bob", bytes.NewBuffer(serialization))
+
+	// a checkout waits for in-flight mutations that already passed their checkout check.
+	d.batchMu.RLock()
+	if err := d.checkCheckouts(v, "bob", 1); err != nil {
+		t.Fatalf("expected label 1 to be free for bob: %v\n", err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := d.CheckoutLabel(v, 1, "alice", time.Minute)
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Errorf("expected checkout to wait for mutation holding batchMu\n")
+	case <-time.After(50 * time.Millisecond):
+	}
+	d.batchMu.RUnlock()
+	if err := <-done; err != nil {
+		t.Errorf("couldn't checkout label 1 after mutation finished: %v\n", err)
+	}
+}
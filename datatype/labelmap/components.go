@@ -0,0 +1,329 @@
+/*
+	This file supports finding the connected components of a label's voxels and relabeling
+	disconnected pieces of a body.
+*/
+
+package labelmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// LabelComponent describes a connected piece of a label.  If the piece was relabeled,
+// the new label and the mutation ID of its split are given.
+type LabelComponent struct {
+	Size       uint64
+	MinPoint   dvid.Point3d
+	MaxPoint   dvid.Point3d
+	Label      uint64 `json:",omitempty"`
+	MutationID uint64 `json:",omitempty"`
+}
+
+// LabelComponents gives the connected components of a label in order of decreasing size.
+type LabelComponents struct {
+	Label        uint64
+	Connectivity int
+	Components   []LabelComponent
+}
+
+// a run of voxels along x.
+type componentRun struct {
+	x0, x1 int32
+	id     int
+}
+
+// row of voxels at a given y and z.
+type componentRow struct {
+	y, z int32
+}
+
+// offsets in (dy, dz) to neighboring rows checked for each connectivity, where each pair
+// of neighboring rows is only checked once.
+var componentRowOffsets = map[int][][2]int32{
+	6:  {{1, 0}, {0, 1}},
+	26: {{1, 0}, {-1, 1}, {0, 1}, {1, 1}},
+}
+
+// maximum number of voxel runs of a label held in memory to find its components.
+var maxComponentRuns = 1 << 24
+
+// getLabelRuns returns the runs of voxels of a label for each row.  Runs are split at
+// block boundaries.  An error is returned if the label has more than maxComponentRuns runs.
+func (d *Data) getLabelRuns(ctx *datastore.VersionedCtx, label uint64, isSupervoxel bool) (map[componentRow][]componentRun, int, error) {
+	idx, err := d.getContactIndex(ctx, label, isSupervoxel)
+	if err != nil || idx == nil {
+		return nil, 0, err
+	}
+	blockSize, ok := d.BlockSize().(dvid.Point3d)
+	if !ok {
+		return nil, 0, fmt.Errorf("block size for %s wasn't 3d", d.DataName())
+	}
+	scanner, err := d.newContactScanner(ctx, 0, isSupervoxel)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows := make(map[componentRow][]componentRun)
+	var numRuns int
+	for _, izyx := range idx.GetBlockIndices() {
+		bcoord, err := izyx.ToChunkPoint3d()
+		if err != nil {
+			return nil, 0, err
+		}
+		cb, err := scanner.getBlock(bcoord)
+		if err != nil {
+			return nil, 0, err
+		}
+		delete(scanner.blocks, izyx) // blocks are only read once.
+		if cb == nil {
+			continue
+		}
+		if cb.size != blockSize {
+			return nil, 0, fmt.Errorf("block %s has size %s, expected %s", bcoord, cb.size, blockSize)
+		}
+		offset, _ := bcoord.BoundingVoxels(blockSize)
+		var i int
+		for z := int32(0); z < blockSize[2]; z++ {
+			for y := int32(0); y < blockSize[1]; y++ {
+				row := componentRow{offset[1] + y, offset[2] + z}
+				inRun := false
+				for x := int32(0); x < blockSize[0]; x, i = x+1, i+1 {
+					if cb.lbls[i] != label {
+						inRun = false
+						continue
+					}
+					if inRun {
+						rows[row][len(rows[row])-1].x1++
+						continue
+					}
+					inRun = true
+					if numRuns == maxComponentRuns {
+						return nil, 0, fmt.Errorf("label %d has more than %d runs of voxels, too many to find components", label, maxComponentRuns)
+					}
+					rows[row] = append(rows[row], componentRun{x0: offset[0] + x, x1: offset[0] + x, id: numRuns})
+					numRuns++
+				}
+			}
+		}
+	}
+	for _, runs := range rows {
+		sort.Slice(runs, func(i, j int) bool { return runs[i].x0 < runs[j].x0 })
+	}
+	return rows, numRuns, nil
+}
+
+// simple union-find over run ids.
+type runUnionFind []int
+
+func (uf runUnionFind) find(id int) int {
+	for uf[id] != id {
+		uf[id] = uf[uf[id]]
+		id = uf[id]
+	}
+	return id
+}
+
+func (uf runUnionFind) union(id1, id2 int) {
+	root1, root2 := uf.find(id1), uf.find(id2)
+	if root1 < root2 {
+		uf[root2] = root1
+	} else if root2 < root1 {
+		uf[root1] = root2
+	}
+}
+
+// GetComponents returns the 6- or 26-connected components of a label's voxels in order of
+// decreasing size.  A nil result is returned if the label is not found.
+func (d *Data) GetComponents(v dvid.VersionID, label uint64, isSupervoxel bool, connectivity int) (*LabelComponents, error) {
+	comps, _, err := d.getComponents(v, label, isSupervoxel, connectivity, false)
+	return comps, err
+}
+
+// getComponents also returns the runs of voxels in each component if withRLEs is true.
+func (d *Data) getComponents(v dvid.VersionID, label uint64, isSupervoxel bool, connectivity int, withRLEs bool) (*LabelComponents, []dvid.RLEs, error) {
+	offsets, found := componentRowOffsets[connectivity]
+	if !found {
+		return nil, nil, fmt.Errorf("connectivity must be 6 or 26, not %d", connectivity)
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	rows, numRuns, err := d.getLabelRuns(ctx, label, isSupervoxel)
+	if err != nil || rows == nil {
+		return nil, nil, err
+	}
+
+	// runs in the same row touch if adjacent while runs in neighboring rows touch if they
+	// overlap in x, or for 26-connectivity, are diagonally adjacent.
+	var gap int32
+	if connectivity == 26 {
+		gap = 1
+	}
+	uf := make(runUnionFind, numRuns)
+	for i := range uf {
+		uf[i] = i
+	}
+	for row, runs := range rows {
+		for i := 1; i < len(runs); i++ {
+			if runs[i-1].x1+1 >= runs[i].x0 {
+				uf.union(runs[i-1].id, runs[i].id)
+			}
+		}
+		for _, o := range offsets {
+			nbrRuns, found := rows[componentRow{row.y + o[0], row.z + o[1]}]
+			if !found {
+				continue
+			}
+			var j int
+			for _, run := range runs {
+				for j < len(nbrRuns) && nbrRuns[j].x1+gap < run.x0 {
+					j++
+				}
+				for k := j; k < len(nbrRuns) && nbrRuns[k].x0-gap <= run.x1; k++ {
+					uf.union(run.id, nbrRuns[k].id)
+				}
+			}
+		}
+	}
+
+	// gather component stats in ZYX order of runs so RLEs are ordered.
+	sortedRows := make([]componentRow, 0, len(rows))
+	for row := range rows {
+		sortedRows = append(sortedRows, row)
+	}
+	sort.Slice(sortedRows, func(i, j int) bool {
+		if sortedRows[i].z != sortedRows[j].z {
+			return sortedRows[i].z < sortedRows[j].z
+		}
+		return sortedRows[i].y < sortedRows[j].y
+	})
+	compIndex := make(map[int]int)
+	var comps []LabelComponent
+	var compRLEs []dvid.RLEs
+	for _, row := range sortedRows {
+		for _, run := range rows[row] {
+			root := uf.find(run.id)
+			c, found := compIndex[root]
+			if !found {
+				c = len(comps)
+				compIndex[root] = c
+				pt := dvid.Point3d{run.x0, row.y, row.z}
+				comps = append(comps, LabelComponent{MinPoint: pt, MaxPoint: pt})
+				compRLEs = append(compRLEs, nil)
+			}
+			comp := &comps[c]
+			comp.Size += uint64(run.x1 - run.x0 + 1)
+			for i, val := range [3]int32{run.x0, row.y, row.z} {
+				if val < comp.MinPoint[i] {
+					comp.MinPoint[i] = val
+				}
+			}
+			for i, val := range [3]int32{run.x1, row.y, row.z} {
+				if val > comp.MaxPoint[i] {
+					comp.MaxPoint[i] = val
+				}
+			}
+			if withRLEs {
+				compRLEs[c] = append(compRLEs[c], dvid.NewRLE(dvid.Point3d{run.x0, row.y, row.z}, run.x1-run.x0+1))
+			}
+		}
+	}
+	order := make([]int, len(comps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return comps[order[i]].Size > comps[order[j]].Size })
+	result := &LabelComponents{
+		Label:        label,
+		Connectivity: connectivity,
+		Components:   make([]LabelComponent, len(comps)),
+	}
+	var sortedRLEs []dvid.RLEs
+	if withRLEs {
+		sortedRLEs = make([]dvid.RLEs, len(comps))
+	}
+	for i, c := range order {
+		result.Components[i] = comps[c]
+		if withRLEs {
+			sortedRLEs[i] = compRLEs[c]
+		}
+	}
+	return result, sortedRLEs, nil
+}
+
+// encodeSplitRLEs returns the sparse volume encoding of RLEs expected by split requests.
+func encodeSplitRLEs(rles dvid.RLEs) ([]byte, error) {
+	numVoxels, numRuns := rles.Stats()
+	buf := new(bytes.Buffer)
+	buf.WriteByte(dvid.EncodingBinary)
+	binary.Write(buf, binary.LittleEndian, uint8(3)) // # of dimensions
+	binary.Write(buf, binary.LittleEndian, byte(0))  // dimension of run (X = 0)
+	buf.WriteByte(byte(0))                           // reserved for later
+	binary.Write(buf, binary.LittleEndian, uint32(numVoxels))
+	binary.Write(buf, binary.LittleEndian, uint32(numRuns))
+	rleBytes, err := rles.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(rleBytes)
+	return buf.Bytes(), nil
+}
+
+// PartialRelabelError is returned when relabeling of disconnected components fails after
+// some components have been split off, which are not undone.
+type PartialRelabelError struct {
+	Label     uint64
+	Relabeled []LabelComponent // components split off before the error
+	Remaining int              // components that still need relabeling
+	Err       error
+}
+
+func (e PartialRelabelError) Error() string {
+	newLabels := make([]uint64, len(e.Relabeled))
+	mutIDs := make([]uint64, len(e.Relabeled))
+	for i, comp := range e.Relabeled {
+		newLabels[i], mutIDs[i] = comp.Label, comp.MutationID
+	}
+	return fmt.Sprintf("relabeled %d disconnected components of label %d into labels %v (mutation ids %v) but failed on the remaining %d: %v",
+		len(e.Relabeled), e.Label, newLabels, mutIDs, e.Remaining, e.Err)
+}
+
+// RelabelDisconnected splits every connected component of a body except the largest into
+// a new label and returns the components with the new labels.  Other label mutations are
+// excluded from finding the components through the last split.  If a split fails after
+// others succeeded, a PartialRelabelError gives the components that were relabeled.
+func (d *Data) RelabelDisconnected(v dvid.VersionID, label uint64, connectivity int, info dvid.ModInfo) (*LabelComponents, error) {
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+
+	comps, compRLEs, err := d.getComponents(v, label, false, connectivity, true)
+	if err != nil || comps == nil {
+		return comps, err
+	}
+	for i := 1; i < len(comps.Components); i++ {
+		data, err := encodeSplitRLEs(compRLEs[i])
+		if err == nil {
+			comps.Components[i].Label, comps.Components[i].MutationID, err = d.splitLabels(v, label, ioutil.NopCloser(bytes.NewReader(data)), info)
+		}
+		if err == nil {
+			continue
+		}
+		if i == 1 {
+			if _, conflict := err.(CheckoutError); conflict {
+				return nil, err
+			}
+			return nil, fmt.Errorf("relabeling component %d of label %d: %v", i, label, err)
+		}
+		return nil, PartialRelabelError{
+			Label:     label,
+			Relabeled: comps.Components[1:i],
+			Remaining: len(comps.Components) - i,
+			Err:       err,
+		}
+	}
+	return comps, nil
+}
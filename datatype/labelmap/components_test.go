@@ -0,0 +1,99 @@
+package labelmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func getComponentsJSON(t *testing.T, uuid dvid.UUID, label uint64, query string) LabelComponents {
+	reqStr := fmt.Sprintf("%snode/%s/labels/components/%d%s", server.WebAPIPath, uuid, label, query)
+	var comps LabelComponents
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &comps); err != nil {
+		t.Fatalf("couldn't unmarshal components of label %d: %v\n", label, err)
+	}
+	return comps
+}
+
+func TestComponents(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{128, 64, 64}, 2)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{32, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{60, 0, 0}, dvid.Point3d{8, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{100, 10, 10}, dvid.Point3d{2, 2, 2}, 3)
+	vol.addSubvol(dvid.Point3d{102, 12, 12}, dvid.Point3d{2, 2, 2}, 3)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+
+	comps := getComponentsJSON(t, uuid, 1, "")
+	if len(comps.Components) != 2 {
+		t.Fatalf("expected 2 components of label 1, got %v\n", comps)
+	}
+	big, small := comps.Components[0], comps.Components[1]
+	if big.Size != 32*64*64 || big.MinPoint != (dvid.Point3d{0, 0, 0}) || big.MaxPoint != (dvid.Point3d{31, 63, 63}) {
+		t.Errorf("bad largest component of label 1: %v\n", big)
+	}
+	if small.Size != 8*64*64 || small.MinPoint != (dvid.Point3d{60, 0, 0}) || small.MaxPoint != (dvid.Point3d{67, 63, 63}) {
+		t.Errorf("bad smaller component of label 1: %v\n", small)
+	}
+
+	// pieces touching only at a corner are only connected with 26-connectivity.
+	if comps = getComponentsJSON(t, uuid, 3, "?connectivity=6"); len(comps.Components) != 2 {
+		t.Errorf("expected 2 6-connected components of label 3, got %v\n", comps)
+	}
+	if comps = getComponentsJSON(t, uuid, 3, "?connectivity=26"); len(comps.Components) != 1 || comps.Components[0].Size != 16 {
+		t.Errorf("expected 1 26-connected component of label 3, got %v\n", comps)
+	}
+	reqStr := fmt.Sprintf("%snode/%s/labels/components/99", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "GET", reqStr, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for components of missing label, got status %d\n", resp.Code)
+	}
+
+	reqStr = fmt.Sprintf("%snode/%s/labels/components/1?relabel-disconnected=true", server.WebAPIPath, uuid)
+	if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, nil), &comps); err != nil {
+		t.Fatalf("couldn't unmarshal relabeled components: %v\n", err)
+	}
+	if len(comps.Components) != 2 || comps.Components[0].Label != 0 || comps.Components[1].Label == 0 {
+		t.Fatalf("expected smaller component of label 1 to be relabeled, got %v\n", comps)
+	}
+	newLabel := comps.Components[1].Label
+	checkLabelSize(t, d, v, 1, false, 32*64*64)
+	checkLabelSize(t, d, v, newLabel, false, 8*64*64)
+	if comps = getComponentsJSON(t, uuid, 1, ""); len(comps.Components) != 1 {
+		t.Errorf("expected label 1 to be connected after relabel, got %v\n", comps)
+	}
+
+	// labels with too many runs of voxels are rejected.
+	oldMaxRuns := maxComponentRuns
+	maxComponentRuns = 100
+	defer func() { maxComponentRuns = oldMaxRuns }()
+	if _, err := d.GetComponents(v, 2, false, 6); err == nil {
+		t.Errorf("expected error finding components of label with too many runs\n")
+	}
+
+	err = PartialRelabelError{Label: 2, Relabeled: []LabelComponent{{Label: 10, MutationID: 5}}, Remaining: 1, Err: fmt.Errorf("bad split")}
+	if msg := err.Error(); !strings.Contains(msg, "[10]") || !strings.Contains(msg, "[5]") {
+		t.Errorf("expected partial relabel error to give relabeled components, got %q\n", msg)
+	}
+}
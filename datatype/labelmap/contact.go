@@ -0,0 +1,329 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// Neighbor describes a label touching another label.
+type Neighbor struct {
+	Label uint64       `json:"label"`
+	Faces uint64       `json:"faces"` // number of voxel faces shared with the given label
+	Point dvid.Point3d `json:"point"` // a voxel of the neighbor touching the given label
+}
+
+// face-adjacent offsets of a voxel.
+var faceOffsets = [6]dvid.Point3d{
+	{-1, 0, 0}, {1, 0, 0},
+	{0, -1, 0}, {0, 1, 0},
+	{0, 0, -1}, {0, 0, 1},
+}
+
+// contactBlock is a decoded block of labels.
+type contactBlock struct {
+	lbls []uint64
+	size dvid.Point3d
+}
+
+func (cb *contactBlock) value(x, y, z int32) uint64 {
+	return cb.lbls[(z*cb.size[1]+y)*cb.size[0]+x]
+}
+
+// contactScanner finds voxel faces between a label and other labels in blocks of a scale.
+// If svmap is non-nil, block supervoxels are mapped to their bodies before comparison.
+type contactScanner struct {
+	d      *Data
+	ctx    *datastore.VersionedCtx
+	scale  uint8
+	svmap  *SVMap
+	blocks map[dvid.IZYXString]*contactBlock
+}
+
+func (d *Data) newContactScanner(ctx *datastore.VersionedCtx, scale uint8, isSupervoxel bool) (*contactScanner, error) {
+	s := &contactScanner{
+		d:      d,
+		ctx:    ctx,
+		scale:  scale,
+		blocks: make(map[dvid.IZYXString]*contactBlock),
+	}
+	if !isSupervoxel {
+		svmap, err := getMapping(d, ctx.VersionID())
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get mapping for data %q: %v", d.DataName(), err)
+		}
+		s.svmap = svmap
+	}
+	return s, nil
+}
+
+// returns the decoded block at the given block coordinate or nil if there is no block.
+func (s *contactScanner) getBlock(bcoord dvid.ChunkPoint3d) (*contactBlock, error) {
+	izyx := bcoord.ToIZYXString()
+	if cb, found := s.blocks[izyx]; found {
+		return cb, nil
+	}
+	pb, err := s.d.getLabelBlock(s.ctx, s.scale, izyx)
+	if err != nil {
+		return nil, err
+	}
+	var cb *contactBlock
+	if pb != nil {
+		if s.svmap != nil {
+			if err := modifyBlockMapping(s.ctx.VersionID(), &pb.Block, s.svmap); err != nil {
+				return nil, err
+			}
+		}
+		data, size := pb.MakeLabelVolume()
+		lbls, err := dvid.AliasByteToUint64(data)
+		if err != nil {
+			return nil, err
+		}
+		cb = &contactBlock{lbls: lbls, size: size}
+	}
+	s.blocks[izyx] = cb
+	return cb, nil
+}
+
+// scan calls voxelFn, if non-nil, for each voxel of the given label and faceFn for each
+// voxel face between a voxel of the label and a voxel of a different label.  Voxels in
+// missing blocks are considered background (label 0).  The given blocks should hold all
+// voxels of the label.  The voxel of the label and the touching voxel are passed to faceFn
+// along with the touching label.
+func (s *contactScanner) scan(label uint64, blocks dvid.IZYXSlice, voxelFn func(pt dvid.Point3d), faceFn func(pt, nbrPt dvid.Point3d, nbr uint64)) error {
+	sorted := make(dvid.IZYXSlice, len(blocks))
+	copy(sorted, blocks)
+	sort.Sort(sorted)
+
+	blockSize, ok := s.d.BlockSize().(dvid.Point3d)
+	if !ok {
+		return fmt.Errorf("block size for %s wasn't 3d", s.d.DataName())
+	}
+	var curZ int32
+	for _, izyx := range sorted {
+		bcoord, err := izyx.ToChunkPoint3d()
+		if err != nil {
+			return err
+		}
+		// blocks are in ZYX order so only keep decoded blocks that can still be neighbors.
+		if bcoord[2] != curZ {
+			curZ = bcoord[2]
+			for cached := range s.blocks {
+				if c, err := cached.ToChunkPoint3d(); err == nil && c[2] < curZ-1 {
+					delete(s.blocks, cached)
+				}
+			}
+		}
+		cb, err := s.getBlock(bcoord)
+		if err != nil {
+			return err
+		}
+		if cb == nil {
+			continue
+		}
+		if cb.size != blockSize {
+			return fmt.Errorf("block %s has size %s, expected %s", bcoord, cb.size, blockSize)
+		}
+		offset, _ := bcoord.BoundingVoxels(blockSize)
+		var i int
+		for z := int32(0); z < blockSize[2]; z++ {
+			for y := int32(0); y < blockSize[1]; y++ {
+				for x := int32(0); x < blockSize[0]; x++ {
+					if cb.lbls[i] != label {
+						i++
+						continue
+					}
+					i++
+					pt := dvid.Point3d{offset[0] + x, offset[1] + y, offset[2] + z}
+					if voxelFn != nil {
+						voxelFn(pt)
+					}
+					for _, o := range faceOffsets {
+						nx, ny, nz := x+o[0], y+o[1], z+o[2]
+						var nbr uint64
+						if nx >= 0 && ny >= 0 && nz >= 0 && nx < blockSize[0] && ny < blockSize[1] && nz < blockSize[2] {
+							nbr = cb.value(nx, ny, nz)
+						} else {
+							nb, err := s.getBlock(dvid.ChunkPoint3d{bcoord[0] + o[0], bcoord[1] + o[1], bcoord[2] + o[2]})
+							if err != nil {
+								return err
+							}
+							if nb != nil {
+								nbr = nb.value((nx+blockSize[0])%blockSize[0], (ny+blockSize[1])%blockSize[1], (nz+blockSize[2])%blockSize[2])
+							}
+						}
+						if nbr != label {
+							faceFn(pt, dvid.Point3d{pt[0] + o[0], pt[1] + o[1], pt[2] + o[2]}, nbr)
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// returns the label index limited to the given supervoxel if requested.
+func (d *Data) getContactIndex(ctx *datastore.VersionedCtx, label uint64, isSupervoxel bool) (*labels.Index, error) {
+	idx, err := d.getTracedLabelIndex(ctx, label, isSupervoxel)
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil || len(idx.Blocks) == 0 {
+		return nil, nil
+	}
+	if isSupervoxel {
+		if idx, err = idx.LimitToSupervoxel(label); err != nil {
+			return nil, err
+		}
+		if idx == nil || len(idx.Blocks) == 0 {
+			return nil, nil
+		}
+	}
+	return idx, nil
+}
+
+// GetNeighbors returns the labels touching the given label, ordered by decreasing number
+// of shared voxel faces.  If isSupervoxel is true, the label and its neighbors are
+// supervoxels, else they are bodies.  A nil slice is returned if the label is not found.
+func (d *Data) GetNeighbors(ctx *datastore.VersionedCtx, label uint64, isSupervoxel bool) ([]Neighbor, error) {
+	idx, err := d.getContactIndex(ctx, label, isSupervoxel)
+	if err != nil || idx == nil {
+		return nil, err
+	}
+	scanner, err := d.newContactScanner(ctx, 0, isSupervoxel)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uint64]*Neighbor)
+	err = scanner.scan(label, idx.GetBlockIndices(), nil, func(pt, nbrPt dvid.Point3d, nbr uint64) {
+		if nbr == 0 {
+			return
+		}
+		n, ok := found[nbr]
+		if !ok {
+			n = &Neighbor{Label: nbr, Point: nbrPt}
+			found[nbr] = n
+		}
+		n.Faces++
+	})
+	if err != nil {
+		return nil, err
+	}
+	neighbors := make([]Neighbor, 0, len(found))
+	for _, n := range found {
+		neighbors = append(neighbors, *n)
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Faces != neighbors[j].Faces {
+			return neighbors[i].Faces > neighbors[j].Faces
+		}
+		return neighbors[i].Label < neighbors[j].Label
+	})
+	return neighbors, nil
+}
+
+// GetContact returns the voxels of either label that share a face with the other label,
+// sorted in ZYX order.  A nil slice is returned if either label is not found or the
+// labels do not touch.
+func (d *Data) GetContact(ctx *datastore.VersionedCtx, label1, label2 uint64, isSupervoxel bool) ([]dvid.Point3d, error) {
+	idx1, err := d.getContactIndex(ctx, label1, isSupervoxel)
+	if err != nil || idx1 == nil {
+		return nil, err
+	}
+	idx2, err := d.getContactIndex(ctx, label2, isSupervoxel)
+	if err != nil || idx2 == nil {
+		return nil, err
+	}
+
+	// only scan blocks of label1 that hold or are adjacent to blocks of label2.
+	blocks2 := make(map[dvid.IZYXString]struct{}, len(idx2.Blocks))
+	for _, izyx := range idx2.GetBlockIndices() {
+		blocks2[izyx] = struct{}{}
+	}
+	var blocks dvid.IZYXSlice
+	for _, izyx := range idx1.GetBlockIndices() {
+		bcoord, err := izyx.ToChunkPoint3d()
+		if err != nil {
+			return nil, err
+		}
+		_, near := blocks2[izyx]
+		for _, o := range faceOffsets {
+			if near {
+				break
+			}
+			_, near = blocks2[dvid.ChunkPoint3d{bcoord[0] + o[0], bcoord[1] + o[1], bcoord[2] + o[2]}.ToIZYXString()]
+		}
+		if near {
+			blocks = append(blocks, izyx)
+		}
+	}
+
+	scanner, err := d.newContactScanner(ctx, 0, isSupervoxel)
+	if err != nil {
+		return nil, err
+	}
+	surface := make(map[dvid.Point3d]struct{})
+	err = scanner.scan(label1, blocks, nil, func(pt, nbrPt dvid.Point3d, nbr uint64) {
+		if nbr == label2 {
+			surface[pt] = struct{}{}
+			surface[nbrPt] = struct{}{}
+		}
+	})
+	if err != nil || len(surface) == 0 {
+		return nil, err
+	}
+	pts := make([]dvid.Point3d, 0, len(surface))
+	for pt := range surface {
+		pts = append(pts, pt)
+	}
+	sort.Slice(pts, func(i, j int) bool {
+		a, b := pts[i], pts[j]
+		if a[2] != b[2] {
+			return a[2] < b[2]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[0] < b[0]
+	})
+	return pts, nil
+}
+
+// encodeContactRLEs returns the legacy sparse volume RLE encoding of points sorted in ZYX order.
+func encodeContactRLEs(pts []dvid.Point3d) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(dvid.EncodingBinary)
+	binary.Write(buf, binary.LittleEndian, uint8(3))  // # of dimensions
+	binary.Write(buf, binary.LittleEndian, byte(0))   // dimension of run (X = 0)
+	buf.WriteByte(byte(0))                            // reserved for later
+	binary.Write(buf, binary.LittleEndian, uint32(0)) // Placeholder for # voxels
+	binary.Write(buf, binary.LittleEndian, uint32(0)) // Placeholder for # spans
+
+	var numRuns uint32
+	for i := 0; i < len(pts); {
+		start := pts[i]
+		run := int32(1)
+		for i+int(run) < len(pts) {
+			next := pts[i+int(run)]
+			if next[1] != start[1] || next[2] != start[2] || next[0] != start[0]+run {
+				break
+			}
+			run++
+		}
+		if err := writeRLE(buf, start, run); err != nil {
+			return nil, err
+		}
+		numRuns++
+		i += int(run)
+	}
+	serialization := buf.Bytes()
+	binary.LittleEndian.PutUint32(serialization[4:8], uint32(len(pts)))
+	binary.LittleEndian.PutUint32(serialization[8:12], numRuns)
+	return serialization, nil
+}
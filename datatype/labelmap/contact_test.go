@@ -0,0 +1,124 @@
+package labelmap
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func getNeighbors(t *testing.T, uuid dvid.UUID, name string, label uint64, supervoxels bool) []Neighbor {
+	reqStr := fmt.Sprintf("%snode/%s/%s/neighbors/%d?supervoxels=%t", server.WebAPIPath, uuid, name, label, supervoxels)
+	r := server.TestHTTP(t, "GET", reqStr, nil)
+	var neighbors []Neighbor
+	if err := json.Unmarshal(r, &neighbors); err != nil {
+		t.Fatalf("couldn't unmarshal neighbors response %q: %v\n", string(r), err)
+	}
+	return neighbors
+}
+
+func TestNeighborsContact(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, _ := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	// label 1 fills the first block and touches label 2 across the block boundary.
+	// label 3 touches label 2 within the second block.
+	vol := newTestVolume(128, 64, 64)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{64, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{64, 0, 0}, dvid.Point3d{32, 64, 64}, 2)
+	vol.addSubvol(dvid.Point3d{96, 0, 0}, dvid.Point3d{32, 32, 64}, 3)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	expected := []Neighbor{
+		{Label: 1, Faces: 4096, Point: dvid.Point3d{63, 0, 0}},
+		{Label: 3, Faces: 2048, Point: dvid.Point3d{96, 0, 0}},
+	}
+	if neighbors := getNeighbors(t, uuid, "labels", 2, false); !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("expected neighbors %v for label 2, got %v\n", expected, neighbors)
+	}
+	expected = []Neighbor{{Label: 2, Faces: 4096, Point: dvid.Point3d{64, 0, 0}}}
+	if neighbors := getNeighbors(t, uuid, "labels", 1, false); !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("expected neighbors %v for label 1, got %v\n", expected, neighbors)
+	}
+	reqStr := fmt.Sprintf("%snode/%s/labels/neighbors/4", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "GET", reqStr, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for neighbors of missing label, got %d\n", resp.Code)
+	}
+
+	// contact surface is x = 63 and 64 for every y, z.
+	reqStr = fmt.Sprintf("%snode/%s/labels/contact/1/2?format=points", server.WebAPIPath, uuid)
+	r := server.TestHTTP(t, "GET", reqStr, nil)
+	var pts []dvid.Point3d
+	if err := json.Unmarshal(r, &pts); err != nil {
+		t.Fatalf("couldn't unmarshal contact points: %v\n", err)
+	}
+	if len(pts) != 2*64*64 {
+		t.Fatalf("expected %d contact points, got %d\n", 2*64*64, len(pts))
+	}
+	for i, pt := range pts {
+		x := int32(63 + i%2)
+		y := int32((i / 2) % 64)
+		z := int32(i / 128)
+		if pt != (dvid.Point3d{x, y, z}) {
+			t.Fatalf("expected contact point %d to be (%d,%d,%d), got %s\n", i, x, y, z, pt)
+		}
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/contact/2/1", server.WebAPIPath, uuid)
+	r = server.TestHTTP(t, "GET", reqStr, nil)
+	if len(r) != 12+16*64*64 {
+		t.Fatalf("expected %d runs in contact RLEs, got %d bytes\n", 64*64, len(r))
+	}
+	if numVoxels := binary.LittleEndian.Uint32(r[4:8]); numVoxels != 2*64*64 {
+		t.Errorf("expected %d voxels in contact RLEs, got %d\n", 2*64*64, numVoxels)
+	}
+	var spans dvid.Spans
+	if err := spans.UnmarshalBinary(r[8:]); err != nil {
+		t.Fatalf("couldn't decode contact RLEs: %v\n", err)
+	}
+	for _, span := range spans {
+		if span[2] != 63 || span[3] != 64 {
+			t.Fatalf("expected contact spans from x = 63 to 64, got %v\n", span)
+		}
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/contact/1/3", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "GET", reqStr, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for labels without contact, got %d\n", resp.Code)
+	}
+
+	// after merging 3 into 2, only supervoxel neighbors should show label 3.
+	testMerge := mergeJSON(`[2, 3]`)
+	testMerge.send(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	expected = []Neighbor{{Label: 1, Faces: 4096, Point: dvid.Point3d{63, 0, 0}}}
+	if neighbors := getNeighbors(t, uuid, "labels", 2, false); !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("expected neighbors %v for merged label 2, got %v\n", expected, neighbors)
+	}
+	expected = []Neighbor{
+		{Label: 1, Faces: 4096, Point: dvid.Point3d{63, 0, 0}},
+		{Label: 3, Faces: 2048, Point: dvid.Point3d{96, 0, 0}},
+	}
+	if neighbors := getNeighbors(t, uuid, "labels", 2, true); !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("expected neighbors %v for supervoxel 2, got %v\n", expected, neighbors)
+	}
+	expected = []Neighbor{{Label: 2, Faces: 2048, Point: dvid.Point3d{95, 0, 0}}}
+	if neighbors := getNeighbors(t, uuid, "labels", 3, true); !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("expected neighbors %v for supervoxel 3, got %v\n", expected, neighbors)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+	This file supports the gRPC data plane by implementing the server's streaming interfaces
+	on top of the labelmap HTTP endpoint methods.
+*/
+
+package labelmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+// GetDataPlaneBlock returns a block in the given compression, which can be any of the
+// formats of GET /blocks.  Returns nil if the block is not stored.
+func (d *Data) GetDataPlaneBlock(ctx *datastore.VersionedCtx, bcoord dvid.ChunkPoint3d, scale uint8, supervoxels bool, compression string) ([]byte, error) {
+	switch compression {
+	case "":
+		compression = "blocks"
+	case "lz4", "gzip", "blocks", "uncompressed":
+	default:
+		return nil, fmt.Errorf(`compression must be "blocks" (default), "lz4", "gzip" or "uncompressed"`)
+	}
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		return nil, err
+	}
+	val, err := store.Get(ctx, NewBlockTKeyByCoord(scale, bcoord.ToIZYXString()))
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, nil
+	}
+	return d.transcodeBlock(blockData{bcoord, compression, supervoxels, ctx.VersionID(), val, nil})
+}
+
+// PutDataPlaneBlocks stores gzipped label blocks as in POST /blocks, including indexing.
+func (d *Data) PutDataPlaneBlocks(ctx *datastore.VersionedCtx, opts server.DataPlanePutOptions, blocks <-chan server.DataPlaneBlock) error {
+	if opts.Mutate {
+		return fmt.Errorf("labelmap %q does not support mutation of blocks through the data plane", d.DataName())
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		hdr := make([]byte, 16)
+		for block := range blocks {
+			binary.LittleEndian.PutUint32(hdr[0:4], uint32(block.BCoord[0]))
+			binary.LittleEndian.PutUint32(hdr[4:8], uint32(block.BCoord[1]))
+			binary.LittleEndian.PutUint32(hdr[8:12], uint32(block.BCoord[2]))
+			binary.LittleEndian.PutUint32(hdr[12:16], uint32(len(block.Data)))
+			if _, err := pw.Write(hdr); err != nil {
+				return
+			}
+			if _, err := pw.Write(block.Data); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+	err := d.ReceiveBlocks(ctx, pr, opts.Scale, opts.Downscale, "blocks", true)
+	pr.Close()
+	return err
+}
+
+// GetDataPlaneSubvolume returns the uint64 labels of a subvolume at the given scale.
+func (d *Data) GetDataPlaneSubvolume(ctx *datastore.VersionedCtx, offset, size dvid.Point3d, scale uint8, supervoxels bool) ([]byte, error) {
+	lbl, err := d.NewLabels(dvid.NewSubvolume(offset, size), nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetVolume(ctx.VersionID(), lbl, supervoxels, scale, "")
+}
+
+// WriteDataPlaneSparsevol writes the sparse volume of a label in the "srles" (default),
+// "blocks" or "rles" format of GET /sparsevol.
+func (d *Data) WriteDataPlaneSparsevol(ctx *datastore.VersionedCtx, label uint64, scale uint8, supervoxels bool, format string, w io.Writer) (found bool, err error) {
+	if label == 0 {
+		return false, fmt.Errorf("label 0 is protected background value and cannot be used as sparse volume")
+	}
+	switch format {
+	case "", "srles":
+		return d.writeStreamingRLE(ctx, label, scale, dvid.Bounds{}, "", supervoxels, w)
+	case "blocks":
+		return d.writeBinaryBlocks(ctx, label, scale, dvid.Bounds{}, "", supervoxels, w)
+	case "rles":
+		return d.writeLegacyRLE(ctx, label, scale, dvid.Bounds{}, "", supervoxels, w)
+	default:
+		return false, fmt.Errorf(`sparsevol format must be "srles" (default), "blocks" or "rles", not %q`, format)
+	}
+}
+
+// DataPlaneMerge merges labels into a target label as in POST /merge.
+func (d *Data) DataPlaneMerge(ctx *datastore.VersionedCtx, target uint64, merged []uint64, info dvid.ModInfo) (mutID uint64, err error) {
+	op, err := labels.MergeTuple(append([]uint64{target}, merged...)).Op()
+	if err != nil {
+		return 0, err
+	}
+	return d.MergeLabels(ctx.VersionID(), op, info)
+}
+
+// DataPlaneCleave cleaves supervoxels from a label as in POST /cleave.
+func (d *Data) DataPlaneCleave(ctx *datastore.VersionedCtx, target uint64, supervoxels []uint64, info dvid.ModInfo) (cleaved, mutID uint64, err error) {
+	jsonBytes, err := json.Marshal(supervoxels)
+	if err != nil {
+		return 0, 0, err
+	}
+	return d.CleaveLabel(ctx.VersionID(), target, info, ioutil.NopCloser(bytes.NewReader(jsonBytes)))
+}
+
+// DataPlaneSplit splits a label using the binary RLEs of POST /split.
+func (d *Data) DataPlaneSplit(ctx *datastore.VersionedCtx, label uint64, rles []byte, info dvid.ModInfo) (newLabel, mutID uint64, err error) {
+	return d.SplitLabels(ctx.VersionID(), label, ioutil.NopCloser(bytes.NewReader(rles)), info)
+}
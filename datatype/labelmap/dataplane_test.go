@@ -0,0 +1,159 @@
+package labelmap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDataPlane(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, _ := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+	server.CreateTestInstance(t, uuid, "labelmap", "labels2", config)
+
+	vol := newTestVolume(128, 64, 64)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{64, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{64, 0, 0}, dvid.Point3d{64, 64, 64}, 2)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	addr, stop := server.OpenTestDataPlane(t, "secret")
+	defer stop()
+	client, closeClient := server.DialTestDataPlane(t, addr, "secret")
+	defer closeClient()
+	ctx := context.Background()
+
+	badClient, closeBadClient := server.DialTestDataPlane(t, addr, "")
+	defer closeBadClient()
+	_, err := badClient.Merge(ctx, &proto.MergeRequest{Uuid: string(uuid), Dataname: "labels", Target: 1, Merged: []uint64{2}})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected request without token to be unauthenticated, got %v\n", err)
+	}
+
+	// Copy the blocks into another instance and make sure the volumes match.
+	blocksStream, err := client.GetBlocks(ctx, &proto.GetBlocksRequest{
+		Uuid:     string(uuid),
+		Dataname: "labels",
+		MinBlock: []int32{0, 0, 0},
+		MaxBlock: []int32{1, 0, 0},
+	})
+	if err != nil {
+		t.Fatalf("error starting GetBlocks: %v\n", err)
+	}
+	var blocks []*proto.Block
+	for {
+		block, err := blocksStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error receiving blocks: %v\n", err)
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d\n", len(blocks))
+	}
+	putStream, err := client.PutBlocks(ctx)
+	if err != nil {
+		t.Fatalf("error starting PutBlocks: %v\n", err)
+	}
+	for _, block := range blocks {
+		req := &proto.PutBlocksRequest{Uuid: string(uuid), Dataname: "labels2", Block: block}
+		if err := putStream.Send(req); err != nil {
+			t.Fatalf("error sending block: %v\n", err)
+		}
+	}
+	putResp, err := putStream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("error on PutBlocks: %v\n", err)
+	}
+	if putResp.NumBlocks != 2 {
+		t.Fatalf("expected 2 blocks stored, got %d\n", putResp.NumBlocks)
+	}
+	if err := datastore.BlockOnUpdating(uuid, "labels2"); err != nil {
+		t.Fatalf("Error blocking on sync of labels2: %v\n", err)
+	}
+	copied := newTestVolume(128, 64, 64)
+	copied.get(t, uuid, "labels2", false)
+	if err := vol.equals(copied); err != nil {
+		t.Fatalf("blocks copied over data plane don't match: %v\n", err)
+	}
+
+	// Stream the subvolume in small chunks.
+	subvolStream, err := client.GetSubvolume(ctx, &proto.SubvolumeRequest{
+		Uuid:       string(uuid),
+		Dataname:   "labels",
+		Offset:     []int32{0, 0, 0},
+		Size_:      []int32{128, 64, 64},
+		ChunkBytes: 4096,
+	})
+	if err != nil {
+		t.Fatalf("error starting GetSubvolume: %v\n", err)
+	}
+	var subvol []byte
+	for {
+		chunk, err := subvolStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error receiving subvolume: %v\n", err)
+		}
+		if len(chunk.Data) > 4096 {
+			t.Fatalf("expected chunks of at most 4096 bytes, got %d\n", len(chunk.Data))
+		}
+		subvol = append(subvol, chunk.Data...)
+	}
+	if !bytes.Equal(subvol, vol.data) {
+		t.Fatalf("streamed subvolume of %d bytes doesn't match expected %d bytes\n", len(subvol), len(vol.data))
+	}
+
+	svStream, err := client.GetSparsevol(ctx, &proto.SparsevolRequest{Uuid: string(uuid), Dataname: "labels", Label: 2, Format: "rles"})
+	if err != nil {
+		t.Fatalf("error starting GetSparsevol: %v\n", err)
+	}
+	var sparsevol []byte
+	for {
+		chunk, err := svStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error receiving sparsevol: %v\n", err)
+		}
+		sparsevol = append(sparsevol, chunk.Data...)
+	}
+	reqStr := fmt.Sprintf("%snode/%s/labels/sparsevol/2", server.WebAPIPath, uuid)
+	if expected := server.TestHTTP(t, "GET", reqStr, nil); !bytes.Equal(sparsevol, expected) {
+		t.Fatalf("streamed sparsevol of %d bytes doesn't match HTTP sparsevol of %d bytes\n", len(sparsevol), len(expected))
+	}
+
+	if _, err := client.Merge(ctx, &proto.MergeRequest{Uuid: string(uuid), Dataname: "labels", Target: 1, Merged: []uint64{2}}); err != nil {
+		t.Fatalf("error on merge: %v\n", err)
+	}
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	merged := newTestVolume(128, 64, 64)
+	merged.get(t, uuid, "labels", false)
+	merged.verifyLabel(t, 1, 100, 10, 10)
+	merged.verifyLabel(t, 1, 10, 10, 10)
+}
@@ -71,7 +71,7 @@ func (d *Data) DumpMutations(leafUUID dvid.UUID, filename string) (comment strin
 	return
 }
 
-func (d *Data) ingestMappings(ctx *datastore.VersionedCtx, mappings proto.MappingOps) error {
+func (d *Data) ingestMappings(ctx *datastore.VersionedCtx, mappings proto.MappingOps, batch *mutationBatch) error {
 	m, err := getMapping(d, ctx.VersionID())
 	if err != nil {
 		return err
@@ -84,6 +84,7 @@ func (d *Data) ingestMappings(ctx *datastore.VersionedCtx, mappings proto.Mappin
 	}
 	for _, mapOp := range mappings.Mappings {
 		for _, supervoxel := range mapOp.Original {
+			batch.saveMapping(m, supervoxel)
 			vm := m.fm[supervoxel]
 			newvm, changed := vm.modify(vid, mapOp.Mapped)
 			if changed {
@@ -92,7 +93,9 @@ func (d *Data) ingestMappings(ctx *datastore.VersionedCtx, mappings proto.Mappin
 		}
 	}
 	m.Unlock()
-	return labels.LogMappings(d, ctx.VersionID(), mappings)
+	return batch.afterCommit(func() error {
+		return labels.LogMappings(d, ctx.VersionID(), mappings)
+	})
 }
 
 // versioned map entry for a given supervoxel.
@@ -483,7 +486,7 @@ func getMapping(d dvid.Data, v dvid.VersionID) (*SVMap, error) {
 
 // adds a merge into the equivalence map for a given instance version and also
 // records the mappings into the log.
-func addMergeToMapping(d dvid.Data, v dvid.VersionID, mutID, toLabel uint64, mergeIdx *labels.Index) error {
+func addMergeToMapping(d dvid.Data, v dvid.VersionID, mutID, toLabel uint64, mergeIdx *labels.Index, batch *mutationBatch) error {
 	m, err := getMapping(d, v)
 	if err != nil {
 		return err
@@ -499,6 +502,7 @@ func addMergeToMapping(d dvid.Data, v dvid.VersionID, mutID, toLabel uint64, mer
 		return err
 	}
 	for supervoxel := range supervoxels {
+		batch.saveMapping(m, supervoxel)
 		m.setMapping(vid, supervoxel, toLabel)
 	}
 	m.Unlock()
@@ -507,7 +511,9 @@ func addMergeToMapping(d dvid.Data, v dvid.VersionID, mutID, toLabel uint64, mer
 		Mapped:   toLabel,
 		Original: supervoxels,
 	}
-	return labels.LogMapping(d, v, op)
+	return batch.afterCommit(func() error {
+		return labels.LogMapping(d, v, op)
+	})
 }
 
 // adds new arbitrary split into the equivalence map for a given instance version.
@@ -574,7 +580,7 @@ func addSplitToMapping(d dvid.Data, v dvid.VersionID, op labels.SplitOp) error {
 
 // adds new cleave into the equivalence map for a given instance version and also
 // records the mappings into the log.
-func addCleaveToMapping(d dvid.Data, v dvid.VersionID, op labels.CleaveOp) error {
+func addCleaveToMapping(d dvid.Data, v dvid.VersionID, op labels.CleaveOp, batch *mutationBatch) error {
 	m, err := getMapping(d, v)
 	if err != nil {
 		return err
@@ -591,6 +597,7 @@ func addCleaveToMapping(d dvid.Data, v dvid.VersionID, op labels.CleaveOp) error
 	supervoxelSet := make(labels.Set, len(op.CleavedSupervoxels))
 	for _, supervoxel := range op.CleavedSupervoxels {
 		supervoxelSet[supervoxel] = struct{}{}
+		batch.saveMapping(m, supervoxel)
 		m.setMapping(vid, supervoxel, op.CleavedLabel)
 	}
 	m.Unlock()
@@ -599,12 +606,14 @@ func addCleaveToMapping(d dvid.Data, v dvid.VersionID, op labels.CleaveOp) error
 		Mapped:   op.CleavedLabel,
 		Original: supervoxelSet,
 	}
-	return labels.LogMapping(d, v, mapOp)
+	return batch.afterCommit(func() error {
+		return labels.LogMapping(d, v, mapOp)
+	})
 }
 
 // adds supervoxel split into the equivalence map for a given instance version and also
 // records the mappings into the log.
-func addSupervoxelSplitToMapping(d dvid.Data, v dvid.VersionID, op labels.SplitSupervoxelOp) error {
+func addSupervoxelSplitToMapping(d dvid.Data, v dvid.VersionID, op labels.SplitSupervoxelOp, batch *mutationBatch) error {
 	m, err := getMapping(d, v)
 	if err != nil {
 		return err
@@ -621,6 +630,10 @@ func addSupervoxelSplitToMapping(d dvid.Data, v dvid.VersionID, op labels.SplitS
 		m.Unlock()
 		return err
 	}
+	batch.saveMapping(m, op.SplitSupervoxel)
+	batch.saveMapping(m, op.RemainSupervoxel)
+	batch.saveMapping(m, op.Supervoxel)
+	batch.saveSplits(m, vid)
 	m.setMapping(vid, op.SplitSupervoxel, label)
 	m.setMapping(vid, op.RemainSupervoxel, label)
 	m.setMapping(vid, op.Supervoxel, 0)
@@ -633,28 +646,30 @@ func addSupervoxelSplitToMapping(d dvid.Data, v dvid.VersionID, op labels.SplitS
 	m.splits[vid] = append(m.splits[vid], rec)
 	m.Unlock()
 
-	if err := labels.LogSupervoxelSplit(d, v, op); err != nil {
-		return err
-	}
+	return batch.afterCommit(func() error {
+		if err := labels.LogSupervoxelSplit(d, v, op); err != nil {
+			return err
+		}
 
-	mapOp := labels.MappingOp{
-		MutID:  op.MutID,
-		Mapped: 0,
-		Original: labels.Set{
-			op.Supervoxel: struct{}{},
-		},
-	}
-	if err := labels.LogMapping(d, v, mapOp); err != nil {
-		return fmt.Errorf("unable to log the mapping of deleted supervoxel %d: %v", op.Supervoxel, err)
-	}
-	newlabels := labels.Set{
-		op.SplitSupervoxel:  struct{}{},
-		op.RemainSupervoxel: struct{}{},
-	}
-	mapOp = labels.MappingOp{
-		MutID:    op.MutID,
-		Mapped:   label,
-		Original: newlabels,
-	}
-	return labels.LogMapping(d, v, mapOp)
+		mapOp := labels.MappingOp{
+			MutID:  op.MutID,
+			Mapped: 0,
+			Original: labels.Set{
+				op.Supervoxel: struct{}{},
+			},
+		}
+		if err := labels.LogMapping(d, v, mapOp); err != nil {
+			return fmt.Errorf("unable to log the mapping of deleted supervoxel %d: %v", op.Supervoxel, err)
+		}
+		newlabels := labels.Set{
+			op.SplitSupervoxel:  struct{}{},
+			op.RemainSupervoxel: struct{}{},
+		}
+		mapOp = labels.MappingOp{
+			MutID:    op.MutID,
+			Mapped:   label,
+			Original: newlabels,
+		}
+		return labels.LogMapping(d, v, mapOp)
+	})
 }
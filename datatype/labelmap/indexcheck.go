@@ -0,0 +1,333 @@
+/*
+	This file supports checking label indices against the supervoxel counts in label blocks
+	and optionally repairing inconsistent indices.
+*/
+
+package labelmap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+// IndexCheck reports the results of checking label indices against label blocks.
+type IndexCheck struct {
+	Blocks       uint64   // number of label blocks scanned
+	Labels       uint64   // number of labels checked
+	Inconsistent []uint64 // labels whose stored index disagrees with the blocks
+	Repaired     bool
+	MutationID   uint64 `json:",omitempty"`
+}
+
+// returns true if the supervoxel counts of two blocks are identical.
+func equalSVCounts(a, b *proto.SVCount) bool {
+	var aCounts, bCounts map[uint64]uint32
+	if a != nil {
+		aCounts = a.Counts
+	}
+	if b != nil {
+		bCounts = b.Counts
+	}
+	if len(aCounts) != len(bCounts) {
+		return false
+	}
+	for sv, count := range aCounts {
+		if bCounts[sv] != count {
+			return false
+		}
+	}
+	return true
+}
+
+// maximum number of check-indices jobs remembered for status requests.
+const maxCheckIndicesJobs = 1000
+
+// checkIndicesJobs records the data instance of each job started by POST /check-indices so
+// only those jobs are returned by GET /check-indices/<job id>.
+var checkIndicesJobs struct {
+	sync.Mutex
+	data map[string]dvid.UUID
+	ids  []string
+}
+
+func addCheckIndicesJob(id string, dataUUID dvid.UUID) {
+	checkIndicesJobs.Lock()
+	defer checkIndicesJobs.Unlock()
+	if checkIndicesJobs.data == nil {
+		checkIndicesJobs.data = make(map[string]dvid.UUID)
+	}
+	checkIndicesJobs.data[id] = dataUUID
+	checkIndicesJobs.ids = append(checkIndicesJobs.ids, id)
+	if len(checkIndicesJobs.ids) > maxCheckIndicesJobs {
+		delete(checkIndicesJobs.data, checkIndicesJobs.ids[0])
+		checkIndicesJobs.ids = checkIndicesJobs.ids[1:]
+	}
+}
+
+// returns true if the job was started by POST /check-indices for the given data instance.
+func isCheckIndicesJob(id string, dataUUID dvid.UUID) bool {
+	checkIndicesJobs.Lock()
+	defer checkIndicesJobs.Unlock()
+	jobData, found := checkIndicesJobs.data[id]
+	return found && jobData == dataUUID
+}
+
+// number of blocks or label indices checked at a time, releasing any locks in between.
+var checkIndicesChunkSize = 1000
+
+// errChunkFull stops a range scan once a chunk has been read.
+var errChunkFull = errors.New("chunk full")
+
+// scanChunk sends up to checkIndicesChunkSize key-values within the range to f and returns
+// the key at which the next chunk starts or nil if the range is done.
+func scanChunk(ctx *datastore.VersionedCtx, store storage.OrderedKeyValueDB, begTKey, endTKey storage.TKey, f func(*storage.TKeyValue) error) (next storage.TKey, err error) {
+	var n int
+	err = store.ProcessRange(ctx, begTKey, endTKey, &storage.ChunkOp{}, func(c *storage.Chunk) error {
+		if c == nil || c.TKeyValue == nil || c.TKeyValue.V == nil {
+			return nil
+		}
+		if n == checkIndicesChunkSize {
+			next = append(storage.TKey{}, c.TKeyValue.K...)
+			return errChunkFull
+		}
+		n++
+		return f(c.TKeyValue)
+	})
+	if err == errChunkFull {
+		return next, nil
+	}
+	return nil, err
+}
+
+// CheckIndices compares the stored label indices with the supervoxel counts of the scale 0
+// label blocks, optionally limited to blocks intersecting an ROI.  Blocks are scanned in
+// chunks, checking the indices of the bodies within each chunk, and then the stored indices
+// are scanned in chunks for blocks that no longer contain their body.  If repair is true,
+// inconsistent indices are rewritten to match the blocks, keeping any stored blocks outside
+// the ROI, and label mutations are blocked while each chunk is checked.
+func (d *Data) CheckIndices(v dvid.VersionID, roiname dvid.InstanceName, repair bool, info dvid.ModInfo) (*IndexCheck, error) {
+	timedLog := dvid.NewTimeLog()
+
+	var filter *roiFilter
+	if roiname != "" {
+		var err error
+		if filter, err = d.newROIFilter(v, roiname); err != nil {
+			return nil, err
+		}
+	}
+	store, err := datastore.GetOrderedKeyValueDB(d)
+	if err != nil {
+		return nil, err
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	mapping, err := getMapping(d, v)
+	if err != nil {
+		return nil, err
+	}
+
+	report := new(IndexCheck)
+	inconsistent := make(labels.Set)
+	lockChunk := func() {
+		if repair {
+			d.batchMu.Lock()
+			d.voxelMu.Lock()
+		}
+	}
+	unlockChunk := func() {
+		if repair {
+			d.voxelMu.Unlock()
+			d.batchMu.Unlock()
+		}
+	}
+	putRepaired := func(idx *labels.Index) error {
+		if !report.Repaired {
+			report.MutationID = d.NewMutationID()
+			report.Repaired = true
+		}
+		if len(idx.Blocks) == 0 {
+			return DeleteLabelIndex(d, v, idx.Label)
+		}
+		idx.LastMutId = report.MutationID
+		idx.LastModUser = info.User
+		idx.LastModTime = info.Time
+		idx.LastModApp = info.App
+		return PutLabelIndex(d, v, idx.Label, idx)
+	}
+
+	// compare the supervoxel counts of each chunk of blocks with the stored indices.
+	checkBlocks := func(begTKey, endTKey storage.TKey) (next storage.TKey, err error) {
+		lockChunk()
+		defer unlockChunk()
+
+		expected := make(map[uint64]map[uint64]*proto.SVCount)
+		next, err = scanChunk(ctx, store, begTKey, endTKey, func(kv *storage.TKeyValue) error {
+			_, indexZYX, err := DecodeBlockTKey(kv.K)
+			if err != nil {
+				return err
+			}
+			x, y, z := indexZYX.Unpack()
+			zyx := labels.EncodeBlockIndex(x, y, z)
+			if filter != nil && !filter.blockWithin(zyx) {
+				return nil
+			}
+			data, _, err := dvid.DeserializeData(kv.V, true)
+			if err != nil {
+				return fmt.Errorf("unable to deserialize block %s: %v", indexZYX, err)
+			}
+			var block labels.Block
+			if err := block.UnmarshalBinary(data); err != nil {
+				return fmt.Errorf("unable to unmarshal block %s: %v", indexZYX, err)
+			}
+			report.Blocks++
+			for sv, count := range block.CalcNumLabels(nil) {
+				if sv == 0 || count <= 0 {
+					continue
+				}
+				body, _ := mapping.MappedLabel(v, sv)
+				blocks, found := expected[body]
+				if !found {
+					blocks = make(map[uint64]*proto.SVCount)
+					expected[body] = blocks
+				}
+				svc, found := blocks[zyx]
+				if !found {
+					svc = new(proto.SVCount)
+					svc.Counts = make(map[uint64]uint32)
+					blocks[zyx] = svc
+				}
+				svc.Counts[sv] = uint32(count)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for body, blocks := range expected {
+			var stored *labels.Index
+			if stored, err = GetLabelIndex(d, v, body, false); err != nil {
+				return nil, err
+			}
+			if stored == nil {
+				// bodies without an index are counted here unless a repair adds the index.
+				if _, found := inconsistent[body]; !found && !repair {
+					report.Labels++
+				}
+				stored = new(labels.Index)
+				stored.Label = body
+				stored.Blocks = make(map[uint64]*proto.SVCount)
+			}
+			var changed bool
+			for zyx, svc := range blocks {
+				if !equalSVCounts(stored.Blocks[zyx], svc) {
+					inconsistent[body] = struct{}{}
+					stored.Blocks[zyx] = svc
+					changed = true
+				}
+			}
+			if changed && repair {
+				if err = putRepaired(stored); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return next, nil
+	}
+	begTKey := NewBlockTKeyByCoord(0, dvid.MinIndexZYX.ToIZYXString())
+	endTKey := NewBlockTKeyByCoord(0, dvid.MaxIndexZYX.ToIZYXString())
+	for begTKey != nil {
+		if begTKey, err = checkBlocks(begTKey, endTKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// find blocks in each chunk of stored indices that don't contain the body.
+	hasBody := func(zyx, body uint64) (bool, error) {
+		x, y, z := labels.DecodeBlockIndex(zyx)
+		pb, err := d.getLabelBlock(ctx, 0, dvid.ChunkPoint3d{x, y, z}.ToIZYXString())
+		if err != nil || pb == nil {
+			return false, err
+		}
+		for sv, count := range pb.CalcNumLabels(nil) {
+			if sv == 0 || count <= 0 {
+				continue
+			}
+			if mapped, _ := mapping.MappedLabel(v, sv); mapped == body {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	checkIndices := func(begTKey, endTKey storage.TKey) (next storage.TKey, err error) {
+		lockChunk()
+		defer unlockChunk()
+
+		var indices []*labels.Index
+		next, err = scanChunk(ctx, store, begTKey, endTKey, func(kv *storage.TKeyValue) error {
+			label, err := DecodeLabelIndexTKey(kv.K)
+			if err != nil {
+				return err
+			}
+			val, _, err := dvid.DeserializeData(kv.V, true)
+			if err != nil {
+				return err
+			}
+			stored := new(labels.Index)
+			if err := stored.Unmarshal(val); err != nil {
+				return fmt.Errorf("unable to unmarshal index for label %d: %v", label, err)
+			}
+			stored.Label = label
+			indices = append(indices, stored)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stored := range indices {
+			report.Labels++
+			var changed bool
+			for zyx := range stored.Blocks {
+				if filter != nil && !filter.blockWithin(zyx) {
+					continue
+				}
+				var found bool
+				if found, err = hasBody(zyx, stored.Label); err != nil {
+					return nil, err
+				}
+				if !found {
+					inconsistent[stored.Label] = struct{}{}
+					delete(stored.Blocks, zyx)
+					changed = true
+				}
+			}
+			if changed && repair {
+				if err = putRepaired(stored); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return next, nil
+	}
+	begTKey = NewLabelIndexTKey(0)
+	endTKey = NewLabelIndexTKey(math.MaxUint64)
+	for begTKey != nil {
+		if begTKey, err = checkIndices(begTKey, endTKey); err != nil {
+			return nil, err
+		}
+	}
+
+	for label := range inconsistent {
+		report.Inconsistent = append(report.Inconsistent, label)
+	}
+	sort.Slice(report.Inconsistent, func(i, j int) bool { return report.Inconsistent[i] < report.Inconsistent[j] })
+	timedLog.Infof("Checked %d label indices against %d blocks of data %q: %d inconsistent, repaired %t", report.Labels, report.Blocks, d.DataName(), len(report.Inconsistent), report.Repaired)
+	return report, nil
+}
@@ -0,0 +1,132 @@
+package labelmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+// runs a check-indices job and returns its report after it completes.
+func checkIndicesJob(t *testing.T, uuid dvid.UUID, query string) IndexCheck {
+	reqStr := fmt.Sprintf("%snode/%s/labels/check-indices%s", server.WebAPIPath, uuid, query)
+	resp := server.TestHTTPResponse(t, "POST", reqStr, nil)
+	var started struct {
+		Job string `json:"job"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &started); err != nil {
+		t.Fatalf("couldn't unmarshal check-indices response %q: %v\n", resp.Body.String(), err)
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/check-indices/%s", server.WebAPIPath, uuid, started.Job)
+	for i := 0; i < 100; i++ {
+		var job server.Job
+		if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &job); err != nil {
+			t.Fatalf("couldn't unmarshal job: %v\n", err)
+		}
+		switch job.Status {
+		case server.JobRunning:
+			time.Sleep(100 * time.Millisecond)
+		case server.JobCompleted:
+			var report IndexCheck
+			if err := json.Unmarshal([]byte(job.Result), &report); err != nil {
+				t.Fatalf("couldn't unmarshal check-indices report %q: %v\n", job.Result, err)
+			}
+			return report
+		default:
+			t.Fatalf("check-indices job failed: %s\n", job.Error)
+		}
+	}
+	t.Fatalf("check-indices job did not complete\n")
+	return IndexCheck{}
+}
+
+func TestCheckIndices(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{64, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{64, 0, 0}, dvid.Point3d{64, 64, 64}, 2)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	const slabSize = 64 * 64 * 64
+
+	report := checkIndicesJob(t, uuid, "")
+	if report.Blocks != 2 || report.Labels != 2 || len(report.Inconsistent) != 0 {
+		t.Fatalf("expected consistent indices, got %v\n", report)
+	}
+
+	// corrupt the index of label 2.
+	idx, err := GetLabelIndex(d, v, 2, false)
+	if err != nil || idx == nil {
+		t.Fatalf("couldn't get label 2 index: %v\n", err)
+	}
+	for _, svc := range idx.Blocks {
+		svc.Counts[2] = 100
+	}
+	if err := PutLabelIndex(d, v, 2, idx); err != nil {
+		t.Fatalf("couldn't put label 2 index: %v\n", err)
+	}
+	checkLabelSize(t, d, v, 2, false, 100)
+
+	report = checkIndicesJob(t, uuid, "")
+	if !reflect.DeepEqual(report.Inconsistent, []uint64{2}) || report.Repaired {
+		t.Fatalf("expected only label 2 to be inconsistent, got %v\n", report)
+	}
+	checkLabelSize(t, d, v, 2, false, 100)
+
+	report = checkIndicesJob(t, uuid, "?repair=true")
+	if !reflect.DeepEqual(report.Inconsistent, []uint64{2}) || !report.Repaired || report.MutationID == 0 {
+		t.Fatalf("expected label 2 to be repaired, got %v\n", report)
+	}
+	checkLabelSize(t, d, v, 2, false, slabSize)
+
+	report = checkIndicesJob(t, uuid, "")
+	if len(report.Inconsistent) != 0 {
+		t.Errorf("expected consistent indices after repair, got %v\n", report)
+	}
+
+	// check one block or index at a time with label 1 indexing a block it isn't in.
+	oldChunkSize := checkIndicesChunkSize
+	checkIndicesChunkSize = 1
+	defer func() { checkIndicesChunkSize = oldChunkSize }()
+	if idx, err = GetLabelIndex(d, v, 1, false); err != nil || idx == nil {
+		t.Fatalf("couldn't get label 1 index: %v\n", err)
+	}
+	idx.Blocks[labels.EncodeBlockIndex(1, 0, 0)] = &proto.SVCount{Counts: map[uint64]uint32{1: 50}}
+	if err := PutLabelIndex(d, v, 1, idx); err != nil {
+		t.Fatalf("couldn't put label 1 index: %v\n", err)
+	}
+	report = checkIndicesJob(t, uuid, "?repair=true")
+	if report.Blocks != 2 || report.Labels != 2 || !reflect.DeepEqual(report.Inconsistent, []uint64{1}) || !report.Repaired {
+		t.Fatalf("expected label 1 to be repaired, got %v\n", report)
+	}
+	checkLabelSize(t, d, v, 1, false, slabSize)
+
+	// only jobs started by check-indices can be retrieved.
+	job := server.StartJob("other job", func() (string, error) { return "", nil })
+	reqStr := fmt.Sprintf("%snode/%s/labels/check-indices/%s", server.WebAPIPath, uuid, job.ID)
+	if resp := server.TestHTTPResponse(t, "GET", reqStr, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for job not started by check-indices, got %d\n", resp.Code)
+	}
+}
@@ -26,9 +26,18 @@ const (
 	// key = label. value = datatype/common/proto/LabelIndex serialization
 	keyLabelIndex = 187
 
-	// key = label.  value = datatype/common/proto/AffinityTable serialization
+	// key = supervoxel.  value = datatype/common/proto/Affinities serialization
 	keyAffinities = 188
 
+	// key = label.  value = JSON of LabelCheckout
+	keyLabelCheckout = 189
+
+	// key = level + label.  value = label of group at the level
+	keyLevelMapping = 190
+
+	// key = level + group.  value = sorted labels of lower level explicitly mapped to group
+	keyLevelMembers = 191
+
 	// Used to store max label on commit for each version of the instance.
 	keyLabelMax = 237
 
@@ -48,6 +57,12 @@ func (d *Data) DescribeTKeyClass(tkc storage.TKeyClass) string {
 		return "labelmap label index key"
 	case keyAffinities:
 		return "labelmap affinities key"
+	case keyLabelCheckout:
+		return "labelmap label checkout key"
+	case keyLevelMapping:
+		return "labelmap level mapping key"
+	case keyLevelMembers:
+		return "labelmap level members key"
 	case keyLabelMax:
 		return "labelmap label max key"
 	case keyRepoLabelMax:
@@ -129,3 +144,66 @@ func DecodeAffinitiesTKey(tk storage.TKey) (label uint64, err error) {
 	label = binary.BigEndian.Uint64(ibytes[0:8])
 	return
 }
+
+// NewCheckoutTKey returns a TKey corresponding to a label's checkout.
+func NewCheckoutTKey(label uint64) storage.TKey {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, label)
+	return storage.NewTKey(keyLabelCheckout, buf)
+}
+
+// DecodeCheckoutTKey parses a TKey and returns the corresponding label.
+func DecodeCheckoutTKey(tk storage.TKey) (label uint64, err error) {
+	ibytes, err := tk.ClassBytes(keyLabelCheckout)
+	if err != nil {
+		return
+	}
+	label = binary.BigEndian.Uint64(ibytes[0:8])
+	return
+}
+
+// NewLevelMappingTKey returns a TKey corresponding to the mapping of a label at a level.
+func NewLevelMappingTKey(level uint8, label uint64) storage.TKey {
+	buf := make([]byte, 9)
+	buf[0] = byte(level)
+	binary.BigEndian.PutUint64(buf[1:], label)
+	return storage.NewTKey(keyLevelMapping, buf)
+}
+
+// DecodeLevelMappingTKey parses a TKey and returns the corresponding level and label.
+func DecodeLevelMappingTKey(tk storage.TKey) (level uint8, label uint64, err error) {
+	ibytes, err := tk.ClassBytes(keyLevelMapping)
+	if err != nil {
+		return
+	}
+	if len(ibytes) != 9 {
+		err = fmt.Errorf("bad labelmap level mapping key of %d bytes: %v", len(ibytes), ibytes)
+		return
+	}
+	level = uint8(ibytes[0])
+	label = binary.BigEndian.Uint64(ibytes[1:9])
+	return
+}
+
+// NewLevelMembersTKey returns a TKey corresponding to the members of a group at a level.
+func NewLevelMembersTKey(level uint8, group uint64) storage.TKey {
+	buf := make([]byte, 9)
+	buf[0] = byte(level)
+	binary.BigEndian.PutUint64(buf[1:], group)
+	return storage.NewTKey(keyLevelMembers, buf)
+}
+
+// DecodeLevelMembersTKey parses a TKey and returns the corresponding level and group.
+func DecodeLevelMembersTKey(tk storage.TKey) (level uint8, group uint64, err error) {
+	ibytes, err := tk.ClassBytes(keyLevelMembers)
+	if err != nil {
+		return
+	}
+	if len(ibytes) != 9 {
+		err = fmt.Errorf("bad labelmap level members key of %d bytes: %v", len(ibytes), ibytes)
+		return
+	}
+	level = uint8(ibytes[0])
+	group = binary.BigEndian.Uint64(ibytes[1:9])
+	return
+}
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/coocood/freecache"
 	"github.com/janelia-flyem/dvid/datastore"
@@ -122,6 +123,7 @@ func putLabelIndex(ctx *datastore.VersionedCtx, idx *labels.Index) error {
 	if err := store.Put(ctx, tk, compressed); err != nil {
 		return fmt.Errorf("unable to store indices for label %d, data %s: %v", idx.Label, ctx.Data().DataName(), err)
 	}
+	invalidateStats(ctx.Data(), ctx.VersionID(), idx.Label)
 	timedLog.Infof("stored label %d index with %d blocks", idx.Label, len(idx.Blocks))
 	return nil
 }
@@ -136,6 +138,7 @@ func deleteLabelIndex(ctx *datastore.VersionedCtx, label uint64) error {
 	if err := store.Delete(ctx, tk); err != nil {
 		return fmt.Errorf("unable to delete indices for label %d, data %s: %v", label, ctx.Data().DataName(), err)
 	}
+	invalidateStats(ctx.Data(), ctx.VersionID(), label)
 	return nil
 }
 
@@ -902,13 +905,89 @@ func (d *Data) FoundSparseVol(ctx *datastore.VersionedCtx, label uint64, bounds
 	return false, nil
 }
 
+// getTracedLabelIndex returns the label index as GetLabelIndex, recording its retrieval
+// within the trace of the request, if any.
+func (d *Data) getTracedLabelIndex(ctx *datastore.VersionedCtx, label uint64, isSupervoxel bool) (*labels.Index, error) {
+	span := dvid.StartSpan(ctx.Span(), "labelmap.GetLabelIndex")
+	span.SetAttribute("label", label)
+	span.SetAttribute("supervoxel", isSupervoxel)
+	idx, err := GetLabelIndex(d, ctx.VersionID(), label, isSupervoxel)
+	if idx != nil {
+		span.SetAttribute("blocks", len(idx.Blocks))
+	}
+	span.SetError(err)
+	span.End()
+	return idx, err
+}
+
+// blockStreamTrace accumulates the time spent getting, decoding and writing blocks when
+// streaming a sparse volume.  Per-block storage spans would swamp the trace for large
+// labels, so block reads use a context without a span and are summarized here.
+type blockStreamTrace struct {
+	span                *dvid.TraceSpan
+	get, decode, write  time.Duration
+	blocks, storedBytes int
+}
+
+func startBlockStream(ctx *datastore.VersionedCtx, numBlocks int) *blockStreamTrace {
+	span := dvid.StartSpan(ctx.Span(), "labelmap.streamBlocks")
+	span.SetAttribute("blocks_requested", numBlocks)
+	return &blockStreamTrace{span: span}
+}
+
+// getBlock gets and decodes a stored block, adding the times to the trace.  Returns a
+// nil block if the block isn't stored.
+func (t *blockStreamTrace) getBlock(store storage.OrderedKeyValueDB, ctx *datastore.VersionedCtx, scale uint8, izyx dvid.IZYXString) (*labels.PositionedBlock, error) {
+	t0 := time.Now()
+	data, err := store.Get(ctx, NewBlockTKeyByCoord(scale, izyx))
+	t.get += time.Since(t0)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	t1 := time.Now()
+	blockData, _, err := dvid.DeserializeData(data, true)
+	if err != nil {
+		return nil, err
+	}
+	pb := labels.PositionedBlock{BCoord: izyx}
+	if err := pb.Block.UnmarshalBinary(blockData); err != nil {
+		return nil, err
+	}
+	t.decode += time.Since(t1)
+	t.storedBytes += len(data)
+	return &pb, nil
+}
+
+// process sends a block to the output op, adding the time to the trace.
+func (t *blockStreamTrace) process(op *labels.OutputOp, pb *labels.PositionedBlock) {
+	t0 := time.Now()
+	op.Process(pb)
+	t.write += time.Since(t0)
+	t.blocks++
+}
+
+func (t *blockStreamTrace) end(err error) {
+	t.span.SetAttribute("blocks", t.blocks)
+	t.span.SetAttribute("stored_bytes", t.storedBytes)
+	t.span.SetAttribute("get_ms", t.get.Seconds()*1000)
+	t.span.SetAttribute("decode_ms", t.decode.Seconds()*1000)
+	t.span.SetAttribute("write_ms", t.write.Seconds()*1000)
+	t.span.SetError(err)
+	t.span.End()
+}
+
 // writeBinaryBlocks does a streaming write of an encoded sparse volume given a label.
 // It returns a bool whether the label was found in the given bounds and any error.
 func (d *Data) writeBinaryBlocks(ctx *datastore.VersionedCtx, label uint64, scale uint8, bounds dvid.Bounds, compression string, isSupervoxel bool, w io.Writer) (bool, error) {
-	idx, err := GetLabelIndex(d, ctx.VersionID(), label, isSupervoxel)
+	idx, err := d.getTracedLabelIndex(ctx, label, isSupervoxel)
 	if err != nil {
 		return false, err
 	}
+	return d.writeIndexBinaryBlocks(ctx, idx, label, scale, bounds, isSupervoxel, w)
+}
+
+// writeIndexBinaryBlocks is writeBinaryBlocks for the blocks and supervoxels of a given index.
+func (d *Data) writeIndexBinaryBlocks(ctx *datastore.VersionedCtx, idx *labels.Index, label uint64, scale uint8, bounds dvid.Bounds, isSupervoxel bool, w io.Writer) (bool, error) {
 	if idx == nil || len(idx.Blocks) == 0 {
 		return false, nil
 	}
@@ -932,37 +1011,26 @@ func (d *Data) writeBinaryBlocks(ctx *datastore.VersionedCtx, label uint64, scal
 	}
 	op := labels.NewOutputOp(w)
 	go labels.WriteBinaryBlocks(label, supervoxels, op, bounds)
+	trace := startBlockStream(ctx, len(indices))
+	blockCtx := datastore.NewVersionedCtx(d, ctx.VersionID())
 	var preErr error
 	for _, izyx := range indices {
-		tk := NewBlockTKeyByCoord(scale, izyx)
-		data, err := store.Get(ctx, tk)
+		pb, err := trace.getBlock(store, blockCtx, scale, izyx)
 		if err != nil {
 			preErr = err
 			break
 		}
-		if data == nil {
+		if pb == nil {
 			preErr = fmt.Errorf("expected block %s @ scale %d to have key-value, but found none", izyx, scale)
 			break
 		}
-		blockData, _, err := dvid.DeserializeData(data, true)
-		if err != nil {
-			preErr = err
-			break
-		}
-		var block labels.Block
-		if err := block.UnmarshalBinary(blockData); err != nil {
-			preErr = err
-			break
-		}
-		pb := labels.PositionedBlock{
-			Block:  block,
-			BCoord: izyx,
-		}
-		op.Process(&pb)
+		trace.process(op, pb)
 	}
 	if err = op.Finish(); err != nil {
+		trace.end(err)
 		return false, err
 	}
+	trace.end(preErr)
 
 	dvid.Infof("[%s] label %d consisting of %d supervoxels: streamed %d of %d blocks within bounds\n", ctx, label, len(supervoxels), len(indices), len(idx.Blocks))
 	return true, preErr
@@ -971,10 +1039,15 @@ func (d *Data) writeBinaryBlocks(ctx *datastore.VersionedCtx, label uint64, scal
 // writeStreamingRLE does a streaming write of an encoded sparse volume given a label.
 // It returns a bool whether the label was found in the given bounds and any error.
 func (d *Data) writeStreamingRLE(ctx *datastore.VersionedCtx, label uint64, scale uint8, bounds dvid.Bounds, compression string, isSupervoxel bool, w io.Writer) (bool, error) {
-	idx, err := GetLabelIndex(d, ctx.VersionID(), label, isSupervoxel)
+	idx, err := d.getTracedLabelIndex(ctx, label, isSupervoxel)
 	if err != nil {
 		return false, err
 	}
+	return d.writeIndexStreamingRLE(ctx, idx, label, scale, bounds, isSupervoxel, w)
+}
+
+// writeIndexStreamingRLE is writeStreamingRLE for the blocks and supervoxels of a given index.
+func (d *Data) writeIndexStreamingRLE(ctx *datastore.VersionedCtx, idx *labels.Index, label uint64, scale uint8, bounds dvid.Bounds, isSupervoxel bool, w io.Writer) (bool, error) {
 	if idx == nil || len(idx.Blocks) == 0 {
 		return false, nil
 	}
@@ -998,27 +1071,24 @@ func (d *Data) writeStreamingRLE(ctx *datastore.VersionedCtx, label uint64, scal
 	}
 	op := labels.NewOutputOp(w)
 	go labels.WriteRLEs(supervoxels, op, bounds)
+	trace := startBlockStream(ctx, len(blocks))
+	blockCtx := datastore.NewVersionedCtx(d, ctx.VersionID())
 	for _, izyx := range blocks {
-		tk := NewBlockTKeyByCoord(scale, izyx)
-		data, err := store.Get(ctx, tk)
+		pb, err := trace.getBlock(store, blockCtx, scale, izyx)
 		if err != nil {
+			trace.end(err)
 			return false, err
 		}
-		blockData, _, err := dvid.DeserializeData(data, true)
-		if err != nil {
+		if pb == nil {
+			err = fmt.Errorf("expected block %s @ scale %d to have key-value, but found none", izyx, scale)
+			trace.end(err)
 			return false, err
 		}
-		var block labels.Block
-		if err := block.UnmarshalBinary(blockData); err != nil {
-			return false, err
-		}
-		pb := labels.PositionedBlock{
-			Block:  block,
-			BCoord: izyx,
-		}
-		op.Process(&pb)
+		trace.process(op, pb)
 	}
-	if err = op.Finish(); err != nil {
+	err = op.Finish()
+	trace.end(err)
+	if err != nil {
 		return false, err
 	}
 
@@ -1032,6 +1102,12 @@ func (d *Data) writeLegacyRLE(ctx *datastore.VersionedCtx, label uint64, scale u
 	if err != nil {
 		return
 	}
+	return writeLegacyRLEData(data, compression, w)
+}
+
+// writeLegacyRLEData writes legacy RLEs with the given compression, returning false if
+// there were no RLEs.
+func writeLegacyRLEData(data []byte, compression string, w io.Writer) (found bool, err error) {
 	if len(data) == 0 {
 		found = false
 		return
@@ -1083,10 +1159,15 @@ func (d *Data) writeLegacyRLE(ctx *datastore.VersionedCtx, label uint64, scale u
 //        bytes   Optional payload dependent on first byte descriptor
 //
 func (d *Data) getLegacyRLEs(ctx *datastore.VersionedCtx, label uint64, scale uint8, bounds dvid.Bounds, isSupervoxel bool) ([]byte, error) {
-	idx, err := GetLabelIndex(d, ctx.VersionID(), label, isSupervoxel)
+	idx, err := d.getTracedLabelIndex(ctx, label, isSupervoxel)
 	if err != nil {
 		return nil, err
 	}
+	return d.getIndexLegacyRLEs(ctx, idx, label, scale, bounds, isSupervoxel)
+}
+
+// getIndexLegacyRLEs is getLegacyRLEs for the blocks and supervoxels of a given index.
+func (d *Data) getIndexLegacyRLEs(ctx *datastore.VersionedCtx, idx *labels.Index, label uint64, scale uint8, bounds dvid.Bounds, isSupervoxel bool) ([]byte, error) {
 	if idx == nil || len(idx.Blocks) == 0 {
 		return nil, nil
 	}
@@ -1118,14 +1199,16 @@ func (d *Data) getLegacyRLEs(ctx *datastore.VersionedCtx, label uint64, scale ui
 	}
 	op := labels.NewOutputOp(buf)
 	go labels.WriteRLEs(supervoxels, op, bounds)
+	trace := startBlockStream(ctx, len(blocks))
+	blockCtx := datastore.NewVersionedCtx(d, ctx.VersionID())
 	var numEmpty int
 	for _, izyx := range blocks {
-		tk := NewBlockTKeyByCoord(scale, izyx)
-		data, err := store.Get(ctx, tk)
+		pb, err := trace.getBlock(store, blockCtx, scale, izyx)
 		if err != nil {
+			trace.end(err)
 			return nil, err
 		}
-		if len(data) == 0 {
+		if pb == nil {
 			numEmpty++
 			if numEmpty < 10 {
 				dvid.Errorf("Block %s included in blocks for labels %s but has no data (%d times)... skipping.\n", izyx, supervoxels, numEmpty)
@@ -1134,25 +1217,15 @@ func (d *Data) getLegacyRLEs(ctx *datastore.VersionedCtx, label uint64, scale ui
 			}
 			continue
 		}
-		blockData, _, err := dvid.DeserializeData(data, true)
-		if err != nil {
-			return nil, err
-		}
-		var block labels.Block
-		if err := block.UnmarshalBinary(blockData); err != nil {
-			return nil, err
-		}
-		pb := labels.PositionedBlock{
-			Block:  block,
-			BCoord: izyx,
-		}
-		op.Process(&pb)
+		trace.process(op, pb)
 	}
 	if numEmpty < len(blocks) {
 		if err = op.Finish(); err != nil {
+			trace.end(err)
 			return nil, err
 		}
 	}
+	trace.end(nil)
 
 	serialization := buf.Bytes()
 	numRuns := uint32(len(serialization)-12) >> 4
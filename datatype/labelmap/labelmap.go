@@ -264,6 +264,8 @@ GET  <api URL>/node/<UUID>/<data name>/specificblocks[?queryopts]
     Arguments:
 
 	supervoxels   If "true", returns unmapped supervoxels, disregarding any kind of merges.
+	level         Name of an agglomeration level (see /levels).  If given, returns the labels
+	                of groups at that level instead of body labels.
     UUID          Hexadecimal string with enough characters to uniquely identify a version node.
     data name     Name of labelmap instance.
 
@@ -361,6 +363,8 @@ GET  <api URL>/node/<UUID>/<data name>/raw/<dims>/<size>/<offset>[/<format>][?qu
     Query-string Options:
 
 	supervoxels   If "true", returns unmapped supervoxels, disregarding any kind of merges.
+	level         Name of an agglomeration level (see /levels).  If given, returns the labels
+	                of groups at that level instead of body labels.
     roi           Name of roi data instance used to mask the requested data.
     scale         A number from 0 up to MaxDownresLevel where each level beyond 0 has 1/2 resolution
 	                of previous level.  Level 0 is the highest resolution.
@@ -440,6 +444,8 @@ GET <api URL>/node/<UUID>/<data name>/label/<coord>[?queryopts]
     Query-string Options:
 
 	supervoxels   If "true", returns unmapped supervoxel label, disregarding any kind of merges.
+	level         Name of an agglomeration level (see /levels).  If given, returns the labels
+	                of groups at that level instead of body labels.
     scale         A number from 0 up to MaxDownresLevel where each level beyond 0 has 1/2 resolution
 	                of previous level.  Level 0 is the highest resolution.
 
@@ -460,6 +466,8 @@ GET <api URL>/node/<UUID>/<data name>/labels[?queryopts]
     Query-string Options:
 
 	supervoxels   If "true", returns unmapped supervoxel label, disregarding any kind of merges.
+	level         Name of an agglomeration level (see /levels).  If given, returns the labels
+	                of groups at that level instead of body labels.
     scale         A number from 0 up to MaxDownresLevel where each level beyond 0 has 1/2 resolution
 	                of previous level.  Level 0 is the highest resolution.
     hash          MD5 hash of request body content in hexidecimal string format.
@@ -511,6 +519,37 @@ GET <api URL>/node/<UUID>/<data name>/supervoxel-splits
     UUID          Hexadecimal string with enough characters to uniquely identify a version node.
     data name     Name of label data.
 
+GET  <api URL>/node/<UUID>/<data name>/levels
+POST <api URL>/node/<UUID>/<data name>/levels/<level name>
+GET  <api URL>/node/<UUID>/<data name>/levels/<level name>/<group>
+
+	Agglomeration levels are named groupings above the body labels, e.g., body -> neuron ->
+	cell type, where each level groups the labels of the level below it.  Bodies are the
+	labels of the supervoxel mapping.  Reads via /label, /labels, /raw, /isotropic, /blocks,
+	/specificblocks and /sparsevol can select a level with a "level=<level name>" query
+	string, and /merge, /cleave and /split can act at a level in the same way.  Groupings at each
+	level are versioned like the supervoxel mapping.
+
+	The GET without a level name returns JSON of the level names from finest to coarsest:
+
+	[ "neuron", "celltype" ]
+
+	The POST adds a new coarsest level where each label of the level below is initially its
+	own group with the same label.  Levels cannot be removed or reordered.
+
+	The GET with a level name and group label returns JSON of the sorted labels of the level
+	below that are within the group.
+
+	A new body from a body cleave or split is put within the first level group of the body
+	it came from, so it stays within the same groups at every level.  Bodies merged into
+	another body are removed from their first level groups.
+
+    Arguments:
+    UUID          Hexadecimal string with enough characters to uniquely identify a version node.
+    data name     Name of label data.
+    level name    Name of the agglomeration level.
+    group         Label of a group at the level.
+
 
 GET <api URL>/node/<UUID>/<data name>/blocks/<size>/<offset>[?queryopts]
 
@@ -566,6 +605,8 @@ GET <api URL>/node/<UUID>/<data name>/blocks/<size>/<offset>[?queryopts]
     Query-string Options:
 
 	supervoxels   If "true", returns unmapped supervoxels, disregarding any kind of merges.
+	level         Name of an agglomeration level (see /levels).  If given, returns the labels
+	                of groups at that level instead of body labels.
 	scale         A number from 0 up to MaxDownresLevel where each level beyond 0 has 1/2 resolution
 	                of previous level.  Level 0 is the highest resolution.
     compression   Allows retrieval of block data in "lz4" (default), "gzip", blocks" (native DVID
@@ -875,6 +916,8 @@ GET  <api URL>/node/<UUID>/<data name>/sparsevol/<label>?<options>
 	scale        A number from 0 up to MaxDownresLevel where each level beyond 0 has 1/2 
                    resolution of previous level.  Level 0 is the highest resolution.
 	supervoxels   If "true", interprets the given label as a supervoxel id.
+	level         Name of an agglomeration level (see /levels).  If given, interprets the given
+	                label as a group at that level and returns the sparse volume of all its bodies.
 
 
 HEAD <api URL>/node/<UUID>/<data name>/sparsevol/<label>[?supervoxels=true]
@@ -898,6 +941,8 @@ HEAD <api URL>/node/<UUID>/<data name>/sparsevol/<label>[?supervoxels=true]
     Query-string Options:
 
 	supervoxels   If "true", interprets the given label as a supervoxel id, not a possibly merged label.
+	level         Name of an agglomeration level.  If given, interprets the given label as a group
+	                at that level.
 
 GET <api URL>/node/<UUID>/<data name>/sparsevol-by-point/<coord>[?supervoxels=true]
 
@@ -976,6 +1021,155 @@ GET <api URL>/node/<UUID>/<data name>/sparsevols-coarse/<start label>/<end label
 			int32   Length of run
 
 
+GET <api URL>/node/<UUID>/<data name>/neighbors/<label>[?supervoxels=true]
+
+	Returns JSON giving every label touching the given label at scale 0, ordered by
+	decreasing contact.  For each neighbor, "faces" is the number of voxel faces shared
+	with the given label and "point" is a voxel of the neighbor touching the given label:
+
+	[
+		{ "label": 23, "faces": 1873, "point": [1021, 344, 671] },
+		{ "label": 189, "faces": 12, "point": [1100, 402, 690] },
+		...
+	]
+
+	Returns a status code 404 (Not Found) if label does not exist.  Only the blocks in the
+	label's index and the blocks adjacent to them are examined.
+
+    Query-string Options:
+
+	supervoxels   If "true", the given label and returned neighbors are supervoxel ids, not
+	                possibly merged labels.
+
+GET <api URL>/node/<UUID>/<data name>/contact/<label1>/<label2>[?<options>]
+
+	Returns the contact surface between two labels at scale 0, i.e., every voxel of either
+	label that shares a face with a voxel of the other label.  By default, the surface is
+	returned in the Legacy RLEs format described for the sparsevol endpoint with the # Voxels
+	field set.
+
+	Returns a status code 404 (Not Found) if either label does not exist or the labels
+	do not touch.
+
+    Query-string Options:
+
+	supervoxels   If "true", the given labels are supervoxel ids, not possibly merged labels.
+	format        If "points", returns a JSON list of [x, y, z] voxel coordinates in ZYX order
+	                instead of RLEs.  Defaults to "rles".
+
+GET  <api URL>/node/<UUID>/<data name>/components/<label>[?<options>]
+POST <api URL>/node/<UUID>/<data name>/components/<label>?relabel-disconnected=true[&<options>]
+
+	Returns JSON giving the connected components of the label's voxels at scale 0, ordered
+	by decreasing size.  Each component gives its number of voxels and the minimum and 
+	maximum voxel coordinates of its bounding box:
+
+	{
+		"Label": 23,
+		"Connectivity": 6,
+		"Components": [
+			{ "Size": 18730, "MinPoint": [1021, 344, 671], "MaxPoint": [1130, 402, 690] },
+			{ "Size": 12, "MinPoint": [1200, 344, 700], "MaxPoint": [1203, 346, 700] }
+		]
+	}
+
+	A body with more than one component has disconnected pieces, e.g., after splits or 
+	painting.  With the POST and "relabel-disconnected=true", every component except the 
+	largest is split off into a new label as if by POST /split, and each of those components
+	also gives its new "Label" and the "MutationID" of its split.  Kafka messages are sent 
+	for each split as described for the split endpoint.
+
+	Other label mutations wait until the components are found and relabeled.  If a split 
+	fails after earlier components were relabeled, those splits are kept and the error 
+	lists their new labels and mutation IDs.  Labels with more than 16M runs of voxels 
+	along x are too large for this endpoint.
+
+	Returns a status code 404 (Not Found) if label does not exist.  Returns a status code 
+	409 (Conflict) when relabeling a label checked out by another user.
+
+    Query-string Options:
+
+	connectivity  Either "6" (default) for voxels sharing faces or "26" for voxels sharing
+	                faces, edges, or corners.
+	supervoxels   If "true", the given label is a supervoxel id, not a possibly merged label.
+	                Supervoxels cannot be relabeled.
+
+
+GET <api URL>/node/<UUID>/<data name>/stats/<label>[?<options>]
+
+	Returns JSON giving geometric statistics of the given label computed from the blocks in
+	its label index.  All coordinates are voxel coordinates at the requested scale:
+
+	{
+		"label": 23,
+		"scale": 0,
+		"voxels": 231387,
+		"minvoxel": [1021, 344, 671],
+		"maxvoxel": [1723, 1279, 4855],
+		"centroid": [1402.3, 811.7, 2511.9],
+		"covariance": [[...], [...], [...]],
+		"principalaxes": [[0.12, 0.05, 0.99], [...], [...]],
+		"principalvariances": [1512003.2, 8812.4, 1203.7],
+		"surfacearea": 80214,
+		"mutationid": 1821
+	}
+
+	The bounding box is voxel-precise and inclusive.  The covariance gives the second central
+	moments of voxel coordinates.  The principal axes are unit eigenvectors of the covariance
+	in order of decreasing variance, which is given by the principal variances.  The surface
+	area is the number of voxel faces not shared with another voxel of the label.  The
+	mutation id is the last mutation of the label index.
+
+	Stats are cached and recomputed only after the label index changes, so repeated
+	queries are cheap.  Returns a status code 404 (Not Found) if label does not exist.
+
+    Query-string Options:
+
+	supervoxels   If "true", interprets the given label as a supervoxel id, not a possibly merged label.
+	scale         A number from 0 up to MaxDownresLevel where each level beyond 0 has 1/2 resolution
+	                of previous level.  Level 0 (default) is the highest resolution.
+
+POST <api URL>/node/<UUID>/<data name>/stats[?<options>]
+
+	Returns a JSON array of stats, as described for the GET above, for a JSON array of labels
+	in the POSTed body.  A null is returned for any label that does not exist.  Accepts the
+	same query-string options as the GET.
+
+	Example request body: [23, 189, 2018]
+
+
+GET  <api URL>/node/<UUID>/<data name>/checkout/<label>
+POST <api URL>/node/<UUID>/<data name>/checkout/<label>?u=<user>[&ttl=<seconds>]
+
+	A POST checks out the given label (not supervoxel) for the user given by the "u" query
+	string so no other user can mutate the label until the checkout is released or expires.
+	The ttl is the number of seconds the checkout lasts, defaulting to 3600.  A user can
+	renew their own checkout by POSTing again.  Returns a status code 409 (Conflict) if the
+	label is checked out by another user.
+
+	Both GET and POST return JSON for the checkout:
+
+	{ "Label": 23, "User": "jane", "Created": "2018-05-01T15:04:05Z", "Expires": "2018-05-01T16:04:05Z" }
+
+	A GET returns a status code 404 (Not Found) if the label is not checked out.
+
+	While a label is checked out, merge, cleave, split, split-supervoxel, and mappings requests that
+	involve the label return a status code 409 (Conflict) unless they are made with the "u"
+	query string of the checkout's user.  Checkouts of merged labels move to the merge target,
+	and labels created by cleaves or splits of a checked out label are checked out to the
+	same user.  Checkouts are stored per version and are inherited by child versions.
+
+POST <api URL>/node/<UUID>/<data name>/release/<label>?u=<user>[&force=true]
+
+	Releases the user's checkout of the given label.  Returns a status code 409 (Conflict)
+	if the label is checked out by another user unless "force" is "true".
+
+GET <api URL>/node/<UUID>/<data name>/checkouts
+
+	Returns a JSON array of all unexpired checkouts, in the format given for the checkout
+	endpoint, ordered by label.
+
+
 POST <api URL>/node/<UUID>/<data name>/merge
 
 	Merges labels (not supervoxels).  Requires JSON in request body using the 
@@ -992,6 +1186,12 @@ POST <api URL>/node/<UUID>/<data name>/merge
 	Note that it's computationally more efficient to group a number of merges into the
 	same toLabel as a single merge request instead of multiple merge requests.
 
+	If a "level=<level name>" query string is given, the labels are groups at that 
+	agglomeration level (see /levels) and only the grouping at that level is changed.  
+	Labels that are members of another group at the level are rejected.  
+	The Kafka message for a level merge has "Action" of "merge-level" with the "Level" name 
+	and "Members" giving the labels of the level below that moved into the target group.
+
 	Kafka JSON message generated by this request:
 		{ 
 			"Action": "merge",
@@ -1024,6 +1224,12 @@ POST <api URL>/node/<UUID>/<data name>/cleave/<label>
 	non-existent body or attempt to cleve all the supervoxels from a label, i.e., you 
 	are not allowed to create empty labels from the cleave operation.
 
+	If a "level=<level name>" query string is given, the label is a group at that 
+	agglomeration level (see /levels) and the POSTed data are labels of the level below 
+	within the group, which are moved to a new group.  The group's own label cannot be
+	cleaved from it.  Only the grouping at that level is changed.  The Kafka message for a level cleave has "Action" of "cleave-level" with the 
+	"Level" name and "CleavedItems" instead of "CleavedSupervoxels".
+
 	Returns the following JSON:
 
 		{ 
@@ -1049,6 +1255,38 @@ POST <api URL>/node/<UUID>/<data name>/cleave/<label>
 		"MutationID": <unique id for mutation>
 	}
 
+POST <api URL>/node/<UUID>/<data name>/cleave-by-seeds/<label>[?graph=affinities][&apply=true]
+
+	Partitions the supervoxels of a label given groups of seed supervoxels, using a seeded
+	watershed over the graph of the label's supervoxels.  Requires JSON in request body
+	with at least two groups of supervoxels:
+
+	[[seed supervoxel1, seed supervoxel2, ...], [seed supervoxel3, ...], ...]
+
+	Each supervoxel of the label is assigned to the group whose seeds it is most strongly 
+	connected to, where the strength of a path between supervoxels is its weakest edge.  
+	By default, edges are weighted by the number of voxel faces shared by supervoxels as 
+	computed from the label's blocks.  Supervoxels not connected to any seed are unassigned.
+
+	Returns the following JSON:
+
+		{ 
+			"Supervoxels": [[<supervoxels of group 1>], [<supervoxels of group 2>], ...],
+			"Unassigned": [<supervoxel not connected to seeds>, ...]
+		}
+
+	If the partition is applied, "Labels" gives the label of each group and "MutationIDs" 
+	gives the mutation ID of each cleave.  Returns a status code 409 (Conflict) if the 
+	label is checked out by another user.
+
+	Query-string Options:
+
+	graph   Use "affinities" to weight edges by the affinities stored via POST /affinities.
+	apply   If "true", the first group and any unassigned supervoxels keep the label while 
+	          each other group is cleaved off into a new label.  The cleaves are applied 
+	          as a batch of mutations, so a single "batch" Kafka message with "cleave" 
+	          messages is sent as described for POST /mutations.
+
 
 POST <api URL>/node/<UUID>/<data name>/split-supervoxel/<supervoxel>?<options>
 
@@ -1148,6 +1386,14 @@ POST <api URL>/node/<UUID>/<data name>/split/<label>
 	NOTE: The POSTed split sparse volume must be a subset of the given label's voxels.  You cannot
 	give an arbitrary sparse volume that may span multiple labels.  An error will be returned if
 	the POSTed split sparsevol isn't contained within the split label.
+
+	If a "level=<level name>" query string is given, the label is a group at that
+	agglomeration level (see /levels) and the POSTed sparse volume must be within the bodies
+	of the group.  Each body with split voxels is split into a new body, unless all of its
+	voxels are in the split, and these bodies are put into a new group whose label is
+	returned.  Other mutations wait until the level split completes.  Besides the Kafka
+	messages of each body split, a "split-level" message gives the "Level" name, "Target"
+	group, "NewLabel" group, "SplitBodies" with their "NewBodies", and "MovedBodies".
 	
 	Kafka JSON message generated by this request:
 		{ 
@@ -1173,6 +1419,154 @@ POST <api URL>/node/<UUID>/<data name>/split/<label>
 			"UUID": <UUID on which split was done>
 		}
 
+POST <api URL>/node/<UUID>/<data name>/paint[?downres=false]
+
+	Paints voxels with a label given a stroke description, which is much faster than POSTing
+	raw subvolumes or blocks for small corrections.  Requires JSON in request body with either 
+	a polyline of points and a radius, where spheres are painted at each point and capsules 
+	along each segment:
+
+	{
+		"label": 23,
+		"mode": "overwrite",
+		"points": [[100, 200, 300], [110, 200, 305]],
+		"radius": 3.5
+	}
+
+	or a binary mask with one byte per voxel in ZYX order, where non-zero bytes are painted:
+
+	{
+		"label": 23,
+		"mode": "label",
+		"only": 189,
+		"mask": {
+			"offset": [100, 200, 300],
+			"size": [16, 16, 4],
+			"data": "<base64 encoded mask bytes>"
+		}
+	}
+
+	The label is written as a supervoxel ID, so painted voxels belong to whatever body the
+	label is mapped to.  The mode determines which voxels within the stroke are painted:
+
+	overwrite   All voxels (default).
+	background  Only background (label 0) voxels.
+	label       Only voxels whose body is the "only" label.
+
+	Only blocks intersected by the stroke are read.  All changed blocks are stored in one
+	batch before label indices are updated, and if either fails, the blocks and indices are
+	rolled back.  Synced data are then notified and lower-res scales are computed.  Returns
+	the following JSON:
+
+		{ "MutationID": <unique id for mutation>, "Voxels": <number of voxels changed> }
+
+	Returns a status code 409 (Conflict) if a body with changed voxels or the body of the
+	painted label is checked out by another user.  Strokes that intersect more blocks than
+	the server's "paintMaxBlocks" setting (default 4096) are rejected.
+
+	Query-string Options:
+
+	downres  Defaults to "true" where all lower-res scales of painted blocks will be computed.
+	           Use "false" if you plan on supplying lower-res scales via POST /blocks.
+
+	After completion, the following JSON message is published:
+		{ 
+			"Action": "paint",
+			"Label": <painted label>,
+			"Mode": <paint mode>,
+			"Only": <only label if "label" mode>,
+			"Labels": [<body with changed voxels>, ...],
+			"Voxels": <number of voxels changed>,
+			"MutationID": <unique id for mutation>,
+			"UUID": <UUID on which paint was done>,
+			"User": <user who painted>
+		}
+
+POST <api URL>/node/<UUID>/<data name>/mutations[?downres=false]
+
+	Applies an ordered batch of merge, cleave, split-supervoxel, and mapping ops all-or-nothing.
+	This is much faster than separate requests for large numbers of small mutations.  Requires
+	a JSON array in the request body where each element has exactly one op:
+
+	[
+		{ "merge": { "target": 23, "merged": [189, 2018] } },
+		{ "cleave": { "target": 23, "cleavedlabel": 30, "cleaved": [2018] } },
+		{ "split-supervoxel": { "supervoxel": 189, "splitlabel": 31, "remainlabel": 32 },
+		  "rles": "<base64 encoded binary sparse volume>" },
+		{ "mapping": { "mapped": 40, "original": [41, 42] } }
+	]
+
+	The fields of each op are those of the corresponding protobuf ops, ignoring "mutid".
+	A cleavedlabel, splitlabel, or remainlabel that is 0 or omitted is replaced by a new
+	label.  The split voxels of a split-supervoxel op use the RLE format given for the split
+	endpoint.  Like the mappings POST, a mapping op only changes the mapping of the original
+	supervoxels and does not change label indices.
+
+	The ops are given consecutive mutation IDs.  If any op fails, all earlier ops in the
+	batch are rolled back and an error is returned.  The rollback also returns labels
+	allocated by the batch unless another request allocated labels in the meantime, and
+	split volumes are only stored once the batch succeeds.  Mutation IDs of a rolled back
+	batch are not reused.  Other mutations of the instance wait until the batch completes.  On success, returns a JSON array with the result of each op:
+
+	[
+		{ "MutationID": 1000 },
+		{ "MutationID": 1001, "CleavedLabel": 30 },
+		{ "MutationID": 1002, "SplitSupervoxel": 31, "RemainSupervoxel": 32 },
+		{ "MutationID": 1003 }
+	]
+
+	Returns a status code 409 (Conflict) if an op involves a label checked out by another user.
+
+	Query-string Options:
+
+	downres  Defaults to "true" where all lower-res scales of split supervoxels will be computed.
+	           Use "false" if you plan on supplying lower-res scales via POST /blocks.
+
+	Instead of the messages sent by each single-op endpoint, a single Kafka JSON message is
+	sent after the batch completes:
+		{
+			"Action": "batch",
+			"FirstMutationID": <mutation id of first op>,
+			"LastMutationID": <mutation id of last op>,
+			"Mutations": [<message for each op as sent by single-op endpoint>, ...],
+			"UUID": <UUID on which batch was done>
+		}
+
+	The message for a mapping op has the form:
+		{ "Action": "mapping", "Mapped": <label>, "Original": [<supervoxel>, ...], "MutationID": ... }
+
+
+POST <api URL>/node/<UUID>/<data name>/check-indices[?roi=<roi name>][&repair=true]
+GET  <api URL>/node/<UUID>/<data name>/check-indices/<job id>
+
+	The POST starts a job that scans the label blocks at scale 0, recomputes the supervoxel
+	counts in each block, and compares them with the stored label indices.  Blocks and then 
+	label indices are checked in chunks of 1000.  Label indices
+	can disagree with block contents after crashes or partial ingestion, which leads to 
+	incorrect sizes, sparse volumes, and split results.  Returns status code 202 (Accepted)
+	with the ID of the job:
+
+		{ "job": "7f3bd1f2a2e14f31b4d4d1c1e0a3a2b9" }
+
+	The GET returns the status of a check job started on this data instance, which can also 
+	be retrieved via GET /api/admin/jobs/<job id>.  Other jobs return status code 404 (Not Found).  When completed, the job's "Result" is JSON describing 
+	the check:
+
+		{
+			"Blocks": <number of blocks scanned>,
+			"Labels": <number of labels checked>,
+			"Inconsistent": [<label with inconsistent index>, ...],
+			"Repaired": <true if inconsistent indices were rewritten>,
+			"MutationID": <unique id for mutation if repaired>
+		}
+
+	Query-string Options:
+
+	roi      Name of an ROI instance in the same repo.  If given, only blocks intersecting
+	           the ROI are scanned and only those blocks of the label indices are checked.
+	repair   If "true", inconsistent label indices are rewritten to match the blocks.  Label
+	           mutations are blocked while each chunk is checked and repaired.  Labels modified
+	           between chunks, or during a check without repair, can be reported as inconsistent.
 
 GET  <api URL>/node/<UUID>/<data name>/index/<label>
 POST <api URL>/node/<UUID>/<data name>/index/<label>
@@ -1248,8 +1642,735 @@ POST <api URL>/node/<UUID>/<data name>/mappings
 	message MappingOps {
 		repeated MappingOp mappings = 1;
 	}
+
+	Returns a status code 409 (Conflict) if a body that would lose or gain supervoxels is
+	checked out by another user.
+
+
+GET  <api URL>/node/<UUID>/<data name>/affinities/<supervoxel>
+POST <api URL>/node/<UUID>/<data name>/affinities
+
+	Allows retrieval or storing of affinities between neighboring supervoxels.  The affinities
+	form a versioned graph of supervoxels that can be used for agglomeration via the 
+	/agglomerate endpoint.  Affinities are symmetric, so an affinity stored for supervoxel A 
+	with neighbor B is also the affinity of B with neighbor A.
+
+	The GET returns JSON of the affinities of the given supervoxel in order of neighbor label:
+
+	[ { "label": 23, "affinity": 0.85 }, { "label": 189, "affinity": 0.12 }, ... ]
+
+	The POST expects a protobuf serialization of an AffinityTable message defined by:
+
+	message Affinities {
+		repeated uint64 labels = 1;
+		repeated float affinities = 2;
+	}
+
+	message AffinityTable {
+		map<uint64, Affinities> table = 1;
+	}
+
+	where the table is keyed by supervoxel and the labels are neighboring supervoxels with
+	the corresponding affinities.  A POSTed affinity replaces any stored affinity between the
+	same supervoxels.
+
+POST <api URL>/node/<UUID>/<data name>/agglomerate?threshold=<affinity>[&roi=<roi name>]
+
+	Sets the mapping of supervoxels in the affinity graph so supervoxels connected by affinities
+	at or above the threshold are in the same body.  Each body is labeled by its smallest 
+	supervoxel unless that label is already used by an unaffected body or by a body that keeps
+	supervoxels without stored affinities, in which case a new label is used.  Supervoxels in
+	the graph without affinities above the threshold become their own bodies, while
+	supervoxels without stored affinities keep their current bodies.  Label indices of all
+	changed bodies are rebuilt, so re-running with a different threshold replaces the
+	previous agglomeration.  If storing the changes fails, they are rolled back.  Since the
+	changes can't be described by merge or cleave sync events, agglomeration is refused with
+	an error if any data instance is synced to this one.
+
+	Returns JSON of the form:
+
+	{ "MutationID": 1000, "Bodies": 23, "Supervoxels": 189 }
+
+	where "Bodies" is the number of bodies created, changed, or removed and "Supervoxels" is
+	the number of supervoxels mapped to a different body.  Returns a status code 409 (Conflict)
+	if a changed body is checked out by another user.
+
+	Query-string Options:
+
+	threshold  Minimum affinity for supervoxels to be agglomerated (required).
+	roi        Name of an ROI instance in the same repo.  If given, only supervoxels with 
+	             voxels in blocks intersecting the ROI are agglomerated.
+
+	After completion, the following JSON message is published:
+		{ 
+			"Action": "agglomerate",
+			"Threshold": <threshold>,
+			"ROI": <roi name or empty>,
+			"Labels": [<changed body>, ...],
+			"MutationID": <unique id for mutation>,
+			"UUID": <UUID on which agglomeration was done>
+		}
 `
 
+// optional bounds on the sparse volume endpoints.
+var routeBounds = []datastore.RouteParam{
+	{Name: "minx", Type: "integer", Description: "Spans must be equal to or larger than this minimum x voxel coordinate."},
+	{Name: "maxx", Type: "integer", Description: "Spans must be equal to or smaller than this maximum x voxel coordinate."},
+	{Name: "miny", Type: "integer", Description: "Spans must be equal to or larger than this minimum y voxel coordinate."},
+	{Name: "maxy", Type: "integer", Description: "Spans must be equal to or smaller than this maximum y voxel coordinate."},
+	{Name: "minz", Type: "integer", Description: "Spans must be equal to or larger than this minimum z voxel coordinate."},
+	{Name: "maxz", Type: "integer", Description: "Spans must be equal to or smaller than this maximum z voxel coordinate."},
+}
+
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings including extents.", Response: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/info",
+		Summary: "Modifies the configuration of the data instance given in the JSON request body.",
+		Request: []string{"application/json"},
+	},
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings including extents.", Response: []string{"application/json"}},
+	{Method: "POST", Path: "/resolution", Summary: "Sets the resolution for the image volume.", Request: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Appends data instances with which this labelmap is synced.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:   "GET",
+		Path:     "/metadata",
+		Summary:  "Returns a JSON schema describing the layout of bytes returned for n-d images.",
+		Response: []string{"application/vnd.dvid-nd-data+json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/specificblocks",
+		Summary: "Returns the blocks at the given block coordinates, each preceded by its block coordinate and size.",
+		Query: []datastore.RouteParam{
+			{Name: "blocks", Required: true, Description: "Comma-separated block coordinates, e.g., \"x1,y1,z1,x2,y2,z2\"."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/isotropic/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image scaled to have isotropic pixels or a 3d array of labels in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d image or 3d array of labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/raw/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns a 2d image or 3d array of labels in ZYX order in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of data extraction, e.g., \"0_1\" or \"xy\" for a slice and \"0_1_2\" for a subvolume."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\" for 2d, e.g., \"jpg:80\", and \"octet-stream\" for 3d."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+			{Name: "compression", Description: "Compression of 3d data: \"lz4\", \"gzip\", \"google\", or \"googlegzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the 3d request."},
+		},
+		Response: []string{"image/png", "image/jpeg", "application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/raw/{dims}/{size}/{offset}",
+		Summary: "Stores block-aligned labels in ZYX order.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Must be \"0_1_2\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "mutate", Type: "boolean", Description: "If true, the POST is a mutation of prior data so synced data can be updated."},
+			{Name: "compression", Description: "Compression of the request body: \"lz4\" or \"gzip\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/pseudocolor/{dims}/{size}/{offset}",
+		Summary: "Returns a 2d PNG image where each label is hashed to a different color.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in voxels along each dimension specified in dims, e.g., \"512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_100\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"image/png"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/label/{coord}",
+		Summary: "Returns the label at the given voxel coordinate.",
+		PathParams: []datastore.RouteParam{
+			{Name: "coord", Description: "Voxel coordinate, e.g., \"10_20_30\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/labels",
+		Summary: "Returns the labels at each voxel coordinate in the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+			{Name: "hash", Description: "MD5 hash of request body content in hexadecimal string format."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/blocks/{size}/{offset}",
+		Summary: "Returns the blocks within a block-aligned subvolume, each preceded by its block coordinate and size.",
+		PathParams: []datastore.RouteParam{
+			{Name: "size", Description: "Size in voxels, e.g., \"64_64_64\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_0\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+			{Name: "compression", Description: "\"lz4\" (default), \"gzip\", \"blocks\" for native DVID label blocks, or \"uncompressed\"."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/blocks",
+		Summary: "Stores the blocks in the request body, each preceded by its block coordinate and size.",
+		Query: []datastore.RouteParam{
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			{Name: "downres", Type: "boolean", Description: "If true, the blocks are also down-sampled to lower resolutions. Requires scale 0."},
+			{Name: "noindexing", Type: "boolean", Description: "If true, label indices are not computed for the blocks."},
+			{Name: "compression", Description: "Compression of the request body: \"blocks\" (default)."},
+			{Name: "throttle", Type: "boolean", Description: "If true, returns 503 if the server is too busy to handle the request."},
+		},
+		Request: []string{"application/octet-stream"},
+	},
+	{Method: "GET", Path: "/maxlabel", Summary: "Returns the maximum label for the version.", Response: []string{"application/json"}},
+	{
+		Method:     "POST",
+		Path:       "/maxlabel/{label}",
+		Summary:    "Sets the maximum label for the version if it is greater than the current maximum.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "The maximum label."}},
+	},
+	{Method: "GET", Path: "/nextlabel", Summary: "Returns what would be the next new label for the version.", Response: []string{"application/json"}},
+	{
+		Method:     "POST",
+		Path:       "/nextlabel/{count}",
+		Summary:    "Reserves the given number of new labels and returns the reserved range.",
+		PathParams: []datastore.RouteParam{{Name: "count", Type: "integer", Description: "Number of labels to reserve."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/mapping",
+		Summary: "Returns the mapped label for each supervoxel in the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "nolookup", Type: "boolean", Description: "If true, supervoxels are not verified to exist via their label indices."},
+			{Name: "hash", Description: "MD5 hash of request body content in hexadecimal string format."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:   "GET",
+		Path:     "/supervoxel-splits",
+		Summary:  "Returns the supervoxel splits up to this version in order of proximity to it.",
+		Response: []string{"application/json"},
+	},
+	{
+		Method:   "GET",
+		Path:     "/levels",
+		Summary:  "Returns the names of the agglomeration levels from finest to coarsest.",
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/levels/{level}",
+		Summary:    "Adds a new coarsest agglomeration level where each label of the level below is its own group.",
+		PathParams: []datastore.RouteParam{{Name: "level", Description: "Name of the new level."}},
+	},
+	{
+		Method:  "GET",
+		Path:    "/levels/{level}/{group}",
+		Summary: "Returns the sorted labels of the level below that are within a group.",
+		PathParams: []datastore.RouteParam{
+			{Name: "level", Description: "Name of the level."},
+			{Name: "group", Type: "integer", Description: "Label of a group at the level."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/lastmod/{label}",
+		Summary:    "Returns the last modification metadata for a label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/supervoxels/{label}",
+		Summary:    "Returns the supervoxels that have been agglomerated into a label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/size/{label}",
+		Summary: "Returns the number of voxels in a label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sizes",
+		Summary: "Returns the number of voxels in each label of the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "hash", Description: "MD5 hash of request body content in hexadecimal string format."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevol-size/{label}",
+		Summary: "Returns the number of blocks and the block-accurate voxel bounding box of a label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevol/{label}",
+		Summary: "Returns a sparse volume with voxels of the given label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: append(append([]datastore.RouteParam{}, routeBounds...),
+			datastore.RouteParam{Name: "format", Description: "\"rles\" (default), \"srles\" for streaming RLEs, or \"blocks\" for a binary block stream."},
+			datastore.RouteParam{Name: "exact", Type: "boolean", Description: "If false, RLEs can extend outside the bounds within border blocks."},
+			datastore.RouteParam{Name: "compression", Description: "\"lz4\" or \"gzip\" compression of the returned \"rles\" data."},
+			datastore.RouteParam{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+			datastore.RouteParam{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			datastore.RouteParam{Name: "level", Description: "Name of an agglomeration level whose groups are returned instead of bodies."},
+		),
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "HEAD",
+		Path:    "/sparsevol/{label}",
+		Summary: "Returns 200 if the label has voxels within the optional block-aligned bounds and 204 if not.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: append(append([]datastore.RouteParam{}, routeBounds...),
+			datastore.RouteParam{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		),
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevol-by-point/{coord}",
+		Summary: "Returns a sparse volume in encoded RLE format of the label at the given voxel.",
+		PathParams: []datastore.RouteParam{
+			{Name: "coord", Description: "Voxel coordinate, e.g., \"10_20_30\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevol-coarse/{label}",
+		Summary: "Returns a sparse volume with blocks of the given label in encoded RLE format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: append(append([]datastore.RouteParam{}, routeBounds...),
+			datastore.RouteParam{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		),
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/sparsevols-coarse/{start}/{end}",
+		Summary: "Returns a stream of coarse sparse volumes for labels in the given range.",
+		PathParams: []datastore.RouteParam{
+			{Name: "start", Type: "integer", Description: "First label of the range."},
+			{Name: "end", Type: "integer", Description: "Last label of the range."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/neighbors/{label}",
+		Summary: "Returns every label touching the given label ordered by decreasing contact.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/contact/{label1}/{label2}",
+		Summary: "Returns the contact surface between two labels.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label1", Type: "integer", Description: "A label."},
+			{Name: "label2", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "format", Description: "\"rles\" (default) or \"points\" for a JSON list of voxel coordinates."},
+		},
+		Response: []string{"application/octet-stream", "application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/components/{label}",
+		Summary: "Returns the connected components of a label ordered by decreasing size.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "connectivity", Type: "integer", Description: "\"6\" (default) for voxels sharing faces or \"26\" for voxels sharing faces, edges, or corners."},
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/components/{label}",
+		Summary: "Splits every connected component of a label except the largest into new labels.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "relabel-disconnected", Type: "boolean", Required: true, Description: "Must be true."},
+			{Name: "connectivity", Type: "integer", Description: "\"6\" (default) for voxels sharing faces or \"26\" for voxels sharing faces, edges, or corners."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/stats/{label}",
+		Summary: "Returns geometric statistics of a label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/stats",
+		Summary: "Returns geometric statistics for each label in the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "supervoxels", Type: "boolean", Description: "If true, labels are supervoxel ids rather than possibly merged labels."},
+			{Name: "scale", Type: "integer", Description: "Resolution level from 0 up to MaxDownresLevel where 0 is the highest resolution."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/checkout/{label}",
+		Summary:    "Returns the checkout of a label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/checkout/{label}",
+		Summary: "Checks out a label so no other user can mutate it until released or expired.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "u", Required: true, Description: "User checking out the label."},
+			{Name: "ttl", Type: "integer", Description: "Number of seconds the checkout lasts, defaulting to 3600."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/release/{label}",
+		Summary: "Releases the user's checkout of a label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "u", Required: true, Description: "User releasing the checkout."},
+			{Name: "force", Type: "boolean", Description: "If true, releases a checkout by another user."},
+		},
+	},
+	{Method: "GET", Path: "/checkouts", Summary: "Returns all unexpired checkouts ordered by label.", Response: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/merge",
+		Summary: "Merges labels given as a JSON array [toLabel, fromLabel1, fromLabel2, ...].",
+		Query: []datastore.RouteParam{
+			{Name: "level", Description: "Name of an agglomeration level at which the labels are groups."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/cleave/{label}",
+		Summary: "Cleaves the supervoxels in the JSON array request body from a label into a new label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "Label to cleave."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "level", Description: "Name of an agglomeration level at which the label is a group of labels of the level below."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/cleave-by-seeds/{label}",
+		Summary: "Partitions the supervoxels of a label given groups of seed supervoxels in the JSON request body.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "Label to partition."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "graph", Description: "Use \"affinities\" to weight edges by stored affinities instead of shared voxel faces."},
+			{Name: "apply", Type: "boolean", Description: "If true, each group but the first is cleaved off into a new label."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/split-supervoxel/{supervoxel}",
+		Summary: "Splits the voxels in the binary sparse volume request body from a supervoxel into two new supervoxels.",
+		PathParams: []datastore.RouteParam{
+			{Name: "supervoxel", Type: "integer", Description: "Supervoxel to split."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "split", Type: "integer", Description: "Label to use for the split voxels."},
+			{Name: "remain", Type: "integer", Description: "Label to use for the remaining voxels."},
+			{Name: "downres", Type: "boolean", Description: "Defaults to true where all lower-res scales are computed."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/split/{label}",
+		Summary: "Splits the voxels in the binary sparse volume request body from a label into a new label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "Label to split."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "level", Description: "Name of an agglomeration level at which the label is a group of labels of the level below."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/paint",
+		Summary: "Paints voxels with a label given a polyline or mask stroke in the JSON request body.",
+		Query: []datastore.RouteParam{
+			{Name: "downres", Type: "boolean", Description: "Defaults to true where all lower-res scales are computed."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/mutations",
+		Summary: "Applies an ordered batch of merge, cleave, split-supervoxel, and mapping ops all-or-nothing.",
+		Query: []datastore.RouteParam{
+			{Name: "downres", Type: "boolean", Description: "Defaults to true where all lower-res scales are computed."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/check-indices",
+		Summary: "Starts a job that checks label indices against the label blocks and returns its ID.",
+		Query: []datastore.RouteParam{
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "repair", Type: "boolean", Description: "If true, inconsistent label indices are rewritten to match the blocks."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/check-indices/{job}",
+		Summary:    "Returns the status of a label index check job.",
+		PathParams: []datastore.RouteParam{{Name: "job", Description: "ID of the job."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/index/{label}",
+		Summary:    "Returns the protobuf serialization of a label index.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Response:   []string{"application/octet-stream"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/index/{label}",
+		Summary:    "Stores the protobuf serialized label index in the request body.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Request:    []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/indices",
+		Summary: "Stores the protobuf serialized label indices in the request body.",
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:   "GET",
+		Path:     "/mappings",
+		Summary:  "Streams the non-identity mappings of supervoxels, one space-delimited mapping per line.",
+		Response: []string{"text/plain"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/mappings",
+		Summary: "Stores the protobuf serialized supervoxel mappings in the request body.",
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/affinities/{supervoxel}",
+		Summary:    "Returns the affinities of a supervoxel in order of neighbor label.",
+		PathParams: []datastore.RouteParam{{Name: "supervoxel", Type: "integer", Description: "A supervoxel."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/affinities",
+		Summary: "Stores the protobuf serialized affinities between neighboring supervoxels in the request body.",
+		Request: []string{"application/octet-stream"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/agglomerate",
+		Summary: "Maps supervoxels connected by affinities at or above a threshold into the same body.",
+		Query: []datastore.RouteParam{
+			{Name: "threshold", Type: "number", Required: true, Description: "Minimum affinity for supervoxels to be agglomerated."},
+			{Name: "roi", Description: "Name of roi data instance used to mask the data."},
+			{Name: "u", Description: "User making the request, which must match the user of any checkout of the involved labels."},
+		},
+		Response: []string{"application/json"},
+	},
+}
+
 var (
 	dtype        Type
 	encodeFormat dvid.DataValues
@@ -1303,6 +2424,7 @@ func init() {
 	// See doc for package on why channels are segregated instead of interleaved.
 	// Data types must be registered with the datastore to be used.
 	datastore.Register(&dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -1425,12 +2547,22 @@ type Data struct {
 	// the higher level.
 	MaxDownresLevel uint8
 
+	// Names of agglomeration levels above the body labels, from finest to coarsest.
+	// Each level groups the labels of the level below it.
+	Levels []string
+
 	updates  []uint32 // tracks updating to each scale of labelmap [0:MaxDownresLevel+1]
 	updateMu sync.RWMutex
 
 	mlMu sync.RWMutex // For atomic access of MaxLabel and MaxRepoLabel
 
 	voxelMu sync.Mutex // Only allow voxel-level label mutation ops sequentially.
+
+	batchMu sync.RWMutex // Batches of mutations exclude all other label mutation ops.
+
+	checkoutMu sync.Mutex // Serializes changes to label checkouts.
+
+	levelsMu sync.RWMutex // For atomic access of Levels
 }
 
 // GetMaxDownresLevel returns the number of down-res levels, where level 0 = high-resolution
@@ -1490,6 +2622,7 @@ func (d *Data) CopyPropertiesFrom(src datastore.DataService, fs storage.FilterSp
 
 	d.IndexedLabels = d2.IndexedLabels
 	d.MaxDownresLevel = d2.MaxDownresLevel
+	d.Levels = d2.GetLevels()
 
 	return d.Data.CopyPropertiesFrom(d2.Data, fs)
 }
@@ -1556,6 +2689,7 @@ type propsJSON struct {
 	MaxRepoLabel    uint64
 	IndexedLabels   bool
 	MaxDownresLevel uint8
+	Levels          []string
 }
 
 func (d *Data) MarshalJSON() ([]byte, error) {
@@ -1570,6 +2704,7 @@ func (d *Data) MarshalJSON() ([]byte, error) {
 			MaxRepoLabel:    d.MaxRepoLabel,
 			IndexedLabels:   d.IndexedLabels,
 			MaxDownresLevel: d.MaxDownresLevel,
+			Levels:          d.GetLevels(),
 		},
 	})
 }
@@ -1601,6 +2736,7 @@ func (d *Data) MarshalJSONExtents(ctx *datastore.VersionedCtx) ([]byte, error) {
 			MaxRepoLabel:    d.MaxRepoLabel,
 			IndexedLabels:   d.IndexedLabels,
 			MaxDownresLevel: d.MaxDownresLevel,
+			Levels:          d.GetLevels(),
 		},
 		extentsJSON,
 	})
@@ -1620,6 +2756,9 @@ func (d *Data) GobDecode(b []byte) error {
 		dvid.Errorf("Decoding labelmap %q: no MaxDownresLevel, setting to 7", d.DataName())
 		d.MaxDownresLevel = 7
 	}
+	if err := dec.Decode(&(d.Levels)); err != nil {
+		d.Levels = nil
+	}
 	d.updates = make([]uint32, d.MaxDownresLevel+1)
 	return nil
 }
@@ -1636,6 +2775,9 @@ func (d *Data) GobEncode() ([]byte, error) {
 	if err := enc.Encode(d.MaxDownresLevel); err != nil {
 		return nil, err
 	}
+	if err := enc.Encode(d.GetLevels()); err != nil {
+		return nil, err
+	}
 	return buf.Bytes(), nil
 }
 
@@ -1718,6 +2860,15 @@ func (d *Data) persistMaxLabel(v dvid.VersionID) error {
 	return store.Put(ctx, maxLabelTKey, buf)
 }
 
+func (d *Data) deleteMaxLabel(v dvid.VersionID) error {
+	store, err := datastore.GetOrderedKeyValueDB(d)
+	if err != nil {
+		return err
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	return store.Delete(ctx, maxLabelTKey)
+}
+
 func (d *Data) persistMaxRepoLabel() error {
 	store, err := datastore.GetOrderedKeyValueDB(d)
 	if err != nil {
@@ -2042,7 +3193,7 @@ func writeBlock(w http.ResponseWriter, bcoord dvid.ChunkPoint3d, out []byte) err
 }
 
 // sendBlocksSpecific writes data to the blocks specified -- best for non-ordered backend
-func (d *Data) sendBlocksSpecific(ctx *datastore.VersionedCtx, w http.ResponseWriter, supervoxels bool, compression, blockstring string, scale uint8) (numBlocks int, err error) {
+func (d *Data) sendBlocksSpecific(ctx *datastore.VersionedCtx, w http.ResponseWriter, supervoxels bool, levels *levelMapper, compression, blockstring string, scale uint8) (numBlocks int, err error) {
 	switch compression {
 	case "":
 		compression = "blocks"
@@ -2123,6 +3274,7 @@ func (d *Data) sendBlocksSpecific(ctx *datastore.VersionedCtx, w http.ResponseWr
 					data:        value,
 					compression: compression,
 					supervoxels: supervoxels,
+					levels:      levels,
 				}
 				out, err := d.transcodeBlock(b)
 				ch <- blockSend{bcoord: bcoord, value: out, err: err}
@@ -2187,6 +3339,7 @@ type blockData struct {
 	supervoxels bool
 	v           dvid.VersionID
 	data        []byte
+	levels      *levelMapper // if non-nil, labels are mapped to a level above bodies
 }
 
 // transcodes a block of data by doing any data modifications necessary to meet requested
@@ -2240,7 +3393,7 @@ func (d *Data) transcodeBlock(b blockData) (out []byte, err error) {
 		if mapping, err = getMapping(d, b.v); err != nil {
 			return
 		}
-		if mapping != nil && mapping.exists(b.v) {
+		if (mapping != nil && mapping.exists(b.v)) || b.levels != nil {
 			doMapping = true
 		}
 	}
@@ -2278,6 +3431,11 @@ func (d *Data) transcodeBlock(b blockData) (out []byte, err error) {
 
 		if doMapping {
 			modifyBlockMapping(b.v, &block, mapping)
+			if b.levels != nil {
+				if err = b.levels.mapBlock(&block); err != nil {
+					return
+				}
+			}
 		}
 
 		if b.compression == "blocks" { // send native DVID block compression with gzip
@@ -2333,6 +3491,11 @@ func (d *Data) sendBlock(w http.ResponseWriter, b blockData) error {
 
 // SendBlocks returns a series of blocks covering the given block-aligned subvolume.
 func (d *Data) SendBlocks(ctx *datastore.VersionedCtx, w http.ResponseWriter, supervoxels bool, scale uint8, subvol *dvid.Subvolume, compression string) error {
+	return d.sendLevelBlocks(ctx, w, supervoxels, nil, scale, subvol, compression)
+}
+
+// sendLevelBlocks is SendBlocks with labels mapped to a level if the level mapper is non-nil.
+func (d *Data) sendLevelBlocks(ctx *datastore.VersionedCtx, w http.ResponseWriter, supervoxels bool, levels *levelMapper, scale uint8, subvol *dvid.Subvolume, compression string) error {
 	w.Header().Set("Content-type", "application/octet-stream")
 
 	switch compression {
@@ -2414,6 +3577,7 @@ func (d *Data) SendBlocks(ctx *datastore.VersionedCtx, w http.ResponseWriter, su
 					bcoord:      dvid.ChunkPoint3d{x, y, z},
 					compression: compression,
 					supervoxels: supervoxels,
+					levels:      levels,
 					v:           ctx.VersionID(),
 					data:        kv.V,
 				}
@@ -2474,6 +3638,9 @@ func (d *Data) ReceiveBlocks(ctx *datastore.VersionedCtx, r io.ReadCloser, scale
 		return fmt.Errorf("Data type labelmap had error initializing store: %v", err)
 	}
 
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
 	// Only do voxel-based mutations one at a time.  This lets us remove handling for block-level concurrency.
 	d.voxelMu.Lock()
 	defer d.voxelMu.Unlock()
@@ -2491,6 +3658,7 @@ func (d *Data) ReceiveBlocks(ctx *datastore.VersionedCtx, r io.ReadCloser, scale
 	var downresMut *downres.Mutation
 	if downscale {
 		downresMut = downres.NewMutation(d, ctx.VersionID(), mutID)
+		downresMut.SetSpan(ctx.Span())
 	}
 
 	svmap, err := getMapping(d, ctx.VersionID())
@@ -2523,7 +3691,7 @@ func (d *Data) ReceiveBlocks(ctx *datastore.VersionedCtx, r io.ReadCloser, scale
 			}
 			go d.updateBlockMaxLabel(ctx.VersionID(), ingestBlock.Data)
 			evt := datastore.SyncEvent{d.DataUUID(), event}
-			msg := datastore.SyncMessage{event, ctx.VersionID(), ingestBlock}
+			msg := datastore.SyncMessage{Event: event, Version: ctx.VersionID(), Delta: ingestBlock, Span: ctx.Span()}
 			if err := datastore.NotifySubscribers(evt, msg); err != nil {
 				dvid.Errorf("Unable to notify subscribers of event %s in %s\n", event, d.DataName())
 			}
@@ -3056,7 +4224,7 @@ func (d *Data) ServeHTTP(uuid dvid.UUID, ctx *datastore.VersionedCtx, w http.Res
 	// Prevent use of APIs that require IndexedLabels when it is not set.
 	if !d.IndexedLabels {
 		switch parts[3] {
-		case "sparsevol", "sparsevol-by-point", "sparsevol-coarse", "maxlabel", "nextlabel", "split-supervoxel", "cleave", "merge":
+		case "sparsevol", "sparsevol-by-point", "sparsevol-coarse", "neighbors", "contact", "components", "stats", "maxlabel", "nextlabel", "split-supervoxel", "cleave", "merge", "mutations", "paint", "check-indices", "affinities", "agglomerate", "cleave-by-seeds":
 			server.BadRequest(w, r, "data %q is not label indexed (IndexedLabels=false): %q endpoint is not supported", d.DataName(), parts[3])
 			return
 		}
@@ -3108,9 +4276,14 @@ func (d *Data) ServeHTTP(uuid dvid.UUID, ctx *datastore.VersionedCtx, w http.Res
 			server.BadRequest(w, r, err)
 			return
 		}
+		levels, err := d.getLevelMapper(ctx, queryStrings)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
 		if action == "get" {
 			timedLog := dvid.NewTimeLog()
-			numBlocks, err := d.sendBlocksSpecific(ctx, w, supervoxels, compression, blocklist, scale)
+			numBlocks, err := d.sendBlocksSpecific(ctx, w, supervoxels, levels, compression, blocklist, scale)
 			if err != nil {
 				server.BadRequest(w, r, err)
 				return
@@ -3147,6 +4320,9 @@ func (d *Data) ServeHTTP(uuid dvid.UUID, ctx *datastore.VersionedCtx, w http.Res
 	case "supervoxel-splits":
 		d.handleSupervoxelSplits(ctx, w, r)
 
+	case "levels":
+		d.handleLevels(ctx, w, r, parts)
+
 	case "blocks":
 		d.handleBlocks(ctx, w, r, parts)
 
@@ -3185,6 +4361,27 @@ func (d *Data) ServeHTTP(uuid dvid.UUID, ctx *datastore.VersionedCtx, w http.Res
 	case "sparsevols-coarse":
 		d.handleSparsevolsCoarse(ctx, w, r, parts)
 
+	case "neighbors":
+		d.handleNeighbors(ctx, w, r, parts)
+
+	case "contact":
+		d.handleContact(ctx, w, r, parts)
+
+	case "components":
+		d.handleComponents(ctx, w, r, parts)
+
+	case "stats":
+		d.handleStats(ctx, w, r, parts)
+
+	case "checkout":
+		d.handleCheckout(ctx, w, r, parts)
+
+	case "release":
+		d.handleRelease(ctx, w, r, parts)
+
+	case "checkouts":
+		d.handleCheckouts(ctx, w, r)
+
 	case "maxlabel":
 		d.handleMaxlabel(ctx, w, r, parts)
 
@@ -3197,21 +4394,39 @@ func (d *Data) ServeHTTP(uuid dvid.UUID, ctx *datastore.VersionedCtx, w http.Res
 	case "cleave":
 		d.handleCleave(ctx, w, r, parts)
 
+	case "cleave-by-seeds":
+		d.handleCleaveBySeeds(ctx, w, r, parts)
+
 	case "split":
 		d.handleSplit(ctx, w, r, parts)
 
 	case "merge":
 		d.handleMerge(ctx, w, r, parts)
 
+	case "mutations":
+		d.handleMutations(ctx, w, r)
+
+	case "paint":
+		d.handlePaint(ctx, w, r)
+
 	case "index":
 		d.handleIndex(ctx, w, r, parts)
 
+	case "check-indices":
+		d.handleCheckIndices(ctx, w, r, parts)
+
 	case "indices":
 		d.handleIngestIndices(ctx, w, r)
 
 	case "mappings":
 		d.handleMappings(ctx, w, r)
 
+	case "affinities":
+		d.handleAffinities(ctx, w, r, parts)
+
+	case "agglomerate":
+		d.handleAgglomerate(ctx, w, r)
+
 	default:
 		server.BadAPIRequest(w, r, d)
 	}
@@ -3240,12 +4455,23 @@ func (d *Data) handleLabel(ctx *datastore.VersionedCtx, w http.ResponseWriter, r
 		return
 	}
 	isSupervoxel := queryStrings.Get("supervoxels") == "true"
+	levels, err := d.getLevelMapper(ctx, queryStrings)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
 
 	labels, err := d.GetLabelPoints(ctx.VersionID(), []dvid.Point3d{coord}, scale, isSupervoxel)
 	if err != nil {
 		server.BadRequest(w, r, err)
 		return
 	}
+	if levels != nil {
+		if err := levels.mapLabels(labels); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+	}
 	w.Header().Set("Content-type", "application/json")
 	jsonStr := fmt.Sprintf(`{"Label": %d}`, labels[0])
 	fmt.Fprintf(w, jsonStr)
@@ -3273,6 +4499,11 @@ func (d *Data) handleLabels(ctx *datastore.VersionedCtx, w http.ResponseWriter,
 		return
 	}
 	isSupervoxel := queryStrings.Get("supervoxels") == "true"
+	levels, err := d.getLevelMapper(ctx, queryStrings)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
 	hash := queryStrings.Get("hash")
 	if err := checkContentHash(hash, data); err != nil {
 		server.BadRequest(w, r, err)
@@ -3288,6 +4519,12 @@ func (d *Data) handleLabels(ctx *datastore.VersionedCtx, w http.ResponseWriter,
 		server.BadRequest(w, r, err)
 		return
 	}
+	if levels != nil {
+		if err := levels.mapLabels(labels); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+	}
 	w.Header().Set("Content-type", "application/json")
 	fmt.Fprintf(w, "[")
 	sep := false
@@ -3408,6 +4645,11 @@ func (d *Data) handleBlocks(ctx *datastore.VersionedCtx, w http.ResponseWriter,
 	supervoxels := queryStrings.Get("supervoxels") == "true"
 
 	if strings.ToLower(r.Method) == "get" {
+		levels, err := d.getLevelMapper(ctx, queryStrings)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
 		if len(parts) < 6 {
 			server.BadRequest(w, r, "must specify size and offset with GET /blocks endpoint")
 			return
@@ -3429,7 +4671,7 @@ func (d *Data) handleBlocks(ctx *datastore.VersionedCtx, w http.ResponseWriter,
 			return
 		}
 
-		if err := d.SendBlocks(ctx, w, supervoxels, scale, subvol, compression); err != nil {
+		if err := d.sendLevelBlocks(ctx, w, supervoxels, levels, scale, subvol, compression); err != nil {
 			server.BadRequest(w, r, err)
 		}
 		timedLog.Infof("HTTP GET blocks at size %s, offset %s (%s)", parts[4], parts[5], r.URL)
@@ -3594,6 +4836,62 @@ func (d *Data) handleIngestIndices(ctx *datastore.VersionedCtx, w http.ResponseW
 	timedLog.Infof("HTTP POST indices for %d labels (%s)", len(indices.Indices), r.URL)
 }
 
+func (d *Data) handleCheckIndices(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// POST <api URL>/node/<UUID>/<data name>/check-indices[?roi=<roi name>][&repair=true]
+	// GET  <api URL>/node/<UUID>/<data name>/check-indices/<job id>
+	switch strings.ToLower(r.Method) {
+	case "post":
+		queryStrings := r.URL.Query()
+		roiname := dvid.InstanceName(queryStrings.Get("roi"))
+		repair := queryStrings.Get("repair") == "true"
+		if roiname != "" {
+			if _, err := d.newROIFilter(ctx.VersionID(), roiname); err != nil {
+				server.BadRequest(w, r, err)
+				return
+			}
+		}
+		info := dvid.GetModInfo(r)
+		uuid, _ := datastore.UUIDFromVersion(ctx.VersionID())
+		command := fmt.Sprintf("node %s %s check-indices roi=%s repair=%t", uuid, d.DataName(), roiname, repair)
+		job := server.StartJob(command, func() (string, error) {
+			report, err := d.CheckIndices(ctx.VersionID(), roiname, repair, info)
+			if err != nil {
+				return "", err
+			}
+			jsonBytes, err := json.Marshal(report)
+			if err != nil {
+				return "", err
+			}
+			return string(jsonBytes), nil
+		})
+		addCheckIndicesJob(job.ID, d.DataUUID())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, `{"job":%q}`, job.ID)
+
+	case "get":
+		if len(parts) < 5 {
+			server.BadRequest(w, r, "DVID requires job ID to follow 'check-indices' command")
+			return
+		}
+		job, found := server.GetJob(parts[4])
+		if !found || !isCheckIndicesJob(job.ID, d.DataUUID()) {
+			http.Error(w, fmt.Sprintf("Job %q not found.", parts[4]), http.StatusNotFound)
+			return
+		}
+		jsonBytes, err := json.Marshal(job)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, string(jsonBytes))
+
+	default:
+		server.BadRequest(w, r, "only GET or POST actions allowed for /check-indices endpoint")
+	}
+}
+
 func (d *Data) handleMappings(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request) {
 	// POST <api URL>/node/<UUID>/<data name>/mappings
 	timedLog := dvid.NewTimeLog()
@@ -3619,8 +4917,20 @@ func (d *Data) handleMappings(ctx *datastore.VersionedCtx, w http.ResponseWriter
 		var mappings proto.MappingOps
 		if err := mappings.Unmarshal(serialization); err != nil {
 			server.BadRequest(w, r, err)
+			return
+		}
+		d.batchMu.RLock()
+		defer d.batchMu.RUnlock()
+		err = d.checkMappingCheckouts(ctx.VersionID(), dvid.GetModInfo(r).User, mappings)
+		if _, conflict := err.(CheckoutError); conflict {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
 		}
-		if err := d.ingestMappings(ctx, mappings); err != nil {
+		if err := d.ingestMappings(ctx, mappings, nil); err != nil {
 			server.BadRequest(w, r, err)
 		}
 		timedLog.Infof("HTTP POST %d merges (%s)", len(mappings.Mappings), r.URL)
@@ -3636,6 +4946,216 @@ func (d *Data) handleMappings(ctx *datastore.VersionedCtx, w http.ResponseWriter
 	}
 }
 
+func (d *Data) handleAffinities(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/affinities/<supervoxel>
+	// POST <api URL>/node/<UUID>/<data name>/affinities
+	timedLog := dvid.NewTimeLog()
+
+	switch strings.ToLower(r.Method) {
+	case "post":
+		if r.Body == nil {
+			server.BadRequest(w, r, fmt.Errorf("no data POSTed"))
+			return
+		}
+		serialization, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		var table proto.AffinityTable
+		if err := table.Unmarshal(serialization); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		if err := d.PutAffinities(ctx.VersionID(), &table); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		timedLog.Infof("HTTP POST affinities for %d supervoxels (%s)", len(table.Table), r.URL)
+
+	case "get":
+		if len(parts) < 5 {
+			server.BadRequest(w, r, "DVID requires supervoxel ID to follow 'affinities' command")
+			return
+		}
+		supervoxel, err := strconv.ParseUint(parts[4], 10, 64)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		affs, err := d.GetAffinities(ctx.VersionID(), supervoxel)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		jsonBytes, err := json.Marshal(affs)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, string(jsonBytes))
+		timedLog.Infof("HTTP GET affinities for supervoxel %d (%s)", supervoxel, r.URL)
+
+	default:
+		server.BadRequest(w, r, "only GET or POST actions allowed for /affinities endpoint")
+	}
+}
+
+func (d *Data) handleLevels(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/levels
+	// POST <api URL>/node/<UUID>/<data name>/levels/<level name>
+	// GET <api URL>/node/<UUID>/<data name>/levels/<level name>/<group>
+	timedLog := dvid.NewTimeLog()
+
+	switch strings.ToLower(r.Method) {
+	case "post":
+		if len(parts) < 5 || parts[4] == "" {
+			server.BadRequest(w, r, "DVID requires level name to follow POST on 'levels' command")
+			return
+		}
+		if err := d.AddLevel(ctx.VersionID(), parts[4]); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		timedLog.Infof("HTTP POST level %q (%s)", parts[4], r.URL)
+
+	case "get":
+		var result interface{}
+		if len(parts) < 5 || parts[4] == "" {
+			result = d.GetLevels()
+		} else {
+			if len(parts) < 6 {
+				server.BadRequest(w, r, "DVID requires group label to follow level name in 'levels' command")
+				return
+			}
+			group, err := strconv.ParseUint(parts[5], 10, 64)
+			if err != nil {
+				server.BadRequest(w, r, err)
+				return
+			}
+			if result, err = d.GetLevelMembers(ctx.VersionID(), parts[4], group); err != nil {
+				server.BadRequest(w, r, err)
+				return
+			}
+		}
+		jsonBytes, err := json.Marshal(result)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, string(jsonBytes))
+		timedLog.Infof("HTTP GET levels (%s)", r.URL)
+
+	default:
+		server.BadRequest(w, r, "only GET or POST actions allowed for /levels endpoint")
+	}
+}
+
+func (d *Data) handleLevelSparsevol(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, level uint8, group uint64, scale uint8, b dvid.Bounds, compression string) {
+	// GET <api URL>/node/<UUID>/<data name>/sparsevol/<group>?level=<level name>
+	// HEAD <api URL>/node/<UUID>/<data name>/sparsevol/<group>?level=<level name>
+	timedLog := dvid.NewTimeLog()
+
+	idx, err := d.getLevelIndex(ctx, level, group)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	switch strings.ToLower(r.Method) {
+	case "get":
+		w.Header().Set("Content-type", "application/octet-stream")
+
+		var found bool
+		switch svformatFromQueryString(r) {
+		case FormatLegacyRLE:
+			var data []byte
+			if data, err = d.getIndexLegacyRLEs(ctx, idx, group, scale, b, false); err == nil {
+				found, err = writeLegacyRLEData(data, compression, w)
+			}
+		case FormatBinaryBlocks:
+			found, err = d.writeIndexBinaryBlocks(ctx, idx, group, scale, b, false, w)
+		case FormatStreamingRLE:
+			found, err = d.writeIndexStreamingRLE(ctx, idx, group, scale, b, false, w)
+		}
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		if !found {
+			dvid.Infof("GET sparsevol on group %d was not found.\n", group)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+	case "head":
+		w.Header().Set("Content-type", "text/html")
+		var found bool
+		if idx != nil {
+			blocks, err := idx.GetProcessedBlockIndices(0, b)
+			if err != nil {
+				server.BadRequest(w, r, err)
+				return
+			}
+			found = len(blocks) > 0
+		}
+		if found {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+
+	default:
+		server.BadRequest(w, r, "Unable to handle HTTP action %s on sparsevol endpoint with level", r.Method)
+		return
+	}
+
+	timedLog.Infof("HTTP %s: sparsevol on group %d (%s)", r.Method, group, r.URL)
+}
+
+func (d *Data) handleAgglomerate(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request) {
+	// POST <api URL>/node/<UUID>/<data name>/agglomerate?threshold=<affinity>[&roi=<roi name>]
+	if strings.ToLower(r.Method) != "post" {
+		server.BadRequest(w, r, "Agglomerate requests must be POST actions.")
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	queryStrings := r.URL.Query()
+	thresholdStr := queryStrings.Get("threshold")
+	if thresholdStr == "" {
+		server.BadRequest(w, r, "agglomerate requires a threshold query string")
+		return
+	}
+	threshold, err := strconv.ParseFloat(thresholdStr, 32)
+	if err != nil {
+		server.BadRequest(w, r, "bad threshold %q: %v", thresholdStr, err)
+		return
+	}
+	roiname := dvid.InstanceName(queryStrings.Get("roi"))
+	info := dvid.GetModInfo(r)
+	result, err := d.Agglomerate(ctx.VersionID(), float32(threshold), roiname, info)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("Error on agglomerate: %v", err))
+		return
+	}
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+
+	timedLog.Infof("HTTP POST agglomerate at threshold %f: %d supervoxels remapped (%s)", threshold, result.Supervoxels, r.URL)
+}
+
 func (d *Data) handlePseudocolor(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
 	if len(parts) < 7 {
 		server.BadRequest(w, r, "'%s' must be followed by shape/size/offset", parts[3])
@@ -3728,6 +5248,11 @@ func (d *Data) handleDataRequest(ctx *datastore.VersionedCtx, w http.ResponseWri
 		server.BadRequest(w, r, "bad scale specified: %v", err)
 		return
 	}
+	levels, err := d.getLevelMapper(ctx, queryStrings)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
 
 	switch plane.ShapeDimensions() {
 	case 2:
@@ -3746,7 +5271,12 @@ func (d *Data) handleDataRequest(ctx *datastore.VersionedCtx, w http.ResponseWri
 			server.BadRequest(w, r, err)
 			return
 		}
-		img, err := d.GetImage(ctx.VersionID(), lbl, supervoxels, scale, roiname)
+		var img *dvid.Image
+		if levels != nil {
+			img, err = d.getLevelImage(ctx.VersionID(), lbl, scale, roiname, levels)
+		} else {
+			img, err = d.GetImage(ctx.VersionID(), lbl, supervoxels, scale, roiname)
+		}
 		if err != nil {
 			server.BadRequest(w, r, err)
 			return
@@ -3794,6 +5324,12 @@ func (d *Data) handleDataRequest(ctx *datastore.VersionedCtx, w http.ResponseWri
 				server.BadRequest(w, r, err)
 				return
 			}
+			if levels != nil {
+				if err := levels.mapVolume(data); err != nil {
+					server.BadRequest(w, r, err)
+					return
+				}
+			}
 			if err := sendBinaryData(compression, data, subvol, w); err != nil {
 				server.BadRequest(w, r, err)
 				return
@@ -4096,6 +5632,11 @@ func (d *Data) handleSparsevol(ctx *datastore.VersionedCtx, w http.ResponseWrite
 		return
 	}
 	isSupervoxel := queryStrings.Get("supervoxels") == "true"
+	levels, err := d.getLevelMapper(ctx, queryStrings)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
 
 	label, err := strconv.ParseUint(parts[4], 10, 64)
 	if err != nil {
@@ -4111,6 +5652,10 @@ func (d *Data) handleSparsevol(ctx *datastore.VersionedCtx, w http.ResponseWrite
 		server.BadRequest(w, r, err)
 		return
 	}
+	if levels != nil {
+		d.handleLevelSparsevol(ctx, w, r, levels.level, label, scale, b, compression)
+		return
+	}
 
 	timedLog := dvid.NewTimeLog()
 	switch strings.ToLower(r.Method) {
@@ -4312,12 +5857,392 @@ func (d *Data) handleSparsevolsCoarse(ctx *datastore.VersionedCtx, w http.Respon
 	timedLog.Infof("HTTP %s: sparsevols-coarse on label %s to %s (%s)", r.Method, parts[4], parts[5], r.URL)
 }
 
-func (d *Data) handleMaxlabel(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
-	// GET <api URL>/node/<UUID>/<data name>/maxlabel
-	// POST <api URL>/node/<UUID>/<data name>/maxlabel/<max label>
-	timedLog := dvid.NewTimeLog()
-	switch strings.ToLower(r.Method) {
-	case "get":
+func (d *Data) handleNeighbors(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/neighbors/<label>
+	if len(parts) < 5 {
+		server.BadRequest(w, r, "DVID requires label ID to follow 'neighbors' command")
+		return
+	}
+	if strings.ToLower(r.Method) != "get" {
+		server.BadRequest(w, r, "DVID does not support %s on /neighbors endpoint", r.Method)
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	label, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if label == 0 {
+		server.BadRequest(w, r, "Label 0 is protected background value and cannot have neighbors.\n")
+		return
+	}
+	isSupervoxel := r.URL.Query().Get("supervoxels") == "true"
+	neighbors, err := d.GetNeighbors(ctx, label, isSupervoxel)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if neighbors == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	jsonBytes, err := json.Marshal(neighbors)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+	timedLog.Infof("HTTP %s: neighbors of label %d, %d found (%s)", r.Method, label, len(neighbors), r.URL)
+}
+
+func (d *Data) handleComponents(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET  <api URL>/node/<UUID>/<data name>/components/<label>
+	// POST <api URL>/node/<UUID>/<data name>/components/<label>?relabel-disconnected=true
+	if len(parts) < 5 {
+		server.BadRequest(w, r, "DVID requires label ID to follow 'components' command")
+		return
+	}
+	queryStrings := r.URL.Query()
+	relabel := queryStrings.Get("relabel-disconnected") == "true"
+	switch strings.ToLower(r.Method) {
+	case "get":
+		if relabel {
+			server.BadRequest(w, r, "relabeling disconnected components requires POST")
+			return
+		}
+	case "post":
+		if !relabel {
+			server.BadRequest(w, r, "POST on /components requires relabel-disconnected=true")
+			return
+		}
+	default:
+		server.BadRequest(w, r, "DVID does not support %s on /components endpoint", r.Method)
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	label, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if label == 0 {
+		server.BadRequest(w, r, "Label 0 is protected background value and cannot be used for components.\n")
+		return
+	}
+	connectivity := 6
+	if connStr := queryStrings.Get("connectivity"); connStr != "" {
+		if connectivity, err = strconv.Atoi(connStr); err != nil {
+			server.BadRequest(w, r, "bad connectivity %q: %v", connStr, err)
+			return
+		}
+	}
+	isSupervoxel := queryStrings.Get("supervoxels") == "true"
+	var comps *LabelComponents
+	if relabel {
+		if isSupervoxel {
+			server.BadRequest(w, r, "cannot relabel disconnected components of supervoxels")
+			return
+		}
+		comps, err = d.RelabelDisconnected(ctx.VersionID(), label, connectivity, dvid.GetModInfo(r))
+	} else {
+		comps, err = d.GetComponents(ctx.VersionID(), label, isSupervoxel, connectivity)
+	}
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if comps == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	jsonBytes, err := json.Marshal(comps)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+
+	timedLog.Infof("HTTP %s components (%d) of label %d (%s)", r.Method, len(comps.Components), label, r.URL)
+}
+
+func (d *Data) handleContact(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/contact/<label1>/<label2>
+	if len(parts) < 6 {
+		server.BadRequest(w, r, "DVID requires two label IDs to follow 'contact' command")
+		return
+	}
+	if strings.ToLower(r.Method) != "get" {
+		server.BadRequest(w, r, "DVID does not support %s on /contact endpoint", r.Method)
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	label1, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	label2, err := strconv.ParseUint(parts[5], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if label1 == 0 || label2 == 0 {
+		server.BadRequest(w, r, "Label 0 is protected background value and cannot be used for contact.\n")
+		return
+	}
+	if label1 == label2 {
+		server.BadRequest(w, r, "contact requires two different labels, got %d twice", label1)
+		return
+	}
+	queryStrings := r.URL.Query()
+	isSupervoxel := queryStrings.Get("supervoxels") == "true"
+	format := queryStrings.Get("format")
+	if format != "" && format != "rles" && format != "points" {
+		server.BadRequest(w, r, "unknown contact format %q, must be 'rles' or 'points'", format)
+		return
+	}
+	pts, err := d.GetContact(ctx, label1, label2, isSupervoxel)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if pts == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if format == "points" {
+		jsonBytes, err := json.Marshal(pts)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-type", "application/json")
+		fmt.Fprint(w, string(jsonBytes))
+	} else {
+		data, err := encodeContactRLEs(pts)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-type", "application/octet-stream")
+		if _, err := w.Write(data); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+	}
+	timedLog.Infof("HTTP %s: contact between labels %d and %d, %d voxels (%s)", r.Method, label1, label2, len(pts), r.URL)
+}
+
+func (d *Data) handleStats(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/stats/<label>
+	// POST <api URL>/node/<UUID>/<data name>/stats
+	timedLog := dvid.NewTimeLog()
+
+	queryStrings := r.URL.Query()
+	isSupervoxel := queryStrings.Get("supervoxels") == "true"
+	scale, err := getScale(queryStrings)
+	if err != nil {
+		server.BadRequest(w, r, "bad scale specified: %v", err)
+		return
+	}
+	if scale > d.MaxDownresLevel {
+		server.BadRequest(w, r, "scale %d exceeds MaxDownresLevel %d of data %q", scale, d.MaxDownresLevel, d.DataName())
+		return
+	}
+
+	switch strings.ToLower(r.Method) {
+	case "get":
+		if len(parts) < 5 {
+			server.BadRequest(w, r, "DVID requires label ID to follow GET on 'stats' endpoint")
+			return
+		}
+		label, err := strconv.ParseUint(parts[4], 10, 64)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		if label == 0 {
+			server.BadRequest(w, r, "Label 0 is protected background value and has no stats.\n")
+			return
+		}
+		stats, err := d.GetLabelStats(ctx, label, scale, isSupervoxel)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		if stats == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		jsonBytes, err := json.Marshal(stats)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-type", "application/json")
+		fmt.Fprint(w, string(jsonBytes))
+		timedLog.Infof("HTTP GET stats for label %d, scale %d (%s)", label, scale, r.URL)
+
+	case "post":
+		var labelList []uint64
+		if err := json.NewDecoder(r.Body).Decode(&labelList); err != nil {
+			server.BadRequest(w, r, "Bad POST request body for batch stats query: %v", err)
+			return
+		}
+		statsList := make([]*LabelStats, len(labelList))
+		for i, label := range labelList {
+			if label == 0 {
+				continue
+			}
+			if statsList[i], err = d.GetLabelStats(ctx, label, scale, isSupervoxel); err != nil {
+				server.BadRequest(w, r, "unable to get stats for label %d: %v", label, err)
+				return
+			}
+		}
+		jsonBytes, err := json.Marshal(statsList)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-type", "application/json")
+		fmt.Fprint(w, string(jsonBytes))
+		timedLog.Infof("HTTP POST batch stats query of %d labels, scale %d (%s)", len(labelList), scale, r.URL)
+
+	default:
+		server.BadRequest(w, r, "DVID does not support %s on /stats endpoint", r.Method)
+	}
+}
+
+func (d *Data) handleCheckout(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/checkout/<label>
+	// POST <api URL>/node/<UUID>/<data name>/checkout/<label>?u=<user>&ttl=<seconds>
+	if len(parts) < 5 {
+		server.BadRequest(w, r, "DVID requires label ID to follow 'checkout' command")
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	label, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if label == 0 {
+		server.BadRequest(w, r, "Label 0 is protected background value and cannot be checked out.\n")
+		return
+	}
+	var co *LabelCheckout
+	switch strings.ToLower(r.Method) {
+	case "get":
+		if co, err = d.GetCheckout(ctx.VersionID(), label); err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		if co == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	case "post":
+		ttl := DefaultCheckoutTTL
+		if ttlStr := r.URL.Query().Get("ttl"); ttlStr != "" {
+			secs, err := strconv.ParseUint(ttlStr, 10, 32)
+			if err != nil {
+				server.BadRequest(w, r, "bad ttl %q: %v", ttlStr, err)
+				return
+			}
+			ttl = time.Duration(secs) * time.Second
+		}
+		info := dvid.GetModInfo(r)
+		co, err = d.CheckoutLabel(ctx.VersionID(), label, info.User, ttl)
+		if _, conflict := err.(CheckoutError); conflict {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+	default:
+		server.BadRequest(w, r, "DVID does not support %s on /checkout endpoint", r.Method)
+		return
+	}
+	jsonBytes, err := json.Marshal(co)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+	timedLog.Infof("HTTP %s checkout of label %d (%s)", r.Method, label, r.URL)
+}
+
+func (d *Data) handleRelease(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// POST <api URL>/node/<UUID>/<data name>/release/<label>?u=<user>
+	if strings.ToLower(r.Method) != "post" {
+		server.BadRequest(w, r, "Release requests must be POST actions.")
+		return
+	}
+	if len(parts) < 5 {
+		server.BadRequest(w, r, "DVID requires label ID to follow 'release' command")
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	label, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	info := dvid.GetModInfo(r)
+	force := r.URL.Query().Get("force") == "true"
+	err = d.ReleaseLabel(ctx.VersionID(), label, info.User, force)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	timedLog.Infof("HTTP release of label %d by user %q (%s)", label, info.User, r.URL)
+}
+
+func (d *Data) handleCheckouts(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request) {
+	// GET <api URL>/node/<UUID>/<data name>/checkouts
+	if strings.ToLower(r.Method) != "get" {
+		server.BadRequest(w, r, "DVID does not support %s on /checkouts endpoint", r.Method)
+		return
+	}
+	checkouts, err := d.GetCheckouts(ctx.VersionID())
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	jsonBytes, err := json.Marshal(checkouts)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+}
+
+func (d *Data) handleMaxlabel(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// GET <api URL>/node/<UUID>/<data name>/maxlabel
+	// POST <api URL>/node/<UUID>/<data name>/maxlabel/<max label>
+	timedLog := dvid.NewTimeLog()
+	switch strings.ToLower(r.Method) {
+	case "get":
 		w.Header().Set("Content-Type", "application/json")
 		maxlabel, ok := d.MaxLabel[ctx.VersionID()]
 		if !ok {
@@ -4453,6 +6378,10 @@ func (d *Data) handleSplitSupervoxel(ctx *datastore.VersionedCtx, w http.Respons
 	}
 	info := dvid.GetModInfo(r)
 	splitSupervoxel, remainSupervoxel, mutID, err := d.SplitSupervoxel(ctx.VersionID(), supervoxel, split, remain, r.Body, info, downscale)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		server.BadRequest(w, r, fmt.Sprintf("split supervoxel %d -> %d, %d: %v", supervoxel, splitSupervoxel, remainSupervoxel, err))
 		return
@@ -4485,7 +6414,32 @@ func (d *Data) handleCleave(ctx *datastore.VersionedCtx, w http.ResponseWriter,
 		return
 	}
 	modInfo := dvid.GetModInfo(r)
+	if level := r.URL.Query().Get("level"); level != "" {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			server.BadRequest(w, r, "Bad POSTed data for cleave.  Should be JSON.")
+			return
+		}
+		var cleaved []uint64
+		if err := json.Unmarshal(data, &cleaved); err != nil {
+			server.BadRequest(w, r, fmt.Sprintf("Bad cleave JSON: %v", err))
+			return
+		}
+		cleaveLabel, mutID, err := d.CleaveAtLevel(ctx.VersionID(), level, label, cleaved, modInfo)
+		if err != nil {
+			server.BadRequest(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"CleavedLabel": %d, "MutationID": %d}`, cleaveLabel, mutID)
+		timedLog.Infof("HTTP cleave of group %d at level %q request (%s)", label, level, r.URL)
+		return
+	}
 	cleaveLabel, mutID, err := d.CleaveLabel(ctx.VersionID(), label, modInfo, r.Body)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		server.BadRequest(w, r, err)
 		return
@@ -4496,8 +6450,63 @@ func (d *Data) handleCleave(ctx *datastore.VersionedCtx, w http.ResponseWriter,
 	timedLog.Infof("HTTP cleave of label %d request (%s)", label, r.URL)
 }
 
+func (d *Data) handleCleaveBySeeds(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
+	// POST <api URL>/node/<UUID>/<data name>/cleave-by-seeds/<label>[?graph=affinities][&apply=true]
+	if strings.ToLower(r.Method) != "post" {
+		server.BadRequest(w, r, "Cleave by seeds requests must be POST actions.")
+		return
+	}
+	if len(parts) < 5 {
+		server.BadRequest(w, r, "ERROR: DVID requires label ID to follow 'cleave-by-seeds' command")
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	label, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	if label == 0 {
+		server.BadRequest(w, r, "Label 0 is protected background value and cannot be used as cleave target\n")
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		server.BadRequest(w, r, "Bad POSTed data for cleave by seeds.  Should be JSON.")
+		return
+	}
+	var seeds [][]uint64
+	if err := json.Unmarshal(data, &seeds); err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("Bad seeds JSON: %v", err))
+		return
+	}
+	queryStrings := r.URL.Query()
+	useAffinities := queryStrings.Get("graph") == "affinities"
+	apply := queryStrings.Get("apply") == "true"
+	info := dvid.GetModInfo(r)
+	result, err := d.CleaveBySeeds(ctx.VersionID(), label, seeds, useAffinities, apply, info)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("cleave by seeds of label %d: %v", label, err))
+		return
+	}
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+
+	timedLog.Infof("HTTP cleave by %d seed groups of label %d request (%s)", len(seeds), label, r.URL)
+}
+
 func (d *Data) handleSplit(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request, parts []string) {
-	// POST <api URL>/node/<UUID>/<data name>/split/<label>[?splitlabel=X]
+	// POST <api URL>/node/<UUID>/<data name>/split/<label>[?level=name]
 	if strings.ToLower(r.Method) != "post" {
 		server.BadRequest(w, r, "Split requests must be POST actions.")
 		return
@@ -4518,7 +6527,22 @@ func (d *Data) handleSplit(ctx *datastore.VersionedCtx, w http.ResponseWriter, r
 		return
 	}
 	info := dvid.GetModInfo(r)
+	if level := r.URL.Query().Get("level"); level != "" {
+		splitGroup, mutID, err := d.SplitAtLevel(ctx.VersionID(), level, fromLabel, r.Body, info)
+		if err != nil {
+			server.BadRequest(w, r, fmt.Sprintf("split group %d at level %q: %v", fromLabel, level, err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"label": %d, "MutationID": %d}`, splitGroup, mutID)
+		timedLog.Infof("HTTP split of group %d at level %q request (%s)", fromLabel, level, r.URL)
+		return
+	}
 	toLabel, mutID, err := d.SplitLabels(ctx.VersionID(), fromLabel, r.Body, info)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		server.BadRequest(w, r, fmt.Sprintf("split label %d: %v", fromLabel, err))
 		return
@@ -4553,7 +6577,22 @@ func (d *Data) handleMerge(ctx *datastore.VersionedCtx, w http.ResponseWriter, r
 		return
 	}
 	info := dvid.GetModInfo(r)
+	if level := r.URL.Query().Get("level"); level != "" {
+		mutID, err := d.MergeAtLevel(ctx.VersionID(), level, mergeOp, info)
+		if err != nil {
+			server.BadRequest(w, r, fmt.Sprintf("Error on merge at level %q: %v", level, err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"MutationID": %d}`, mutID)
+		timedLog.Infof("HTTP merge at level %q request (%s)", level, r.URL)
+		return
+	}
 	mutID, err := d.MergeLabels(ctx.VersionID(), mergeOp, info)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		server.BadRequest(w, r, fmt.Sprintf("Error on merge: %v", err))
 		return
@@ -4564,6 +6603,81 @@ func (d *Data) handleMerge(ctx *datastore.VersionedCtx, w http.ResponseWriter, r
 	timedLog.Infof("HTTP merge request (%s)", r.URL)
 }
 
+func (d *Data) handleMutations(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request) {
+	// POST <api URL>/node/<UUID>/<data name>/mutations
+	if strings.ToLower(r.Method) != "post" {
+		server.BadRequest(w, r, "Batch mutation requests must be POST actions.")
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		server.BadRequest(w, r, "Bad POSTed data for mutations.  Should be JSON.")
+		return
+	}
+	var ops []MutationOp
+	if err := json.Unmarshal(data, &ops); err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("Bad mutations JSON: %v", err))
+		return
+	}
+	downscale := r.URL.Query().Get("downres") != "false"
+	info := dvid.GetModInfo(r)
+	results, err := d.ApplyMutations(ctx.VersionID(), ops, info, downscale)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("Error on mutations: %v", err))
+		return
+	}
+	jsonBytes, err := json.Marshal(results)
+	if err != nil {
+		server.BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(jsonBytes))
+
+	timedLog.Infof("HTTP POST batch of %d mutations (%s)", len(ops), r.URL)
+}
+
+func (d *Data) handlePaint(ctx *datastore.VersionedCtx, w http.ResponseWriter, r *http.Request) {
+	// POST <api URL>/node/<UUID>/<data name>/paint
+	if strings.ToLower(r.Method) != "post" {
+		server.BadRequest(w, r, "Paint requests must be POST actions.")
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		server.BadRequest(w, r, "Bad POSTed data for paint.  Should be JSON.")
+		return
+	}
+	var stroke PaintStroke
+	if err := json.Unmarshal(data, &stroke); err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("Bad paint JSON: %v", err))
+		return
+	}
+	downscale := r.URL.Query().Get("downres") != "false"
+	info := dvid.GetModInfo(r)
+	result, err := d.Paint(ctx.VersionID(), &stroke, info, downscale)
+	if _, conflict := err.(CheckoutError); conflict {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		server.BadRequest(w, r, fmt.Sprintf("Error on paint: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"MutationID": %d, "Voxels": %d}`, result.MutationID, result.Voxels)
+
+	timedLog.Infof("HTTP paint of %d voxels with label %d (%s)", result.Voxels, stroke.Label, r.URL)
+}
+
 // --------- Other functions on labelmap Data -----------------
 
 // GetLabelBlock returns a compressed label Block of the given block coordinate.
@@ -1039,6 +1039,67 @@ func TestBadCalls(t *testing.T) {
 	server.TestBadHTTP(t, "POST", reqStr, nil)
 }
 
+func TestOpenAPIPaths(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	reqStr := fmt.Sprintf("%sopenapi.json?type=labelmap", server.WebAPIPath)
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &doc); err != nil {
+		t.Fatalf("can't parse OpenAPI document: %v\n", err)
+	}
+	expected := []struct{ method, path string }{
+		{"get", "/label/{coord}"},
+		{"get", "/sparsevol/{label}"},
+		{"head", "/sparsevol/{label}"},
+		{"post", "/merge"},
+		{"post", "/cleave/{label}"},
+		{"post", "/split/{label}"},
+		{"post", "/split-supervoxel/{supervoxel}"},
+		{"get", "/neighbors/{label}"},
+		{"get", "/contact/{label1}/{label2}"},
+		{"get", "/components/{label}"},
+		{"post", "/components/{label}"},
+		{"get", "/stats/{label}"},
+		{"post", "/stats"},
+		{"post", "/checkout/{label}"},
+		{"post", "/release/{label}"},
+		{"get", "/checkouts"},
+		{"post", "/mutations"},
+		{"post", "/cleave-by-seeds/{label}"},
+		{"post", "/paint"},
+		{"post", "/check-indices"},
+		{"get", "/check-indices/{job}"},
+		{"post", "/mappings"},
+		{"get", "/affinities/{supervoxel}"},
+		{"post", "/affinities"},
+		{"post", "/agglomerate"},
+		{"get", "/levels"},
+		{"post", "/levels/{level}"},
+		{"get", "/levels/{level}/{group}"},
+	}
+	for _, e := range expected {
+		if _, found := doc.Paths["/node/{uuid}/{dataname}"+e.path][e.method]; !found {
+			t.Errorf("expected %s %s in labelmap OpenAPI document\n", strings.ToUpper(e.method), e.path)
+		}
+	}
+	ids := make(map[string]string)
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			if prev, found := ids[op.OperationID]; found {
+				t.Errorf("operation id %q used for both %s and %s %s\n", op.OperationID, prev, method, path)
+			}
+			ids[op.OperationID] = method + " " + path
+		}
+	}
+}
+
 func TestMultiscaleIngest(t *testing.T) {
 	if err := server.OpenTest(); err != nil {
 		t.Fatalf("can't open test server: %v\n", err)
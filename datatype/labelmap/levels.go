@@ -0,0 +1,793 @@
+/*
+	This file supports named agglomeration levels above the body labels, where each level
+	groups the labels of the level below it, e.g., supervoxel -> body -> neuron -> cell type.
+	Level 0 is the body labels given by the supervoxel mapping.  A label of a lower level
+	without a stored mapping belongs to the group with the same label at the next level.
+*/
+
+package labelmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+// serializes changes to level mappings.
+var levelMu sync.Mutex
+
+// GetLevels returns the names of the agglomeration levels from finest to coarsest.
+func (d *Data) GetLevels() []string {
+	d.levelsMu.RLock()
+	defer d.levelsMu.RUnlock()
+	return append([]string{}, d.Levels...)
+}
+
+// AddLevel adds a named agglomeration level that groups the labels of the current
+// coarsest level.  A new level starts with each group holding the same label below it.
+func (d *Data) AddLevel(v dvid.VersionID, name string) error {
+	if name == "" || strings.ContainsAny(name, "/?&") {
+		return fmt.Errorf("bad level name %q", name)
+	}
+	if err := d.appendLevel(name); err != nil {
+		return err
+	}
+	return datastore.SaveDataByVersion(v, d)
+}
+
+// appends a level name under lock, which is released before the data is saved since
+// encoding the data reads the levels.
+func (d *Data) appendLevel(name string) error {
+	d.levelsMu.Lock()
+	defer d.levelsMu.Unlock()
+	for _, level := range d.Levels {
+		if level == name {
+			return fmt.Errorf("level %q already exists in data %q", name, d.DataName())
+		}
+	}
+	if len(d.Levels) >= 255 {
+		return fmt.Errorf("data %q already has the maximum of 255 levels", d.DataName())
+	}
+	d.Levels = append(d.Levels, name)
+	return nil
+}
+
+// returns the index of a named level, where 0 is the body level given by an empty name.
+func (d *Data) getLevel(name string) (uint8, error) {
+	if name == "" {
+		return 0, nil
+	}
+	d.levelsMu.RLock()
+	defer d.levelsMu.RUnlock()
+	for i, level := range d.Levels {
+		if level == name {
+			return uint8(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("no level %q in data %q", name, d.DataName())
+}
+
+// returns the group of a label at the given level and whether a mapping was stored.
+func getLevelMapping(ctx *datastore.VersionedCtx, level uint8, label uint64) (uint64, bool, error) {
+	store, err := datastore.GetKeyValueDB(ctx.Data())
+	if err != nil {
+		return 0, false, err
+	}
+	data, err := store.Get(ctx, NewLevelMappingTKey(level, label))
+	if err != nil {
+		return 0, false, err
+	}
+	if len(data) == 0 {
+		return label, false, nil
+	}
+	if len(data) != 8 {
+		return 0, false, fmt.Errorf("bad level %d mapping for label %d in data %q: %d bytes", level, label, ctx.Data().DataName(), len(data))
+	}
+	return binary.LittleEndian.Uint64(data), true, nil
+}
+
+// returns the labels explicitly mapped to a group at the given level.
+func getLevelExplicitMembers(ctx *datastore.VersionedCtx, level uint8, group uint64) (labels.Set, error) {
+	store, err := datastore.GetKeyValueDB(ctx.Data())
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(ctx, NewLevelMembersTKey(level, group))
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("bad level %d members for group %d in data %q: %d bytes", level, group, ctx.Data().DataName(), len(data))
+	}
+	members := make(labels.Set, len(data)/8)
+	for i := 0; i < len(data); i += 8 {
+		members[binary.LittleEndian.Uint64(data[i:i+8])] = struct{}{}
+	}
+	return members, nil
+}
+
+// returns the labels of the lower level within a group at the given level, which includes
+// the label of the group itself unless it was mapped to another group.
+func getLevelMembers(ctx *datastore.VersionedCtx, level uint8, group uint64) (labels.Set, error) {
+	members, err := getLevelExplicitMembers(ctx, level, group)
+	if err != nil {
+		return nil, err
+	}
+	_, mapped, err := getLevelMapping(ctx, level, group)
+	if err != nil {
+		return nil, err
+	}
+	if !mapped {
+		members[group] = struct{}{}
+	}
+	return members, nil
+}
+
+// GetLevelMembers returns the sorted labels of the level below the named level that are
+// within a group.
+func (d *Data) GetLevelMembers(v dvid.VersionID, name string, group uint64) ([]uint64, error) {
+	level, err := d.getLevel(name)
+	if err != nil {
+		return nil, err
+	}
+	if level == 0 {
+		return nil, fmt.Errorf("members require a level above the body labels")
+	}
+	members, err := getLevelMembers(datastore.NewVersionedCtx(d, v), level, group)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]uint64, 0, len(members))
+	for label := range members {
+		sorted = append(sorted, label)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted, nil
+}
+
+// stores the group of a label at a level, removing the mapping if the label is its own group.
+func putLevelMapping(ctx *datastore.VersionedCtx, store storage.KeyValueDB, level uint8, label, group uint64) error {
+	tk := NewLevelMappingTKey(level, label)
+	if label == group {
+		return store.Delete(ctx, tk)
+	}
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, group)
+	return store.Put(ctx, tk, buf)
+}
+
+// stores the labels explicitly mapped to a group at a level, ignoring the group's own label.
+func putLevelMembers(ctx *datastore.VersionedCtx, store storage.KeyValueDB, level uint8, group uint64, members labels.Set) error {
+	sorted := make([]uint64, 0, len(members))
+	for label := range members {
+		if label != group {
+			sorted = append(sorted, label)
+		}
+	}
+	tk := NewLevelMembersTKey(level, group)
+	if len(sorted) == 0 {
+		return store.Delete(ctx, tk)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	buf := new(bytes.Buffer)
+	for _, label := range sorted {
+		binary.Write(buf, binary.LittleEndian, label)
+	}
+	return store.Put(ctx, tk, buf.Bytes())
+}
+
+// returns an error if a label is mapped to another group at the given level, so it is a
+// member rather than a group of its own.
+func checkLevelGroup(ctx *datastore.VersionedCtx, level uint8, name string, group uint64) error {
+	mapped, found, err := getLevelMapping(ctx, level, group)
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("label %d is within group %d at level %q and is not a group", group, mapped, name)
+	}
+	return nil
+}
+
+// moves a label into a group at a level, updating the explicit members of both its old
+// and new group.  A label moved into its own group is unmapped.
+func setLevelGroup(ctx *datastore.VersionedCtx, store storage.KeyValueDB, level uint8, label, group uint64) error {
+	old, mapped, err := getLevelMapping(ctx, level, label)
+	if err != nil {
+		return err
+	}
+	if old == group {
+		return nil
+	}
+	if mapped {
+		members, err := getLevelExplicitMembers(ctx, level, old)
+		if err != nil {
+			return err
+		}
+		delete(members, label)
+		if err := putLevelMembers(ctx, store, level, old, members); err != nil {
+			return err
+		}
+	}
+	if group != label {
+		members, err := getLevelExplicitMembers(ctx, level, group)
+		if err != nil {
+			return err
+		}
+		members[label] = struct{}{}
+		if err := putLevelMembers(ctx, store, level, group, members); err != nil {
+			return err
+		}
+	}
+	return putLevelMapping(ctx, store, level, label, group)
+}
+
+// copyLevelGroup puts a new body created from another body into the first level group of
+// that body, so the new body stays within the same groups at every level.
+func (d *Data) copyLevelGroup(v dvid.VersionID, from, to uint64) {
+	if len(d.GetLevels()) == 0 {
+		return
+	}
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		dvid.Errorf("unable to get store for levels of data %q: %v\n", d.DataName(), err)
+		return
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	group, _, err := getLevelMapping(ctx, 1, from)
+	if err != nil {
+		dvid.Errorf("unable to get level group of label %d in data %q: %v\n", from, d.DataName(), err)
+		return
+	}
+	if err := setLevelGroup(ctx, store, 1, to, group); err != nil {
+		dvid.Errorf("unable to put label %d into level group %d in data %q: %v\n", to, group, d.DataName(), err)
+	}
+}
+
+// mergeLevels removes merged bodies, which no longer have voxels, from their first level
+// groups.  Their voxels are within the target body and so within its groups.
+func (d *Data) mergeLevels(v dvid.VersionID, op labels.MergeOp) {
+	if len(d.GetLevels()) == 0 {
+		return
+	}
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		dvid.Errorf("unable to get store for levels of data %q: %v\n", d.DataName(), err)
+		return
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	for merged := range op.Merged {
+		if err := setLevelGroup(ctx, store, 1, merged, merged); err != nil {
+			dvid.Errorf("unable to remove merged label %d from its level group in data %q: %v\n", merged, d.DataName(), err)
+		}
+	}
+}
+
+// returns the body labels within a group at the given level.
+func getLevelBodies(ctx *datastore.VersionedCtx, level uint8, group uint64) (labels.Set, error) {
+	bodies := labels.Set{group: struct{}{}}
+	for ; level > 0; level-- {
+		lower := make(labels.Set)
+		for label := range bodies {
+			members, err := getLevelMembers(ctx, level, label)
+			if err != nil {
+				return nil, err
+			}
+			for member := range members {
+				lower[member] = struct{}{}
+			}
+		}
+		bodies = lower
+	}
+	return bodies, nil
+}
+
+// getLevelIndex returns a label index for a group at the given level that combines the
+// indices of all bodies within the group.  A nil index is returned if no bodies are found.
+func (d *Data) getLevelIndex(ctx *datastore.VersionedCtx, level uint8, group uint64) (*labels.Index, error) {
+	bodies, err := getLevelBodies(ctx, level, group)
+	if err != nil {
+		return nil, err
+	}
+	idx := new(labels.Index)
+	idx.Label = group
+	idx.Blocks = make(map[uint64]*proto.SVCount)
+	for body := range bodies {
+		bodyIdx, err := d.getTracedLabelIndex(ctx, body, false)
+		if err != nil {
+			return nil, err
+		}
+		if bodyIdx == nil {
+			continue
+		}
+		for zyx, svc := range bodyIdx.Blocks {
+			if svc == nil {
+				continue
+			}
+			combined, found := idx.Blocks[zyx]
+			if !found {
+				combined = new(proto.SVCount)
+				combined.Counts = make(map[uint64]uint32, len(svc.Counts))
+				idx.Blocks[zyx] = combined
+			}
+			for sv, count := range svc.Counts {
+				combined.Counts[sv] = count
+			}
+		}
+	}
+	if len(idx.Blocks) == 0 {
+		return nil, nil
+	}
+	return idx, nil
+}
+
+// levelMapper maps body labels to their groups at a level, caching mappings for the
+// duration of a request.
+type levelMapper struct {
+	ctx   *datastore.VersionedCtx
+	level uint8
+
+	mu    sync.Mutex
+	cache map[uint64]uint64
+}
+
+// getLevelMapper returns a mapper for any level given by the "level" query string, or nil
+// if labels should not be mapped beyond bodies.
+func (d *Data) getLevelMapper(ctx *datastore.VersionedCtx, queryStrings url.Values) (*levelMapper, error) {
+	name := queryStrings.Get("level")
+	if name == "" {
+		return nil, nil
+	}
+	if queryStrings.Get("supervoxels") == "true" {
+		return nil, fmt.Errorf("level %q can't be used with supervoxels", name)
+	}
+	level, err := d.getLevel(name)
+	if err != nil {
+		return nil, err
+	}
+	return &levelMapper{ctx: ctx, level: level, cache: make(map[uint64]uint64)}, nil
+}
+
+func (m *levelMapper) mapLabel(label uint64) (uint64, error) {
+	if label == 0 {
+		return 0, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mapped, found := m.cache[label]; found {
+		return mapped, nil
+	}
+	mapped := label
+	for level := uint8(1); level <= m.level; level++ {
+		group, _, err := getLevelMapping(m.ctx, level, mapped)
+		if err != nil {
+			return 0, err
+		}
+		mapped = group
+	}
+	m.cache[label] = mapped
+	return mapped, nil
+}
+
+// mapLabels maps a slice of body labels in place.
+func (m *levelMapper) mapLabels(lbls []uint64) error {
+	for i, label := range lbls {
+		mapped, err := m.mapLabel(label)
+		if err != nil {
+			return err
+		}
+		lbls[i] = mapped
+	}
+	return nil
+}
+
+// mapVolume maps a little-endian uint64 volume of body labels in place.
+func (m *levelMapper) mapVolume(data []byte) error {
+	if len(data)%8 != 0 {
+		return fmt.Errorf("label volume of %d bytes is not a multiple of 8 bytes", len(data))
+	}
+	for i := 0; i < len(data); i += 8 {
+		mapped, err := m.mapLabel(binary.LittleEndian.Uint64(data[i : i+8]))
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint64(data[i:i+8], mapped)
+	}
+	return nil
+}
+
+// mapBlock maps the body labels of a block in place.
+func (m *levelMapper) mapBlock(block *labels.Block) error {
+	return m.mapLabels(block.Labels)
+}
+
+// getLevelImage returns a 2d image of labels mapped to a level.
+func (d *Data) getLevelImage(v dvid.VersionID, vox *Labels, scale uint8, roiname dvid.InstanceName, m *levelMapper) (*dvid.Image, error) {
+	data, err := d.GetVolume(v, vox, false, scale, roiname)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.mapVolume(data); err != nil {
+		return nil, err
+	}
+	return vox.GetImage2d()
+}
+
+// MergeAtLevel merges groups at the named level into a target group and returns the
+// mutation ID.  The target and merged labels must be groups rather than members of other
+// groups at the level.  Body labels and supervoxels are not changed.
+func (d *Data) MergeAtLevel(v dvid.VersionID, name string, op labels.MergeOp, info dvid.ModInfo) (uint64, error) {
+	level, err := d.getLevel(name)
+	if err != nil {
+		return 0, err
+	}
+	if level == 0 {
+		return 0, fmt.Errorf("merges at a level require a level above the body labels")
+	}
+	if _, found := op.Merged[op.Target]; found {
+		return 0, fmt.Errorf("can't merge group %d into itself", op.Target)
+	}
+
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	d.StartUpdate()
+	defer d.StopUpdate()
+
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		return 0, err
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	if err := checkLevelGroup(ctx, level, name, op.Target); err != nil {
+		return 0, err
+	}
+	targetMembers, err := getLevelExplicitMembers(ctx, level, op.Target)
+	if err != nil {
+		return 0, err
+	}
+	var lbls, moved []uint64
+	for group := range op.Merged {
+		if err := checkLevelGroup(ctx, level, name, group); err != nil {
+			return 0, err
+		}
+		members, err := getLevelMembers(ctx, level, group)
+		if err != nil {
+			return 0, err
+		}
+		if len(members) == 0 {
+			return 0, fmt.Errorf("group %d has no members at level %q", group, name)
+		}
+		for label := range members {
+			moved = append(moved, label)
+		}
+		lbls = append(lbls, group)
+	}
+	for _, label := range moved {
+		if err := putLevelMapping(ctx, store, level, label, op.Target); err != nil {
+			return 0, err
+		}
+		targetMembers[label] = struct{}{}
+	}
+	for _, group := range lbls {
+		if err := store.Delete(ctx, NewLevelMembersTKey(level, group)); err != nil {
+			return 0, err
+		}
+	}
+	if err := putLevelMembers(ctx, store, level, op.Target, targetMembers); err != nil {
+		return 0, err
+	}
+	mutID := d.NewMutationID()
+
+	versionuuid, _ := datastore.UUIDFromVersion(v)
+	msginfo := map[string]interface{}{
+		"Action":     "merge-level",
+		"Level":      name,
+		"Target":     op.Target,
+		"Labels":     lbls,
+		"Members":    moved,
+		"MutationID": mutID,
+		"UUID":       string(versionuuid),
+		"User":       info.User,
+		"Timestamp":  time.Now().String(),
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	if err := d.ProduceKafkaMsg(jsonmsg); err != nil {
+		dvid.Errorf("error on sending level merge op to kafka: %v", err)
+	}
+	dvid.Infof("Merged %d groups into group %d at level %q of data %q\n", len(op.Merged), op.Target, name, d.DataName())
+	return mutID, nil
+}
+
+// CleaveAtLevel moves the given members of a group at the named level into a new group
+// and returns the new group label and the mutation ID.  Body labels and supervoxels are
+// not changed.  The group's own label can't be cleaved since it identifies the group.
+func (d *Data) CleaveAtLevel(v dvid.VersionID, name string, group uint64, cleaved []uint64, info dvid.ModInfo) (cleavedGroup, mutID uint64, err error) {
+	level, err := d.getLevel(name)
+	if err != nil {
+		return
+	}
+	if level == 0 {
+		err = fmt.Errorf("cleaves at a level require a level above the body labels")
+		return
+	}
+	if len(cleaved) == 0 {
+		err = fmt.Errorf("no labels given for cleave of group %d at level %q", group, name)
+		return
+	}
+
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	d.StartUpdate()
+	defer d.StopUpdate()
+
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		return
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	members, err := getLevelMembers(ctx, level, group)
+	if err != nil {
+		return
+	}
+	cleavedMembers := make(labels.Set, len(cleaved))
+	for _, label := range cleaved {
+		if label == group {
+			err = fmt.Errorf("cannot cleave label %d from the group it labels at level %q", label, name)
+			return
+		}
+		if _, found := members[label]; !found {
+			err = fmt.Errorf("label %d is not within group %d at level %q", label, group, name)
+			return
+		}
+		cleavedMembers[label] = struct{}{}
+		delete(members, label)
+	}
+	if cleavedGroup, err = d.newLabel(v); err != nil {
+		return
+	}
+	for label := range cleavedMembers {
+		if err = putLevelMapping(ctx, store, level, label, cleavedGroup); err != nil {
+			return
+		}
+	}
+	if err = putLevelMembers(ctx, store, level, group, members); err != nil {
+		return
+	}
+	if err = putLevelMembers(ctx, store, level, cleavedGroup, cleavedMembers); err != nil {
+		return
+	}
+	mutID = d.NewMutationID()
+
+	versionuuid, _ := datastore.UUIDFromVersion(v)
+	msginfo := map[string]interface{}{
+		"Action":       "cleave-level",
+		"Level":        name,
+		"OrigLabel":    group,
+		"CleavedLabel": cleavedGroup,
+		"CleavedItems": cleaved,
+		"MutationID":   mutID,
+		"UUID":         string(versionuuid),
+		"User":         info.User,
+		"Timestamp":    time.Now().String(),
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	if err := d.ProduceKafkaMsg(jsonmsg); err != nil {
+		dvid.Errorf("error on sending level cleave op to kafka: %v", err)
+	}
+	dvid.Infof("Cleaved %d labels from group %d into group %d at level %q of data %q\n", len(cleaved), group, cleavedGroup, name, d.DataName())
+	return
+}
+
+// returns the split RLEs within each body, giving an error if any split voxel is not within
+// the given bodies.
+func (d *Data) splitRLEsByBody(ctx *datastore.VersionedCtx, split dvid.RLEs, bodies labels.Set) (map[uint64]dvid.RLEs, error) {
+	blockSize, ok := d.BlockSize().(dvid.Point3d)
+	if !ok {
+		return nil, fmt.Errorf("can't do split because block size for instance %s is not 3d: %v", d.DataName(), d.BlockSize())
+	}
+	splitmap, err := split.Partition(blockSize)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := getMapping(d, ctx.VersionID())
+	if err != nil {
+		return nil, err
+	}
+	mapped := make(map[uint64]uint64)
+	bodyRLEs := make(map[uint64]dvid.RLEs)
+	for _, izyx := range splitmap.SortedKeys() {
+		pb, err := d.getLabelBlock(ctx, 0, izyx)
+		if err != nil {
+			return nil, err
+		}
+		if pb == nil {
+			return nil, fmt.Errorf("split on block %s attempted but block doesn't exist", izyx)
+		}
+		offset, err := pb.OffsetDVID()
+		if err != nil {
+			return nil, err
+		}
+		lblarrayBytes, _ := pb.MakeLabelVolume()
+		lblarray, err := dvid.AliasByteToUint64(lblarrayBytes)
+		if err != nil {
+			return nil, err
+		}
+		for _, rle := range splitmap[izyx] {
+			pt := rle.StartPt()
+			i := (pt[2]-offset[2])*pb.Size[1]*pb.Size[0] + (pt[1]-offset[1])*pb.Size[0] + pt[0] - offset[0]
+			var runBody uint64
+			var runStart int32
+			for x := int32(0); x < rle.Length(); x++ {
+				sv := lblarray[i+x]
+				body, found := mapped[sv]
+				if !found {
+					body, _ = mapping.MappedLabel(ctx.VersionID(), sv)
+					mapped[sv] = body
+				}
+				if _, found := bodies[body]; !found || sv == 0 {
+					return nil, fmt.Errorf("split voxel at (%d,%d,%d) has label %d outside the split group", pt[0]+x, pt[1], pt[2], body)
+				}
+				if x > 0 && body != runBody {
+					bodyRLEs[runBody] = append(bodyRLEs[runBody], dvid.NewRLE(dvid.Point3d{pt[0] + runStart, pt[1], pt[2]}, x-runStart))
+					runStart = x
+				}
+				runBody = body
+			}
+			bodyRLEs[runBody] = append(bodyRLEs[runBody], dvid.NewRLE(dvid.Point3d{pt[0] + runStart, pt[1], pt[2]}, rle.Length()-runStart))
+		}
+		runtime.KeepAlive(&lblarrayBytes)
+	}
+	return bodyRLEs, nil
+}
+
+// SplitAtLevel splits the voxels in a binary sparse volume from a group at the named level
+// into a new group and returns the new group label and the mutation ID.  Each body of the
+// group with split voxels is split into a new body, except bodies wholly within the split,
+// and these bodies form the new group.  Other label mutations are excluded until the new
+// group is stored.
+func (d *Data) SplitAtLevel(v dvid.VersionID, name string, group uint64, r io.ReadCloser, info dvid.ModInfo) (splitGroup, mutID uint64, err error) {
+	level, err := d.getLevel(name)
+	if err != nil {
+		return
+	}
+	if level == 0 {
+		err = fmt.Errorf("splits at a level require a level above the body labels")
+		return
+	}
+	var split dvid.RLEs
+	if split, err = dvid.ReadRLEs(r); err != nil {
+		return
+	}
+	if splitSize, _ := split.Stats(); splitSize == 0 {
+		err = fmt.Errorf("bad split since split volume was zero voxels")
+		return
+	}
+
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	if err = checkLevelGroup(ctx, level, name, group); err != nil {
+		return
+	}
+	var bodies labels.Set
+	if bodies, err = getLevelBodies(ctx, level, group); err != nil {
+		return
+	}
+	var bodyRLEs map[uint64]dvid.RLEs
+	if bodyRLEs, err = d.splitRLEsByBody(ctx, split, bodies); err != nil {
+		return
+	}
+	var splitBodies, movedBodies []uint64
+	whole := true
+	for body := range bodies {
+		var idx *labels.Index
+		if idx, err = GetLabelIndex(d, v, body, false); err != nil {
+			return
+		}
+		if idx == nil {
+			continue
+		}
+		rles, found := bodyRLEs[body]
+		if !found {
+			whole = false
+			continue
+		}
+		if numVoxels, _ := rles.Stats(); numVoxels < idx.NumVoxels() {
+			splitBodies = append(splitBodies, body)
+			whole = false
+		} else {
+			movedBodies = append(movedBodies, body)
+		}
+	}
+	if whole {
+		err = fmt.Errorf("split volume includes all voxels of group %d at level %q", group, name)
+		return
+	}
+	sort.Slice(splitBodies, func(i, j int) bool { return splitBodies[i] < splitBodies[j] })
+	sort.Slice(movedBodies, func(i, j int) bool { return movedBodies[i] < movedBodies[j] })
+
+	newBodies := make([]uint64, len(splitBodies))
+	for i, body := range splitBodies {
+		var data []byte
+		if data, err = encodeSplitRLEs(bodyRLEs[body]); err == nil {
+			newBodies[i], _, err = d.splitLabels(v, body, ioutil.NopCloser(bytes.NewReader(data)), info)
+		}
+		if err != nil {
+			err = fmt.Errorf("splitting body %d of group %d at level %q after splitting bodies %v into %v: %v",
+				body, group, name, splitBodies[:i], newBodies[:i], err)
+			return
+		}
+	}
+
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	d.StartUpdate()
+	defer d.StopUpdate()
+
+	store, err := datastore.GetKeyValueDB(d)
+	if err != nil {
+		return
+	}
+	if splitGroup, err = d.newLabel(v); err != nil {
+		return
+	}
+	for _, body := range append(newBodies, movedBodies...) {
+		if err = setLevelGroup(ctx, store, 1, body, splitGroup); err != nil {
+			return
+		}
+	}
+	mutID = d.NewMutationID()
+
+	versionuuid, _ := datastore.UUIDFromVersion(v)
+	msginfo := map[string]interface{}{
+		"Action":      "split-level",
+		"Level":       name,
+		"Target":      group,
+		"NewLabel":    splitGroup,
+		"SplitBodies": splitBodies,
+		"NewBodies":   newBodies,
+		"MovedBodies": movedBodies,
+		"MutationID":  mutID,
+		"UUID":        string(versionuuid),
+		"User":        info.User,
+		"Timestamp":   time.Now().String(),
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	if err := d.ProduceKafkaMsg(jsonmsg); err != nil {
+		dvid.Errorf("error on sending level split op to kafka: %v", err)
+	}
+	dvid.Infof("Split %d bodies and moved %d bodies from group %d into group %d at level %q of data %q\n",
+		len(splitBodies), len(movedBodies), group, splitGroup, name, d.DataName())
+	return
+}
@@ -0,0 +1,274 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func getLevelLabel(t *testing.T, uuid dvid.UUID, pt dvid.Point3d, level string) uint64 {
+	reqStr := fmt.Sprintf("%snode/%s/labels/label/%d_%d_%d?level=%s", server.WebAPIPath, uuid, pt[0], pt[1], pt[2], level)
+	var resp struct {
+		Label uint64
+	}
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &resp); err != nil {
+		t.Fatalf("couldn't unmarshal label at %s, level %q: %v\n", pt, level, err)
+	}
+	return resp.Label
+}
+
+func getLevelMembersJSON(t *testing.T, uuid dvid.UUID, level string, group uint64) []uint64 {
+	reqStr := fmt.Sprintf("%snode/%s/labels/levels/%s/%d", server.WebAPIPath, uuid, level, group)
+	var members []uint64
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &members); err != nil {
+		t.Fatalf("couldn't unmarshal members of group %d, level %q: %v\n", group, level, err)
+	}
+	return members
+}
+
+func TestLevels(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, _ := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	for i := int32(0); i < 4; i++ {
+		vol.addSubvol(dvid.Point3d{i * 32, 0, 0}, dvid.Point3d{32, 64, 64}, uint64(i+1))
+	}
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	for _, level := range []string{"neuron", "celltype"} {
+		reqStr := fmt.Sprintf("%snode/%s/labels/levels/%s", server.WebAPIPath, uuid, level)
+		server.TestHTTP(t, "POST", reqStr, nil)
+	}
+	reqStr := fmt.Sprintf("%snode/%s/labels/levels/neuron", server.WebAPIPath, uuid)
+	server.TestBadHTTP(t, "POST", reqStr, nil)
+
+	var levels []string
+	reqStr = fmt.Sprintf("%snode/%s/labels/levels", server.WebAPIPath, uuid)
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, nil), &levels); err != nil {
+		t.Fatalf("couldn't unmarshal levels: %v\n", err)
+	}
+	if !reflect.DeepEqual(levels, []string{"neuron", "celltype"}) {
+		t.Fatalf("expected levels neuron and celltype, got %v\n", levels)
+	}
+
+	// merges at a level don't change bodies.
+	for _, merge := range []string{"[1, 2]", "[3, 4]"} {
+		reqStr = fmt.Sprintf("%snode/%s/labels/merge?level=neuron", server.WebAPIPath, uuid)
+		server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString(merge))
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/merge?level=celltype", server.WebAPIPath, uuid)
+	server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString("[1, 3]"))
+
+	pts := []dvid.Point3d{{10, 10, 10}, {40, 10, 10}, {70, 10, 10}, {100, 10, 10}}
+	expected := map[string][]uint64{
+		"":         {1, 2, 3, 4},
+		"neuron":   {1, 1, 3, 3},
+		"celltype": {1, 1, 1, 1},
+	}
+	for level, labels := range expected {
+		for i, pt := range pts {
+			if label := getLevelLabel(t, uuid, pt, level); label != labels[i] {
+				t.Errorf("expected label %d at %s for level %q, got %d\n", labels[i], pt, level, label)
+			}
+		}
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/labels?level=neuron", server.WebAPIPath, uuid)
+	ptsJSON, _ := json.Marshal(pts)
+	var got []uint64
+	if err := json.Unmarshal(server.TestHTTP(t, "GET", reqStr, bytes.NewBuffer(ptsJSON)), &got); err != nil {
+		t.Fatalf("couldn't unmarshal labels: %v\n", err)
+	}
+	if !reflect.DeepEqual(got, expected["neuron"]) {
+		t.Errorf("expected labels %v at level neuron, got %v\n", expected["neuron"], got)
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/label/10_10_10?level=neuron&supervoxels=true", server.WebAPIPath, uuid)
+	server.TestBadHTTP(t, "GET", reqStr, nil)
+
+	reqStr = fmt.Sprintf("%snode/%s/labels/raw/0_1_2/128_64_64/0_0_0?level=neuron", server.WebAPIPath, uuid)
+	vol.data = server.TestHTTP(t, "GET", reqStr, nil)
+	vol.verifyLabel(t, 1, 40, 10, 10)
+	vol.verifyLabel(t, 3, 100, 10, 10)
+
+	reqStr = fmt.Sprintf("%snode/%s/labels/blocks/64_64_64/64_0_0?compression=blocks&level=neuron", server.WebAPIPath, uuid)
+	blocks := decodeReturnedBlocks(t, server.TestHTTP(t, "GET", reqStr, nil))
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d\n", len(blocks))
+	}
+	for _, label := range blocks[0].Labels {
+		if label != 3 {
+			t.Errorf("expected only group 3 in block at level neuron, got labels %v\n", blocks[0].Labels)
+			break
+		}
+	}
+
+	// sparse volume of a group includes all its bodies.
+	reqStr = fmt.Sprintf("%snode/%s/labels/sparsevol/1?level=celltype", server.WebAPIPath, uuid)
+	encoding := server.TestHTTP(t, "GET", reqStr, nil)
+	numRuns := binary.LittleEndian.Uint32(encoding[8:12])
+	var numVoxels int32
+	for i := uint32(0); i < numRuns; i++ {
+		numVoxels += int32(binary.LittleEndian.Uint32(encoding[12+i*16+12 : 12+i*16+16]))
+	}
+	if numVoxels != 128*64*64 {
+		t.Errorf("expected %d voxels in celltype group 1, got %d\n", 128*64*64, numVoxels)
+	}
+
+	if members := getLevelMembersJSON(t, uuid, "celltype", 1); !reflect.DeepEqual(members, []uint64{1, 3}) {
+		t.Errorf("expected celltype group 1 to have members [1 3], got %v\n", members)
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/cleave/1?level=neuron", server.WebAPIPath, uuid)
+	var cleaveResp struct {
+		CleavedLabel uint64
+	}
+	if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString("[2]")), &cleaveResp); err != nil {
+		t.Fatalf("couldn't unmarshal cleave response: %v\n", err)
+	}
+	if label := getLevelLabel(t, uuid, pts[1], "neuron"); label != cleaveResp.CleavedLabel {
+		t.Errorf("expected label %d at %s for level neuron after cleave, got %d\n", cleaveResp.CleavedLabel, pts[1], label)
+	}
+	if members := getLevelMembersJSON(t, uuid, "neuron", 1); !reflect.DeepEqual(members, []uint64{1}) {
+		t.Errorf("expected neuron group 1 to have members [1] after cleave, got %v\n", members)
+	}
+	if label := getLevelLabel(t, uuid, pts[1], ""); label != 2 {
+		t.Errorf("expected body 2 at %s after level cleave, got %d\n", pts[1], label)
+	}
+
+	// the label of a group can't be cleaved from it.
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString("[1]")); err == nil {
+		t.Errorf("expected error cleaving group label 1 from its neuron group\n")
+	}
+	if members := getLevelMembersJSON(t, uuid, "neuron", 1); !reflect.DeepEqual(members, []uint64{1}) {
+		t.Errorf("expected neuron group 1 unchanged after bad cleave, got %v\n", members)
+	}
+}
+
+// returns a split sparse volume covering x from x0 through x0+length-1 of the test volume.
+func levelSplitVolume(t *testing.T, x0, length int32) []byte {
+	var rles dvid.RLEs
+	for z := int32(0); z < 64; z++ {
+		for y := int32(0); y < 64; y++ {
+			rles = append(rles, dvid.NewRLE(dvid.Point3d{x0, y, z}, length))
+		}
+	}
+	data, err := encodeSplitRLEs(rles)
+	if err != nil {
+		t.Fatalf("couldn't encode split volume: %v\n", err)
+	}
+	return data
+}
+
+func TestLevelMutations(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, _ := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	for i := int32(0); i < 4; i++ {
+		vol.addSubvol(dvid.Point3d{i * 32, 0, 0}, dvid.Point3d{32, 64, 64}, uint64(i+1))
+	}
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	reqStr := fmt.Sprintf("%snode/%s/labels/levels/neuron", server.WebAPIPath, uuid)
+	server.TestHTTP(t, "POST", reqStr, nil)
+	reqStr = fmt.Sprintf("%snode/%s/labels/merge?level=neuron", server.WebAPIPath, uuid)
+	server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString("[1, 2]"))
+
+	// labels that are members of another group can't be merged at the level.
+	for _, merge := range []string{"[2, 3]", "[3, 2]"} {
+		server.TestBadHTTP(t, "POST", reqStr, bytes.NewBufferString(merge))
+	}
+
+	// a new body split from body 2 stays within its group.
+	var splitResp struct {
+		Label uint64
+	}
+	reqStr = fmt.Sprintf("%snode/%s/labels/split/2", server.WebAPIPath, uuid)
+	if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, bytes.NewBuffer(levelSplitVolume(t, 32, 16))), &splitResp); err != nil {
+		t.Fatalf("couldn't unmarshal split response: %v\n", err)
+	}
+	newBody := splitResp.Label
+	if label := getLevelLabel(t, uuid, dvid.Point3d{40, 10, 10}, "neuron"); label != 1 {
+		t.Errorf("expected split body %d within neuron group 1, got %d\n", newBody, label)
+	}
+	if members := getLevelMembersJSON(t, uuid, "neuron", 1); !reflect.DeepEqual(members, []uint64{1, 2, newBody}) {
+		t.Errorf("expected neuron group 1 to have members [1 2 %d] after split, got %v\n", newBody, members)
+	}
+
+	// a body merged into another body leaves its group.
+	reqStr = fmt.Sprintf("%snode/%s/labels/merge", server.WebAPIPath, uuid)
+	server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString("[3, 2]"))
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	if label := getLevelLabel(t, uuid, dvid.Point3d{50, 10, 10}, "neuron"); label != 3 {
+		t.Errorf("expected merged body within neuron group 3, got %d\n", label)
+	}
+	if members := getLevelMembersJSON(t, uuid, "neuron", 1); !reflect.DeepEqual(members, []uint64{1, newBody}) {
+		t.Errorf("expected neuron group 1 to have members [1 %d] after merge, got %v\n", newBody, members)
+	}
+
+	// a split at a level splits part of body 1 and moves all of the new body.
+	reqStr = fmt.Sprintf("%snode/%s/labels/split/1?level=neuron", server.WebAPIPath, uuid)
+	server.TestBadHTTP(t, "POST", reqStr, bytes.NewBuffer(levelSplitVolume(t, 16, 64)))
+	var rles dvid.RLEs
+	for _, x0 := range []int32{0, 32} {
+		for z := int32(0); z < 64; z++ {
+			for y := int32(0); y < 64; y++ {
+				rles = append(rles, dvid.NewRLE(dvid.Point3d{x0, y, z}, 16))
+			}
+		}
+	}
+	split, err := encodeSplitRLEs(rles)
+	if err != nil {
+		t.Fatalf("couldn't encode split volume: %v\n", err)
+	}
+	if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, bytes.NewBuffer(split)), &splitResp); err != nil {
+		t.Fatalf("couldn't unmarshal level split response: %v\n", err)
+	}
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	splitGroup := splitResp.Label
+	expected := map[dvid.Point3d]uint64{
+		{5, 10, 10}:  splitGroup,
+		{20, 10, 10}: 1,
+		{40, 10, 10}: splitGroup,
+		{50, 10, 10}: 3,
+	}
+	for pt, group := range expected {
+		if label := getLevelLabel(t, uuid, pt, "neuron"); label != group {
+			t.Errorf("expected neuron group %d at %s after level split, got %d\n", group, pt, label)
+		}
+	}
+	if label := getLevelLabel(t, uuid, dvid.Point3d{5, 10, 10}, ""); label == 1 || label == newBody {
+		t.Errorf("expected a new body at (5,10,10) after level split, got %d\n", label)
+	}
+	if label := getLevelLabel(t, uuid, dvid.Point3d{40, 10, 10}, ""); label != newBody {
+		t.Errorf("expected body %d to be moved by level split, got %d\n", newBody, label)
+	}
+}
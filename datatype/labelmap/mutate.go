@@ -43,13 +43,32 @@ type sizeChange struct {
 // labels.MergeEndEvent occurs at end of merge and transmits labels.DeltaMergeEnd struct.
 //
 func (d *Data) MergeLabels(v dvid.VersionID, op labels.MergeOp, info dvid.ModInfo) (mutID uint64, err error) {
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	mutID = d.NewMutationID()
+	err = d.mergeLabels(v, op, info, mutID, nil)
+	return
+}
+
+// mergeLabels does a merge with the given mutation ID.  If batch is non-nil, the merge is
+// part of a batch and its logging, notifications and kafka messages are deferred.
+func (d *Data) mergeLabels(v dvid.VersionID, op labels.MergeOp, info dvid.ModInfo, mutID uint64, batch *mutationBatch) (err error) {
 	dvid.Debugf("Merging %s into label %d ...\n", op.Merged, op.Target)
 
+	if err = d.checkCheckouts(v, info.User, op.Target); err != nil {
+		return
+	}
+	for label := range op.Merged {
+		if err = d.checkCheckouts(v, info.User, label); err != nil {
+			return
+		}
+	}
+
 	d.StartUpdate()
 	defer d.StopUpdate()
 
 	timedLog := dvid.NewTimeLog()
-	mutID = d.NewMutationID()
 
 	// send kafka merge event to instance-uuid topic
 	// msg: {"action": "merge", "target": targetlabel, "labels": [merge labels]}
@@ -67,15 +86,14 @@ func (d *Data) MergeLabels(v dvid.VersionID, op labels.MergeOp, info dvid.ModInf
 		"MutationID": mutID,
 		"Timestamp":  time.Now().String(),
 	}
-	jsonmsg, _ := json.Marshal(msginfo)
-	if err := d.ProduceKafkaMsg(jsonmsg); err != nil {
+	if err := d.produceMutationMsg(batch, msginfo); err != nil {
 		dvid.Errorf("can't send merge op for %q to kafka: %v\n", d.DataName(), err)
 	}
 
 	// Signal that we are starting a merge.
 	evt := datastore.SyncEvent{d.DataUUID(), labels.MergeStartEvent}
-	msg := datastore.SyncMessage{labels.MergeStartEvent, v, labels.DeltaMergeStart{op}}
-	if err = datastore.NotifySubscribers(evt, msg); err != nil {
+	msg := datastore.SyncMessage{Event: labels.MergeStartEvent, Version: v, Delta: labels.DeltaMergeStart{op}, Span: info.Span}
+	if err = batch.notify(evt, msg); err != nil {
 		return
 	}
 
@@ -94,7 +112,7 @@ func (d *Data) MergeLabels(v dvid.VersionID, op labels.MergeOp, info dvid.ModInf
 		return
 	}
 
-	if err = addMergeToMapping(d, v, mutID, op.Target, mergeIdx); err != nil {
+	if err = addMergeToMapping(d, v, mutID, op.Target, mergeIdx, batch); err != nil {
 		return
 	}
 
@@ -118,37 +136,44 @@ func (d *Data) MergeLabels(v dvid.VersionID, op labels.MergeOp, info dvid.ModInf
 	for merged := range delta.Merged {
 		DeleteLabelIndex(d, v, merged)
 	}
-	if err = labels.LogMerge(d, v, op); err != nil {
+	if err = batch.afterCommit(func() error { return labels.LogMerge(d, v, op) }); err != nil {
 		return
 	}
+	batch.afterCommit(func() error {
+		d.mergeCheckouts(v, op)
+		d.mergeLevels(v, op)
+		return nil
+	})
 
 	dvid.Infof("merged label %d: supervoxels %v, %d blocks\n", op.Target, mergeIdx.GetSupervoxels(), len(mergeIdx.Blocks))
 
 	delta.Blocks = targetIdx.GetBlockIndices()
 	evt = datastore.SyncEvent{d.DataUUID(), labels.MergeBlockEvent}
-	msg = datastore.SyncMessage{labels.MergeBlockEvent, v, delta}
-	if err = datastore.NotifySubscribers(evt, msg); err != nil {
+	msg = datastore.SyncMessage{Event: labels.MergeBlockEvent, Version: v, Delta: delta, Span: info.Span}
+	if err = batch.notify(evt, msg); err != nil {
 		err = fmt.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 		return
 	}
 
 	evt = datastore.SyncEvent{d.DataUUID(), labels.MergeEndEvent}
-	msg = datastore.SyncMessage{labels.MergeEndEvent, v, labels.DeltaMergeEnd{delta.MergeOp}}
-	if err := datastore.NotifySubscribers(evt, msg); err != nil {
+	msg = datastore.SyncMessage{Event: labels.MergeEndEvent, Version: v, Delta: labels.DeltaMergeEnd{delta.MergeOp}, Span: info.Span}
+	if err := batch.notify(evt, msg); err != nil {
 		dvid.Criticalf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
 
 	timedLog.Infof("Merged %s -> %d, data %q, resulting in %d blocks", delta.Merged, delta.Target, d.DataName(), len(delta.Blocks))
 
 	// send kafka merge complete event to instance-uuid topic
-	msginfo = map[string]interface{}{
-		"Action":     "merge-complete",
-		"MutationID": mutID,
-		"UUID":       string(versionuuid),
-		"Timestamp":  time.Now().String(),
+	if batch == nil {
+		msginfo = map[string]interface{}{
+			"Action":     "merge-complete",
+			"MutationID": mutID,
+			"UUID":       string(versionuuid),
+			"Timestamp":  time.Now().String(),
+		}
+		jsonmsg, _ := json.Marshal(msginfo)
+		err = d.ProduceKafkaMsg(jsonmsg)
 	}
-	jsonmsg, _ = json.Marshal(msginfo)
-	err = d.ProduceKafkaMsg(jsonmsg)
 	return
 }
 
@@ -164,6 +189,12 @@ func (d *Data) CleaveLabel(v dvid.VersionID, label uint64, info dvid.ModInfo, r
 		err = fmt.Errorf("no cleave supervoxels JSON was POSTed")
 		return
 	}
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	if err = d.checkCheckouts(v, info.User, label); err != nil {
+		return
+	}
 
 	cleaveLabel, err = d.newLabel(v)
 	if err != nil {
@@ -187,59 +218,73 @@ func (d *Data) CleaveLabel(v dvid.VersionID, label uint64, info dvid.ModInfo, r
 		return
 	}
 
-	// send kafka cleave event to instance-uuid topic
 	mutID = d.NewMutationID()
+	op := labels.CleaveOp{
+		MutID:              mutID,
+		Target:             label,
+		CleavedLabel:       cleaveLabel,
+		CleavedSupervoxels: cleaveSupervoxels,
+	}
+	err = d.cleaveLabel(v, op, info, nil)
+	return
+}
+
+// cleaveLabel does a cleave whose mutation ID and cleaved label have already been set.  If
+// batch is non-nil, the cleave is part of a batch and its logging, notifications and kafka
+// messages are deferred.
+func (d *Data) cleaveLabel(v dvid.VersionID, op labels.CleaveOp, info dvid.ModInfo, batch *mutationBatch) (err error) {
+	// send kafka cleave event to instance-uuid topic
 	versionuuid, _ := datastore.UUIDFromVersion(v)
 	msginfo := map[string]interface{}{
 		"Action":             "cleave",
-		"OrigLabel":          label,
-		"CleavedLabel":       cleaveLabel,
-		"CleavedSupervoxels": cleaveSupervoxels,
-		"MutationID":         mutID,
+		"OrigLabel":          op.Target,
+		"CleavedLabel":       op.CleavedLabel,
+		"CleavedSupervoxels": op.CleavedSupervoxels,
+		"MutationID":         op.MutID,
 		"UUID":               string(versionuuid),
 		"Timestamp":          time.Now().String(),
 	}
-	jsonmsg, _ := json.Marshal(msginfo)
-	if err = d.ProduceKafkaMsg(jsonmsg); err != nil {
+	if err = d.produceMutationMsg(batch, msginfo); err != nil {
 		dvid.Errorf("error on sending split op to kafka: %v", err)
 	}
 
 	d.StartUpdate()
 	defer d.StopUpdate()
 
-	op := labels.CleaveOp{
-		MutID:              mutID,
-		Target:             label,
-		CleavedLabel:       cleaveLabel,
-		CleavedSupervoxels: cleaveSupervoxels,
-	}
 	if err = CleaveIndex(d, v, op, info); err != nil {
 		return
 	}
-	if err = addCleaveToMapping(d, v, op); err != nil {
+	if err = addCleaveToMapping(d, v, op, batch); err != nil {
 		return
 	}
-	if err = labels.LogCleave(d, v, op); err != nil {
+	if err = batch.afterCommit(func() error { return labels.LogCleave(d, v, op) }); err != nil {
 		return
 	}
+	batch.afterCommit(func() error {
+		d.copyCheckout(v, op.Target, op.CleavedLabel)
+		d.copyLevelGroup(v, op.Target, op.CleavedLabel)
+		return nil
+	})
 
 	// notify syncs after processing because downstream sync might rely on changes
 	evt := datastore.SyncEvent{d.DataUUID(), labels.CleaveLabelEvent}
-	msg := datastore.SyncMessage{labels.CleaveLabelEvent, v, op}
-	if err = datastore.NotifySubscribers(evt, msg); err != nil {
+	msg := datastore.SyncMessage{Event: labels.CleaveLabelEvent, Version: v, Delta: op, Span: info.Span}
+	if err = batch.notify(evt, msg); err != nil {
 		err = fmt.Errorf("can't notify subscribers for event %v: %v", evt, err)
 		return
 	}
 
-	msginfo = map[string]interface{}{
-		"Action":     "cleave-complete",
-		"MutationID": mutID,
-		"UUID":       string(versionuuid),
-		"Timestamp":  time.Now().String(),
-	}
-	jsonmsg, _ = json.Marshal(msginfo)
-	if err = d.ProduceKafkaMsg(jsonmsg); err != nil {
-		dvid.Errorf("error on sending cleave complete op to kafka: %v", err)
+	if batch == nil {
+		msginfo = map[string]interface{}{
+			"Action":     "cleave-complete",
+			"MutationID": op.MutID,
+			"UUID":       string(versionuuid),
+			"Timestamp":  time.Now().String(),
+		}
+		jsonmsg, _ := json.Marshal(msginfo)
+		if err = d.ProduceKafkaMsg(jsonmsg); err != nil {
+			dvid.Errorf("error on sending cleave complete op to kafka: %v", err)
+		}
 	}
 	return
 }
@@ -500,8 +545,20 @@ func getAffectedBlocks(idx *labels.Index, svsplit *labels.SVSplitMap) (affectedB
 // voxels are within the fromLabel set of voxels and will generate unspecified behavior if this is
 // not the case.
 func (d *Data) SplitLabels(v dvid.VersionID, fromLabel uint64, r io.ReadCloser, info dvid.ModInfo) (toLabel, mutID uint64, err error) {
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	return d.splitLabels(v, fromLabel, r, info)
+}
+
+// splitLabels does a split with batchMu held by the caller.
+func (d *Data) splitLabels(v dvid.VersionID, fromLabel uint64, r io.ReadCloser, info dvid.ModInfo) (toLabel, mutID uint64, err error) {
 	timedLog := dvid.NewTimeLog()
 
+	if err = d.checkCheckouts(v, info.User, fromLabel); err != nil {
+		return
+	}
+
 	// Create a new label id for this version that will persist to store
 	toLabel, err = d.newLabel(v)
 	if err != nil {
@@ -611,6 +668,7 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel uint64, r io.ReadCloser,
 	// in each block, and either modify header or rewrite the voxel labels.  Activate downres for affected
 	// blocks.
 	downresMut := downres.NewMutation(d, v, mutID)
+	downresMut.SetSpan(info.Span)
 	if err = d.splitPass2(ctx, downresMut, idx, affectedBlocks, svsplit.Splits, splitmap, blockSplits); err != nil {
 		return
 	}
@@ -632,6 +690,8 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel uint64, r io.ReadCloser,
 	if err = labels.LogSplit(d, v, op); err != nil {
 		return
 	}
+	d.copyCheckout(v, fromLabel, toLabel)
+	d.copyLevelGroup(v, fromLabel, toLabel)
 	if err = downresMut.Execute(); err != nil {
 		return
 	}
@@ -646,7 +706,7 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel uint64, r io.ReadCloser,
 		SplitVoxels:  splitSize,
 	}
 	evt := datastore.SyncEvent{d.DataUUID(), labels.SplitLabelEvent}
-	msg := datastore.SyncMessage{labels.SplitLabelEvent, v, deltaSplit}
+	msg := datastore.SyncMessage{Event: labels.SplitLabelEvent, Version: v, Delta: deltaSplit, Span: info.Span}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
@@ -670,9 +730,34 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel uint64, r io.ReadCloser,
 // The first returned label is assigned to the split voxels while the second returned label is
 // assigned to the remainder voxels.
 func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabel uint64, r io.ReadCloser, info dvid.ModInfo, downscale bool) (splitSupervoxel, remainSupervoxel, mutID uint64, err error) {
-	timedLog := dvid.NewTimeLog()
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	if splitSupervoxel, remainSupervoxel, err = d.splitSupervoxelLabels(v, splitlabel, remainlabel); err != nil {
+		return
+	}
+	dvid.Debugf("Splitting subset of label %d into new label %d and renaming remainder to label %d...\n", svlabel, splitSupervoxel, remainSupervoxel)
 
-	// Create new labels for this split that will persist to store
+	// Read the sparse volume from reader.
+	var split dvid.RLEs
+	split, err = dvid.ReadRLEs(r)
+	if err != nil {
+		return
+	}
+	mutID = d.NewMutationID()
+	op := labels.SplitSupervoxelOp{
+		MutID:            mutID,
+		Supervoxel:       svlabel,
+		SplitSupervoxel:  splitSupervoxel,
+		RemainSupervoxel: remainSupervoxel,
+	}
+	err = d.splitSupervoxel(v, op, split, info, downscale, nil)
+	return
+}
+
+// splitSupervoxelLabels returns the given split and remain labels or, if they are 0, new labels
+// that will persist to store.
+func (d *Data) splitSupervoxelLabels(v dvid.VersionID, splitlabel, remainlabel uint64) (splitSupervoxel, remainSupervoxel uint64, err error) {
 	if splitlabel != 0 {
 		splitSupervoxel = splitlabel
 		if _, err = d.updateMaxLabel(v, splitlabel); err != nil {
@@ -689,14 +774,16 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 	} else if remainSupervoxel, err = d.newLabel(v); err != nil {
 		return
 	}
-	dvid.Debugf("Splitting subset of label %d into new label %d and renaming remainder to label %d...\n", svlabel, splitSupervoxel, remainSupervoxel)
+	return
+}
 
-	// Read the sparse volume from reader.
-	var split dvid.RLEs
-	split, err = dvid.ReadRLEs(r)
-	if err != nil {
-		return
-	}
+// splitSupervoxel splits the given voxels from a supervoxel using the mutation ID and new
+// supervoxels already set in the op.  If batch is non-nil, the split is part of a batch and
+// its logging, notifications, down-res and kafka messages are deferred.
+func (d *Data) splitSupervoxel(v dvid.VersionID, op labels.SplitSupervoxelOp, split dvid.RLEs, info dvid.ModInfo, downscale bool, batch *mutationBatch) (err error) {
+	timedLog := dvid.NewTimeLog()
+
+	svlabel, splitlabel, remainlabel := op.Supervoxel, op.SplitSupervoxel, op.RemainSupervoxel
 	splitSize, _ := split.Stats()
 	if splitSize == 0 {
 		dvid.Infof("split on supervoxel %d -> %d was given split size of 0\n", svlabel, remainlabel)
@@ -717,6 +804,9 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 			label = mapped
 		}
 	}
+	if err = d.checkCheckouts(v, info.User, label); err != nil {
+		return
+	}
 	shard := label % numIndexShards
 	indexMu[shard].Lock()
 	defer indexMu[shard].Unlock()
@@ -743,31 +833,34 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 	d.voxelMu.Lock()
 	defer d.voxelMu.Unlock()
 
-	// store split info into separate data.
-	var splitData []byte
-	if splitData, err = split.MarshalBinary(); err != nil {
-		return
-	}
-	var splitRef string
-	if splitRef, err = d.PutBlob(splitData); err != nil {
-		dvid.Errorf("error storing split data: %v", err)
-	}
-
 	// send kafka split event to instance-uuid topic
-	mutID = d.NewMutationID()
+	mutID := op.MutID
 	versionuuid, _ := datastore.UUIDFromVersion(v)
 	msginfo := map[string]interface{}{
 		"Action":           "split-supervoxel",
 		"Supervoxel":       svlabel,
-		"SplitSupervoxel":  splitSupervoxel,
-		"RemainSupervoxel": remainSupervoxel,
-		"Split":            splitRef,
+		"SplitSupervoxel":  splitlabel,
+		"RemainSupervoxel": remainlabel,
 		"MutationID":       mutID,
 		"UUID":             string(versionuuid),
 		"Timestamp":        time.Now().String(),
 	}
-	jsonmsg, _ := json.Marshal(msginfo)
-	if err = d.ProduceKafkaMsg(jsonmsg); err != nil {
+
+	// store split info into separate data, which for a batch waits until commit so a
+	// rolled back split leaves no blob behind.
+	var splitData []byte
+	if splitData, err = split.MarshalBinary(); err != nil {
+		return
+	}
+	batch.afterCommit(func() error {
+		splitRef, err := d.PutBlob(splitData)
+		if err != nil {
+			dvid.Errorf("error storing split data: %v", err)
+		}
+		msginfo["Split"] = splitRef
+		return nil
+	})
+	if err = d.produceMutationMsg(batch, msginfo); err != nil {
 		dvid.Errorf("error on sending split op to kafka: %v", err)
 	}
 
@@ -786,16 +879,12 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 	if err != nil {
 		return
 	}
-	op := labels.SplitSupervoxelOp{
-		MutID:            mutID,
-		Supervoxel:       svlabel,
-		SplitSupervoxel:  splitSupervoxel,
-		RemainSupervoxel: remainSupervoxel,
-		Split:            splitmap,
-	}
+	op.Split = splitmap
 	var downresMut *downres.Mutation
 	if downscale {
 		downresMut = downres.NewMutation(d, v, mutID)
+		downresMut.SetSpan(info.Span)
+		batch.saveDownres()
 	}
 
 	var splitblks dvid.IZYXSlice
@@ -831,6 +920,7 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 		numBlocks++
 		blockCh <- pb
 	}
+	batch.saveBlocks(origBlocks[:numBlocks])
 
 	// Wait for all blocks in supervoxel to be relabeled before returning.
 	getLog.Debugf("supervoxel split of %d: got %d blocks", svlabel, numBlocks)
@@ -848,10 +938,10 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 		d.restoreOldBlocks(ctx, numBlocks, origBlocks)
 		return
 	}
-	if err = addSupervoxelSplitToMapping(d, v, op); err != nil {
+	if err = addSupervoxelSplitToMapping(d, v, op, batch); err != nil {
 		return
 	}
-	if err = labels.LogSupervoxelSplit(d, v, op); err != nil {
+	if err = batch.afterCommit(func() error { return labels.LogSupervoxelSplit(d, v, op) }); err != nil {
 		return
 	}
 	// store the new split index
@@ -862,7 +952,7 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 	}
 
 	if downresMut != nil {
-		if err = downresMut.Execute(); err != nil {
+		if err = batch.afterCommit(downresMut.Execute); err != nil {
 			dvid.Criticalf("down-res compute of supervoxel split %d failed with error: %v\n", svlabel, err)
 			dvid.Criticalf("down-res error can lead to sync issue between scale 0 and higher affecting these blocks: %s\n", splitblks)
 			return
@@ -872,20 +962,22 @@ func (d *Data) SplitSupervoxel(v dvid.VersionID, svlabel, splitlabel, remainlabe
 	timedLog.Debugf("labelmap supervoxel %d split complete (%d blocks split)", op.Supervoxel, len(op.Split))
 
 	evt := datastore.SyncEvent{d.DataUUID(), labels.SupervoxelSplitEvent}
-	msg := datastore.SyncMessage{labels.SupervoxelSplitEvent, v, op}
-	if err := datastore.NotifySubscribers(evt, msg); err != nil {
+	msg := datastore.SyncMessage{Event: labels.SupervoxelSplitEvent, Version: v, Delta: op, Span: info.Span}
+	if err := batch.notify(evt, msg); err != nil {
 		dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
 
-	msginfo = map[string]interface{}{
-		"Action":     "split-supervoxel-complete",
-		"MutationID": mutID,
-		"UUID":       string(versionuuid),
-		"Timestamp":  time.Now().String(),
-	}
-	jsonmsg, _ = json.Marshal(msginfo)
-	if err = d.ProduceKafkaMsg(jsonmsg); err != nil {
-		dvid.Errorf("error on sending split complete op to kafka: %v", err)
+	if batch == nil {
+		msginfo = map[string]interface{}{
+			"Action":     "split-supervoxel-complete",
+			"MutationID": mutID,
+			"UUID":       string(versionuuid),
+			"Timestamp":  time.Now().String(),
+		}
+		jsonmsg, _ := json.Marshal(msginfo)
+		if err = d.ProduceKafkaMsg(jsonmsg); err != nil {
+			dvid.Errorf("error on sending split complete op to kafka: %v", err)
+		}
 	}
 	return
 }
@@ -0,0 +1,623 @@
+/*
+	This file supports painting label voxels directly with brush strokes or binary masks.
+*/
+
+package labelmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/downres"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+// Paint modes that determine which voxels within a stroke are changed.
+const (
+	PaintOverwrite  = "overwrite"  // paint all voxels
+	PaintBackground = "background" // only paint background (label 0) voxels
+	PaintLabel      = "label"      // only paint voxels of a given label
+)
+
+// PaintMask is a binary mask of voxels to paint, one byte per voxel in ZYX order where
+// any non-zero byte is painted.
+type PaintMask struct {
+	Offset dvid.Point3d `json:"offset"`
+	Size   dvid.Point3d `json:"size"`
+	Data   []byte       `json:"data"`
+}
+
+// PaintStroke describes voxels to paint with a label.  Either a polyline of points with
+// a radius, where spheres are painted at each point and capsules along each segment, or a
+// mask should be given.
+type PaintStroke struct {
+	Label uint64 `json:"label"`
+	Mode  string `json:"mode"`
+	Only  uint64 `json:"only"` // label of voxels that can be painted in "label" mode
+
+	Points []dvid.Point3d `json:"points"`
+	Radius float64        `json:"radius"`
+
+	Mask *PaintMask `json:"mask"`
+}
+
+// PaintResult gives the mutation ID of a paint stroke and the number of voxels changed.
+type PaintResult struct {
+	MutationID uint64
+	Voxels     uint64
+}
+
+// check makes sure the stroke is well-formed and returns the voxel bounds of the stroke.
+func (s *PaintStroke) check() (minPt, maxPt dvid.Point3d, err error) {
+	switch s.Mode {
+	case "":
+		s.Mode = PaintOverwrite
+	case PaintOverwrite, PaintBackground:
+	case PaintLabel:
+		if s.Only == 0 {
+			err = fmt.Errorf(`paint mode "label" requires a non-zero "only" label`)
+			return
+		}
+	default:
+		err = fmt.Errorf("unknown paint mode %q", s.Mode)
+		return
+	}
+	if s.Label == 0 && s.Mode == PaintBackground {
+		err = fmt.Errorf("painting background with label 0 would do nothing")
+		return
+	}
+	switch {
+	case s.Mask != nil && len(s.Points) != 0:
+		err = fmt.Errorf("paint stroke should have either points or a mask, not both")
+	case s.Mask != nil:
+		m := s.Mask
+		if m.Size[0] <= 0 || m.Size[1] <= 0 || m.Size[2] <= 0 {
+			err = fmt.Errorf("bad paint mask size %s", m.Size)
+			return
+		}
+		// size product can overflow, so compare by division.
+		numBytes, sliceBytes := int64(len(m.Data)), int64(m.Size[0])*int64(m.Size[1])
+		if numBytes%sliceBytes != 0 || numBytes/sliceBytes != int64(m.Size[2]) {
+			err = fmt.Errorf("paint mask of size %s should have one byte per voxel, got %d bytes", m.Size, len(m.Data))
+			return
+		}
+		for i := 0; i < 3; i++ {
+			if int64(m.Offset[i])+int64(m.Size[i])-1 > math.MaxInt32 {
+				err = fmt.Errorf("paint mask at offset %s with size %s exceeds voxel coordinates", m.Offset, m.Size)
+				return
+			}
+		}
+		minPt = m.Offset
+		maxPt = dvid.Point3d{m.Offset[0] + m.Size[0] - 1, m.Offset[1] + m.Size[1] - 1, m.Offset[2] + m.Size[2] - 1}
+	case len(s.Points) != 0:
+		if math.IsNaN(s.Radius) || s.Radius < 0 || s.Radius > math.MaxInt32 {
+			err = fmt.Errorf("bad paint radius %f", s.Radius)
+			return
+		}
+		r := int32(math.Ceil(s.Radius))
+		minPt, maxPt = s.Points[0], s.Points[0]
+		for _, pt := range s.Points[1:] {
+			for i := 0; i < 3; i++ {
+				if pt[i] < minPt[i] {
+					minPt[i] = pt[i]
+				}
+				if pt[i] > maxPt[i] {
+					maxPt[i] = pt[i]
+				}
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if int64(minPt[i])-int64(r) < math.MinInt32 || int64(maxPt[i])+int64(r) > math.MaxInt32 {
+				err = fmt.Errorf("paint stroke with radius %f exceeds voxel coordinates", s.Radius)
+				return
+			}
+			minPt[i] -= r
+			maxPt[i] += r
+		}
+	default:
+		err = fmt.Errorf("paint stroke requires points or a mask")
+	}
+	return
+}
+
+// paintSegment is a capsule between two points, or a sphere if the points are equal.
+type paintSegment struct {
+	a, ab        [3]float64
+	abLen2       float64
+	minPt, maxPt dvid.Point3d
+}
+
+// returns the segments of a polyline stroke that could intersect the given voxel bounds.
+func (s *PaintStroke) segments(minPt, maxPt dvid.Point3d) []paintSegment {
+	r := int32(math.Ceil(s.Radius))
+	var segs []paintSegment
+	for i := range s.Points {
+		p0 := s.Points[i]
+		p1 := p0
+		if i > 0 {
+			p0 = s.Points[i-1]
+		} else if len(s.Points) > 1 {
+			continue // single points only painted as spheres if not part of a polyline.
+		}
+		var seg paintSegment
+		overlaps := true
+		for j := 0; j < 3; j++ {
+			lo, hi := p0[j], p1[j]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			seg.minPt[j], seg.maxPt[j] = lo-r, hi+r
+			if seg.maxPt[j] < minPt[j] || seg.minPt[j] > maxPt[j] {
+				overlaps = false
+			}
+			seg.a[j] = float64(p0[j])
+			seg.ab[j] = float64(p1[j] - p0[j])
+			seg.abLen2 += seg.ab[j] * seg.ab[j]
+		}
+		if overlaps {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// returns true if the voxel is within the radius of the segment.
+func (seg *paintSegment) contains(x, y, z int32, r2 float64) bool {
+	if x < seg.minPt[0] || x > seg.maxPt[0] || y < seg.minPt[1] || y > seg.maxPt[1] || z < seg.minPt[2] || z > seg.maxPt[2] {
+		return false
+	}
+	ap := [3]float64{float64(x) - seg.a[0], float64(y) - seg.a[1], float64(z) - seg.a[2]}
+	var t float64
+	if seg.abLen2 > 0 {
+		t = (ap[0]*seg.ab[0] + ap[1]*seg.ab[1] + ap[2]*seg.ab[2]) / seg.abLen2
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+	}
+	var dist2 float64
+	for i := 0; i < 3; i++ {
+		d := ap[i] - t*seg.ab[i]
+		dist2 += d * d
+	}
+	return dist2 <= r2
+}
+
+// blocks returns the coordinates, in ZYX order, of the blocks that could have voxels
+// painted by the stroke within the given voxel bounds.  For a mask, these are the blocks
+// with any masked voxels.  For a polyline, each segment is sampled at intervals of at most
+// half the smallest block dimension and blocks within the radius plus half an interval of
+// a sample are kept.  An error is returned if there are more than maxBlocks blocks.
+func (s *PaintStroke) blocks(minPt, maxPt, blockSize dvid.Point3d, maxBlocks int64) ([]dvid.ChunkPoint3d, error) {
+	set := make(map[dvid.ChunkPoint3d]struct{})
+	add := func(bcoord dvid.ChunkPoint3d) error {
+		set[bcoord] = struct{}{}
+		if int64(len(set)) > maxBlocks {
+			return fmt.Errorf("paint stroke intersects more than the limit of %d blocks", maxBlocks)
+		}
+		return nil
+	}
+	minBlock := minPt.Chunk(blockSize).(dvid.ChunkPoint3d)
+	maxBlock := maxPt.Chunk(blockSize).(dvid.ChunkPoint3d)
+	if m := s.Mask; m != nil {
+		for bz := minBlock[2]; bz <= maxBlock[2]; bz++ {
+			for by := minBlock[1]; by <= maxBlock[1]; by++ {
+				for bx := minBlock[0]; bx <= maxBlock[0]; bx++ {
+					bcoord := dvid.ChunkPoint3d{bx, by, bz}
+					if m.within(bcoord, blockSize) {
+						if err := add(bcoord); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+		}
+	} else {
+		step := float64(blockSize[0])
+		for i := 1; i < 3; i++ {
+			step = math.Min(step, float64(blockSize[i]))
+		}
+		step = math.Max(step/2, 1)
+		reach := s.Radius + step/2
+		for _, seg := range s.segments(minPt, maxPt) {
+			n := int(math.Ceil(math.Sqrt(seg.abLen2) / step))
+			for k := 0; k <= n; k++ {
+				var t float64
+				if n > 0 {
+					t = float64(k) / float64(n)
+				}
+				var p [3]float64
+				var lo, hi dvid.Point3d
+				for i := 0; i < 3; i++ {
+					p[i] = seg.a[i] + t*seg.ab[i]
+					lo[i] = int32(math.Max(math.Floor(p[i]-reach), float64(minPt[i])))
+					hi[i] = int32(math.Min(math.Ceil(p[i]+reach), float64(maxPt[i])))
+				}
+				loBlock := lo.Chunk(blockSize).(dvid.ChunkPoint3d)
+				hiBlock := hi.Chunk(blockSize).(dvid.ChunkPoint3d)
+				for bz := loBlock[2]; bz <= hiBlock[2]; bz++ {
+					for by := loBlock[1]; by <= hiBlock[1]; by++ {
+						for bx := loBlock[0]; bx <= hiBlock[0]; bx++ {
+							bcoord := dvid.ChunkPoint3d{bx, by, bz}
+							if _, found := set[bcoord]; found {
+								continue
+							}
+							offset, endPt := bcoord.BoundingVoxels(blockSize)
+							var dist2 float64
+							for i := 0; i < 3; i++ {
+								var d float64
+								if p[i] < float64(offset[i]) {
+									d = float64(offset[i]) - p[i]
+								} else if p[i] > float64(endPt[i]) {
+									d = p[i] - float64(endPt[i])
+								}
+								dist2 += d * d
+							}
+							if dist2 <= reach*reach {
+								if err := add(bcoord); err != nil {
+									return nil, err
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	bcoords := make([]dvid.ChunkPoint3d, 0, len(set))
+	for bcoord := range set {
+		bcoords = append(bcoords, bcoord)
+	}
+	sort.Slice(bcoords, func(i, j int) bool {
+		a, b := bcoords[i], bcoords[j]
+		if a[2] != b[2] {
+			return a[2] < b[2]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[0] < b[0]
+	})
+	return bcoords, nil
+}
+
+// within returns true if any voxel of the given block is masked.
+func (m *PaintMask) within(bcoord dvid.ChunkPoint3d, blockSize dvid.Point3d) bool {
+	offset, endPt := bcoord.BoundingVoxels(blockSize)
+	var lo, hi dvid.Point3d
+	for i := 0; i < 3; i++ {
+		lo[i] = offset[i] - m.Offset[i]
+		if lo[i] < 0 {
+			lo[i] = 0
+		}
+		hi[i] = endPt[i] - m.Offset[i]
+		if hi[i] >= m.Size[i] {
+			hi[i] = m.Size[i] - 1
+		}
+		if lo[i] > hi[i] {
+			return false
+		}
+	}
+	for z := lo[2]; z <= hi[2]; z++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			i := (z*m.Size[1]+y)*m.Size[0] + lo[0]
+			for x := lo[0]; x <= hi[0]; x, i = x+1, i+1 {
+				if m.Data[i] != 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// paintBlock paints the stroke into a copy of the given block's labels, returning nil if
+// no voxels changed.  The mapping is used to get bodies of voxels in "label" mode.
+func (d *Data) paintBlock(v dvid.VersionID, s *PaintStroke, bcoord dvid.ChunkPoint3d, prev *labels.Block, mapping *SVMap, blockSize dvid.Point3d) (block *labels.Block, numPainted uint64, err error) {
+	var data []byte
+	if prev == nil {
+		data = make([]byte, blockSize.Prod()*8)
+	} else {
+		var size dvid.Point3d
+		if data, size = prev.MakeLabelVolume(); size != blockSize {
+			return nil, 0, fmt.Errorf("block %s has size %s, expected %s", bcoord, size, blockSize)
+		}
+	}
+	lbls, err := dvid.AliasByteToUint64(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset, endPt := bcoord.BoundingVoxels(blockSize)
+	var segs []paintSegment
+	if s.Mask == nil {
+		segs = s.segments(offset, endPt)
+		if len(segs) == 0 {
+			return nil, 0, nil
+		}
+	}
+	r2 := s.Radius * s.Radius
+	bodies := make(map[uint64]uint64)
+	var i int
+	for z := offset[2]; z <= endPt[2]; z++ {
+		for y := offset[1]; y <= endPt[1]; y++ {
+			for x := offset[0]; x <= endPt[0]; x, i = x+1, i+1 {
+				sv := lbls[i]
+				if sv == s.Label {
+					continue
+				}
+				switch s.Mode {
+				case PaintBackground:
+					if sv != 0 {
+						continue
+					}
+				case PaintLabel:
+					body, found := bodies[sv]
+					if !found {
+						body = sv
+						if mapped, found := mapping.MappedLabel(v, sv); found {
+							body = mapped
+						}
+						bodies[sv] = body
+					}
+					if body != s.Only {
+						continue
+					}
+				}
+				var inside bool
+				if m := s.Mask; m != nil {
+					mx, my, mz := x-m.Offset[0], y-m.Offset[1], z-m.Offset[2]
+					if mx >= 0 && my >= 0 && mz >= 0 && mx < m.Size[0] && my < m.Size[1] && mz < m.Size[2] {
+						inside = m.Data[(mz*m.Size[1]+my)*m.Size[0]+mx] != 0
+					}
+				} else {
+					for j := range segs {
+						if inside = segs[j].contains(x, y, z, r2); inside {
+							break
+						}
+					}
+				}
+				if inside {
+					lbls[i] = s.Label
+					numPainted++
+				}
+			}
+		}
+	}
+	if numPainted == 0 {
+		return nil, 0, nil
+	}
+	block, err = labels.MakeBlock(data, blockSize)
+	return
+}
+
+// Paint changes the voxels within a stroke to the stroke's label, which is a supervoxel ID.
+// Only blocks intersected by the stroke are read, and their number is limited by the
+// server's paint max blocks setting.  All changed blocks are written in one batch, after
+// which label indices are updated.  If any of these writes fail, the blocks, indices and
+// max label are rolled back.  Synced data are then notified and lower-res scales are
+// computed if downscale is true.
+func (d *Data) Paint(v dvid.VersionID, s *PaintStroke, info dvid.ModInfo, downscale bool) (result PaintResult, err error) {
+	minPt, maxPt, err := s.check()
+	if err != nil {
+		return
+	}
+	blockSize, ok := d.BlockSize().(dvid.Point3d)
+	if !ok {
+		err = fmt.Errorf("block size for data %q should be 3d, not: %s", d.DataName(), d.BlockSize())
+		return
+	}
+	// painted blocks are held in memory until stored, so limit the blocks of a stroke.
+	bcoords, err := s.blocks(minPt, maxPt, blockSize, server.PaintMaxBlocks())
+	if err != nil {
+		return
+	}
+	timedLog := dvid.NewTimeLog()
+
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
+	// Only do voxel-based mutations one at a time.
+	d.voxelMu.Lock()
+	defer d.voxelMu.Unlock()
+
+	d.StartUpdate()
+	defer d.StopUpdate()
+
+	ctx := datastore.NewVersionedCtx(d, v)
+	mapping, err := getMapping(d, v)
+	if err != nil {
+		return
+	}
+
+	// paint all blocks before storing anything.
+	var prevBlocks, paintedBlocks []*labels.PositionedBlock
+	for _, bcoord := range bcoords {
+		izyx := bcoord.ToIZYXString()
+		var prev *labels.PositionedBlock
+		if prev, err = d.getLabelBlock(ctx, 0, izyx); err != nil {
+			return
+		}
+		var prevBlock *labels.Block
+		if prev != nil {
+			prevBlock = &prev.Block
+		}
+		var block *labels.Block
+		var numPainted uint64
+		if block, numPainted, err = d.paintBlock(v, s, bcoord, prevBlock, mapping, blockSize); err != nil {
+			return
+		}
+		if block != nil {
+			prevBlocks = append(prevBlocks, prev)
+			paintedBlocks = append(paintedBlocks, &labels.PositionedBlock{Block: *block, BCoord: izyx})
+			result.Voxels += numPainted
+		}
+	}
+	if len(paintedBlocks) == 0 {
+		return
+	}
+
+	// get the supervoxel changes and make sure affected bodies aren't checked out.
+	svChanges := make(labels.SupervoxelChanges)
+	for i, pb := range paintedBlocks {
+		var prev *labels.Block
+		if prevBlocks[i] != nil {
+			prev = &prevBlocks[i].Block
+		}
+		for supervoxel, delta := range pb.Block.CalcNumLabels(prev) {
+			if delta == 0 {
+				continue
+			}
+			blockChanges, found := svChanges[supervoxel]
+			if !found {
+				blockChanges = make(map[dvid.IZYXString]int32)
+				svChanges[supervoxel] = blockChanges
+			}
+			blockChanges[pb.BCoord] += delta
+		}
+	}
+	labelset := make(labels.Set)
+	for supervoxel := range svChanges {
+		label := supervoxel
+		if mapped, found := mapping.MappedLabel(v, supervoxel); found {
+			label = mapped
+		}
+		labelset[label] = struct{}{}
+	}
+	var lbls []uint64
+	for label := range labelset {
+		lbls = append(lbls, label)
+	}
+	sort.Slice(lbls, func(i, j int) bool { return lbls[i] < lbls[j] })
+	if err = d.checkCheckouts(v, info.User, lbls...); err != nil {
+		return
+	}
+
+	// store the painted blocks, max label and label indices, rolling back on failure.
+	result.MutationID = d.NewMutationID()
+	batch := newMutationBatch(d, v, result.MutationID, 1)
+	if err = d.storePaint(ctx, s, prevBlocks, paintedBlocks, lbls, svChanges, batch); err != nil {
+		batch.rollback()
+		return
+	}
+
+	extents := d.Extents()
+	if extents.AdjustPoints(minPt, maxPt) {
+		if err := d.PostExtents(ctx, extents.MinPoint, extents.MaxPoint); err != nil {
+			dvid.Errorf("unable to update extents of data %q after paint: %v\n", d.DataName(), err)
+		} else if err := datastore.SaveDataByVersion(v, d); err != nil {
+			dvid.Errorf("unable to save data %q after paint: %v\n", d.DataName(), err)
+		}
+	}
+
+	var downresMut *downres.Mutation
+	if downscale {
+		downresMut = downres.NewMutation(d, v, result.MutationID)
+		downresMut.SetSpan(info.Span)
+	}
+	for i, pb := range paintedBlocks {
+		var event string
+		var delta interface{}
+		if prevBlocks[i] != nil {
+			event = labels.MutateBlockEvent
+			delta = MutatedBlock{result.MutationID, pb.BCoord, &prevBlocks[i].Block, &pb.Block}
+		} else {
+			event = labels.IngestBlockEvent
+			delta = IngestedBlock{result.MutationID, pb.BCoord, &pb.Block}
+		}
+		evt := datastore.SyncEvent{d.DataUUID(), event}
+		msg := datastore.SyncMessage{Event: event, Version: v, Delta: delta}
+		if err := datastore.NotifySubscribers(evt, msg); err != nil {
+			dvid.Errorf("Unable to notify subscribers of event %s in %s\n", event, d.DataName())
+		}
+		if downresMut != nil {
+			if err = downresMut.BlockMutated(pb.BCoord, &pb.Block); err != nil {
+				return
+			}
+		}
+	}
+	if downresMut != nil {
+		if err = downresMut.Execute(); err != nil {
+			return
+		}
+	}
+
+	// send kafka paint event to instance-uuid topic
+	versionuuid, _ := datastore.UUIDFromVersion(v)
+	msginfo := map[string]interface{}{
+		"Action":     "paint",
+		"Label":      s.Label,
+		"Mode":       s.Mode,
+		"Labels":     lbls,
+		"Voxels":     result.Voxels,
+		"MutationID": result.MutationID,
+		"UUID":       string(versionuuid),
+		"User":       info.User,
+		"Timestamp":  time.Now().String(),
+	}
+	if s.Mode == PaintLabel {
+		msginfo["Only"] = s.Only
+	}
+	jsonmsg, _ := json.Marshal(msginfo)
+	if err := d.ProduceKafkaMsg(jsonmsg); err != nil {
+		dvid.Errorf("error on sending paint op to kafka: %v", err)
+	}
+
+	timedLog.Infof("Painted %d voxels in %d blocks with label %d in data %q", result.Voxels, len(paintedBlocks), s.Label, d.DataName())
+	return
+}
+
+// storePaint writes the painted blocks in one batch, then updates the max label and the
+// indices of the given labels, journaling all changes in the mutation batch.
+func (d *Data) storePaint(ctx *datastore.VersionedCtx, s *PaintStroke, prevBlocks, paintedBlocks []*labels.PositionedBlock, lbls []uint64, svChanges labels.SupervoxelChanges, batch *mutationBatch) error {
+	v := ctx.VersionID()
+	batcher, err := datastore.GetKeyValueBatcher(d)
+	if err != nil {
+		return err
+	}
+	kvBatch := batcher.NewBatch(ctx)
+	for i, pb := range paintedBlocks {
+		if prevBlocks[i] == nil {
+			batch.saveNewBlock(pb.BCoord)
+		} else {
+			batch.saveBlocks(prevBlocks[i : i+1])
+		}
+		data, err := pb.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		serialization, err := dvid.SerializeData(data, d.Compression(), d.Checksum())
+		if err != nil {
+			return err
+		}
+		kvBatch.Put(NewBlockTKeyByCoord(0, pb.BCoord), serialization)
+	}
+	if err := kvBatch.Commit(); err != nil {
+		return fmt.Errorf("error on trying to write painted blocks: %v", err)
+	}
+	err = batch.reserveLabels(func() error {
+		_, err := d.updateMaxLabel(v, s.Label)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !d.IndexedLabels {
+		return nil
+	}
+	for _, label := range lbls {
+		if err := batch.saveIndex(label); err != nil {
+			return err
+		}
+		if err := ChangeLabelIndex(d, v, label, svChanges); err != nil {
+			return fmt.Errorf("indexing label %d after paint: %v", label, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func TestPaint(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	vol.addSubvol(dvid.Point3d{0, 0, 0}, dvid.Point3d{64, 64, 64}, 1)
+	vol.addSubvol(dvid.Point3d{64, 0, 0}, dvid.Point3d{64, 64, 64}, 2)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	const slabSize = 64 * 64 * 64
+
+	reqStr := fmt.Sprintf("%snode/%s/labels/paint", server.WebAPIPath, uuid)
+	paint := func(stroke PaintStroke, expected uint64) {
+		strokeJSON, err := json.Marshal(stroke)
+		if err != nil {
+			t.Fatalf("couldn't marshal paint stroke: %v\n", err)
+		}
+		var result PaintResult
+		if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, bytes.NewBuffer(strokeJSON)), &result); err != nil {
+			t.Fatalf("couldn't unmarshal paint result: %v\n", err)
+		}
+		if result.Voxels != expected {
+			t.Errorf("expected %d voxels painted by stroke %v, got %d\n", expected, stroke, result.Voxels)
+		}
+	}
+
+	mask := &PaintMask{Size: dvid.Point3d{8, 8, 8}, Data: bytes.Repeat([]byte{1}, 512)}
+	paint(PaintStroke{Label: 3, Mode: PaintLabel, Only: 2, Mask: mask}, 0)
+	paint(PaintStroke{Label: 3, Mode: PaintOverwrite, Mask: mask}, 512)
+	checkLabelSize(t, d, v, 3, true, 512)
+	checkLabelSize(t, d, v, 1, false, slabSize-512)
+
+	center := dvid.Point3d{96, 32, 32}
+	paint(PaintStroke{Label: 4, Mode: PaintBackground, Points: []dvid.Point3d{center}, Radius: 2}, 0)
+	paint(PaintStroke{Label: 4, Points: []dvid.Point3d{center}, Radius: 2}, 33)
+	checkLabelSize(t, d, v, 4, true, 33)
+	checkLabelSize(t, d, v, 2, false, slabSize-33)
+	sv, err := d.GetLabelAtScaledPoint(v, center, 0, true)
+	if err != nil {
+		t.Fatalf("couldn't get supervoxel at point: %v\n", err)
+	}
+	if sv != 4 {
+		t.Errorf("expected painted supervoxel 4 at %s, got %d\n", center, sv)
+	}
+
+	// capsule across the slab boundary only painting body 2.
+	pts := []dvid.Point3d{{60, 10, 10}, {70, 10, 10}}
+	paint(PaintStroke{Label: 5, Mode: PaintLabel, Only: 2, Points: pts}, 7)
+	checkLabelSize(t, d, v, 5, true, 7)
+
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString(`{"label": 3, "mode": "bogus", "points": [[0, 0, 0]]}`)); err == nil {
+		t.Errorf("expected error on bad paint mode\n")
+	}
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString(`{"label": 3, "points": [[0, 0, 0]], "radius": 1e12}`)); err == nil {
+		t.Errorf("expected error on paint radius beyond voxel coordinates\n")
+	}
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString(`{"label": 3, "points": [[2147483000, 0, 0]], "radius": 1000}`)); err == nil {
+		t.Errorf("expected error on paint stroke beyond voxel coordinates\n")
+	}
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString(`{"label": 3, "points": [[0, 0, 0], [0, 0, 100000]], "radius": 1000}`)); err == nil {
+		t.Errorf("expected error on paint stroke spanning too many blocks\n")
+	}
+}
+
+func TestPaintStrokeBlocks(t *testing.T) {
+	blockSize := dvid.Point3d{64, 64, 64}
+
+	// a long diagonal stroke whose bounds span far more blocks than it intersects.
+	s := PaintStroke{Label: 3, Points: []dvid.Point3d{{0, 0, 0}, {1280, 1280, 1280}}, Radius: 2}
+	minPt, maxPt, err := s.check()
+	if err != nil {
+		t.Fatalf("bad stroke: %v\n", err)
+	}
+	bcoords, err := s.blocks(minPt, maxPt, blockSize, 4096)
+	if err != nil {
+		t.Fatalf("expected diagonal stroke within block limit: %v\n", err)
+	}
+	if len(bcoords) > 200 {
+		t.Errorf("expected diagonal stroke to intersect few blocks, got %d\n", len(bcoords))
+	}
+	found := make(map[dvid.ChunkPoint3d]bool)
+	for _, bcoord := range bcoords {
+		found[bcoord] = true
+	}
+	for i := int32(0); i <= 20; i++ {
+		if !found[dvid.ChunkPoint3d{i, i, i}] {
+			t.Errorf("expected block (%d,%d,%d) along diagonal stroke\n", i, i, i)
+		}
+	}
+	if found[dvid.ChunkPoint3d{20, 0, 0}] {
+		t.Errorf("didn't expect block far from diagonal stroke\n")
+	}
+	if _, err := s.blocks(minPt, maxPt, blockSize, 10); err == nil {
+		t.Errorf("expected error when stroke intersects more than the block limit\n")
+	}
+
+	// a mask spanning 8 blocks with a single masked voxel.
+	mask := &PaintMask{Offset: dvid.Point3d{32, 32, 32}, Size: dvid.Point3d{64, 64, 64}, Data: make([]byte, 64*64*64)}
+	mask.Data[len(mask.Data)-1] = 1
+	s = PaintStroke{Label: 3, Mask: mask}
+	if minPt, maxPt, err = s.check(); err != nil {
+		t.Fatalf("bad mask stroke: %v\n", err)
+	}
+	if bcoords, err = s.blocks(minPt, maxPt, blockSize, 4096); err != nil {
+		t.Fatalf("couldn't get mask blocks: %v\n", err)
+	}
+	if len(bcoords) != 1 || bcoords[0] != (dvid.ChunkPoint3d{1, 1, 1}) {
+		t.Errorf("expected only block (1,1,1) for mask, got %v\n", bcoords)
+	}
+}
@@ -0,0 +1,265 @@
+/*
+	This file supports partitioning the supervoxels of a body given groups of seed
+	supervoxels, e.g., for proofreading clients that want to cleave a body.
+*/
+
+package labelmap
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/labels"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// SeededCleave is the partition of a body's supervoxels from groups of seed supervoxels.
+// If the partition was applied, the label of each group and the mutation ID of each
+// cleave are also given.
+type SeededCleave struct {
+	Supervoxels [][]uint64 // supervoxels assigned to each seed group
+	Unassigned  []uint64   // supervoxels not connected to any seed
+
+	Labels      []uint64 `json:",omitempty"`
+	MutationIDs []uint64 `json:",omitempty"`
+}
+
+// weighted edge between supervoxels, where higher weights are stronger connections.
+type svEdge struct {
+	sv1, sv2 uint64
+	weight   float64
+}
+
+// getContactGraph returns the edges between the given supervoxels weighted by the number of
+// shared voxel faces, computed from the supervoxel blocks of the body index.
+func (d *Data) getContactGraph(ctx *datastore.VersionedCtx, idx *labels.Index) ([]svEdge, error) {
+	blockSize, ok := d.BlockSize().(dvid.Point3d)
+	if !ok {
+		return nil, fmt.Errorf("block size for %s wasn't 3d", d.DataName())
+	}
+	scanner, err := d.newContactScanner(ctx, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	inBody := idx.GetSupervoxels()
+	faces := make(map[[2]uint64]uint64)
+	addFace := func(sv1, sv2 uint64) {
+		if sv1 == sv2 || sv2 == 0 {
+			return
+		}
+		if _, found := inBody[sv2]; !found {
+			return
+		}
+		if sv1 > sv2 {
+			sv1, sv2 = sv2, sv1
+		}
+		faces[[2]uint64{sv1, sv2}]++
+	}
+
+	blocks := idx.GetBlockIndices()
+	sort.Sort(blocks)
+	var curZ int32
+	for _, izyx := range blocks {
+		bcoord, err := izyx.ToChunkPoint3d()
+		if err != nil {
+			return nil, err
+		}
+		if bcoord[2] != curZ {
+			curZ = bcoord[2]
+			for cached := range scanner.blocks {
+				if c, err := cached.ToChunkPoint3d(); err == nil && c[2] < curZ {
+					delete(scanner.blocks, cached)
+				}
+			}
+		}
+		cb, err := scanner.getBlock(bcoord)
+		if err != nil {
+			return nil, err
+		}
+		if cb == nil {
+			continue
+		}
+		if cb.size != blockSize {
+			return nil, fmt.Errorf("block %s has size %s, expected %s", bcoord, cb.size, blockSize)
+		}
+		// only check faces in the positive directions so each face is counted once.
+		var i int
+		for z := int32(0); z < blockSize[2]; z++ {
+			for y := int32(0); y < blockSize[1]; y++ {
+				for x := int32(0); x < blockSize[0]; x++ {
+					sv := cb.lbls[i]
+					i++
+					if _, found := inBody[sv]; !found {
+						continue
+					}
+					for _, o := range faceOffsets {
+						if o[0] < 0 || o[1] < 0 || o[2] < 0 {
+							continue
+						}
+						nx, ny, nz := x+o[0], y+o[1], z+o[2]
+						if nx < blockSize[0] && ny < blockSize[1] && nz < blockSize[2] {
+							addFace(sv, cb.value(nx, ny, nz))
+							continue
+						}
+						nb, err := scanner.getBlock(dvid.ChunkPoint3d{bcoord[0] + o[0], bcoord[1] + o[1], bcoord[2] + o[2]})
+						if err != nil {
+							return nil, err
+						}
+						if nb != nil {
+							addFace(sv, nb.value(nx%blockSize[0], ny%blockSize[1], nz%blockSize[2]))
+						}
+					}
+				}
+			}
+		}
+	}
+	edges := make([]svEdge, 0, len(faces))
+	for pair, n := range faces {
+		edges = append(edges, svEdge{pair[0], pair[1], float64(n)})
+	}
+	return edges, nil
+}
+
+// getAffinityGraph returns the edges between the given supervoxels weighted by their
+// stored affinities.
+func (d *Data) getAffinityGraph(ctx *datastore.VersionedCtx, supervoxels labels.Set) ([]svEdge, error) {
+	var edges []svEdge
+	for sv1 := range supervoxels {
+		affs, err := getAffinities(ctx, sv1)
+		if err != nil {
+			return nil, err
+		}
+		for i, sv2 := range affs.Labels {
+			if _, found := supervoxels[sv2]; found && sv1 < sv2 {
+				edges = append(edges, svEdge{sv1, sv2, float64(affs.Affinities[i])})
+			}
+		}
+	}
+	return edges, nil
+}
+
+// partitionBySeeds assigns each supervoxel to a seed group using a seeded watershed over
+// the edges, i.e., a maximum spanning forest where each tree holds the seeds of one group.
+// Supervoxels not connected to any seed are returned as unassigned.
+func partitionBySeeds(supervoxels labels.Set, edges []svEdge, seeds [][]uint64) (groups [][]uint64, unassigned []uint64) {
+	uf := make(svUnionFind, len(supervoxels))
+	rootGroup := make(map[uint64]int)
+	for group, svs := range seeds {
+		for _, sv := range svs {
+			uf.union(svs[0], sv)
+		}
+		rootGroup[uf.find(svs[0])] = group
+	}
+
+	sorted := make([]svEdge, len(edges))
+	copy(sorted, edges)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].weight != sorted[j].weight {
+			return sorted[i].weight > sorted[j].weight
+		}
+		if sorted[i].sv1 != sorted[j].sv1 {
+			return sorted[i].sv1 < sorted[j].sv1
+		}
+		return sorted[i].sv2 < sorted[j].sv2
+	})
+	for _, edge := range sorted {
+		root1, root2 := uf.find(edge.sv1), uf.find(edge.sv2)
+		if root1 == root2 {
+			continue
+		}
+		group1, seeded1 := rootGroup[root1]
+		group2, seeded2 := rootGroup[root2]
+		if seeded1 && seeded2 {
+			continue // never join different seed groups.
+		}
+		uf.union(root1, root2)
+		delete(rootGroup, root1)
+		delete(rootGroup, root2)
+		if seeded1 {
+			rootGroup[uf.find(root1)] = group1
+		} else if seeded2 {
+			rootGroup[uf.find(root1)] = group2
+		}
+	}
+
+	groups = make([][]uint64, len(seeds))
+	for sv := range supervoxels {
+		if group, found := rootGroup[uf.find(sv)]; found {
+			groups[group] = append(groups[group], sv)
+		} else {
+			unassigned = append(unassigned, sv)
+		}
+	}
+	for _, svs := range groups {
+		sort.Slice(svs, func(i, j int) bool { return svs[i] < svs[j] })
+	}
+	sort.Slice(unassigned, func(i, j int) bool { return unassigned[i] < unassigned[j] })
+	return
+}
+
+// CleaveBySeeds partitions the supervoxels of a body given at least two groups of seed
+// supervoxels.  If useAffinities is true, the supervoxel graph is given by the stored
+// affinities, else by the number of voxel faces shared by supervoxels.  If apply is true,
+// the first group and any unassigned supervoxels keep the body label while each other
+// group is cleaved off into a new label, all within one batch of mutations.
+func (d *Data) CleaveBySeeds(v dvid.VersionID, label uint64, seeds [][]uint64, useAffinities, apply bool, info dvid.ModInfo) (*SeededCleave, error) {
+	if len(seeds) < 2 {
+		return nil, fmt.Errorf("at least two groups of seed supervoxels are required, got %d", len(seeds))
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	idx, err := d.getTracedLabelIndex(ctx, label, false)
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil || len(idx.Blocks) == 0 {
+		return nil, fmt.Errorf("label %d not found", label)
+	}
+	supervoxels := idx.GetSupervoxels()
+	seeded := make(map[uint64]int)
+	for group, svs := range seeds {
+		if len(svs) == 0 {
+			return nil, fmt.Errorf("seed group %d has no supervoxels", group)
+		}
+		for _, sv := range svs {
+			if _, found := supervoxels[sv]; !found {
+				return nil, fmt.Errorf("seed supervoxel %d is not in label %d", sv, label)
+			}
+			if prev, found := seeded[sv]; found && prev != group {
+				return nil, fmt.Errorf("seed supervoxel %d is in groups %d and %d", sv, prev, group)
+			}
+			seeded[sv] = group
+		}
+	}
+
+	var edges []svEdge
+	if useAffinities {
+		edges, err = d.getAffinityGraph(ctx, supervoxels)
+	} else {
+		edges, err = d.getContactGraph(ctx, idx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := new(SeededCleave)
+	result.Supervoxels, result.Unassigned = partitionBySeeds(supervoxels, edges, seeds)
+	if !apply {
+		return result, nil
+	}
+
+	ops := make([]MutationOp, len(seeds)-1)
+	for i := range ops {
+		ops[i].Cleave = &proto.CleaveOp{Target: label, Cleaved: result.Supervoxels[i+1]}
+	}
+	results, err := d.ApplyMutations(v, ops, info, true)
+	if err != nil {
+		return nil, err
+	}
+	result.Labels = []uint64{label}
+	for _, r := range results {
+		result.Labels = append(result.Labels, r.CleavedLabel)
+		result.MutationIDs = append(result.MutationIDs, r.MutationID)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func TestCleaveBySeeds(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	vol := newTestVolume(128, 64, 64)
+	for i := int32(0); i < 4; i++ {
+		vol.addSubvol(dvid.Point3d{32 * i, 0, 0}, dvid.Point3d{32, 64, 64}, uint64(i+1))
+	}
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	const slabSize = 32 * 64 * 64
+
+	server.TestHTTP(t, "POST", fmt.Sprintf("%snode/%s/labels/merge", server.WebAPIPath, uuid), bytes.NewBufferString("[1, 2, 3, 4]"))
+
+	// slabs touch in a chain so contacts assign middle slabs by tie-breaking on label.
+	reqStr := fmt.Sprintf("%snode/%s/labels/cleave-by-seeds/1", server.WebAPIPath, uuid)
+	var result SeededCleave
+	if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString("[[1], [4]]")), &result); err != nil {
+		t.Fatalf("couldn't unmarshal cleave by seeds result: %v\n", err)
+	}
+	if !reflect.DeepEqual(result.Supervoxels, [][]uint64{{1, 2, 3}, {4}}) || len(result.Unassigned) != 0 {
+		t.Errorf("unexpected partition by contacts: %v\n", result)
+	}
+	if len(result.Labels) != 0 {
+		t.Errorf("expected partition to not be applied: %v\n", result)
+	}
+	checkLabelSize(t, d, v, 1, false, 4*slabSize)
+
+	table := proto.AffinityTable{
+		Table: map[uint64]*proto.Affinities{
+			2: {Labels: []uint64{1, 3}, Affinities: []float32{0.9, 0.2}},
+			4: {Labels: []uint64{3}, Affinities: []float32{0.7}},
+		},
+	}
+	serialization, err := table.Marshal()
+	if err != nil {
+		t.Fatalf("couldn't serialize affinities: %v\n", err)
+	}
+	server.TestHTTP(t, "POST", fmt.Sprintf("%snode/%s/labels/affinities", server.WebAPIPath, uuid), bytes.NewBuffer(serialization))
+
+	result = SeededCleave{}
+	if err := json.Unmarshal(server.TestHTTP(t, "POST", reqStr+"?graph=affinities&apply=true", bytes.NewBufferString("[[1], [4]]")), &result); err != nil {
+		t.Fatalf("couldn't unmarshal cleave by seeds result: %v\n", err)
+	}
+	if !reflect.DeepEqual(result.Supervoxels, [][]uint64{{1, 2}, {3, 4}}) {
+		t.Fatalf("unexpected partition by affinities: %v\n", result)
+	}
+	if len(result.Labels) != 2 || result.Labels[0] != 1 || len(result.MutationIDs) != 1 {
+		t.Fatalf("expected applied cleave into one new label: %v\n", result)
+	}
+	checkLabelSize(t, d, v, 1, false, 2*slabSize)
+	checkLabelSize(t, d, v, result.Labels[1], false, 2*slabSize)
+
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString("[[1], [4]]")); err == nil {
+		t.Errorf("expected error on seeds outside of label\n")
+	}
+	if _, err := server.TestHTTPError(t, "POST", reqStr, bytes.NewBufferString("[[1, 2]]")); err == nil {
+		t.Errorf("expected error on single seed group\n")
+	}
+}
@@ -0,0 +1,262 @@
+package labelmap
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// maximum number of label stats cached across all labelmap instances.
+const maxCachedStats = 100000
+
+// LabelStats gives geometric statistics of a label's voxels at a given scale.  All
+// coordinates are voxel coordinates at that scale.
+type LabelStats struct {
+	Label  uint64 `json:"label"`
+	Scale  uint8  `json:"scale"`
+	Voxels uint64 `json:"voxels"`
+
+	// voxel-precise bounding box
+	MinVoxel dvid.Point3d `json:"minvoxel"`
+	MaxVoxel dvid.Point3d `json:"maxvoxel"`
+
+	Centroid [3]float64 `json:"centroid"`
+
+	// Covariance holds the second central moments of voxel coordinates.  PrincipalAxes are
+	// the unit eigenvectors of the covariance in order of decreasing variance, which is
+	// given by the corresponding PrincipalVariances.
+	Covariance         [3][3]float64 `json:"covariance"`
+	PrincipalAxes      [3][3]float64 `json:"principalaxes"`
+	PrincipalVariances [3]float64    `json:"principalvariances"`
+
+	// number of voxel faces not shared with another voxel of the label.
+	SurfaceArea uint64 `json:"surfacearea"`
+
+	// last mutation of the label index when the stats were computed.
+	MutationID uint64 `json:"mutationid"`
+}
+
+type statsKey struct {
+	data       dvid.UUID
+	v          dvid.VersionID
+	label      uint64
+	scale      uint8
+	supervoxel bool
+}
+
+// key for label index whose change invalidates stats.
+type statsIndexKey struct {
+	data  dvid.UUID
+	v     dvid.VersionID
+	label uint64
+}
+
+type cachedStats struct {
+	stats      *LabelStats
+	indexLabel uint64
+}
+
+var statsCache struct {
+	sync.Mutex
+	stats   map[statsKey]cachedStats
+	byIndex map[statsIndexKey]map[statsKey]struct{}
+}
+
+func init() {
+	statsCache.stats = make(map[statsKey]cachedStats)
+	statsCache.byIndex = make(map[statsIndexKey]map[statsKey]struct{})
+}
+
+func getCachedStats(k statsKey, indexLabel, mutID uint64) *LabelStats {
+	statsCache.Lock()
+	defer statsCache.Unlock()
+	cs, found := statsCache.stats[k]
+	if !found || cs.indexLabel != indexLabel || cs.stats.MutationID != mutID {
+		return nil
+	}
+	return cs.stats
+}
+
+func putCachedStats(k statsKey, indexLabel uint64, stats *LabelStats) {
+	statsCache.Lock()
+	defer statsCache.Unlock()
+	if len(statsCache.stats) >= maxCachedStats {
+		for evict, cs := range statsCache.stats {
+			removeCachedStats(evict, cs.indexLabel)
+			break
+		}
+	}
+	statsCache.stats[k] = cachedStats{stats: stats, indexLabel: indexLabel}
+	ik := statsIndexKey{data: k.data, v: k.v, label: indexLabel}
+	keys, found := statsCache.byIndex[ik]
+	if !found {
+		keys = make(map[statsKey]struct{})
+		statsCache.byIndex[ik] = keys
+	}
+	keys[k] = struct{}{}
+}
+
+// must be called with statsCache lock held.
+func removeCachedStats(k statsKey, indexLabel uint64) {
+	delete(statsCache.stats, k)
+	ik := statsIndexKey{data: k.data, v: k.v, label: indexLabel}
+	if keys, found := statsCache.byIndex[ik]; found {
+		delete(keys, k)
+		if len(keys) == 0 {
+			delete(statsCache.byIndex, ik)
+		}
+	}
+}
+
+// invalidateStats removes any cached stats computed from the given label's index.
+func invalidateStats(d dvid.Data, v dvid.VersionID, label uint64) {
+	statsCache.Lock()
+	defer statsCache.Unlock()
+	ik := statsIndexKey{data: d.DataUUID(), v: v, label: label}
+	for k := range statsCache.byIndex[ik] {
+		delete(statsCache.stats, k)
+	}
+	delete(statsCache.byIndex, ik)
+}
+
+// GetLabelStats returns geometric statistics for a label at the given scale, using cached
+// stats if the label index has not changed since they were computed.  If isSupervoxel is
+// true, the label is a supervoxel.  A nil LabelStats is returned if the label is not found.
+// Stats are not cached while a downres of the scale is in progress since its blocks may
+// not yet reflect the label index.
+func (d *Data) GetLabelStats(ctx *datastore.VersionedCtx, label uint64, scale uint8, isSupervoxel bool) (*LabelStats, error) {
+	idx, err := d.getTracedLabelIndex(ctx, label, isSupervoxel)
+	if err != nil || idx == nil || len(idx.Blocks) == 0 {
+		return nil, err
+	}
+	indexLabel, mutID := idx.Label, idx.LastMutId
+	k := statsKey{data: d.DataUUID(), v: ctx.VersionID(), label: label, scale: scale, supervoxel: isSupervoxel}
+	cacheable := !d.ScaleUpdating(scale)
+	if stats := getCachedStats(k, indexLabel, mutID); cacheable && stats != nil {
+		return stats, nil
+	}
+	if isSupervoxel {
+		if idx, err = idx.LimitToSupervoxel(label); err != nil || idx == nil {
+			return nil, err
+		}
+	}
+	blocks, err := idx.GetProcessedBlockIndices(scale, dvid.Bounds{})
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := d.newContactScanner(ctx, scale, isSupervoxel)
+	if err != nil {
+		return nil, err
+	}
+
+	// moments are accumulated relative to the first voxel to preserve precision.
+	var origin dvid.Point3d
+	var sum [3]float64
+	var sum2 [3][3]float64
+	stats := &LabelStats{Label: label, Scale: scale, MutationID: mutID}
+	voxelFn := func(pt dvid.Point3d) {
+		if stats.Voxels == 0 {
+			origin = pt
+			stats.MinVoxel = pt
+			stats.MaxVoxel = pt
+		}
+		stats.Voxels++
+		var rel [3]float64
+		for i := 0; i < 3; i++ {
+			if pt[i] < stats.MinVoxel[i] {
+				stats.MinVoxel[i] = pt[i]
+			}
+			if pt[i] > stats.MaxVoxel[i] {
+				stats.MaxVoxel[i] = pt[i]
+			}
+			rel[i] = float64(pt[i] - origin[i])
+			sum[i] += rel[i]
+		}
+		for i := 0; i < 3; i++ {
+			for j := i; j < 3; j++ {
+				sum2[i][j] += rel[i] * rel[j]
+			}
+		}
+	}
+	faceFn := func(pt, nbrPt dvid.Point3d, nbr uint64) {
+		stats.SurfaceArea++
+	}
+	if err := scanner.scan(label, blocks, voxelFn, faceFn); err != nil {
+		return nil, err
+	}
+	if stats.Voxels == 0 {
+		return nil, nil
+	}
+	n := float64(stats.Voxels)
+	var mean [3]float64
+	for i := 0; i < 3; i++ {
+		mean[i] = sum[i] / n
+		stats.Centroid[i] = float64(origin[i]) + mean[i]
+	}
+	for i := 0; i < 3; i++ {
+		for j := i; j < 3; j++ {
+			stats.Covariance[i][j] = sum2[i][j]/n - mean[i]*mean[j]
+			stats.Covariance[j][i] = stats.Covariance[i][j]
+		}
+	}
+	stats.PrincipalVariances, stats.PrincipalAxes = symmetricEigen3(stats.Covariance)
+
+	if cacheable && !d.ScaleUpdating(scale) {
+		putCachedStats(k, indexLabel, stats)
+	}
+	return stats, nil
+}
+
+// symmetricEigen3 returns the eigenvalues of a symmetric 3x3 matrix in decreasing order
+// with the corresponding unit eigenvectors, using Jacobi rotations.
+func symmetricEigen3(m [3][3]float64) (values [3]float64, vectors [3][3]float64) {
+	a := m
+	v := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for sweep := 0; sweep < 50; sweep++ {
+		off := a[0][1]*a[0][1] + a[0][2]*a[0][2] + a[1][2]*a[1][2]
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < 3; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < 3; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	order := []int{0, 1, 2}
+	sort.Slice(order, func(i, j int) bool { return a[order[i]][order[i]] > a[order[j]][order[j]] })
+	for i, col := range order {
+		values[i] = a[col][col]
+		for k := 0; k < 3; k++ {
+			vectors[i][k] = v[k][col]
+		}
+	}
+	return
+}
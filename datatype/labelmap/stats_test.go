@@ -0,0 +1,151 @@
+package labelmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/server"
+)
+
+func getLabelStats(t *testing.T, uuid dvid.UUID, name string, label uint64, supervoxels bool) LabelStats {
+	reqStr := fmt.Sprintf("%snode/%s/%s/stats/%d?supervoxels=%t", server.WebAPIPath, uuid, name, label, supervoxels)
+	r := server.TestHTTP(t, "GET", reqStr, nil)
+	var stats LabelStats
+	if err := json.Unmarshal(r, &stats); err != nil {
+		t.Fatalf("couldn't unmarshal stats response %q: %v\n", string(r), err)
+	}
+	return stats
+}
+
+// checks stats for a solid box of voxels.
+func checkBoxStats(t *testing.T, stats LabelStats, origin, size dvid.Point3d) {
+	numVoxels := uint64(size[0]) * uint64(size[1]) * uint64(size[2])
+	if stats.Voxels != numVoxels {
+		t.Fatalf("expected %d voxels for label %d, got %d\n", numVoxels, stats.Label, stats.Voxels)
+	}
+	maxVoxel := dvid.Point3d{origin[0] + size[0] - 1, origin[1] + size[1] - 1, origin[2] + size[2] - 1}
+	if stats.MinVoxel != origin || stats.MaxVoxel != maxVoxel {
+		t.Errorf("expected bounds %s to %s for label %d, got %s to %s\n", origin, maxVoxel, stats.Label, stats.MinVoxel, stats.MaxVoxel)
+	}
+	area := 2 * uint64(size[0]*size[1]+size[0]*size[2]+size[1]*size[2])
+	if stats.SurfaceArea != area {
+		t.Errorf("expected surface area %d for label %d, got %d\n", area, stats.Label, stats.SurfaceArea)
+	}
+	for i := 0; i < 3; i++ {
+		centroid := float64(origin[i]) + float64(size[i]-1)/2
+		if math.Abs(stats.Centroid[i]-centroid) > 1e-6 {
+			t.Errorf("expected centroid %f in dim %d for label %d, got %f\n", centroid, i, stats.Label, stats.Centroid[i])
+		}
+		n := float64(size[i])
+		variance := (n*n - 1) / 12
+		for j := 0; j < 3; j++ {
+			expected := 0.0
+			if i == j {
+				expected = variance
+			}
+			if math.Abs(stats.Covariance[i][j]-expected) > 1e-6 {
+				t.Errorf("expected covariance %f at (%d,%d) for label %d, got %f\n", expected, i, j, stats.Label, stats.Covariance[i][j])
+			}
+		}
+	}
+}
+
+func TestLabelStats(t *testing.T) {
+	if err := server.OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer server.CloseTest()
+
+	uuid, v := initTestRepo()
+	var config dvid.Config
+	server.CreateTestInstance(t, uuid, "labelmap", "labels", config)
+
+	// two boxes spanning blocks that touch along x.
+	vol := newTestVolume(128, 64, 64)
+	vol.addSubvol(dvid.Point3d{10, 20, 5}, dvid.Point3d{30, 40, 50}, 1)
+	vol.addSubvol(dvid.Point3d{40, 20, 5}, dvid.Point3d{40, 40, 50}, 2)
+	vol.put(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+
+	stats := getLabelStats(t, uuid, "labels", 1, false)
+	checkBoxStats(t, stats, dvid.Point3d{10, 20, 5}, dvid.Point3d{30, 40, 50})
+	expectedAxes := [3][3]float64{{0, 0, 1}, {0, 1, 0}, {1, 0, 0}}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if math.Abs(math.Abs(stats.PrincipalAxes[i][j])-expectedAxes[i][j]) > 1e-6 {
+				t.Fatalf("expected principal axes %v, got %v\n", expectedAxes, stats.PrincipalAxes)
+			}
+		}
+	}
+
+	// repeat queries use cache until label index changes.
+	d, err := GetByUUIDName(uuid, "labels")
+	if err != nil {
+		t.Fatalf("couldn't get labelmap data: %v\n", err)
+	}
+	ctx := datastore.NewVersionedCtx(d, v)
+	cached1, err := d.GetLabelStats(ctx, 1, 0, false)
+	if err != nil {
+		t.Fatalf("couldn't get stats for label 1: %v\n", err)
+	}
+	cached2, err := d.GetLabelStats(ctx, 1, 0, false)
+	if err != nil {
+		t.Fatalf("couldn't get stats for label 1: %v\n", err)
+	}
+	if cached1 != cached2 {
+		t.Errorf("expected repeated stats query to be cached\n")
+	}
+
+	// stats computed while the scale is being updated aren't cached.
+	d.StartScaleUpdate(0)
+	updating1, err := d.GetLabelStats(ctx, 1, 0, false)
+	if err != nil {
+		t.Fatalf("couldn't get stats for label 1: %v\n", err)
+	}
+	updating2, err := d.GetLabelStats(ctx, 1, 0, false)
+	d.StopScaleUpdate(0)
+	if err != nil {
+		t.Fatalf("couldn't get stats for label 1: %v\n", err)
+	}
+	if updating1 == nil || updating1 == cached1 || updating1 == updating2 {
+		t.Errorf("expected stats not to be cached during scale update\n")
+	}
+
+	testMerge := mergeJSON(`[1, 2]`)
+	testMerge.send(t, uuid, "labels")
+	if err := datastore.BlockOnUpdating(uuid, "labels"); err != nil {
+		t.Fatalf("Error blocking on sync of labels: %v\n", err)
+	}
+	merged := getLabelStats(t, uuid, "labels", 1, false)
+	checkBoxStats(t, merged, dvid.Point3d{10, 20, 5}, dvid.Point3d{70, 40, 50})
+	if merged.MutationID == stats.MutationID {
+		t.Errorf("expected mutation id to change after merge, got %d\n", merged.MutationID)
+	}
+	svStats := getLabelStats(t, uuid, "labels", 2, true)
+	checkBoxStats(t, svStats, dvid.Point3d{40, 20, 5}, dvid.Point3d{40, 40, 50})
+
+	// batch query returns null for missing labels.
+	reqStr := fmt.Sprintf("%snode/%s/labels/stats", server.WebAPIPath, uuid)
+	r := server.TestHTTP(t, "POST", reqStr, bytes.NewBufferString("[1, 2, 99]"))
+	var batch []*LabelStats
+	if err := json.Unmarshal(r, &batch); err != nil {
+		t.Fatalf("couldn't unmarshal batch stats response %q: %v\n", string(r), err)
+	}
+	if len(batch) != 3 || batch[0] == nil || batch[1] != nil || batch[2] != nil {
+		t.Fatalf("expected stats only for label 1 in batch response, got %s\n", string(r))
+	}
+	checkBoxStats(t, *batch[0], dvid.Point3d{10, 20, 5}, dvid.Point3d{70, 40, 50})
+
+	reqStr = fmt.Sprintf("%snode/%s/labels/stats/99", server.WebAPIPath, uuid)
+	if resp := server.TestHTTPResponse(t, "GET", reqStr, nil); resp.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for stats of missing label, got %d\n", resp.Code)
+	}
+}
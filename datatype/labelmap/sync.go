@@ -35,6 +35,16 @@ type MutatedBlock struct {
 	Data   *labels.Block
 }
 
+// DescribeChange adds the block coordinate and affected labels to a change feed event.
+func (b IngestedBlock) DescribeChange(e *datastore.ChangeEvent) {
+	labels.DescribeBlockChange(e, b.BCoord, b.Data)
+}
+
+// DescribeChange adds the block coordinate and affected labels to a change feed event.
+func (b MutatedBlock) DescribeChange(e *datastore.ChangeEvent) {
+	labels.DescribeBlockChange(e, b.BCoord, b.Prev, b.Data)
+}
+
 type procMsg struct {
 	v  dvid.VersionID
 	op interface{}
@@ -49,6 +49,9 @@ func (d *Data) PutLabels(v dvid.VersionID, subvol *dvid.Subvolume, data []byte,
 		return err
 	}
 
+	d.batchMu.RLock()
+	defer d.batchMu.RUnlock()
+
 	// Only do voxel-based mutations one at a time.  This lets us remove handling for block-level concurrency.
 	d.voxelMu.Lock()
 	defer d.voxelMu.Unlock()
@@ -228,7 +231,7 @@ func (d *Data) putChunk(op *putOperation, wg *sync.WaitGroup, putbuffer storage.
 			dvid.Errorf("data %q publishing downres: %v\n", d.DataName(), err)
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), event}
-		msg := datastore.SyncMessage{event, op.version, delta}
+		msg := datastore.SyncMessage{Event: event, Version: op.version, Delta: delta}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Errorf("Unable to notify subscribers of event %s in %s\n", event, d.DataName())
 		}
@@ -363,7 +366,7 @@ func (d *Data) writeBlocks(v dvid.VersionID, b storage.TKeyValues, wg1, wg2 *syn
 			block := IngestedBlock{mutID, indexZYX.ToIZYXString(), lblBlock}
 			d.handleBlockIndexing(v, blockCh, block)
 
-			msg := datastore.SyncMessage{labels.IngestBlockEvent, v, block}
+			msg := datastore.SyncMessage{Event: labels.IngestBlockEvent, Version: v, Delta: block}
 			if err := datastore.NotifySubscribers(evt, msg); err != nil {
 				dvid.Errorf("Unable to notify subscribers of ChangeBlockEvent in %s\n", d.DataName())
 				return
@@ -214,6 +214,66 @@ POST <api URL>/node/<UUID>/<data name>/reload
 	the denormalization is finished with a log message.
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Establishes the annotation data instances for which the label sizes are computed.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/count/{label}/{index_type}",
+		Summary: "Returns the count of the given index type for the given label.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+			{Name: "index_type", Description: "\"PostSyn\", \"PreSyn\", \"Gap\", \"Note\", \"AllSyn\", or \"Voxels\"."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/counts/{index_type}",
+		Summary: "Returns the count of the given index type for each label in the JSON array request body.",
+		PathParams: []datastore.RouteParam{
+			{Name: "index_type", Description: "\"PostSyn\", \"PreSyn\", \"Gap\", \"Note\", \"AllSyn\", or \"Voxels\"."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/top/{n}/{index_type}",
+		Summary: "Returns the top N labels with respect to the count of the given index type.",
+		PathParams: []datastore.RouteParam{
+			{Name: "n", Type: "integer", Description: "Number of labels to return."},
+			{Name: "index_type", Description: "\"PostSyn\", \"PreSyn\", \"Gap\", \"Note\", \"AllSyn\", or \"Voxels\"."},
+		},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/threshold/{t}/{index_type}",
+		Summary: "Returns up to 10,000 labels with a count of the given index type at or above a threshold.",
+		PathParams: []datastore.RouteParam{
+			{Name: "t", Type: "integer", Description: "Minimum count."},
+			{Name: "index_type", Description: "\"PostSyn\", \"PreSyn\", \"Gap\", \"Note\", \"AllSyn\", or \"Voxels\"."},
+		},
+		Query: []datastore.RouteParam{
+			{Name: "offset", Type: "integer", Description: "Starting rank in the descending list of labels."},
+			{Name: "n", Type: "integer", Description: "Number of labels to return."},
+		},
+		Response: []string{"application/json"},
+	},
+	{Method: "POST", Path: "/reload", Summary: "Forces asynchronous denormalization from the synced annotation instance."},
+}
+
 var (
 	dtype *Type
 )
@@ -236,6 +296,7 @@ func init() {
 	// See doc for package on why channels are segregated instead of interleaved.
 	// Data types must be registered with the datastore to be used.
 	datastore.Register(dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
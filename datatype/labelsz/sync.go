@@ -95,12 +95,15 @@ func (d *Data) processEvents() {
 		case msg := <-d.syncCh:
 			d.StartUpdate()
 			ctx := datastore.NewVersionedCtx(d, msg.Version)
+			span := msg.StartSpan(d)
+			ctx.SetSpan(span)
 			switch delta := msg.Delta.(type) {
 			case annotation.DeltaModifyElements:
 				d.modifyElements(ctx, delta, batcher)
 			default:
 				dvid.Criticalf("Cannot sync annotations from modify element.  Got unexpected delta: %v\n", msg)
 			}
+			span.End()
 			d.StopUpdate()
 
 			if stop && len(d.syncCh) == 0 {
@@ -229,7 +232,7 @@ func (d *Data) modifyElements(ctx *datastore.VersionedCtx, delta annotation.Delt
 			successful = false
 		}
 	}
-	if server.KafkaAvailable() {
+	if server.KafkaAvailable() || storage.EventSinksAvailable() {
 		t := time.Since(t0)
 		activity := map[string]interface{}{
 			"time":       t0.Unix(),
@@ -377,6 +377,117 @@ DELETE <api URL>/node/<UUID>/<data name>/area/<label>/<size>/<offset>
 	that is 512 x 512 x 512 starting at offset (0,0,128).
 `
 
+// optional bounds on the sparse volume endpoints.
+var routeBounds = []datastore.RouteParam{
+	{Name: "minx", Type: "integer", Description: "Spans must be equal to or larger than this minimum x voxel coordinate."},
+	{Name: "maxx", Type: "integer", Description: "Spans must be equal to or smaller than this maximum x voxel coordinate."},
+	{Name: "miny", Type: "integer", Description: "Spans must be equal to or larger than this minimum y voxel coordinate."},
+	{Name: "maxy", Type: "integer", Description: "Spans must be equal to or smaller than this maximum y voxel coordinate."},
+	{Name: "minz", Type: "integer", Description: "Spans must be equal to or larger than this minimum z voxel coordinate."},
+	{Name: "maxz", Type: "integer", Description: "Spans must be equal to or smaller than this maximum z voxel coordinate."},
+}
+
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Establishes labelblk data instances with which the label volumes are synced.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/sparsevol/{label}",
+		Summary:    "Returns a sparse volume with voxels of the given label in encoded RLE format.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Query: append(append([]datastore.RouteParam{}, routeBounds...),
+			datastore.RouteParam{Name: "exact", Type: "boolean", Description: "If false, RLEs can extend outside the bounds within border blocks."},
+			datastore.RouteParam{Name: "compression", Description: "\"lz4\" or \"gzip\" compression of the returned data."},
+		),
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:     "HEAD",
+		Path:       "/sparsevol/{label}",
+		Summary:    "Returns 200 if the label has voxels within the optional block-aligned bounds, 204 if not, and 404 if the label doesn't exist.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Query:      routeBounds,
+	},
+	{
+		Method:     "GET",
+		Path:       "/sparsevol-by-point/{coord}",
+		Summary:    "Returns a sparse volume in encoded RLE format of the label at the given voxel.",
+		PathParams: []datastore.RouteParam{{Name: "coord", Description: "Voxel coordinate, e.g., \"10_20_30\"."}},
+		Response:   []string{"application/octet-stream"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/sparsevol-coarse/{label}",
+		Summary:    "Returns a sparse volume with blocks of the given label in encoded RLE format.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label."}},
+		Response:   []string{"application/octet-stream"},
+	},
+	{Method: "GET", Path: "/maxlabel", Summary: "Returns the maximum label for the version.", Response: []string{"application/json"}},
+	{Method: "GET", Path: "/nextlabel", Summary: "Returns the next label for the version.", Response: []string{"application/json"}},
+	{
+		Method:   "POST",
+		Path:     "/nextlabel",
+		Summary:  "Reserves the number of labels given in the JSON request body and returns the reserved range.",
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/merge",
+		Summary: "Merges labels given as a JSON array [toLabel, fromLabel1, fromLabel2, ...].",
+		Request: []string{"application/json"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/split/{label}",
+		Summary:    "Splits the voxels in the binary sparse volume request body from a label and returns the new label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "Label to split."}},
+		Query: []datastore.RouteParam{
+			{Name: "splitlabel", Type: "integer", Description: "Label to use for the split voxels instead of a new label."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/split-coarse/{label}",
+		Summary:    "Splits the blocks in the coarse sparse volume request body from a label and returns the new label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "Label to split."}},
+		Query: []datastore.RouteParam{
+			{Name: "splitlabel", Type: "integer", Description: "Label to use for the split blocks instead of a new label."},
+		},
+		Request:  []string{"application/octet-stream"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/resync/{label}",
+		Summary:    "Regenerates the label's sparse volume from its synced labelblk within the coarse sparse volume request body.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "Label to resync."}},
+		Request:    []string{"application/octet-stream"},
+	},
+	{
+		Method:  "DELETE",
+		Path:    "/area/{label}/{size}/{offset}",
+		Summary: "Deletes a label's sparse volume within a subvolume without honoring syncs.",
+		PathParams: []datastore.RouteParam{
+			{Name: "label", Type: "integer", Description: "A label."},
+			{Name: "size", Description: "Size in voxels, e.g., \"512_512_512\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"0_0_128\"."},
+		},
+	},
+}
+
 var (
 	dtype *Type
 
@@ -399,6 +510,7 @@ func init() {
 	// See doc for package on why channels are segregated instead of interleaved.
 	// Data types must be registered with the datastore to be used.
 	datastore.Register(dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
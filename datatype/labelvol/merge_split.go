@@ -62,7 +62,7 @@ func (d *Data) MergeLabels(v dvid.VersionID, m labels.MergeOp) error {
 
 	// Signal that we are starting a merge.
 	evt := datastore.SyncEvent{d.DataUUID(), labels.MergeStartEvent}
-	msg := datastore.SyncMessage{labels.MergeStartEvent, v, labels.DeltaMergeStart{m}}
+	msg := datastore.SyncMessage{Event: labels.MergeStartEvent, Version: v, Delta: labels.DeltaMergeStart{m}}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		d.StopUpdate()
 		return err
@@ -131,7 +131,7 @@ func (d *Data) asyncMergeLabels(v dvid.VersionID, m labels.MergeOp) {
 			OldKnown: true,
 		}
 		evt := datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-		msg := datastore.SyncMessage{labels.ChangeSizeEvent, v, delta}
+		msg := datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: delta}
 		if err := datastore.NotifySubscribers(evt, msg); err != nil {
 			dvid.Criticalf("can't notify subscribers for event %v: %v\n", evt, err)
 		}
@@ -167,7 +167,7 @@ func (d *Data) asyncMergeLabels(v dvid.VersionID, m labels.MergeOp) {
 
 	// Publish block-level merge
 	evt := datastore.SyncEvent{d.DataUUID(), labels.MergeBlockEvent}
-	msg := datastore.SyncMessage{labels.MergeBlockEvent, v, labels.DeltaMerge{MergeOp: m, BlockMap: blocksChanged}}
+	msg := datastore.SyncMessage{Event: labels.MergeBlockEvent, Version: v, Delta: labels.DeltaMerge{MergeOp: m, BlockMap: blocksChanged}}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
@@ -192,13 +192,13 @@ func (d *Data) asyncMergeLabels(v dvid.VersionID, m labels.MergeOp) {
 		NewSize: toLabelSize + addedVoxels,
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-	msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, delta}
+	msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: delta}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
 
 	evt = datastore.SyncEvent{d.DataUUID(), labels.MergeEndEvent}
-	msg = datastore.SyncMessage{labels.MergeEndEvent, v, labels.DeltaMergeEnd{m}}
+	msg = datastore.SyncMessage{Event: labels.MergeEndEvent, Version: v, Delta: labels.DeltaMergeEnd{m}}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		dvid.Errorf("can't notify subscribers for event %v: %v\n", evt, err)
 	}
@@ -254,7 +254,7 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel, splitLabel uint64, r io.
 	defer labels.SplitStop(d.getMergeIV(v), splitOpEnd)
 
 	// Signal that we are starting a split.
-	msg := datastore.SyncMessage{labels.SplitStartEvent, v, splitOpStart}
+	msg := datastore.SyncMessage{Event: labels.SplitStartEvent, Version: v, Delta: splitOpStart}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -300,7 +300,7 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel, splitLabel uint64, r io.
 	}
 
 	evt = datastore.SyncEvent{d.DataUUID(), labels.SplitLabelEvent}
-	msg = datastore.SyncMessage{labels.SplitLabelEvent, v, deltaSplit}
+	msg = datastore.SyncMessage{Event: labels.SplitLabelEvent, Version: v, Delta: deltaSplit}
 	if err = datastore.NotifySubscribers(evt, msg); err != nil {
 		return
 	}
@@ -362,7 +362,7 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel, splitLabel uint64, r io.
 		Size:  toLabelSize,
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-	msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, delta}
+	msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: delta}
 	if err = datastore.NotifySubscribers(evt, msg); err != nil {
 		return
 	}
@@ -372,14 +372,14 @@ func (d *Data) SplitLabels(v dvid.VersionID, fromLabel, splitLabel uint64, r io.
 		SizeChange: int64(-toLabelSize),
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-	msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, delta2}
+	msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: delta2}
 	if err = datastore.NotifySubscribers(evt, msg); err != nil {
 		return
 	}
 
 	// Publish split end
 	evt = datastore.SyncEvent{d.DataUUID(), labels.SplitEndEvent}
-	msg = datastore.SyncMessage{labels.SplitEndEvent, v, splitOpEnd}
+	msg = datastore.SyncMessage{Event: labels.SplitEndEvent, Version: v, Delta: splitOpEnd}
 	if err = datastore.NotifySubscribers(evt, msg); err != nil {
 		return
 	}
@@ -434,7 +434,7 @@ func (d *Data) SplitCoarseLabels(v dvid.VersionID, fromLabel, splitLabel uint64,
 	defer labels.SplitStop(d.getMergeIV(v), splitOpEnd)
 
 	// Signal that we are starting a split.
-	msg := datastore.SyncMessage{labels.SplitStartEvent, v, splitOpStart}
+	msg := datastore.SyncMessage{Event: labels.SplitStartEvent, Version: v, Delta: splitOpStart}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -518,7 +518,7 @@ func (d *Data) SplitCoarseLabels(v dvid.VersionID, fromLabel, splitLabel uint64,
 		SplitVoxels:  toLabelSize,
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.SplitLabelEvent}
-	msg = datastore.SyncMessage{labels.SplitLabelEvent, v, deltaSplit}
+	msg = datastore.SyncMessage{Event: labels.SplitLabelEvent, Version: v, Delta: deltaSplit}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -529,7 +529,7 @@ func (d *Data) SplitCoarseLabels(v dvid.VersionID, fromLabel, splitLabel uint64,
 		Size:  toLabelSize,
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-	msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, delta}
+	msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: delta}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -539,14 +539,14 @@ func (d *Data) SplitCoarseLabels(v dvid.VersionID, fromLabel, splitLabel uint64,
 		SizeChange: int64(-toLabelSize),
 	}
 	evt = datastore.SyncEvent{d.DataUUID(), labels.ChangeSizeEvent}
-	msg = datastore.SyncMessage{labels.ChangeSizeEvent, v, delta2}
+	msg = datastore.SyncMessage{Event: labels.ChangeSizeEvent, Version: v, Delta: delta2}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
 
 	// Publish split end
 	evt = datastore.SyncEvent{d.DataUUID(), labels.SplitEndEvent}
-	msg = datastore.SyncMessage{labels.SplitEndEvent, v, splitOpEnd}
+	msg = datastore.SyncMessage{Event: labels.SplitEndEvent, Version: v, Delta: splitOpEnd}
 	if err := datastore.NotifySubscribers(evt, msg); err != nil {
 		return 0, err
 	}
@@ -112,6 +112,36 @@ POST <api URL>/node/<UUID>/<data name>/<dims>/<size>/<offset>[/<format>]
 
 `
 
+// machine-readable description of the HTTP API above.  Only GET of 2d images is
+// currently supported for voxel data.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:  "GET",
+		Path:    "/{dims}/{size}/{offset}",
+		Summary: "Returns an image of one channel, chosen by a numerical suffix on the data name.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in pixels in the format \"dx_dy\"."},
+			{Name: "offset", Description: "Voxel coordinate of the upper left voxel in the format \"x_y_z\"."},
+		},
+		Response: []string{"image/png"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/{dims}/{size}/{offset}/{format}",
+		Summary: "Returns an image of one channel, chosen by a numerical suffix on the data name, in the given format.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the image, e.g., \"0_2\" or \"xz\"."},
+			{Name: "size", Description: "Size in pixels in the format \"dx_dy\"."},
+			{Name: "offset", Description: "Voxel coordinate of the upper left voxel in the format \"x_y_z\"."},
+			{Name: "format", Description: "\"png\" or \"jpg\", which allows a quality setting, e.g., \"jpg:80\"."},
+		},
+		Response: []string{"image/png", "image/jpeg"},
+	},
+}
+
 // DefaultBlockMax specifies the default size for each block of this data type.
 var (
 	DefaultBlockSize int32 = 32
@@ -146,6 +176,7 @@ func init() {
 	// Data types must be registered with the datastore to be used.
 	typeService = &dtype
 	datastore.Register(&dtype)
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
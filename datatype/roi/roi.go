@@ -173,8 +173,56 @@ GET  <api URL>/node/<UUID>/<data name>/erode/<element size>
 
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:   "GET",
+		Path:     "/roi",
+		Summary:  "Returns the ROI as a JSON list of [z, y, x0, x1] block spans.",
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/roi",
+		Summary: "Stores the JSON list of [z, y, x0, x1] block spans in the request body in the ROI.",
+		Request: []string{"application/json"},
+	},
+	{Method: "DELETE", Path: "/roi", Summary: "Deletes the ROI."},
+	{
+		Method:  "GET",
+		Path:    "/mask/{dims}/{size}/{offset}",
+		Summary: "Returns a binary volume in ZYX order with non-zero elements for voxels within the ROI.",
+		PathParams: []datastore.RouteParam{
+			{Name: "dims", Description: "Axes of the volume.  Only \"0_1_2\" is supported."},
+			{Name: "size", Description: "Size in voxels, e.g., \"512_512_256\"."},
+			{Name: "offset", Description: "Voxel coordinate of the first voxel, e.g., \"100_200_300\"."},
+		},
+		Response: []string{"application/octet-stream"},
+	},
+	{
+		Method:   "POST",
+		Path:     "/ptquery",
+		Summary:  "Returns whether each point in the JSON list of voxel coordinates in the request body is within the ROI.",
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/partition",
+		Summary: "Returns JSON of subvolumes that are batchsize^3 blocks in volume and cover the ROI.",
+		Query: []datastore.RouteParam{
+			{Name: "batchsize", Type: "integer", Required: true, Description: "Number of blocks along each axis of a subvolume."},
+			{Name: "optimized", Type: "boolean", Description: "If true, subvolumes have non-fixed sizes with better coverage."},
+		},
+		Response: []string{"application/json"},
+	},
+}
+
 func init() {
 	datastore.Register(NewType())
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -208,8 +208,80 @@ POST <api URL>/node/<UUID>/<data name>/load
 
 `
 
+// machine-readable description of the HTTP API above.
+var routes = []datastore.Route{
+	{Method: "GET", Path: "/help", Summary: "Returns data-specific help message.", Response: []string{"text/plain"}},
+	{Method: "GET", Path: "/info", Summary: "Returns configuration settings.", Response: []string{"application/json"}},
+	{
+		Method:  "POST",
+		Path:    "/sync",
+		Summary: "Establishes the labelmap whose supervoxel mapping is used.",
+		Query: []datastore.RouteParam{
+			{Name: "replace", Type: "boolean", Description: "If true, passed syncs replace rather than append to current syncs."},
+		},
+		Request: []string{"application/json"},
+	},
+	{
+		Method:     "GET",
+		Path:       "/supervoxel/{id}",
+		Summary:    "Returns the data stored for a supervoxel.",
+		PathParams: []datastore.RouteParam{{Name: "id", Type: "integer", Description: "A supervoxel id."}},
+		Response:   []string{"application/octet-stream"},
+	},
+	{
+		Method:     "POST",
+		Path:       "/supervoxel/{id}",
+		Summary:    "Stores the request body as the data for a supervoxel.",
+		PathParams: []datastore.RouteParam{{Name: "id", Type: "integer", Description: "A supervoxel id."}},
+		Request:    []string{"application/octet-stream"},
+	},
+	{
+		Method:     "DELETE",
+		Path:       "/supervoxel/{id}",
+		Summary:    "Deletes the data stored for a supervoxel.",
+		PathParams: []datastore.RouteParam{{Name: "id", Type: "integer", Description: "A supervoxel id."}},
+	},
+	{
+		Method:     "GET",
+		Path:       "/tarfile/{label}",
+		Summary:    "Returns a tarfile of the data for all supervoxels mapped to the given label.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label (body) id."}},
+		Response:   []string{"application/tar"},
+	},
+	{
+		Method:     "HEAD",
+		Path:       "/tarfile/{label}",
+		Summary:    "Returns 200 if the label exists and all its supervoxels have stored data.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label (body) id."}},
+	},
+	{
+		Method:     "GET",
+		Path:       "/missing/{label}",
+		Summary:    "Returns a JSON array of the label's supervoxels with missing data.",
+		PathParams: []datastore.RouteParam{{Name: "label", Type: "integer", Description: "A label (body) id."}},
+		Response:   []string{"application/json"},
+	},
+	{
+		Method:  "GET",
+		Path:    "/exists",
+		Summary: "Returns whether data is stored for each supervoxel in the JSON array request body.",
+		Query: []datastore.RouteParam{
+			{Name: "hash", Description: "MD5 hash of request body content in hexadecimal string format."},
+		},
+		Request:  []string{"application/json"},
+		Response: []string{"application/json"},
+	},
+	{
+		Method:  "POST",
+		Path:    "/load",
+		Summary: "Bulk loads a tarfile with files named by supervoxel id.",
+		Request: []string{"application/tar"},
+	},
+}
+
 func init() {
 	datastore.Register(NewType())
+	datastore.RegisterRoutes(TypeName, routes...)
 
 	// Need to register types that will be used to fulfill interfaces.
 	gob.Register(&Type{})
@@ -10,8 +10,11 @@ import (
 	"time"
 )
 
-// global email config
-var emailCfg EmailConfig
+// global email config, which can be replaced on configuration reload.
+var (
+	emailCfg   EmailConfig
+	emailCfgMu sync.RWMutex
+)
 
 type sentTimes struct {
 	topics map[string]time.Time
@@ -56,17 +59,22 @@ type emailData struct {
 
 // SetEmailServer sets the email server used for all subsequent SendEmail calls.
 func SetEmailServer(e EmailConfig) {
+	emailCfgMu.Lock()
 	emailCfg = e
+	emailCfgMu.Unlock()
 }
 
 // SendEmail sends e-mail to the given recipients or the default emails loaded
 // during configuration.  If a "periodicTopic" is set, then only one email per
 // ten minutes is sent for that particular topic.
 func SendEmail(subject, message string, recipients []string, periodicTopic string) error {
-	if !emailCfg.IsAvailable() {
+	emailCfgMu.RLock()
+	e := emailCfg
+	emailCfgMu.RUnlock()
+	if !e.IsAvailable() {
 		return nil
 	}
-	return emailCfg.sendEmail(subject, message, recipients, periodicTopic)
+	return e.sendEmail(subject, message, recipients, periodicTopic)
 }
 
 // Go template
@@ -5,15 +5,22 @@ package dvid
 import (
 	"fmt"
 	"log"
+	"os"
+	"sync"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-type stdLogger struct {
-	*lumberjack.Logger
-}
+type stdLogger struct{}
+
+var (
+	logger stdLogger
 
-var logger stdLogger
+	// logFile is the rotating log file, if any.  Writes hold a read lock so SetLogger can
+	// close a replaced log file once no writes are in flight.
+	logFile  *lumberjack.Logger
+	loggerMu sync.RWMutex
+)
 
 type LogConfig struct {
 	Logfile string
@@ -21,67 +28,72 @@ type LogConfig struct {
 	MaxAge  int `toml:"max_log_age"`
 }
 
-// SetLogger creates a logger that saves to a rotating log file.
+// SetLogger creates a logger that saves to a rotating log file.  It can be called again
+// to change the log file or its rotation settings, e.g., on reload of the configuration.
 func (c *LogConfig) SetLogger() {
+	var l *lumberjack.Logger
 	if c == nil || c.Logfile == "" {
 		Infof("Sending log messages to stdout since no log file specified.")
-		return
+	} else {
+		fmt.Printf("Sending log messages to: %s\n", c.Logfile)
+		l = &lumberjack.Logger{
+			Filename: c.Logfile,
+			MaxSize:  c.MaxSize, // megabytes
+			MaxAge:   c.MaxAge,  //days
+		}
 	}
-	fmt.Printf("Sending log messages to: %s\n", c.Logfile)
-	l := &lumberjack.Logger{
-		Filename: c.Logfile,
-		MaxSize:  c.MaxSize, // megabytes
-		MaxAge:   c.MaxAge,  //days
+
+	// Switch all writers to the new output before closing the old log file.
+	if l != nil {
+		log.SetOutput(l)
+	} else {
+		log.SetOutput(os.Stderr)
+	}
+	loggerMu.Lock()
+	old := logFile
+	logFile = l
+	loggerMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
+
+// write sends a message to the log file or, if none is set, the standard logger.
+func (slog stdLogger) write(s string) {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	if logFile != nil {
+		logFile.Write([]byte(s))
+	} else {
+		log.Printf(s)
 	}
-	log.SetOutput(l)
-	logger = stdLogger{l}
 }
 
 // --- Logger implementation ----
 
 // Debug writes directly to logger at DEBUG level.
 func (slog stdLogger) Debug(s string) {
-	if logger.Logger != nil {
-		logger.Write([]byte(" DEBUG " + s))
-	} else {
-		log.Printf(" DEBUG " + s)
-	}
+	slog.write(" DEBUG " + s)
 }
 
 // Info writes directly to logger at INFO level
 func (slog stdLogger) Info(s string) {
-	if logger.Logger != nil {
-		logger.Write([]byte(" INFO " + s))
-	} else {
-		log.Printf(" INFO " + s)
-	}
+	slog.write(" INFO " + s)
 }
 
 // Warning writes directly to logger at INFO level
 func (slog stdLogger) Warning(s string) {
-	if logger.Logger != nil {
-		logger.Write([]byte(" WARNING " + s))
-	} else {
-		log.Printf(" WARNING " + s)
-	}
+	slog.write(" WARNING " + s)
 }
 
 // Error writes directly to logger at ERROR level
 func (slog stdLogger) Error(s string) {
-	if logger.Logger != nil {
-		logger.Write([]byte(" ERROR " + s))
-	} else {
-		log.Printf(" ERROR " + s)
-	}
+	slog.write(" ERROR " + s)
 }
 
 // Critical writes directly to logger at CRITICAL level
 func (slog stdLogger) Critical(s string) {
-	if logger.Logger != nil {
-		logger.Write([]byte(" CRITICAL " + s))
-	} else {
-		log.Printf(" CRITICAL " + s)
-	}
+	slog.write(" CRITICAL " + s)
 }
 
 // Debugf formats its arguments analogous to fmt.Printf and records the text as a log
@@ -113,7 +125,9 @@ func (slog stdLogger) Criticalf(format string, args ...interface{}) {
 
 func (slog stdLogger) Shutdown() {
 	log.Printf("Closing log file...\n")
-	if slog.Logger != nil {
-		slog.Close()
+	loggerMu.Lock()
+	if logFile != nil {
+		logFile.Close()
 	}
+	loggerMu.Unlock()
 }
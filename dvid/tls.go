@@ -0,0 +1,214 @@
+/*
+	This file supports TLS for DVID servers and clients, with certificates that are
+	reloaded when their files change so they can be rotated without a restart.
+*/
+
+package dvid
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// minimum time between checks of certificate files for changes.
+const certReloadInterval = 10 * time.Second
+
+// TLSConfig specifies PEM files for encrypting connections.
+type TLSConfig struct {
+	CertFile     string // certificate chain presented by this server or client
+	KeyFile      string // private key for the certificate
+	ClientCAFile string // CAs for verifying client certificates.  If set, servers require client certificates.
+	CAFile       string // CAs for verifying servers.  If empty, the system roots are used.
+}
+
+// Enabled returns true if a certificate is configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// ServerConfig returns a TLS configuration for servers that supports HTTP/2 and picks up
+// changes to the certificate, key, and client CA files.
+func (c TLSConfig) ServerConfig() (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, fmt.Errorf("TLS requires both a certificate and a key file")
+	}
+	r, err := newCertReloader(c)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _, _ := r.current()
+			return cert, nil
+		},
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, clientCAs, _ := r.current()
+		hcfg := &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			NextProtos:   cfg.NextProtos,
+			Certificates: []tls.Certificate{*cert},
+		}
+		if clientCAs != nil {
+			hcfg.ClientCAs = clientCAs
+			hcfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return hcfg, nil
+	}
+	return cfg, nil
+}
+
+// ClientConfig returns a TLS configuration for clients that verifies servers using the
+// CA file, if any, and presents the certificate, if any, for mutual TLS.
+func (c TLSConfig) ClientConfig() (*tls.Config, error) {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, fmt.Errorf("TLS client certificate requires both a certificate and a key file")
+	}
+	r, err := newCertReloader(c)
+	if err != nil {
+		return nil, err
+	}
+	return r.clientConfig(), nil
+}
+
+// clientConfig returns a client TLS configuration using the reloader's certificates.
+// Since the CA pool of a tls.Config is fixed, servers are verified against the current
+// CA pool on each handshake in place of the default verification.
+func (r *certReloader) clientConfig() *tls.Config {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if r.cfg.CAFile != "" {
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("server presented no certificate")
+			}
+			_, _, rootCAs := r.current()
+			opts := x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Roots:         rootCAs,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	}
+	if r.cfg.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _, _ := r.current()
+			return cert, nil
+		}
+	}
+	return cfg
+}
+
+// certReloader holds certificates loaded from files and reloads them when the files
+// are modified.
+type certReloader struct {
+	cfg TLSConfig
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	rootCAs   *x509.CertPool
+	modTime   time.Time // latest modification time of the loaded files
+	lastCheck time.Time
+}
+
+func newCertReloader(cfg TLSConfig) (*certReloader, error) {
+	r := &certReloader{cfg: cfg, lastCheck: time.Now()}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, filename := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile, r.cfg.CAFile} {
+		if filename == "" {
+			continue
+		}
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return latest, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func loadCertPool(filename string) (*x509.CertPool, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	pem, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %q", filename)
+	}
+	return pool, nil
+}
+
+// load reads all files, only replacing the current certificates if all can be read.
+func (r *certReloader) load() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	var cert *tls.Certificate
+	if r.cfg.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load TLS key pair (%s, %s): %v", r.cfg.CertFile, r.cfg.KeyFile, err)
+		}
+		cert = &c
+	}
+	clientCAs, err := loadCertPool(r.cfg.ClientCAFile)
+	if err != nil {
+		return fmt.Errorf("unable to load client CAs: %v", err)
+	}
+	rootCAs, err := loadCertPool(r.cfg.CAFile)
+	if err != nil {
+		return fmt.Errorf("unable to load CAs: %v", err)
+	}
+	r.cert, r.clientCAs, r.rootCAs = cert, clientCAs, rootCAs
+	r.modTime = modTime
+	return nil
+}
+
+// current returns the certificates after reloading them if their files have changed.
+// Files are checked at most once per certReloadInterval, and if they can't be reloaded,
+// the previous certificates are kept.
+func (r *certReloader) current() (cert *tls.Certificate, clientCAs, rootCAs *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := time.Now(); now.Sub(r.lastCheck) >= certReloadInterval {
+		r.lastCheck = now
+		modTime, err := r.latestModTime()
+		if err != nil {
+			Errorf("unable to check TLS certificate files: %v\n", err)
+		} else if modTime.After(r.modTime) {
+			if err := r.load(); err != nil {
+				Errorf("keeping previous TLS certificates: %v\n", err)
+			} else {
+				Infof("Reloaded TLS certificates from %s\n", r.cfg.CertFile)
+			}
+		}
+	}
+	return r.cert, r.clientCAs, r.rootCAs
+}
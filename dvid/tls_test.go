@@ -0,0 +1,238 @@
+package dvid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a PEM certificate and key signed by the parent, or self-signed if the
+// parent is nil, and returns the certificate and key.
+func writeCert(t *testing.T, dir, name string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func handshake(t *testing.T, serverCfg, clientCfg *tls.Config) (*x509.Certificate, error) {
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", serverCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.(*tls.Conn).Handshake()
+		conn.Close()
+	}()
+	conn, err := tls.Dial("tcp", ln.Addr().String(), clientCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// TLS 1.3 clients learn of a rejected certificate on first read.
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil && err != io.EOF {
+		if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+			return nil, err
+		}
+	}
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := writeCert(t, dir, "ca", true, nil, nil)
+	writeCert(t, dir, "server", false, ca, caKey)
+	writeCert(t, dir, "client", false, ca, caKey)
+	writeCert(t, dir, "rogue", false, nil, nil)
+
+	serverCfg, err := TLSConfig{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}.ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCfg, err := TLSConfig{
+		CertFile: filepath.Join(dir, "client.crt"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := handshake(t, serverCfg, clientCfg)
+	if err != nil {
+		t.Fatalf("expected mutual TLS handshake to succeed: %v\n", err)
+	}
+	if peer.Subject.CommonName != "server" {
+		t.Fatalf("expected server certificate, got %q\n", peer.Subject.CommonName)
+	}
+
+	rogueCfg, err := TLSConfig{
+		CertFile: filepath.Join(dir, "rogue.crt"),
+		KeyFile:  filepath.Join(dir, "rogue.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handshake(t, serverCfg, rogueCfg); err == nil {
+		t.Fatalf("expected client with untrusted certificate to be rejected\n")
+	}
+
+	if _, err := (TLSConfig{CertFile: filepath.Join(dir, "server.crt")}).ServerConfig(); err == nil {
+		t.Fatalf("expected error for server config without key\n")
+	}
+}
+
+func TestCertReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first, _ := writeCert(t, dir, "server", false, nil, nil)
+	r, err := newCertReloader(TLSConfig{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := writeCert(t, dir, "server", false, nil, nil)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, "server.crt"), future, future)
+
+	serial := func(cert *tls.Certificate) *big.Int {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber
+	}
+	cert, _, _ := r.current()
+	if serial(cert).Cmp(first.SerialNumber) != 0 {
+		t.Fatalf("expected certificate to not be reloaded before check interval\n")
+	}
+	r.lastCheck = time.Now().Add(-certReloadInterval)
+	cert, _, _ = r.current()
+	if serial(cert).Cmp(second.SerialNumber) != 0 {
+		t.Fatalf("expected reloaded certificate after files changed\n")
+	}
+
+	// A bad file keeps the previous certificate.
+	if err := ioutil.WriteFile(filepath.Join(dir, "server.key"), []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := future.Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, "server.key"), later, later)
+	r.lastCheck = time.Now().Add(-certReloadInterval)
+	if cert2, _, _ := r.current(); cert2 != cert {
+		t.Fatalf("expected previous certificate to be kept after failed reload\n")
+	}
+}
+
+func TestClientCAReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := writeCert(t, dir, "ca", true, nil, nil)
+	writeCert(t, dir, "server", false, ca, caKey)
+	writeCert(t, dir, "roots", true, nil, nil)
+
+	serverCfg, err := TLSConfig{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	}.ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newCertReloader(TLSConfig{CAFile: filepath.Join(dir, "roots.crt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCfg := r.clientConfig()
+	if _, err := handshake(t, serverCfg, clientCfg); err == nil {
+		t.Fatalf("expected server signed by untrusted CA to be rejected\n")
+	}
+
+	// Rotating the CA file lets the same client configuration trust the server.
+	caPEM, err := ioutil.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "roots.crt"), caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, "roots.crt"), future, future)
+	r.lastCheck = time.Now().Add(-certReloadInterval)
+	if _, err := handshake(t, serverCfg, clientCfg); err != nil {
+		t.Fatalf("expected server to be trusted after CA reload: %v\n", err)
+	}
+}
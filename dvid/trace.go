@@ -0,0 +1,408 @@
+/*
+	This file provides OpenTelemetry-style request tracing.  Spans are started by the HTTP
+	server and passed down via request contexts, DataContexts and sync messages to
+	datastore, storage, sync and downres operations.  Finished spans are batched and sent
+	to a pluggable SpanExporter.
+*/
+
+package dvid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	mrand "math/rand"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maximum number of finished spans waiting for export before new spans are dropped.
+	spanQueueSize = 8192
+
+	// maximum number of spans sent to an exporter at one time.
+	spanBatchSize = 512
+
+	// maximum time a finished span waits before export.
+	spanFlushInterval = time.Second
+)
+
+// TracingConfig specifies the export of request traces.
+type TracingConfig struct {
+	Exporter   string            // "stdout", "file", or "otlp".  If empty, tracing is disabled.
+	Path       string            // file of JSON spans, one per line, for the "file" exporter
+	Endpoint   string            // OTLP/HTTP traces URL, e.g., "http://localhost:4318/v1/traces"
+	Headers    map[string]string // extra HTTP headers for the "otlp" exporter
+	Service    string            // service name for the "otlp" exporter.  Default is "dvid".
+	SampleRate float64           // fraction of new traces recorded.  Zero value = 1.0.
+}
+
+// Initialize starts tracing with the configured exporter.
+func (c TracingConfig) Initialize() error {
+	var exporter SpanExporter
+	switch strings.ToLower(c.Exporter) {
+	case "":
+		return nil
+	case "stdout":
+		exporter = NewJSONSpanExporter(nopWriteCloser{os.Stdout})
+	case "file":
+		if c.Path == "" {
+			return fmt.Errorf("tracing with file exporter requires a path")
+		}
+		f, err := os.OpenFile(c.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("unable to open trace file %q: %v", c.Path, err)
+		}
+		exporter = NewJSONSpanExporter(f)
+	case "otlp":
+		if c.Endpoint == "" {
+			return fmt.Errorf("tracing with otlp exporter requires an endpoint")
+		}
+		exporter = NewOTLPSpanExporter(c.Endpoint, c.Service, c.Headers)
+	default:
+		return fmt.Errorf("unknown trace exporter %q", c.Exporter)
+	}
+	sampleRate := c.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 1
+	}
+	SetSpanExporter(exporter, sampleRate)
+	Infof("Exporting traces via %s exporter with sample rate %g\n", c.Exporter, sampleRate)
+	return nil
+}
+
+// SpanData is the record of a finished span sent to exporters.
+type SpanData struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Duration   float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// SpanExporter sends finished spans to a tracing backend.
+type SpanExporter interface {
+	ExportSpans(spans []SpanData) error
+	Shutdown() error
+}
+
+// TraceSpan is a timed operation within a trace.  All methods can be called on a nil TraceSpan,
+// which is returned when tracing is disabled or a trace is not sampled, so callers
+// need not check whether tracing is active.
+type TraceSpan struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+
+	mu    sync.Mutex
+	attrs map[string]interface{}
+	err   string
+	ended bool
+}
+
+var tracer struct {
+	sync.RWMutex
+	exporter   SpanExporter
+	sampleRate float64
+	queue      chan SpanData
+	done       chan struct{}
+	dropped    uint64
+}
+
+// SetSpanExporter starts tracing with the given exporter, sampling the given fraction of
+// new traces.  Any previous exporter is shut down after its pending spans are exported.
+func SetSpanExporter(exporter SpanExporter, sampleRate float64) {
+	ShutdownTracing()
+	queue := make(chan SpanData, spanQueueSize)
+	done := make(chan struct{})
+	tracer.Lock()
+	tracer.exporter = exporter
+	tracer.sampleRate = sampleRate
+	tracer.queue = queue
+	tracer.done = done
+	atomic.StoreUint64(&tracer.dropped, 0)
+	tracer.Unlock()
+	go exportSpans(exporter, queue, done)
+}
+
+// TracingEnabled returns true if a span exporter has been set.
+func TracingEnabled() bool {
+	tracer.RLock()
+	defer tracer.RUnlock()
+	return tracer.exporter != nil
+}
+
+// ShutdownTracing exports any pending spans and shuts down the exporter.
+func ShutdownTracing() {
+	tracer.Lock()
+	queue, done, exporter := tracer.queue, tracer.done, tracer.exporter
+	tracer.exporter = nil
+	tracer.queue = nil
+	tracer.done = nil
+	tracer.Unlock()
+	if queue == nil {
+		return
+	}
+	close(queue)
+	<-done
+	if err := exporter.Shutdown(); err != nil {
+		Errorf("error shutting down trace exporter: %v\n", err)
+	}
+}
+
+func exportSpans(exporter SpanExporter, queue chan SpanData, done chan struct{}) {
+	ticker := time.NewTicker(spanFlushInterval)
+	defer ticker.Stop()
+	batch := make([]SpanData, 0, spanBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := exporter.ExportSpans(batch); err != nil {
+			Errorf("unable to export %d spans: %v\n", len(batch), err)
+		}
+		batch = make([]SpanData, 0, spanBatchSize)
+	}
+	for {
+		select {
+		case span, ok := <-queue:
+			if !ok {
+				flush()
+				close(done)
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= spanBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func newSpan(name string) *TraceSpan {
+	s := &TraceSpan{name: name, start: time.Now()}
+	rand.Read(s.spanID[:])
+	return s
+}
+
+// StartTrace starts a new trace if tracing is enabled and the trace is sampled, returning
+// its root span.  Otherwise nil is returned.
+func StartTrace(name string) *TraceSpan {
+	tracer.RLock()
+	enabled, sampleRate := tracer.exporter != nil, tracer.sampleRate
+	tracer.RUnlock()
+	if !enabled || (sampleRate < 1 && mrand.Float64() >= sampleRate) {
+		return nil
+	}
+	s := newSpan(name)
+	rand.Read(s.traceID[:])
+	return s
+}
+
+// StartSpan starts a span that is a child of the given parent.  If the parent is nil,
+// i.e., the operation is not traced, nil is returned.
+func StartSpan(parent *TraceSpan, name string) *TraceSpan {
+	if parent == nil {
+		return nil
+	}
+	s := newSpan(name)
+	s.traceID = parent.traceID
+	s.parentID = parent.spanID
+	return s
+}
+
+// StartRemoteSpan starts a span that continues a trace given by a W3C traceparent header,
+// e.g., "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".  If the header is
+// empty or invalid, a new trace is started as in StartTrace.  Remote traces that are
+// not sampled by the caller are not recorded.
+func StartRemoteSpan(traceparent, name string) *TraceSpan {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return StartTrace(name)
+	}
+	var traceID [16]byte
+	var parentID [8]byte
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return StartTrace(name)
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil || traceID == [16]byte{} {
+		return StartTrace(name)
+	}
+	if _, err := hex.Decode(parentID[:], []byte(parts[2])); err != nil || parentID == [8]byte{} {
+		return StartTrace(name)
+	}
+	if !TracingEnabled() || flags[0]&1 == 0 {
+		return nil
+	}
+	s := newSpan(name)
+	s.traceID = traceID
+	s.parentID = parentID
+	return s
+}
+
+// TraceID returns the hex-encoded trace ID or the empty string for a nil TraceSpan.
+func (s *TraceSpan) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// TraceParent returns a W3C traceparent header value for propagating the span to
+// other services, or the empty string for a nil TraceSpan.
+func (s *TraceSpan) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%x-%x-01", s.traceID, s.spanID)
+}
+
+// SetAttribute sets a key-value attribute of the span.
+func (s *TraceSpan) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.attrs == nil {
+		s.attrs = make(map[string]interface{})
+	}
+	s.attrs[key] = value
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed if the error is non-nil.
+func (s *TraceSpan) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export.  Only the first call has an effect.
+func (s *TraceSpan) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	var attrs map[string]interface{}
+	if len(s.attrs) != 0 {
+		// Copy since attributes set after End, e.g., by a late goroutine, would
+		// otherwise race with export of the span.
+		attrs = make(map[string]interface{}, len(s.attrs))
+		for k, v := range s.attrs {
+			attrs[k] = v
+		}
+	}
+	data := SpanData{
+		TraceID:    hex.EncodeToString(s.traceID[:]),
+		SpanID:     hex.EncodeToString(s.spanID[:]),
+		Name:       s.name,
+		Start:      s.start,
+		End:        end,
+		Duration:   durationMs(end.Sub(s.start)),
+		Attributes: attrs,
+		Error:      s.err,
+	}
+	s.mu.Unlock()
+	if s.parentID != [8]byte{} {
+		data.ParentID = hex.EncodeToString(s.parentID[:])
+	}
+
+	tracer.RLock()
+	defer tracer.RUnlock()
+	if tracer.queue == nil {
+		return
+	}
+	select {
+	case tracer.queue <- data:
+	default:
+		if dropped := atomic.AddUint64(&tracer.dropped, 1); dropped&(dropped-1) == 0 {
+			Errorf("trace export queue full: %d spans dropped\n", dropped)
+		}
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a context holding the span.
+func ContextWithSpan(ctx context.Context, s *TraceSpan) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// SpanFromContext returns the span held by the context or nil if there is none.
+func SpanFromContext(ctx context.Context) *TraceSpan {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*TraceSpan)
+	return s
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// JSONSpanExporter writes spans as JSON, one per line.
+type JSONSpanExporter struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+// NewJSONSpanExporter returns an exporter that writes to w and closes it on Shutdown.
+func NewJSONSpanExporter(w io.WriteCloser) *JSONSpanExporter {
+	return &JSONSpanExporter{w: w}
+}
+
+// ExportSpans implements SpanExporter.
+func (e *JSONSpanExporter) ExportSpans(spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	enc := json.NewEncoder(e.w)
+	for _, span := range spans {
+		if err := enc.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown implements SpanExporter.
+func (e *JSONSpanExporter) Shutdown() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.w.Close()
+}
+
+// durationMs rounds a duration to microsecond precision in milliseconds.
+func durationMs(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
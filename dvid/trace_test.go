@@ -0,0 +1,88 @@
+package dvid
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error { return nil }
+
+func TestNilTraceSpan(t *testing.T) {
+	ShutdownTracing()
+	if span := StartTrace("untraced"); span != nil {
+		t.Fatalf("expected nil span when tracing is disabled\n")
+	}
+	var span *TraceSpan
+	child := StartSpan(span, "child")
+	if child != nil {
+		t.Fatalf("expected nil child of nil span\n")
+	}
+	child.SetAttribute("key", 1)
+	child.SetError(fmt.Errorf("ignored"))
+	child.End()
+	if child.TraceID() != "" || child.TraceParent() != "" {
+		t.Fatalf("expected empty ids for nil span\n")
+	}
+	if SpanFromContext(ContextWithSpan(context.Background(), nil)) != nil {
+		t.Fatalf("expected no span in context\n")
+	}
+}
+
+func TestTraceExport(t *testing.T) {
+	buf := new(bufferCloser)
+	SetSpanExporter(NewJSONSpanExporter(buf), 1)
+
+	root := StartRemoteSpan("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "HTTP GET")
+	if root == nil {
+		t.Fatalf("expected span continuing remote trace\n")
+	}
+	if root.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected remote trace ID, got %s\n", root.TraceID())
+	}
+	ctx := ContextWithSpan(context.Background(), root)
+	child := StartSpan(SpanFromContext(ctx), "storage.Get")
+	child.SetAttribute("bytes", 42)
+	child.SetError(fmt.Errorf("not found"))
+	child.End()
+	child.SetAttribute("late", 1) // not exported after End
+	root.End()
+	root.End() // second End has no effect
+
+	if span := StartRemoteSpan("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "unsampled"); span != nil {
+		t.Fatalf("expected unsampled remote trace to not be recorded\n")
+	}
+	if span := StartRemoteSpan("garbage", "new trace"); span == nil || span.TraceID() == root.TraceID() {
+		t.Fatalf("expected new trace for bad traceparent\n")
+	}
+	ShutdownTracing()
+
+	var spans []SpanData
+	scanner := bufio.NewScanner(&buf.Buffer)
+	for scanner.Scan() {
+		var span SpanData
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("bad exported span %q: %v\n", scanner.Text(), err)
+		}
+		spans = append(spans, span)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d: %v\n", len(spans), spans)
+	}
+	if spans[0].Name != "storage.Get" || spans[0].ParentID != spans[1].SpanID || spans[0].Error != "not found" {
+		t.Fatalf("bad child span: %v\n", spans[0])
+	}
+	if _, found := spans[0].Attributes["late"]; found || spans[0].Attributes["bytes"].(float64) != 42 {
+		t.Fatalf("bad child attributes: %v\n", spans[0].Attributes)
+	}
+	if spans[1].ParentID != "00f067aa0ba902b7" || spans[1].TraceID != spans[0].TraceID {
+		t.Fatalf("bad root span: %v\n", spans[1])
+	}
+}
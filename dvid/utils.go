@@ -95,6 +95,7 @@ type ModInfo struct {
 	User string
 	App  string
 	Time string
+	Span *TraceSpan `json:"-"` // trace span of the request making the mutation, if any
 }
 
 // GetModInfo sets and returns a ModInfo using "u" query string.
@@ -104,6 +105,7 @@ func GetModInfo(r *http.Request) ModInfo {
 	info.User = q.Get("u")
 	info.App = q.Get("app")
 	info.Time = time.Now().Format(time.RFC3339)
+	info.Span = SpanFromContext(r.Context())
 	return info
 }
 
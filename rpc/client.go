@@ -5,6 +5,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"fmt"
 	"sync"
 
@@ -20,6 +21,31 @@ type MessageID string
 // Caller is a function that meets the rpc Call signature.
 type Caller func(string, interface{}) (interface{}, error)
 
+var (
+	clientTLS   *tls.Config
+	clientTLSMu sync.RWMutex
+)
+
+// SetClientTLS sets the TLS configuration used by clients to connect to remote servers.
+// If nil, connections are not encrypted.
+func SetClientTLS(cfg *tls.Config) {
+	clientTLSMu.Lock()
+	clientTLS = cfg
+	clientTLSMu.Unlock()
+}
+
+// NewClient returns a gorpc client for the address, using TLS if SetClientTLS has been
+// called with a configuration.  The client must be started by the caller.
+func NewClient(addr string) *gorpc.Client {
+	clientTLSMu.RLock()
+	cfg := clientTLS
+	clientTLSMu.RUnlock()
+	if cfg != nil {
+		return gorpc.NewTLSClient(addr, cfg)
+	}
+	return gorpc.NewTCPClient(addr)
+}
+
 // Session provides ability to send data to remote DVID using multiple
 // RPCs.
 type Session struct {
@@ -32,7 +58,7 @@ type Session struct {
 // NewSession returns a new session to the remote address where the
 // type of session is reflected by the MessageID.
 func NewSession(addr string, mid MessageID) (Session, error) {
-	c := gorpc.NewTCPClient(addr)
+	c := NewClient(addr)
 	c.Start()
 	dc := dispatcher.NewFuncClient(c)
 	if dc == nil {
@@ -5,6 +5,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
@@ -165,6 +166,12 @@ func GetSessionHandler(sid SessionID) (SessionHandler, error) {
 
 // StartServer starts an RPC server.
 func StartServer(address string) error {
+	return StartTLSServer(address, nil)
+}
+
+// StartTLSServer starts an RPC server that only accepts TLS connections using the given
+// configuration.  If the configuration is nil, connections are not encrypted.
+func StartTLSServer(address string, tlsConfig *tls.Config) error {
 	defer func() {
 		if e := recover(); e != nil {
 			msg := fmt.Sprintf("Panic detected on rpc serve thread: %+v\n", e)
@@ -175,7 +182,12 @@ func StartServer(address string) error {
 
 	gorpc.SetErrorLogger(dvid.Errorf) // Send gorpc errors to appropriate error log.
 
-	s := gorpc.NewTCPServer(address, dispatcher.NewHandlerFunc())
+	var s *gorpc.Server
+	if tlsConfig != nil {
+		s = gorpc.NewTLSServer(address, dispatcher.NewHandlerFunc(), tlsConfig)
+	} else {
+		s = gorpc.NewTCPServer(address, dispatcher.NewHandlerFunc())
+	}
 	if servers == nil {
 		servers = make(map[string]*gorpc.Server)
 	}
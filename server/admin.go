@@ -0,0 +1,243 @@
+/*
+	This file provides authenticated HTTP equivalents of commands that were only available
+	through RPC from the dvid command line.  Each request runs the RPC command code as a
+	tracked job, so servers can be administered without shell access to the host.
+*/
+
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/zenazn/goji/web"
+)
+
+// adminRepoCommands gives the JSON keys for the positional arguments of each repo command
+// available via POST /api/admin/repo/{uuid}/{command}.  Other JSON keys are passed as
+// command settings.
+var adminRepoCommands = map[string][]string{
+	"copy":              {"source", "target"},
+	"migrate":           {"source", "oldstore"},
+	"push":              {"target"},
+	"rename":            {"data", "newname", "passcode"},
+	"delete":            {"data", "passcode"},
+	"delete-class":      {"data", "class", "allversions"},
+	"delete-repo":       {"passcode"},
+	"flatten-mutations": {"datauuid", "filename"},
+	"storage-details":   {},
+}
+
+// optional positional arguments that may be omitted from admin requests.
+var adminOptionalArgs = map[string]bool{
+	"passcode": true,
+}
+
+// Middleware that only allows requests with the configured admin bearer token or a TLS
+// client certificate with an allowed common name.
+func adminAuthHandler(c *web.C, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		token, clients := AdminCredentials()
+		if token == "" && len(clients) == 0 {
+			http.Error(w, "Admin API is disabled since no adminToken or adminClients set in server config.", http.StatusForbidden)
+			return
+		}
+		if !adminAuthorized(r, token, clients) {
+			rejectAdminRequest(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// configuredAdminAuth wraps a handler outside the admin API so it requires admin
+// credentials only if an admin bearer token or client common names are configured.
+func configuredAdminAuth(h web.HandlerFunc) web.HandlerFunc {
+	return func(c web.C, w http.ResponseWriter, r *http.Request) {
+		token, clients := AdminCredentials()
+		if (token != "" || len(clients) != 0) && !adminAuthorized(r, token, clients) {
+			rejectAdminRequest(w, r)
+			return
+		}
+		h(c, w, r)
+	}
+}
+
+func rejectAdminRequest(w http.ResponseWriter, r *http.Request) {
+	dvid.Errorf("Unauthorized admin request from %s: %s %s\n", r.RemoteAddr, r.Method, r.URL.Path)
+	w.Header().Set("WWW-Authenticate", `Bearer realm="dvid admin"`)
+	http.Error(w, "Admin API requires a valid bearer token or client certificate.", http.StatusUnauthorized)
+}
+
+func adminAuthorized(r *http.Request, token string, clients []string) bool {
+	if bearerTokenMatches(r.Header.Get("Authorization"), token) {
+		return true
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) != 0 {
+		name := r.TLS.PeerCertificates[0].Subject.CommonName
+		for _, client := range clients {
+			if client == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bearerTokenMatches returns true if the authorization value is a bearer token equal to
+// a non-empty admin token.
+func bearerTokenMatches(auth, token string) bool {
+	if token == "" || !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	given := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// adminCommandArgs converts a JSON object into positional arguments in the given order
+// followed by "key=value" settings for any other keys.
+func adminCommandArgs(body io.Reader, positional []string) ([]string, error) {
+	var values map[string]interface{}
+	if err := json.NewDecoder(body).Decode(&values); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("malformed JSON request in body: %v", err)
+	}
+	args := make(map[string]string, len(values))
+	for key, value := range values {
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case []interface{}:
+			strs := make([]string, len(v))
+			for i, elem := range v {
+				strs[i] = fmt.Sprint(elem)
+			}
+			s = strings.Join(strs, ",")
+		default:
+			s = fmt.Sprint(v)
+		}
+		args[strings.ToLower(key)] = s
+	}
+	var cmdArgs []string
+	for _, key := range positional {
+		value, found := args[key]
+		if !found && !adminOptionalArgs[key] {
+			return nil, fmt.Errorf("required key %q not found in JSON", key)
+		}
+		if strings.Contains(value, "=") {
+			return nil, fmt.Errorf("value for key %q cannot contain '='", key)
+		}
+		cmdArgs = append(cmdArgs, value)
+		delete(args, key)
+	}
+	for key, value := range args {
+		cmdArgs = append(cmdArgs, key+"="+value)
+	}
+	return cmdArgs, nil
+}
+
+// startAdminJob runs an RPC command as a job and returns the job ID as JSON.
+func startAdminJob(w http.ResponseWriter, cmd dvid.Command) {
+	req := &datastore.Request{Command: cmd}
+	job := StartJob(cmd.String(), func() (string, error) {
+		reply, err := execCommand(req, true)
+		if err != nil {
+			return "", err
+		}
+		return reply.Text, nil
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, `{"job":%q}`, job.ID)
+}
+
+func adminRepoCommandHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	command := c.URLParams["command"]
+	positional, found := adminRepoCommands[command]
+	if !found {
+		BadRequest(w, r, "unknown admin repo command %q", command)
+		return
+	}
+	uuid, _, err := datastore.MatchingUUID(c.URLParams["uuid"])
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	args, err := adminCommandArgs(r.Body, positional)
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	var cmd dvid.Command
+	if command == "delete-repo" {
+		cmd = append(dvid.Command{"repos", "delete", string(uuid)}, args...)
+	} else {
+		cmd = append(dvid.Command{"repo", string(uuid), command}, args...)
+	}
+	startAdminJob(w, cmd)
+}
+
+func adminNodeCommandHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	uuid, _, err := datastore.MatchingUUID(c.URLParams["uuid"])
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	dataname := dvid.InstanceName(c.URLParams["dataname"])
+	if _, err := datastore.GetDataByUUIDName(uuid, dataname); err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	var body struct {
+		Args     []string
+		Settings map[string]string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		BadRequest(w, r, "malformed JSON request in body: %v", err)
+		return
+	}
+	cmd := dvid.Command{"node", string(uuid), string(dataname), c.URLParams["command"]}
+	for _, arg := range body.Args {
+		if strings.Contains(arg, "=") {
+			BadRequest(w, r, "argument %q cannot contain '=', use settings instead", arg)
+			return
+		}
+		cmd = append(cmd, arg)
+	}
+	for key, value := range body.Settings {
+		cmd = append(cmd, key+"="+value)
+	}
+	startAdminJob(w, cmd)
+}
+
+func adminJobsHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	m, err := json.Marshal(GetJobs())
+	if err != nil {
+		BadRequest(w, r, "Cannot marshal JSON for jobs: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(m))
+}
+
+func adminJobHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	job, found := GetJob(c.URLParams["id"])
+	if !found {
+		http.Error(w, fmt.Sprintf("Job %q not found.", c.URLParams["id"]), http.StatusNotFound)
+		return
+	}
+	m, err := json.Marshal(job)
+	if err != nil {
+		BadRequest(w, r, "Cannot marshal JSON for job %s: %v", job.ID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(m))
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+)
+
+func adminRequest(t *testing.T, token, method, urlStr, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, urlStr, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Unsuccessful %s on %q: %v\n", method, urlStr, err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	ServeSingleHTTP(resp, req)
+	return resp
+}
+
+func TestAdminAPI(t *testing.T) {
+	if err := OpenTest(); err != nil {
+		t.Fatalf("can't open test server: %v\n", err)
+	}
+	defer CloseTest()
+
+	uuid, _ := datastore.NewTestRepo()
+	deleteURL := fmt.Sprintf("%sadmin/repo/%s/delete-repo", WebAPIPath, uuid)
+
+	oldToken := tc.Server.AdminToken
+	defer func() { tc.Server.AdminToken = oldToken }()
+	tc.Server.AdminToken = ""
+	if resp := adminRequest(t, "", "POST", deleteURL, "{}"); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected admin API to be disabled without credentials, got status %d\n", resp.Code)
+	}
+
+	tc.Server.AdminToken = "secret"
+	if resp := adminRequest(t, "wrong", "POST", deleteURL, "{}"); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected bad token to be rejected, got status %d\n", resp.Code)
+	}
+	if resp := adminRequest(t, "secret", "POST", fmt.Sprintf("%sadmin/repo/%s/copy", WebAPIPath, uuid), `{"source": "foo"}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing copy target to be rejected, got status %d\n", resp.Code)
+	}
+
+	resp := adminRequest(t, "secret", "POST", deleteURL, "{}")
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("expected admin job to be accepted, got status %d: %s\n", resp.Code, resp.Body.String())
+	}
+	var started struct {
+		Job string
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &started); err != nil || started.Job == "" {
+		t.Fatalf("bad admin job response %q: %v\n", resp.Body.String(), err)
+	}
+
+	var job Job
+	for i := 0; i < 100; i++ {
+		resp = adminRequest(t, "secret", "GET", fmt.Sprintf("%sadmin/jobs/%s", WebAPIPath, started.Job), "")
+		if resp.Code != http.StatusOK {
+			t.Fatalf("bad response getting job %s: %d\n", started.Job, resp.Code)
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &job); err != nil {
+			t.Fatalf("bad job JSON %q: %v\n", resp.Body.String(), err)
+		}
+		if job.Status != JobRunning {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != JobCompleted {
+		t.Fatalf("expected repo deletion job to complete, got %v\n", job)
+	}
+	if _, err := datastore.GetRepoRoot(uuid); err == nil {
+		t.Errorf("expected repo %s to be deleted by admin job\n", uuid)
+	}
+
+	resp = adminRequest(t, "secret", "GET", fmt.Sprintf("%sadmin/jobs/notajob", WebAPIPath), "")
+	if resp.Code != http.StatusNotFound {
+		t.Errorf("expected unknown job to return status 404, got %d\n", resp.Code)
+	}
+
+	reloadURL := WebAPIPath + "server/reload-config"
+	if resp := adminRequest(t, "", "POST", reloadURL, ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("expected config reload without token to be rejected, got status %d\n", resp.Code)
+	}
+	if resp := adminRequest(t, "secret", "POST", reloadURL, ""); resp.Code == http.StatusUnauthorized || resp.Code == http.StatusNotFound {
+		t.Errorf("expected config reload with token to be handled, got status %d\n", resp.Code)
+	}
+	tc.Server.AdminToken = ""
+	if resp := adminRequest(t, "", "POST", reloadURL, ""); resp.Code == http.StatusUnauthorized || resp.Code == http.StatusForbidden || resp.Code == http.StatusNotFound {
+		t.Errorf("expected config reload without admin credentials configured to be handled, got status %d\n", resp.Code)
+	}
+	tc.Server.AdminToken = "secret"
+	if resp := adminRequest(t, "", "POST", WebAPIPath+"admin/mirrors/replay", ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("expected mirror replay without token to be rejected, got status %d\n", resp.Code)
+	}
+	if resp := adminRequest(t, "", "POST", WebAPIPath+"server/mirrors/replay", ""); resp.Code == http.StatusOK {
+		t.Errorf("expected mirror replay outside admin API to be unavailable\n")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+   This file supports streaming of data instance change feeds as Server-Sent Events.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+const (
+	// time in milliseconds a client should wait before reconnecting a dropped feed.
+	changeFeedRetry = 2000
+
+	// interval between comments sent to keep idle feeds from being closed by proxies.
+	changeFeedKeepAlive = 30 * time.Second
+)
+
+// serveChangeFeed streams changes to a data instance as Server-Sent Events until the
+// client disconnects or falls too far behind.  Each event's id is its sequence number,
+// so browser EventSource clients automatically resume via the Last-Event-ID header.
+func serveChangeFeed(w http.ResponseWriter, r *http.Request, data datastore.DataService, uuid dvid.UUID) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		BadRequest(w, r, "change feed requires a connection that supports streaming")
+		return
+	}
+	queryStrings := r.URL.Query()
+
+	var filter datastore.ChangeFilter
+	if queryStrings.Get("allversions") != "true" {
+		filter.Version = uuid
+	}
+	if typesStr := queryStrings.Get("types"); typesStr != "" {
+		filter.Events = make(map[string]struct{})
+		for _, eventType := range strings.Split(typesStr, ",") {
+			filter.Events[strings.TrimSpace(eventType)] = struct{}{}
+		}
+	}
+	if labelsStr := queryStrings.Get("labels"); labelsStr != "" {
+		filter.Labels = make(map[uint64]struct{})
+		for _, labelStr := range strings.Split(labelsStr, ",") {
+			label, err := strconv.ParseUint(strings.TrimSpace(labelStr), 10, 64)
+			if err != nil {
+				BadRequest(w, r, "bad label %q in labels query string: %v", labelStr, err)
+				return
+			}
+			filter.Labels[label] = struct{}{}
+		}
+	}
+
+	var resume bool
+	var since uint64
+	sinceStr := queryStrings.Get("since")
+	if sinceStr == "" {
+		sinceStr = r.Header.Get("Last-Event-ID")
+	}
+	if sinceStr != "" {
+		var err error
+		if since, err = strconv.ParseUint(sinceStr, 10, 64); err != nil {
+			BadRequest(w, r, "bad sequence number %q to resume change feed: %v", sinceStr, err)
+			return
+		}
+		resume = true
+	}
+
+	sub, replay, complete, err := datastore.SubscribeChanges(data, filter, resume, since)
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: %d\n\n", changeFeedRetry)
+	if !complete {
+		fmt.Fprintf(w, "event: truncated\ndata: {\"Since\": %d}\n\n", since)
+	}
+	for _, e := range replay {
+		if err := writeChangeEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(changeFeedKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-sub.Ch:
+			if !ok {
+				dvid.Infof("Closing change feed for data %q after client fell behind\n", data.DataName())
+				return
+			}
+			if err := writeChangeEvent(w, e); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-drainCh:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeChangeEvent(w http.ResponseWriter, e datastore.ChangeEvent) error {
+	jsonBytes, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.Seq, jsonBytes)
+	return err
+}
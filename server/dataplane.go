@@ -0,0 +1,767 @@
+/*
+   This file provides a gRPC data plane for streaming block and volume I/O.  The service
+   is defined in datatype/common/proto/dataplane.proto and dispatches to data instances
+   that implement the interfaces below.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	proto1 "github.com/gogo/protobuf/proto"
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// number of goroutines fetching blocks for each GetBlocks stream.
+	dataPlaneFetchers = 8
+
+	// default and maximum bytes per streamed DataChunk.  The default keeps messages under
+	// the 4 MiB receive limit that gRPC clients use by default.
+	dataPlaneChunkBytes    = 1 << 20
+	dataPlaneMaxChunkBytes = 64 << 20
+
+	// maximum size of a received message, which must hold the largest encoded block.
+	dataPlaneMaxRecvBytes = 64 << 20
+
+	// maximum number of blocks in a GetBlocks request.
+	dataPlaneMaxBlocks = 1 << 20
+
+	// maximum number of voxels read at a time for a GetSubvolume stream.
+	dataPlaneSlabVoxels = 1 << 22
+
+	// time allowed for active streams to finish during shutdown.
+	dataPlaneStopTimeout = 30 * time.Second
+)
+
+// DataPlaneBlock is a block of data received over the gRPC data plane, encoded as for the
+// data type's HTTP POST /blocks endpoint.
+type DataPlaneBlock struct {
+	BCoord dvid.ChunkPoint3d
+	Data   []byte
+}
+
+// DataPlanePutOptions gives options for storing blocks received over the gRPC data plane.
+type DataPlanePutOptions struct {
+	Scale     uint8
+	Downscale bool // compute lower-resolution scales if supported
+	Mutate    bool // treat blocks as mutations rather than ingestion if supported
+	Info      dvid.ModInfo
+}
+
+// BlockStreamer is implemented by data that support block I/O over the gRPC data plane.
+type BlockStreamer interface {
+	// GetDataPlaneBlock returns the block encoded as for the data type's HTTP /blocks
+	// endpoint or nil if the block should not be sent, e.g., it is not stored.
+	GetDataPlaneBlock(ctx *datastore.VersionedCtx, bcoord dvid.ChunkPoint3d, scale uint8, supervoxels bool, compression string) ([]byte, error)
+
+	// PutDataPlaneBlocks stores blocks received on the channel until it is closed.  If it
+	// returns before the channel is closed, no further blocks are sent.
+	PutDataPlaneBlocks(ctx *datastore.VersionedCtx, opts DataPlanePutOptions, blocks <-chan DataPlaneBlock) error
+}
+
+// SubvolumeStreamer is implemented by data that can return voxels over the gRPC data plane.
+type SubvolumeStreamer interface {
+	// GetDataPlaneSubvolume returns the little-endian voxel values of the given subvolume,
+	// which is specified in voxels at the given scale.
+	GetDataPlaneSubvolume(ctx *datastore.VersionedCtx, offset, size dvid.Point3d, scale uint8, supervoxels bool) ([]byte, error)
+}
+
+// SparsevolStreamer is implemented by data that can stream sparse volumes over the gRPC
+// data plane.
+type SparsevolStreamer interface {
+	// WriteDataPlaneSparsevol writes a label's sparse volume in the given format, as for the
+	// HTTP /sparsevol endpoint, and returns false if the label was not found.
+	WriteDataPlaneSparsevol(ctx *datastore.VersionedCtx, label uint64, scale uint8, supervoxels bool, format string, w io.Writer) (found bool, err error)
+}
+
+// LabelMutator is implemented by data that can do label mutations over the gRPC data plane.
+type LabelMutator interface {
+	DataPlaneMerge(ctx *datastore.VersionedCtx, target uint64, merged []uint64, info dvid.ModInfo) (mutID uint64, err error)
+	DataPlaneCleave(ctx *datastore.VersionedCtx, target uint64, supervoxels []uint64, info dvid.ModInfo) (cleaved, mutID uint64, err error)
+	DataPlaneSplit(ctx *datastore.VersionedCtx, label uint64, rles []byte, info dvid.ModInfo) (newLabel, mutID uint64, err error)
+}
+
+// dataPlaneCodec marshals messages with gogo protobuf, which uses the marshalers generated
+// for the data plane messages.
+type dataPlaneCodec struct{}
+
+func (dataPlaneCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto1.Message)
+	if !ok {
+		return nil, fmt.Errorf("data plane can't marshal non-protobuf message %T", v)
+	}
+	return proto1.Marshal(msg)
+}
+
+func (dataPlaneCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto1.Message)
+	if !ok {
+		return fmt.Errorf("data plane can't unmarshal into non-protobuf message %T", v)
+	}
+	return proto1.Unmarshal(data, msg)
+}
+
+func (dataPlaneCodec) String() string { return "proto" }
+
+var dataPlane struct {
+	sync.Mutex
+	server *grpc.Server
+}
+
+// serveDataPlane starts the gRPC data plane on the given address and blocks until it stops.
+// If a TLS configuration is given, only TLS connections are accepted.
+func serveDataPlane(address string, tlsConfig *tls.Config) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return serveDataPlaneListener(lis, tlsConfig)
+}
+
+func serveDataPlaneListener(lis net.Listener, tlsConfig *tls.Config) error {
+	opts := []grpc.ServerOption{
+		grpc.CustomCodec(dataPlaneCodec{}),
+		grpc.MaxRecvMsgSize(dataPlaneMaxRecvBytes),
+		grpc.UnaryInterceptor(dataPlaneUnaryInterceptor),
+		grpc.StreamInterceptor(dataPlaneStreamInterceptor),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s := grpc.NewServer(opts...)
+	proto.RegisterDataPlaneServer(s, dataPlaneService{})
+	dataPlane.Lock()
+	dataPlane.server = s
+	dataPlane.Unlock()
+	return s.Serve(lis)
+}
+
+// stopDataPlane stops the gRPC data plane, letting active streams finish if they can do
+// so within a timeout.
+func stopDataPlane() {
+	dataPlane.Lock()
+	s := dataPlane.server
+	dataPlane.server = nil
+	dataPlane.Unlock()
+	if s == nil {
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(dataPlaneStopTimeout):
+		dvid.Infof("Stopping gRPC data plane with active streams after %s\n", dataPlaneStopTimeout)
+		s.Stop()
+	}
+}
+
+// dataPlaneCommonName returns the common name of a verified TLS client certificate or
+// the empty string if the client did not present one.
+func dataPlaneCommonName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.PeerCertificates) == 0 {
+		return ""
+	}
+	return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+}
+
+// dataPlaneAuthorize returns an error unless a request has the credentials of the admin
+// API: the admin token as a bearer token in the "authorization" metadata or a TLS client
+// certificate with an allowed common name.
+func dataPlaneAuthorize(ctx context.Context) error {
+	token, clients := AdminCredentials()
+	if token == "" && len(clients) == 0 {
+		return status.Error(codes.PermissionDenied, "gRPC data plane is disabled since no adminToken or adminClients set in server config")
+	}
+	if token != "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			for _, auth := range md.Get("authorization") {
+				if bearerTokenMatches(auth, token) {
+					return nil
+				}
+			}
+		}
+	}
+	if name := dataPlaneCommonName(ctx); name != "" {
+		for _, client := range clients {
+			if client == name {
+				return nil
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		dvid.Errorf("Unauthorized gRPC data plane request from %s\n", p.Addr)
+	}
+	return status.Error(codes.Unauthenticated, "gRPC data plane requires a valid bearer token or client certificate")
+}
+
+// dataPlaneClient returns the rate limiting client identifier and name of a request,
+// which is its verified TLS client certificate or else its remote IP.
+func dataPlaneClient(ctx context.Context) (client, name string) {
+	if name = dataPlaneCommonName(ctx); name != "" {
+		return "cert:" + name, name
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		var err error
+		if name, _, err = net.SplitHostPort(p.Addr.String()); err != nil {
+			name = p.Addr.String()
+		}
+	}
+	return "ip:" + name, name
+}
+
+// dataPlaneKeywords gives the HTTP endpoint keyword and method of each data plane RPC,
+// which determine the rate limiting budgets charged for it.
+var dataPlaneKeywords = map[string][2]string{
+	"/proto.DataPlane/GetBlocks":    {"blocks", "GET"},
+	"/proto.DataPlane/PutBlocks":    {"blocks", "POST"},
+	"/proto.DataPlane/GetSubvolume": {"raw", "GET"},
+	"/proto.DataPlane/GetSparsevol": {"sparsevol", "GET"},
+	"/proto.DataPlane/Merge":        {"merge", "POST"},
+	"/proto.DataPlane/Cleave":       {"cleave", "POST"},
+	"/proto.DataPlane/Split":        {"split", "POST"},
+}
+
+// dataPlaneAdmit returns an error if the client is over its rate limiting budget.
+// Otherwise it returns the client identifier if the client's transfers should be charged,
+// or the empty string if the client is not limited.
+func dataPlaneAdmit(ctx context.Context, fullMethod string, contentLength int64) (client string, err error) {
+	client, name := dataPlaneClient(ctx)
+	endpoint := dataPlaneKeywords[fullMethod]
+	limited, retry, budget := admitRequest(client, name, endpoint[0], endpoint[1], contentLength, time.Now())
+	if !limited {
+		return "", nil
+	}
+	if retry > 0 {
+		secs := int(math.Ceil(retry.Seconds()))
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(secs)))
+		msg := fmt.Sprintf("client %q exceeded its %s budget; retry after %d seconds", client, budget, secs)
+		dvid.Infof("%s: %s\n", fullMethod, msg)
+		return "", status.Error(codes.ResourceExhausted, msg)
+	}
+	return client, nil
+}
+
+func protoSize(v interface{}) int64 {
+	if msg, ok := v.(proto1.Message); ok {
+		return int64(proto1.Size(msg))
+	}
+	return 0
+}
+
+// dataPlaneUnaryInterceptor authorizes and rate limits unary RPCs.
+func dataPlaneUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := dataPlaneAuthorize(ctx); err != nil {
+		return nil, err
+	}
+	reqBytes := protoSize(req)
+	client, err := dataPlaneAdmit(ctx, info.FullMethod, reqBytes)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if client != "" {
+		chargeRequest(client, protoSize(resp), reqBytes, time.Now())
+	}
+	return resp, err
+}
+
+// countingStream counts the bytes of messages sent and received on a stream.
+type countingStream struct {
+	grpc.ServerStream
+	sent, received int64
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent += protoSize(m)
+	}
+	return err
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received += protoSize(m)
+	}
+	return err
+}
+
+// dataPlaneStreamInterceptor authorizes and rate limits streaming RPCs.  The size of a
+// client stream isn't known in advance, so it only needs some write budget to start.
+func dataPlaneStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := ss.Context()
+	if err := dataPlaneAuthorize(ctx); err != nil {
+		return err
+	}
+	var contentLength int64
+	if info.IsClientStream {
+		contentLength = -1
+	}
+	client, err := dataPlaneAdmit(ctx, info.FullMethod, contentLength)
+	if err != nil {
+		return err
+	}
+	if client == "" {
+		return handler(srv, ss)
+	}
+	cs := &countingStream{ServerStream: ss}
+	err = handler(srv, cs)
+	chargeRequest(client, cs.sent, cs.received, time.Now())
+	return err
+}
+
+type dataPlaneService struct{}
+
+// instance returns the data and versioned context for a request, applying the same
+// checks as HTTP requests to data instances.
+func (dataPlaneService) instance(uuidStr, dataname string, mutation bool) (datastore.DataService, *datastore.VersionedCtx, error) {
+	if !dvid.RequestsOK() {
+		return nil, nil, status.Error(codes.Unavailable, "DVID server is unavailable")
+	}
+	if mutation && readonly {
+		return nil, nil, status.Error(codes.PermissionDenied, "server is in read-only mode")
+	}
+	uuid, v, err := datastore.MatchingUUID(uuidStr)
+	if err != nil {
+		return nil, nil, status.Error(codes.NotFound, err.Error())
+	}
+	data, err := datastore.GetDataByUUIDName(uuid, dvid.InstanceName(dataname))
+	if err != nil {
+		return nil, nil, status.Error(codes.NotFound, err.Error())
+	}
+	if data.Versioned() {
+		if mutation && !fullwrite {
+			locked, err := datastore.LockedUUID(uuid)
+			if err != nil {
+				return nil, nil, status.Error(codes.Internal, err.Error())
+			}
+			if locked {
+				return nil, nil, status.Errorf(codes.FailedPrecondition, "cannot modify data %q in locked node %s", dataname, uuid)
+			}
+		}
+	} else if v, err = datastore.GetRepoRootVersion(v); err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	return data, datastore.NewVersionedCtx(data, v), nil
+}
+
+func modInfo(user, app string) dvid.ModInfo {
+	return dvid.ModInfo{User: user, App: app, Time: time.Now().Format(time.RFC3339)}
+}
+
+func point3d(coords []int32, name string) (dvid.Point3d, error) {
+	if len(coords) != 3 {
+		return dvid.Point3d{}, status.Errorf(codes.InvalidArgument, "%s must have 3 coordinates, got %d", name, len(coords))
+	}
+	return dvid.Point3d{coords[0], coords[1], coords[2]}, nil
+}
+
+// numRequestedBlocks returns the number of blocks requested, which is computed without
+// overflow for ranges that reach the limits of int32 block coordinates.
+func numRequestedBlocks(req *proto.GetBlocksRequest) int64 {
+	if len(req.Blocks) != 0 {
+		return int64(len(req.Blocks) / 3)
+	}
+	n := int64(1)
+	for i := 0; i < 3; i++ {
+		n *= int64(req.MaxBlock[i]) - int64(req.MinBlock[i]) + 1
+	}
+	return n
+}
+
+// forEachBlock calls fn for each requested block coordinate until fn returns false.
+func forEachBlock(req *proto.GetBlocksRequest, fn func(dvid.ChunkPoint3d) bool) {
+	if len(req.Blocks) != 0 {
+		for i := 0; i+2 < len(req.Blocks); i += 3 {
+			if !fn(dvid.ChunkPoint3d{req.Blocks[i], req.Blocks[i+1], req.Blocks[i+2]}) {
+				return
+			}
+		}
+		return
+	}
+	minB, maxB := req.MinBlock, req.MaxBlock
+	for z := int64(minB[2]); z <= int64(maxB[2]); z++ {
+		for y := int64(minB[1]); y <= int64(maxB[1]); y++ {
+			for x := int64(minB[0]); x <= int64(maxB[0]); x++ {
+				if !fn(dvid.ChunkPoint3d{int32(x), int32(y), int32(z)}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+type dataPlaneBlock struct {
+	bcoord dvid.ChunkPoint3d
+	data   []byte
+	err    error
+}
+
+// GetBlocks streams requested blocks, fetching a few concurrently.  Blocks are sent in
+// the order they are fetched, which may differ from the requested order.
+func (s dataPlaneService) GetBlocks(req *proto.GetBlocksRequest, stream proto.DataPlane_GetBlocksServer) error {
+	if len(req.Blocks) != 0 {
+		if len(req.Blocks)%3 != 0 {
+			return status.Error(codes.InvalidArgument, "blocks must be given as x,y,z triples")
+		}
+	} else {
+		minB, err := point3d(req.MinBlock, "min_block")
+		if err != nil {
+			return err
+		}
+		maxB, err := point3d(req.MaxBlock, "max_block")
+		if err != nil {
+			return err
+		}
+		if minB[0] > maxB[0] || minB[1] > maxB[1] || minB[2] > maxB[2] {
+			return status.Errorf(codes.InvalidArgument, "min_block %s must not exceed max_block %s", minB, maxB)
+		}
+	}
+	if n := numRequestedBlocks(req); n > dataPlaneMaxBlocks {
+		return status.Errorf(codes.InvalidArgument, "request for %d blocks exceeds server limit of %d", n, dataPlaneMaxBlocks)
+	}
+	data, ctx, err := s.instance(req.Uuid, req.Dataname, false)
+	if err != nil {
+		return err
+	}
+	bs, ok := data.(BlockStreamer)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "data %q of type %s does not support block streaming", req.Dataname, data.TypeName())
+	}
+	scale := uint8(req.Scale)
+
+	done := make(chan struct{})
+	defer close(done)
+	coordCh := make(chan dvid.ChunkPoint3d)
+	go func() {
+		forEachBlock(req, func(bcoord dvid.ChunkPoint3d) bool {
+			select {
+			case coordCh <- bcoord:
+				return true
+			case <-done:
+				return false
+			}
+		})
+		close(coordCh)
+	}()
+
+	// The result channel is small so fetching stalls when the client applies backpressure.
+	resultCh := make(chan dataPlaneBlock, dataPlaneFetchers)
+	var wg sync.WaitGroup
+	for i := 0; i < dataPlaneFetchers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for bcoord := range coordCh {
+				out, err := bs.GetDataPlaneBlock(ctx, bcoord, scale, req.Supervoxels, req.Compression)
+				select {
+				case resultCh <- dataPlaneBlock{bcoord, out, err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	timedLog := dvid.NewTimeLog()
+	var numBlocks int
+	for result := range resultCh {
+		if result.err != nil {
+			return status.Errorf(codes.Internal, "unable to get block %s: %v", result.bcoord, result.err)
+		}
+		if result.data == nil {
+			continue
+		}
+		msg := &proto.Block{X: result.bcoord[0], Y: result.bcoord[1], Z: result.bcoord[2], Data: result.data}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+		numBlocks++
+	}
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	timedLog.Infof("gRPC GetBlocks sent %d blocks of data %q", numBlocks, req.Dataname)
+	return nil
+}
+
+// PutBlocks stores a stream of blocks.  The instance and options are taken from the first
+// message.  Blocks stored before an error remain stored.
+func (s dataPlaneService) PutBlocks(stream proto.DataPlane_PutBlocksServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return stream.SendAndClose(&proto.PutBlocksResponse{})
+		}
+		return err
+	}
+	dataname := req.Dataname
+	data, ctx, err := s.instance(req.Uuid, dataname, true)
+	if err != nil {
+		return err
+	}
+	bs, ok := data.(BlockStreamer)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "data %q of type %s does not support block streaming", dataname, data.TypeName())
+	}
+	opts := DataPlanePutOptions{
+		Scale:     uint8(req.Scale),
+		Downscale: req.Downscale,
+		Mutate:    req.Mutate,
+		Info:      modInfo(req.User, req.App),
+	}
+
+	timedLog := dvid.NewTimeLog()
+	blockCh := make(chan DataPlaneBlock, dataPlaneFetchers)
+	putErrCh := make(chan error, 1)
+	go func() {
+		putErrCh <- bs.PutDataPlaneBlocks(ctx, opts, blockCh)
+	}()
+
+	var numBlocks uint64
+	var recvErr error
+	for recvErr == nil {
+		if req.Block != nil {
+			block := DataPlaneBlock{dvid.ChunkPoint3d{req.Block.X, req.Block.Y, req.Block.Z}, req.Block.Data}
+			select {
+			case blockCh <- block:
+				numBlocks++
+			case err := <-putErrCh:
+				if err == nil {
+					err = fmt.Errorf("block storage ended before all blocks were received")
+				}
+				return status.Errorf(codes.Internal, "PutBlocks on data %q: %v", dataname, err)
+			}
+		}
+		req, recvErr = stream.Recv()
+	}
+	close(blockCh)
+	if err := <-putErrCh; err != nil {
+		return status.Errorf(codes.Internal, "PutBlocks on data %q: %v", dataname, err)
+	}
+	if recvErr != io.EOF {
+		return recvErr
+	}
+	timedLog.Infof("gRPC PutBlocks received %d blocks for data %q", numBlocks, dataname)
+	return stream.SendAndClose(&proto.PutBlocksResponse{NumBlocks: numBlocks})
+}
+
+// chunkSender is a stream of DataChunk messages.
+type chunkSender interface {
+	Send(*proto.DataChunk) error
+}
+
+// chunkWriter sends written bytes as a stream of DataChunk messages.
+type chunkWriter struct {
+	stream chunkSender
+	buf    []byte
+}
+
+func newChunkWriter(stream chunkSender, chunkBytes uint32) *chunkWriter {
+	size := int(chunkBytes)
+	if size == 0 {
+		size = dataPlaneChunkBytes
+	} else if size > dataPlaneMaxChunkBytes {
+		size = dataPlaneMaxChunkBytes
+	}
+	return &chunkWriter{stream: stream, buf: make([]byte, 0, size)}
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+		if len(w.buf) == cap(w.buf) {
+			if err := w.Flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Flush sends any buffered bytes.
+func (w *chunkWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	if err := w.stream.Send(&proto.DataChunk{Data: w.buf}); err != nil {
+		return err
+	}
+	w.buf = make([]byte, 0, cap(w.buf))
+	return nil
+}
+
+// GetSubvolume streams the voxels of a subvolume in ZYX order, reading slabs along z so
+// only a slab of voxels is held in memory at a time.
+func (s dataPlaneService) GetSubvolume(req *proto.SubvolumeRequest, stream proto.DataPlane_GetSubvolumeServer) error {
+	offset, err := point3d(req.Offset, "offset")
+	if err != nil {
+		return err
+	}
+	size, err := point3d(req.Size_, "size")
+	if err != nil {
+		return err
+	}
+	if size[0] <= 0 || size[1] <= 0 || size[2] <= 0 {
+		return status.Errorf(codes.InvalidArgument, "subvolume size %s must be positive", size)
+	}
+	if size.Prod() > MaxDataRequest {
+		return status.Errorf(codes.InvalidArgument, "subvolume of %d voxels exceeds server limit of %d", size.Prod(), MaxDataRequest)
+	}
+	data, ctx, err := s.instance(req.Uuid, req.Dataname, false)
+	if err != nil {
+		return err
+	}
+	ss, ok := data.(SubvolumeStreamer)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "data %q of type %s does not support subvolume streaming", req.Dataname, data.TypeName())
+	}
+	slabZ := int32(dataPlaneSlabVoxels / (int64(size[0]) * int64(size[1])))
+	if slabZ < 1 {
+		slabZ = 1
+	}
+	w := newChunkWriter(stream, req.ChunkBytes)
+	for z := int32(0); z < size[2]; z += slabZ {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		slabOffset := dvid.Point3d{offset[0], offset[1], offset[2] + z}
+		slabSize := dvid.Point3d{size[0], size[1], slabZ}
+		if z+slabZ > size[2] {
+			slabSize[2] = size[2] - z
+		}
+		voxels, err := ss.GetDataPlaneSubvolume(ctx, slabOffset, slabSize, uint8(req.Scale), req.Supervoxels)
+		if err != nil {
+			return status.Errorf(codes.Internal, "unable to get subvolume of data %q: %v", req.Dataname, err)
+		}
+		if _, err := w.Write(voxels); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// GetSparsevol streams a label's sparse volume.
+func (s dataPlaneService) GetSparsevol(req *proto.SparsevolRequest, stream proto.DataPlane_GetSparsevolServer) error {
+	data, ctx, err := s.instance(req.Uuid, req.Dataname, false)
+	if err != nil {
+		return err
+	}
+	ss, ok := data.(SparsevolStreamer)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "data %q of type %s does not support sparsevol streaming", req.Dataname, data.TypeName())
+	}
+	w := newChunkWriter(stream, 0)
+	found, err := ss.WriteDataPlaneSparsevol(ctx, req.Label, uint8(req.Scale), req.Supervoxels, req.Format, w)
+	if err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		return status.Errorf(codes.Internal, "unable to get sparsevol for label %d: %v", req.Label, err)
+	}
+	if !found {
+		return status.Errorf(codes.NotFound, "label %d not found in data %q", req.Label, req.Dataname)
+	}
+	return w.Flush()
+}
+
+// labelMutator returns the data for a mutation request.  Mutations can't be interrupted
+// once started, so an expired deadline is checked first.
+func (s dataPlaneService) labelMutator(ctx context.Context, uuidStr, dataname string) (LabelMutator, *datastore.VersionedCtx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, status.FromContextError(err).Err()
+	}
+	data, vctx, err := s.instance(uuidStr, dataname, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	lm, ok := data.(LabelMutator)
+	if !ok {
+		return nil, nil, status.Errorf(codes.Unimplemented, "data %q of type %s does not support label mutations", dataname, data.TypeName())
+	}
+	return lm, vctx, nil
+}
+
+// Merge merges labels into a target label.
+func (s dataPlaneService) Merge(ctx context.Context, req *proto.MergeRequest) (*proto.MutationResponse, error) {
+	lm, vctx, err := s.labelMutator(ctx, req.Uuid, req.Dataname)
+	if err != nil {
+		return nil, err
+	}
+	if req.Target == 0 || len(req.Merged) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "merge requires a non-zero target and labels to merge")
+	}
+	mutID, err := lm.DataPlaneMerge(vctx, req.Target, req.Merged, modInfo(req.User, req.App))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error on merge: %v", err)
+	}
+	return &proto.MutationResponse{Mutid: mutID}, nil
+}
+
+// Cleave cleaves supervoxels from a label into a new label.
+func (s dataPlaneService) Cleave(ctx context.Context, req *proto.CleaveRequest) (*proto.MutationResponse, error) {
+	lm, vctx, err := s.labelMutator(ctx, req.Uuid, req.Dataname)
+	if err != nil {
+		return nil, err
+	}
+	if req.Target == 0 {
+		return nil, status.Error(codes.InvalidArgument, "label 0 is protected background value and cannot be used as cleave target")
+	}
+	cleaved, mutID, err := lm.DataPlaneCleave(vctx, req.Target, req.Supervoxels, modInfo(req.User, req.App))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error on cleave: %v", err)
+	}
+	return &proto.MutationResponse{Mutid: mutID, Label: cleaved}, nil
+}
+
+// Split splits the voxels given by RLEs from a label into a new label.
+func (s dataPlaneService) Split(ctx context.Context, req *proto.SplitRequest) (*proto.MutationResponse, error) {
+	lm, vctx, err := s.labelMutator(ctx, req.Uuid, req.Dataname)
+	if err != nil {
+		return nil, err
+	}
+	if req.Label == 0 {
+		return nil, status.Error(codes.InvalidArgument, "label 0 is protected background value and cannot be split")
+	}
+	newLabel, mutID, err := lm.DataPlaneSplit(vctx, req.Label, req.Rles, modInfo(req.User, req.App))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error on split: %v", err)
+	}
+	return &proto.MutationResponse{Mutid: mutID, Label: newLabel}, nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"github.com/janelia-flyem/dvid/dvid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestForEachBlockLimits(t *testing.T) {
+	req := &proto.GetBlocksRequest{
+		MinBlock: []int32{math.MaxInt32 - 1, math.MaxInt32, math.MaxInt32 - 2},
+		MaxBlock: []int32{math.MaxInt32, math.MaxInt32, math.MaxInt32},
+	}
+	if n := numRequestedBlocks(req); n != 6 {
+		t.Fatalf("expected 6 requested blocks, got %d\n", n)
+	}
+	var visited []dvid.ChunkPoint3d
+	forEachBlock(req, func(bcoord dvid.ChunkPoint3d) bool {
+		visited = append(visited, bcoord)
+		return len(visited) < 100
+	})
+	if len(visited) != 6 {
+		t.Fatalf("expected 6 blocks visited at int32 limits, got %d: %v\n", len(visited), visited)
+	}
+	if last := visited[5]; last != (dvid.ChunkPoint3d{math.MaxInt32, math.MaxInt32, math.MaxInt32}) {
+		t.Fatalf("bad last block %s\n", last)
+	}
+
+	req = &proto.GetBlocksRequest{
+		MinBlock: []int32{math.MinInt32, math.MinInt32, math.MinInt32},
+		MaxBlock: []int32{math.MaxInt32, math.MaxInt32, math.MaxInt32},
+	}
+	if n := numRequestedBlocks(req); n <= dataPlaneMaxBlocks {
+		t.Fatalf("expected huge block count, got %d\n", n)
+	}
+	if err := (dataPlaneService{}).GetBlocks(req, nil); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected too many blocks to be rejected, got %v\n", err)
+	}
+}
+
+type testChunkSender struct {
+	chunks [][]byte
+}
+
+func (s *testChunkSender) Send(chunk *proto.DataChunk) error {
+	s.chunks = append(s.chunks, chunk.Data)
+	return nil
+}
+
+func TestChunkWriter(t *testing.T) {
+	var sender testChunkSender
+	w := newChunkWriter(&sender, 4)
+	data := []byte("0123456789")
+	if n, err := w.Write(data[:3]); n != 3 || err != nil {
+		t.Fatalf("bad write: %d, %v\n", n, err)
+	}
+	if n, err := w.Write(data[3:]); n != 7 || err != nil {
+		t.Fatalf("bad write: %d, %v\n", n, err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("bad flush: %v\n", err)
+	}
+	if len(sender.chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d\n", len(sender.chunks))
+	}
+	for i, chunk := range sender.chunks[:2] {
+		if len(chunk) != 4 {
+			t.Errorf("expected chunk %d to have 4 bytes, got %d\n", i, len(chunk))
+		}
+	}
+	if got := bytes.Join(sender.chunks, nil); !bytes.Equal(got, data) {
+		t.Fatalf("expected %q sent, got %q\n", data, got)
+	}
+	if err := w.Flush(); err != nil || len(sender.chunks) != 3 {
+		t.Fatalf("expected empty flush to send nothing, got %d chunks, %v\n", len(sender.chunks), err)
+	}
+}
+
+func TestDataPlaneAuthorize(t *testing.T) {
+	oldToken, oldClients := tc.Server.AdminToken, tc.Server.AdminClients
+	defer func() { tc.Server.AdminToken, tc.Server.AdminClients = oldToken, oldClients }()
+
+	withAuth := func(auth string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", auth))
+	}
+
+	tc.Server.AdminToken, tc.Server.AdminClients = "", nil
+	if err := dataPlaneAuthorize(withAuth("Bearer ")); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected data plane to be disabled without credentials, got %v\n", err)
+	}
+
+	tc.Server.AdminToken = "secret"
+	if err := dataPlaneAuthorize(context.Background()); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected missing token to be rejected, got %v\n", err)
+	}
+	if err := dataPlaneAuthorize(withAuth("Bearer wrong")); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected bad token to be rejected, got %v\n", err)
+	}
+	if err := dataPlaneAuthorize(withAuth("secret")); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected token without bearer scheme to be rejected, got %v\n", err)
+	}
+	if err := dataPlaneAuthorize(withAuth("Bearer secret")); err != nil {
+		t.Fatalf("expected valid token to be accepted, got %v\n", err)
+	}
+}
+
+func TestDataPlaneKeywords(t *testing.T) {
+	for _, method := range []string{"GetBlocks", "PutBlocks", "GetSubvolume", "GetSparsevol", "Merge", "Cleave", "Split"} {
+		endpoint, found := dataPlaneKeywords["/proto.DataPlane/"+method]
+		if !found || endpoint[0] == "" || endpoint[1] == "" {
+			t.Errorf("no rate limiting endpoint for data plane method %s\n", method)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+	This file tracks long-running jobs started by admin HTTP requests or RPC commands so
+	their progress and outcome can be retrieved later.
+*/
+
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// maximum number of finished jobs kept for status requests.
+const maxFinishedJobs = 1000
+
+// Job status values.
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// Job describes a long-running command.
+type Job struct {
+	ID       string
+	Command  string
+	Status   string
+	Started  time.Time
+	Finished *time.Time `json:",omitempty"`
+	Result   string     `json:",omitempty"`
+	Error    string     `json:",omitempty"`
+}
+
+func (j *Job) String() string {
+	return j.ID
+}
+
+var jobs struct {
+	sync.RWMutex
+	byID     map[string]*Job
+	finished []string // IDs of finished jobs in order of completion
+}
+
+// StartJob runs f in a new goroutine and returns a job that records its outcome.  The
+// job can be retrieved via GetJob or the /api/admin/jobs endpoints.
+func StartJob(command string, f func() (string, error)) *Job {
+	job := &Job{
+		ID:      string(dvid.NewUUID()),
+		Command: command,
+		Status:  JobRunning,
+		Started: time.Now(),
+	}
+	jobs.Lock()
+	if jobs.byID == nil {
+		jobs.byID = make(map[string]*Job)
+	}
+	jobs.byID[job.ID] = job
+	jobs.Unlock()
+
+	dvid.Infof("Started job %s: %s\n", job.ID, command)
+	go func() {
+		var result string
+		var err error
+		defer func() {
+			if e := recover(); e != nil {
+				err = fmt.Errorf("panic: %v", e)
+			}
+			finishJob(job, result, err)
+		}()
+		result, err = f()
+	}()
+	return job
+}
+
+func finishJob(job *Job, result string, err error) {
+	jobs.Lock()
+	defer jobs.Unlock()
+	finished := time.Now()
+	job.Finished = &finished
+	job.Result = result
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+		dvid.Errorf("Job %s failed after %s: %v\n", job.ID, finished.Sub(job.Started), err)
+	} else {
+		job.Status = JobCompleted
+		dvid.Infof("Job %s completed after %s\n", job.ID, finished.Sub(job.Started))
+	}
+	jobs.finished = append(jobs.finished, job.ID)
+	if len(jobs.finished) > maxFinishedJobs {
+		delete(jobs.byID, jobs.finished[0])
+		jobs.finished = jobs.finished[1:]
+	}
+}
+
+// GetJob returns the job with the given ID.
+func GetJob(id string) (Job, bool) {
+	jobs.RLock()
+	defer jobs.RUnlock()
+	job, found := jobs.byID[id]
+	if !found {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// GetJobs returns all running and recently finished jobs in order of start time.
+func GetJobs() []Job {
+	jobs.RLock()
+	list := make([]Job, 0, len(jobs.byID))
+	for _, job := range jobs.byID {
+		list = append(list, *job)
+	}
+	jobs.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.Before(list[j].Started) })
+	return list
+}
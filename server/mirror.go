@@ -0,0 +1,382 @@
+/*
+	This file implements reliable mirroring of data instance POSTs to other servers.  Each
+	mirror target has an outbox so requests are delivered in order and retried with
+	exponential backoff until accepted.  Requests a mirror rejects, or that fail more than
+	a configured number of attempts, are moved to the target's dead-letter outbox where
+	they are kept until replayed.
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+const (
+	// DefaultMirrorMaxBackoff is the default maximum time between retries of a mirrored request.
+	DefaultMirrorMaxBackoff = 5 * time.Minute
+
+	// timeout for a single delivery of a mirrored request.
+	mirrorTimeout = 10 * time.Minute
+)
+
+// initial delay before retrying a failed mirror delivery.
+var mirrorInitialBackoff = time.Second
+
+// MirrorStats holds delivery statistics for a mirror target.
+type MirrorStats struct {
+	Pending       int             // requests awaiting delivery
+	DeadLetters   int             // requests that failed permanently and can be replayed
+	Delivered     uint64          // requests delivered since startup
+	Failures      uint64          // failed delivery attempts since startup
+	LagSeconds    float64         // age of the oldest request awaiting delivery
+	LastDelivered *MirrorDelivery `json:",omitempty"`
+	LastFailure   string          `json:",omitempty"`
+}
+
+// MirrorDelivery describes a delivered mirror request.
+type MirrorDelivery struct {
+	Path      string
+	Queued    time.Time
+	Delivered time.Time
+}
+
+// mirrorRequest is the header of a mirrored POST, which is stored as the outbox topic
+// while the POST body is the outbox message.
+type mirrorRequest struct {
+	Path        string // path and query string
+	ContentType string
+}
+
+type mirrorTarget struct {
+	url         string
+	outbox      *storage.Outbox
+	dead        *storage.Outbox
+	client      *http.Client
+	maxBackoff  time.Duration
+	maxAttempts int
+
+	done    chan struct{}
+	stopped chan struct{}
+
+	replayMu sync.Mutex
+
+	statsMu sync.RWMutex
+	stats   MirrorStats
+	oldest  time.Time // queue time of the request being delivered
+}
+
+var mirrorOutboxes struct {
+	sync.RWMutex
+	dir         string
+	maxBackoff  time.Duration
+	maxAttempts int
+	targets     map[string]*mirrorTarget
+}
+
+// initMirrors sets the outbox directory and retry policy for mirror targets and resumes
+// delivery of any requests left in outboxes from a previous run.
+func initMirrors(dir string, maxBackoffSecs, maxAttempts int) error {
+	mirrorOutboxes.Lock()
+	mirrorOutboxes.dir = dir
+	mirrorOutboxes.maxBackoff = time.Duration(maxBackoffSecs) * time.Second
+	if mirrorOutboxes.maxBackoff <= 0 {
+		mirrorOutboxes.maxBackoff = DefaultMirrorMaxBackoff
+	}
+	mirrorOutboxes.maxAttempts = maxAttempts
+	mirrorOutboxes.Unlock()
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create mirror outbox directory %q: %v", dir, err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range files {
+		if !fi.IsDir() {
+			continue
+		}
+		target, err := url.QueryUnescape(fi.Name())
+		if err != nil {
+			continue
+		}
+		if _, err := getMirrorTarget(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMirrorTarget returns the mirror target for a server, starting delivery to it if
+// this is the first request.
+func getMirrorTarget(target string) (*mirrorTarget, error) {
+	mirrorOutboxes.RLock()
+	m, found := mirrorOutboxes.targets[target]
+	mirrorOutboxes.RUnlock()
+	if found {
+		return m, nil
+	}
+
+	mirrorOutboxes.Lock()
+	defer mirrorOutboxes.Unlock()
+	if m, found = mirrorOutboxes.targets[target]; found {
+		return m, nil
+	}
+	var outboxDir, deadDir string
+	if mirrorOutboxes.dir != "" {
+		outboxDir = filepath.Join(mirrorOutboxes.dir, url.QueryEscape(target))
+		deadDir = filepath.Join(outboxDir, "dead")
+	}
+	outbox, err := storage.NewOutbox(outboxDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open outbox for mirror %s: %v", target, err)
+	}
+	dead, err := storage.NewOutbox(deadDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open dead-letter outbox for mirror %s: %v", target, err)
+	}
+	maxBackoff := mirrorOutboxes.maxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMirrorMaxBackoff
+	}
+	client := &http.Client{Timeout: mirrorTimeout}
+	if clientTLS != nil {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: clientTLS,
+		}
+	}
+	m = &mirrorTarget{
+		url:         target,
+		outbox:      outbox,
+		dead:        dead,
+		client:      client,
+		maxBackoff:  maxBackoff,
+		maxAttempts: mirrorOutboxes.maxAttempts,
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	if mirrorOutboxes.targets == nil {
+		mirrorOutboxes.targets = make(map[string]*mirrorTarget)
+	}
+	mirrorOutboxes.targets[target] = m
+	dvid.Infof("Mirroring POSTs to %s (%d pending, %d dead letters)\n", target, outbox.NumPending(), dead.NumPending())
+	go m.deliver()
+	return m, nil
+}
+
+// queueMirrors persists a POST in the outbox of each mirror target for later delivery.
+// All targets are opened before queueing so a bad target doesn't leave the POST queued
+// for only some mirrors.
+func queueMirrors(targets []string, r *http.Request, body []byte) error {
+	header, err := json.Marshal(mirrorRequest{
+		Path:        r.URL.RequestURI(),
+		ContentType: r.Header.Get("Content-Type"),
+	})
+	if err != nil {
+		return err
+	}
+	mirrors := make([]*mirrorTarget, len(targets))
+	for i, target := range targets {
+		if mirrors[i], err = getMirrorTarget(target); err != nil {
+			return err
+		}
+	}
+	for i, m := range mirrors {
+		target := targets[i]
+		if err := m.outbox.Add(string(header), body); err != nil {
+			return fmt.Errorf("unable to queue POST %s for mirror %s: %v", r.URL.Path, target, err)
+		}
+	}
+	return nil
+}
+
+// GetMirrorStats returns delivery statistics for each mirror target.
+func GetMirrorStats() map[string]MirrorStats {
+	mirrorOutboxes.RLock()
+	defer mirrorOutboxes.RUnlock()
+	stats := make(map[string]MirrorStats, len(mirrorOutboxes.targets))
+	for target, m := range mirrorOutboxes.targets {
+		m.statsMu.RLock()
+		st := m.stats
+		oldest := m.oldest
+		m.statsMu.RUnlock()
+		st.Pending = m.outbox.NumPending()
+		st.DeadLetters = m.dead.NumPending()
+		if st.Pending != 0 && !oldest.IsZero() {
+			st.LagSeconds = time.Since(oldest).Seconds()
+		}
+		stats[target] = st
+	}
+	return stats
+}
+
+// ReplayMirrorDeadLetters moves dead letters back into the outbox of the given mirror
+// target, or all targets if target is empty, and returns the number replayed per target.
+// Replayed requests are delivered after any requests already pending.
+func ReplayMirrorDeadLetters(target string) (map[string]int, error) {
+	mirrorOutboxes.RLock()
+	var targets []*mirrorTarget
+	for name, m := range mirrorOutboxes.targets {
+		if target == "" || target == name {
+			targets = append(targets, m)
+		}
+	}
+	mirrorOutboxes.RUnlock()
+	if target != "" && len(targets) == 0 {
+		return nil, fmt.Errorf("no mirror target %q", target)
+	}
+	replayed := make(map[string]int, len(targets))
+	for _, m := range targets {
+		n, err := m.replay()
+		replayed[m.url] = n
+		if err != nil {
+			return replayed, err
+		}
+	}
+	return replayed, nil
+}
+
+// ShutdownMirrors stops delivery to all mirror targets.  Undelivered requests remain in
+// any on-disk outbox.
+func ShutdownMirrors() {
+	mirrorOutboxes.Lock()
+	targets := mirrorOutboxes.targets
+	mirrorOutboxes.targets = nil
+	mirrorOutboxes.Unlock()
+	for _, m := range targets {
+		close(m.done)
+		<-m.stopped
+	}
+}
+
+// replay moves the current dead letters to the end of the outbox.
+func (m *mirrorTarget) replay() (int, error) {
+	m.replayMu.Lock()
+	defer m.replayMu.Unlock()
+	closed := make(chan struct{})
+	close(closed)
+	num := m.dead.NumPending()
+	for i := 0; i < num; i++ {
+		entry, ok := m.dead.Next(closed)
+		if !ok {
+			return i, nil
+		}
+		if err := m.outbox.Add(entry.Topic, entry.Msg); err != nil {
+			return i, fmt.Errorf("unable to replay dead letter for mirror %s: %v", m.url, err)
+		}
+		if err := m.dead.Remove(entry.Seq); err != nil {
+			return i + 1, fmt.Errorf("unable to remove replayed dead letter for mirror %s: %v", m.url, err)
+		}
+	}
+	if num != 0 {
+		dvid.Infof("Replayed %d dead letters for mirror %s\n", num, m.url)
+	}
+	return num, nil
+}
+
+// deliver sends outbox requests in order, retrying each with exponential backoff.
+func (m *mirrorTarget) deliver() {
+	defer close(m.stopped)
+	for {
+		entry, ok := m.outbox.Next(m.done)
+		if !ok {
+			return
+		}
+		if entry.Queued != 0 {
+			m.statsMu.Lock()
+			m.oldest = time.Unix(0, entry.Queued)
+			m.statsMu.Unlock()
+		}
+
+		var req mirrorRequest
+		if err := json.Unmarshal([]byte(entry.Topic), &req); err != nil {
+			m.deadLetter(entry, fmt.Errorf("bad mirror request header %q: %v", entry.Topic, err))
+		} else {
+			backoff := mirrorInitialBackoff
+			for attempt := 1; ; attempt++ {
+				permanent, err := m.send(req, entry.Msg)
+				if err == nil {
+					m.statsMu.Lock()
+					m.stats.Delivered++
+					m.stats.LastDelivered = &MirrorDelivery{
+						Path:      req.Path,
+						Queued:    time.Unix(0, entry.Queued),
+						Delivered: time.Now(),
+					}
+					m.statsMu.Unlock()
+					break
+				}
+				m.statsMu.Lock()
+				m.stats.Failures++
+				m.stats.LastFailure = err.Error()
+				m.statsMu.Unlock()
+				if permanent || (m.maxAttempts > 0 && attempt >= m.maxAttempts) {
+					m.deadLetter(entry, err)
+					break
+				}
+				dvid.Errorf("mirror %s failed delivery of POST %s, retrying in %s: %v\n", m.url, req.Path, backoff, err)
+				select {
+				case <-m.done:
+					return
+				case <-time.After(backoff):
+				}
+				backoff *= 2
+				if backoff > m.maxBackoff {
+					backoff = m.maxBackoff
+				}
+			}
+		}
+		if err := m.outbox.Remove(entry.Seq); err != nil {
+			dvid.Errorf("unable to remove delivered request %d from outbox of mirror %s: %v\n", entry.Seq, m.url, err)
+		}
+		m.statsMu.Lock()
+		m.oldest = time.Time{}
+		m.statsMu.Unlock()
+	}
+}
+
+func (m *mirrorTarget) deadLetter(entry *storage.OutboxEntry, err error) {
+	dvid.Errorf("moving request to dead letters of mirror %s after error: %v\n", m.url, err)
+	if err := m.dead.Add(entry.Topic, entry.Msg); err != nil {
+		dvid.Criticalf("unable to save dead letter for mirror %s, dropping request %s: %v\n", m.url, entry.Topic, err)
+	}
+}
+
+// send POSTs a request to the mirror.  Errors are permanent if the mirror rejected the
+// request, so retrying would not succeed.
+func (m *mirrorTarget) send(req mirrorRequest, body []byte) (permanent bool, err error) {
+	resp, err := m.client.Post(m.url+req.Path, req.ContentType, bytes.NewBuffer(body))
+	if err != nil {
+		return false, err
+	}
+	msg, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	if len(msg) > 200 {
+		msg = msg[:200]
+	}
+	err = fmt.Errorf("mirror %s returned status %d for POST %s: %s", m.url, resp.StatusCode, req.Path, bytes.TrimSpace(msg))
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false, err
+	}
+	return resp.StatusCode >= 400 && resp.StatusCode < 500, err
+}
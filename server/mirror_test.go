@@ -0,0 +1,100 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitForMirror(t *testing.T, target string, done func(MirrorStats) bool) MirrorStats {
+	for i := 0; i < 200; i++ {
+		stats := GetMirrorStats()[target]
+		if done(stats) {
+			return stats
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := GetMirrorStats()[target]
+	t.Fatalf("timed out waiting for mirror %s, stats: %v\n", target, stats)
+	return stats
+}
+
+func TestMirrorDelivery(t *testing.T) {
+	oldBackoff := mirrorInitialBackoff
+	mirrorInitialBackoff = time.Millisecond
+	defer func() { mirrorInitialBackoff = oldBackoff }()
+	dir, err := ioutil.TempDir("", "dvid-test-mirror")
+	if err != nil {
+		t.Fatalf("couldn't create outbox dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// mirror is down for first attempts and rejects any "bad" POSTs.
+	var mu sync.Mutex
+	var received []string
+	failures := 3
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if strings.Contains(r.URL.Path, "bad") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		received = append(received, r.URL.RequestURI()+" "+string(body))
+	}))
+	defer ts.Close()
+
+	if err := initMirrors(dir, 1, 0); err != nil {
+		t.Fatalf("couldn't init mirrors: %v\n", err)
+	}
+	defer ShutdownMirrors()
+	for _, path := range []string{"/api/node/abc/seg/blocks?u=me", "/api/node/abc/bad/blocks", "/api/node/abc/seg/split/3"} {
+		r := httptest.NewRequest("POST", path, nil)
+		if err := queueMirrors([]string{ts.URL}, r, []byte("data")); err != nil {
+			t.Fatalf("couldn't queue mirror POST: %v\n", err)
+		}
+	}
+	stats := waitForMirror(t, ts.URL, func(st MirrorStats) bool { return st.Delivered == 2 && st.Pending == 0 })
+	if stats.DeadLetters != 1 || stats.Failures != 4 {
+		t.Errorf("bad mirror stats: %v\n", stats)
+	}
+	if stats.LastDelivered == nil || stats.LastDelivered.Path != "/api/node/abc/seg/split/3" {
+		t.Errorf("bad last delivered in mirror stats: %v\n", stats.LastDelivered)
+	}
+	mu.Lock()
+	if len(received) != 2 || received[0] != "/api/node/abc/seg/blocks?u=me data" || received[1] != "/api/node/abc/seg/split/3 data" {
+		t.Errorf("mirror received bad POSTs: %v\n", received)
+	}
+	mu.Unlock()
+
+	// dead letters are kept across restarts and can be replayed.
+	ShutdownMirrors()
+	if err := initMirrors(dir, 1, 0); err != nil {
+		t.Fatalf("couldn't init mirrors: %v\n", err)
+	}
+	if stats := GetMirrorStats()[ts.URL]; stats.DeadLetters != 1 {
+		t.Fatalf("expected dead letter after restart, got stats: %v\n", stats)
+	}
+	if _, err := ReplayMirrorDeadLetters("http://unknown:8000"); err == nil {
+		t.Errorf("expected error replaying dead letters of unknown mirror\n")
+	}
+	replayed, err := ReplayMirrorDeadLetters("")
+	if err != nil {
+		t.Fatalf("couldn't replay dead letters: %v\n", err)
+	}
+	if replayed[ts.URL] != 1 {
+		t.Errorf("expected 1 replayed dead letter, got %v\n", replayed)
+	}
+	waitForMirror(t, ts.URL, func(st MirrorStats) bool { return st.DeadLetters == 1 && st.Pending == 0 && st.Failures == 1 })
+}
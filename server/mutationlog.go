@@ -13,8 +13,6 @@ import (
 )
 
 var (
-	mutCfg MutationsConfig
-
 	mutOrderID  uint64
 	mutOrderMux sync.RWMutex
 )
@@ -28,7 +26,7 @@ type MutationsConfig struct {
 	Blobstore storage.Alias // alias to a store
 }
 
-func logMutationPayload(data []byte) (ref string, err error) {
+func logMutationPayload(mutCfg MutationsConfig, data []byte) (ref string, err error) {
 	var store dvid.Store
 	if store, err = storage.GetStoreByAlias(mutCfg.Blobstore); err != nil {
 		return
@@ -43,6 +41,7 @@ func logMutationPayload(data []byte) (ref string, err error) {
 
 // LogMutation logs a HTTP mutation request to the mutation log specific in the config.
 func LogMutation(versionID, dataID dvid.UUID, r *http.Request, data []byte) (err error) {
+	mutCfg := MutationLogSpec()
 	if mutCfg.Blobstore == "" || mutCfg.Logstore == "" {
 		return nil
 	}
@@ -58,7 +57,7 @@ func LogMutation(versionID, dataID dvid.UUID, r *http.Request, data []byte) (err
 	}
 	if len(data) != 0 {
 		var postRef string
-		if postRef, err = logMutationPayload(data); err != nil {
+		if postRef, err = logMutationPayload(mutCfg, data); err != nil {
 			return fmt.Errorf("unable to store mutation payload (%s): %v", r.RequestURI, err)
 		}
 		mutation["DataBytes"] = len(data)
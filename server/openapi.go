@@ -0,0 +1,265 @@
+/*
+   This file generates OpenAPI 3 descriptions of data instance HTTP APIs from the
+   routes registered by each datatype.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/janelia-flyem/dvid/datastore"
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+type openAPIDoc struct {
+	OpenAPI string                          `json:"openapi"`
+	Info    openAPIInfo                     `json:"info"`
+	Servers []openAPIServer                 `json:"servers"`
+	Paths   map[string]map[string]openAPIOp `json:"paths"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOp struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParam             `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParam struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Schema      openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+type openAPIBody struct {
+	Content map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema openAPISchema `json:"schema"`
+}
+
+func openAPIContent(contentTypes []string) map[string]openAPIMedia {
+	if len(contentTypes) == 0 {
+		return nil
+	}
+	content := make(map[string]openAPIMedia, len(contentTypes))
+	for _, contentType := range contentTypes {
+		var schema openAPISchema
+		switch {
+		case contentType == "application/json":
+			// any JSON value
+		case strings.HasPrefix(contentType, "text/"):
+			schema.Type = "string"
+		default:
+			schema.Type = "string"
+			schema.Format = "binary"
+		}
+		content[contentType] = openAPIMedia{schema}
+	}
+	return content
+}
+
+func openAPIParamFrom(p datastore.RouteParam, in string) openAPIParam {
+	paramType := p.Type
+	if paramType == "" {
+		paramType = "string"
+	}
+	return openAPIParam{
+		Name:        p.Name,
+		In:          in,
+		Description: p.Description,
+		Required:    p.Required || in == "path",
+		Schema:      openAPISchema{Type: paramType},
+	}
+}
+
+// operationID returns a client-friendly identifier for a route, e.g., "getKeyrange"
+// for GET /keyrange/{key1}/{key2}.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(path, "/") {
+		if part == "" || strings.HasPrefix(part, "{") {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+// uniqueOperationID returns the operation ID of a route, distinguished by its path
+// parameters or a number if another route already has the ID, e.g., GET /raw/{dims}
+// and GET /raw/{dims}/{format} are "getRaw" and "getRawByDimsFormat".
+func uniqueOperationID(used map[string]bool, route datastore.Route) string {
+	id := operationID(route.Method, route.Path)
+	if used[id] && len(route.PathParams) != 0 {
+		byID := id + "By"
+		for _, p := range route.PathParams {
+			for _, word := range strings.FieldsFunc(p.Name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+				byID += strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		id = byID
+	}
+	if used[id] {
+		base := id
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s%d", base, n)
+		}
+	}
+	used[id] = true
+	return id
+}
+
+// newOpenAPIDoc returns an OpenAPI document for the given datatype.  If uuid and
+// dataname are empty, paths are templated on them; otherwise the paths are specific
+// to the given data instance.
+func newOpenAPIDoc(t datastore.TypeService, uuid dvid.UUID, dataname dvid.InstanceName) openAPIDoc {
+	typename := t.GetTypeName()
+	doc := openAPIDoc{
+		OpenAPI: "3.0.0",
+		Info: openAPIInfo{
+			Title:   fmt.Sprintf("DVID %s API", typename),
+			Version: t.GetTypeVersion(),
+		},
+		Servers: []openAPIServer{{WebAPIPath[:len(WebAPIPath)-1]}},
+		Paths:   make(map[string]map[string]openAPIOp),
+	}
+	var prefix string
+	var instanceParams []openAPIParam
+	if uuid == "" {
+		prefix = "/node/{uuid}/{dataname}"
+		instanceParams = []openAPIParam{
+			openAPIParamFrom(datastore.RouteParam{Name: "uuid", Description: "Hexadecimal string with enough characters to uniquely identify a version node."}, "path"),
+			openAPIParamFrom(datastore.RouteParam{Name: "dataname", Description: fmt.Sprintf("Name of %s data instance.", typename)}, "path"),
+		}
+	} else {
+		prefix = fmt.Sprintf("/node/%s/%s", uuid, url.PathEscape(string(dataname)))
+		doc.Info.Title = fmt.Sprintf("DVID %s API for data %q in node %s", typename, dataname, uuid)
+	}
+
+	typeRoutes := datastore.TypeRoutes(typename)
+	if typeRoutes == nil {
+		doc.Info.Description = fmt.Sprintf("Datatype %s has not registered its endpoints. See %shelp/%s for its full API.", typename, WebAPIPath, typename)
+	}
+	rts := append(append([]datastore.Route{}, datastore.CommonRoutes...), typeRoutes...)
+	usedIDs := make(map[string]bool, len(rts))
+	for _, route := range rts {
+		op := openAPIOp{
+			OperationID: uniqueOperationID(usedIDs, route),
+			Summary:     route.Summary,
+			Tags:        []string{string(typename)},
+			Parameters:  append([]openAPIParam{}, instanceParams...),
+			Responses: map[string]openAPIResponse{
+				"200": {Description: "Success", Content: openAPIContent(route.Response)},
+				"400": {Description: "Bad request or server error", Content: openAPIContent([]string{"text/plain"})},
+			},
+		}
+		for _, p := range route.PathParams {
+			op.Parameters = append(op.Parameters, openAPIParamFrom(p, "path"))
+		}
+		for _, p := range route.Query {
+			op.Parameters = append(op.Parameters, openAPIParamFrom(p, "query"))
+		}
+		if len(route.Request) != 0 {
+			op.RequestBody = &openAPIBody{openAPIContent(route.Request)}
+		}
+		path := prefix + route.Path
+		if _, found := doc.Paths[path]; !found {
+			doc.Paths[path] = make(map[string]openAPIOp)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
+
+// openAPIHandler returns an OpenAPI document for a compiled datatype (?type=<typename>)
+// or a data instance (?uuid=<uuid>&data=<data name>), or an index of the available
+// documents if no query string is given.
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	queryStrings := r.URL.Query()
+	typename := queryStrings.Get("type")
+	uuidStr := queryStrings.Get("uuid")
+	dataname := dvid.InstanceName(queryStrings.Get("data"))
+
+	var doc interface{}
+	switch {
+	case typename != "":
+		t, err := datastore.TypeServiceByName(dvid.TypeString(typename))
+		if err != nil {
+			BadRequest(w, r, err)
+			return
+		}
+		doc = newOpenAPIDoc(t, "", "")
+	case uuidStr != "" && dataname != "":
+		uuid, _, err := datastore.MatchingUUID(uuidStr)
+		if err != nil {
+			BadRequest(w, r, err)
+			return
+		}
+		data, err := datastore.GetDataByUUIDName(uuid, dataname)
+		if err != nil {
+			BadRequest(w, r, err)
+			return
+		}
+		t, err := datastore.TypeServiceByName(data.TypeName())
+		if err != nil {
+			BadRequest(w, r, err)
+			return
+		}
+		doc = newOpenAPIDoc(t, uuid, dataname)
+	case uuidStr != "" || dataname != "":
+		BadRequest(w, r, "both uuid and data must be specified for an instance's OpenAPI document")
+		return
+	default:
+		var typenames []string
+		for name := range datastore.CompiledTypes() {
+			typenames = append(typenames, string(name))
+		}
+		sort.Strings(typenames)
+		index := make(map[string]string, len(typenames))
+		for _, name := range typenames {
+			index[name] = fmt.Sprintf("%sopenapi.json?type=%s", WebAPIPath, name)
+		}
+		doc = map[string]interface{}{"types": index}
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonBytes)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datastore"
+)
+
+func TestOperationID(t *testing.T) {
+	tests := []struct {
+		method, path, expected string
+	}{
+		{"GET", "/keyrange/{key1}/{key2}", "getKeyrange"},
+		{"POST", "/key/{key}", "postKey"},
+		{"GET", "/sparsevol-by-point/{coord}", "getSparsevolByPoint"},
+		{"DELETE", "/label_index", "deleteLabelIndex"},
+	}
+	for _, tc := range tests {
+		if id := operationID(tc.method, tc.path); id != tc.expected {
+			t.Errorf("expected operation id %q for %s %s, got %q\n", tc.expected, tc.method, tc.path, id)
+		}
+	}
+}
+
+func TestOpenAPIContent(t *testing.T) {
+	content := openAPIContent([]string{"application/json", "application/octet-stream", "text/plain"})
+	if content["application/json"].Schema.Type != "" {
+		t.Errorf("expected JSON content to have unconstrained schema\n")
+	}
+	if s := content["application/octet-stream"].Schema; s.Type != "string" || s.Format != "binary" {
+		t.Errorf("expected binary schema for octet-stream, got %v\n", s)
+	}
+	if s := content["text/plain"].Schema; s.Type != "string" || s.Format != "" {
+		t.Errorf("expected string schema for text, got %v\n", s)
+	}
+	if p := openAPIParamFrom(datastore.RouteParam{Name: "key"}, "path"); !p.Required || p.Schema.Type != "string" {
+		t.Errorf("expected path parameter to be a required string, got %v\n", p)
+	}
+}
+
+func TestUniqueOperationID(t *testing.T) {
+	dims := datastore.RouteParam{Name: "dims"}
+	format := datastore.RouteParam{Name: "format"}
+	rts := []datastore.Route{
+		{Method: "GET", Path: "/raw/{dims}", PathParams: []datastore.RouteParam{dims}},
+		{Method: "GET", Path: "/raw/{dims}/{format}", PathParams: []datastore.RouteParam{dims, format}},
+		{Method: "GET", Path: "/raw"},
+		{Method: "GET", Path: "/raw"},
+	}
+	expected := []string{"getRaw", "getRawByDimsFormat", "getRaw2", "getRaw3"}
+	used := make(map[string]bool)
+	for i, route := range rts {
+		if id := uniqueOperationID(used, route); id != expected[i] {
+			t.Errorf("expected operation id %q for route %d, got %q\n", expected[i], i, id)
+		}
+	}
+}
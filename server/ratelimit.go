@@ -0,0 +1,384 @@
+/*
+	This file provides per-client rate limiting of data instance requests using token
+	buckets for response bytes, request bytes, and CPU-heavy endpoints.
+*/
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/zenazn/goji/web"
+)
+
+const (
+	// DefaultRateLimitBurst is the default number of seconds of budget a client can accumulate.
+	DefaultRateLimitBurst = 10
+
+	// clients not seen for this long are dropped from the limiter.
+	rateLimitIdle = 10 * time.Minute
+
+	// maximum number of clients tracked.  When full, the least recently seen client is dropped.
+	rateLimitMaxClients = 10000
+)
+
+// DefaultHeavyEndpoints are the data instance endpoints counted against the CPU-heavy budget
+// if no endpoints are configured.
+var DefaultHeavyEndpoints = []string{
+	"raw", "isotropic", "arb", "sparsevol", "sparsevol-by-point", "sparsevols-coarse",
+	"split", "split-supervoxel", "cleave", "merge",
+}
+
+// RateLimits sets per-client budgets for data instance requests.  A client is the common
+// name of a verified TLS client certificate, or else the "u" query string user, or else the
+// "app" query string, or else the remote IP.  Since the query strings can be forged, they
+// can be ignored so clients without certificates are identified by IP.  Zero budgets are
+// not enforced.
+type RateLimits struct {
+	ReadMB         float64  // MB per second of response data
+	WriteMB        float64  // MB per second of request data
+	HeavyOps       float64  // requests per minute to CPU-heavy endpoints
+	Burst          int      // seconds of budget a client can accumulate.  Zero value = DefaultRateLimitBurst.
+	HeavyEndpoints []string // endpoint keywords, e.g., "sparsevol".  Default is DefaultHeavyEndpoints.
+	Exempt         []string // TLS client common names, users, apps or IPs that are not limited
+	IgnoreNames    bool     // if true, "u" and "app" query strings don't identify clients
+}
+
+func (cfg RateLimits) enabled() bool {
+	return cfg.ReadMB > 0 || cfg.WriteMB > 0 || cfg.HeavyOps > 0
+}
+
+// tokenBucket holds tokens that refill at a fixed rate up to a burst size.  Tokens can go
+// negative when a request is charged after it completes, e.g., for response bytes.
+type tokenBucket struct {
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burstSecs int, now time.Time) *tokenBucket {
+	burst := rate * float64(burstSecs)
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns the time until the given number of tokens are available.
+func (b *tokenBucket) wait(need float64, now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= need {
+		return 0
+	}
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+type clientLimits struct {
+	read, write, heavy *tokenBucket // nil if not limited
+	rejected           uint64
+	lastSeen           time.Time
+}
+
+var rateLimiter struct {
+	sync.Mutex
+	cfg       RateLimits
+	heavy     map[string]struct{}
+	exempt    map[string]struct{}
+	clients   map[string]*clientLimits
+	rejected  uint64
+	lastSweep time.Time
+}
+
+// SetRateLimits configures per-client rate limiting, replacing any previous limits and
+// resetting all client budgets and statistics.
+func SetRateLimits(cfg RateLimits) {
+	if cfg.Burst <= 0 {
+		cfg.Burst = DefaultRateLimitBurst
+	}
+	if len(cfg.HeavyEndpoints) == 0 {
+		cfg.HeavyEndpoints = DefaultHeavyEndpoints
+	}
+	rateLimiter.Lock()
+	rateLimiter.cfg = cfg
+	rateLimiter.heavy = make(map[string]struct{}, len(cfg.HeavyEndpoints))
+	for _, keyword := range cfg.HeavyEndpoints {
+		rateLimiter.heavy[keyword] = struct{}{}
+	}
+	rateLimiter.exempt = make(map[string]struct{}, len(cfg.Exempt))
+	for _, name := range cfg.Exempt {
+		rateLimiter.exempt[name] = struct{}{}
+	}
+	rateLimiter.clients = make(map[string]*clientLimits)
+	rateLimiter.rejected = 0
+	rateLimiter.Unlock()
+	if cfg.enabled() {
+		dvid.Infof("Rate limiting clients to %g MB/s reads, %g MB/s writes, %g heavy ops/min with %d sec burst\n",
+			cfg.ReadMB, cfg.WriteMB, cfg.HeavyOps, cfg.Burst)
+	}
+}
+
+// rateLimitClient returns the client identifier and name used for exemptions, which is
+// the common name of a verified TLS client certificate, else the "u" or "app" query string
+// unless names are ignored, else the remote IP.
+func rateLimitClient(r *http.Request, ignoreNames bool) (client, name string) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) != 0 && len(r.TLS.PeerCertificates) != 0 {
+		name = r.TLS.PeerCertificates[0].Subject.CommonName
+		return "cert:" + name, name
+	}
+	if !ignoreNames {
+		query := r.URL.Query()
+		if name = query.Get("u"); name != "" {
+			return "user:" + name, name
+		}
+		if name = query.Get("app"); name != "" {
+			return "app:" + name, name
+		}
+	}
+	name, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		name = r.RemoteAddr
+	}
+	return "ip:" + name, name
+}
+
+// getClient returns the limits for a client, creating them if necessary.  Returns nil if
+// the client is not limited.  Must be called with the rateLimiter lock held.
+func getClient(client, name string, now time.Time) *clientLimits {
+	cfg := rateLimiter.cfg
+	if !cfg.enabled() {
+		return nil
+	}
+	if _, exempt := rateLimiter.exempt[name]; exempt {
+		return nil
+	}
+	if now.Sub(rateLimiter.lastSweep) > time.Minute {
+		for c, limits := range rateLimiter.clients {
+			if now.Sub(limits.lastSeen) > rateLimitIdle {
+				delete(rateLimiter.clients, c)
+			}
+		}
+		rateLimiter.lastSweep = now
+	}
+	limits, found := rateLimiter.clients[client]
+	if !found {
+		if len(rateLimiter.clients) >= rateLimitMaxClients {
+			dropLeastRecentClient()
+		}
+		limits = new(clientLimits)
+		if cfg.ReadMB > 0 {
+			limits.read = newTokenBucket(cfg.ReadMB*dvid.Mega, cfg.Burst, now)
+		}
+		if cfg.WriteMB > 0 {
+			limits.write = newTokenBucket(cfg.WriteMB*dvid.Mega, cfg.Burst, now)
+		}
+		if cfg.HeavyOps > 0 {
+			limits.heavy = newTokenBucket(cfg.HeavyOps/60, cfg.Burst, now)
+			if limits.heavy.burst < 1 {
+				limits.heavy.burst = 1
+				limits.heavy.tokens = 1
+			}
+		}
+		rateLimiter.clients[client] = limits
+	}
+	limits.lastSeen = now
+	return limits
+}
+
+// dropLeastRecentClient removes the client that was least recently seen.  Must be called
+// with the rateLimiter lock held.
+func dropLeastRecentClient() {
+	var oldest string
+	var oldestSeen time.Time
+	for c, limits := range rateLimiter.clients {
+		if oldest == "" || limits.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = c, limits.lastSeen
+		}
+	}
+	delete(rateLimiter.clients, oldest)
+}
+
+// admitRequest returns false if the client is not limited.  Otherwise it returns zero
+// retry if the client can start the request, or the time until it can be retried and the
+// name of the exceeded budget.  Bytes are charged via chargeRequest after the request
+// completes, so clients are admitted while they have any byte budget left.
+func admitRequest(client, name, keyword, method string, contentLength int64, now time.Time) (limited bool, retry time.Duration, budget string) {
+	rateLimiter.Lock()
+	defer rateLimiter.Unlock()
+
+	limits := getClient(client, name, now)
+	if limits == nil {
+		return false, 0, ""
+	}
+	limited = true
+	_, heavy := rateLimiter.heavy[keyword]
+	if heavy && limits.heavy != nil {
+		if retry = limits.heavy.wait(1, now); retry > 0 {
+			budget = "CPU-heavy request"
+		}
+	}
+	if retry == 0 && limits.read != nil && (method == "GET" || method == "HEAD") {
+		if retry = limits.read.wait(1, now); retry > 0 {
+			budget = "read bytes"
+		}
+	}
+	if retry == 0 && limits.write != nil && contentLength != 0 && method != "GET" && method != "HEAD" {
+		need := math.Max(1, math.Min(float64(contentLength), limits.write.burst))
+		if retry = limits.write.wait(need, now); retry > 0 {
+			budget = "write bytes"
+		}
+	}
+	if retry > 0 {
+		limits.rejected++
+		rateLimiter.rejected++
+		return
+	}
+	if heavy && limits.heavy != nil {
+		limits.heavy.tokens--
+	}
+	return
+}
+
+// chargeRequest deducts the bytes transferred by a completed request from a client's budgets.
+func chargeRequest(client string, readBytes, writeBytes int64, now time.Time) {
+	rateLimiter.Lock()
+	defer rateLimiter.Unlock()
+	limits, found := rateLimiter.clients[client]
+	if !found {
+		return
+	}
+	if limits.read != nil {
+		limits.read.refill(now)
+		limits.read.tokens -= float64(readBytes)
+	}
+	if limits.write != nil {
+		limits.write.refill(now)
+		limits.write.tokens -= float64(writeBytes)
+	}
+}
+
+// RateLimitStats returns the rate limiting configuration and the budgets of clients
+// that have made requests recently, or nil if rate limiting is not enabled.
+func RateLimitStats() map[string]interface{} {
+	rateLimiter.Lock()
+	defer rateLimiter.Unlock()
+	cfg := rateLimiter.cfg
+	if !cfg.enabled() {
+		return nil
+	}
+	now := time.Now()
+	clients := make(map[string]interface{}, len(rateLimiter.clients))
+	for client, limits := range rateLimiter.clients {
+		stats := map[string]interface{}{
+			"Rejected":  limits.rejected,
+			"Last seen": limits.lastSeen.Format(time.RFC3339),
+		}
+		if limits.read != nil {
+			limits.read.refill(now)
+			stats["Read MB available"] = limits.read.tokens / dvid.Mega
+		}
+		if limits.write != nil {
+			limits.write.refill(now)
+			stats["Write MB available"] = limits.write.tokens / dvid.Mega
+		}
+		if limits.heavy != nil {
+			limits.heavy.refill(now)
+			stats["Heavy ops available"] = limits.heavy.tokens
+		}
+		clients[client] = stats
+	}
+	heavy := make([]string, 0, len(rateLimiter.heavy))
+	for keyword := range rateLimiter.heavy {
+		heavy = append(heavy, keyword)
+	}
+	sort.Strings(heavy)
+	return map[string]interface{}{
+		"Read MB/sec":       cfg.ReadMB,
+		"Write MB/sec":      cfg.WriteMB,
+		"Heavy ops/min":     cfg.HeavyOps,
+		"Burst seconds":     cfg.Burst,
+		"Heavy endpoints":   heavy,
+		"Rejected requests": rateLimiter.rejected,
+		"Clients":           clients,
+	}
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush allows streaming handlers to flush through the counting writer.
+func (w *countingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Middleware that rejects data instance requests from clients over their budgets with
+// a 429 status and Retry-After header, and charges admitted requests for bytes transferred.
+func rateLimitHandler(c *web.C, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rateLimiter.Lock()
+		ignoreNames := rateLimiter.cfg.IgnoreNames
+		rateLimiter.Unlock()
+		client, name := rateLimitClient(r, ignoreNames)
+		limited, retry, budget := admitRequest(client, name, c.URLParams["keyword"], r.Method, r.ContentLength, time.Now())
+		if !limited {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if retry > 0 {
+			secs := int(math.Ceil(retry.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			msg := fmt.Sprintf("client %q exceeded its %s budget; retry after %d seconds", client, budget, secs)
+			dvid.Infof("%s: %s\n", r.URL.Path, msg)
+			http.Error(w, msg, http.StatusTooManyRequests)
+			return
+		}
+		var body *countingReader
+		if r.Body != nil {
+			body = &countingReader{ReadCloser: r.Body}
+			r.Body = body
+		}
+		cw := &countingWriter{ResponseWriter: w}
+		h.ServeHTTP(cw, r)
+
+		var writeBytes int64
+		if body != nil {
+			writeBytes = body.n
+		}
+		chargeRequest(client, cw.n, writeBytes, time.Now())
+	}
+	return http.HandlerFunc(fn)
+}
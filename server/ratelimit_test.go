@@ -0,0 +1,122 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+func TestRateLimitHeavy(t *testing.T) {
+	SetRateLimits(RateLimits{HeavyOps: 60, Burst: 2, Exempt: []string{"batch"}})
+	defer SetRateLimits(RateLimits{})
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if limited, retry, _ := admitRequest("cert:alice", "alice", "sparsevol", "GET", 0, now); !limited || retry != 0 {
+			t.Fatalf("expected heavy request %d to be admitted, got limited %t, retry %s\n", i, limited, retry)
+		}
+	}
+	limited, retry, budget := admitRequest("cert:alice", "alice", "sparsevol", "GET", 0, now)
+	if !limited || retry != time.Second || budget != "CPU-heavy request" {
+		t.Fatalf("expected heavy request rejection with 1 sec retry, got %s for %q\n", retry, budget)
+	}
+	if _, retry, _ := admitRequest("cert:alice", "alice", "info", "GET", 0, now); retry != 0 {
+		t.Fatalf("expected light request to be admitted, got retry %s\n", retry)
+	}
+	if _, retry, _ := admitRequest("cert:bob", "bob", "sparsevol", "GET", 0, now); retry != 0 {
+		t.Fatalf("expected other client to have its own budget, got retry %s\n", retry)
+	}
+	if _, retry, _ := admitRequest("cert:alice", "alice", "sparsevol", "GET", 0, now.Add(time.Second)); retry != 0 {
+		t.Fatalf("expected budget to refill after a second, got retry %s\n", retry)
+	}
+	if limited, _, _ := admitRequest("cert:batch", "batch", "sparsevol", "GET", 0, now); limited {
+		t.Fatalf("expected exempt client to not be limited\n")
+	}
+}
+
+func TestRateLimitBytes(t *testing.T) {
+	SetRateLimits(RateLimits{ReadMB: 1, WriteMB: 1, Burst: 1})
+	defer SetRateLimits(RateLimits{})
+
+	now := time.Now()
+	if _, retry, _ := admitRequest("ip:1.2.3.4", "1.2.3.4", "raw", "GET", 0, now); retry != 0 {
+		t.Fatalf("expected read to be admitted, got retry %s\n", retry)
+	}
+	chargeRequest("ip:1.2.3.4", 3*dvid.Mega, 0, now)
+	_, retry, budget := admitRequest("ip:1.2.3.4", "1.2.3.4", "raw", "GET", 0, now)
+	if retry <= 2*time.Second || budget != "read bytes" {
+		t.Fatalf("expected read rejection for over 2 seconds, got %s for %q\n", retry, budget)
+	}
+	if _, retry, _ := admitRequest("ip:1.2.3.4", "1.2.3.4", "raw", "POST", dvid.Mega/2, now); retry != 0 {
+		t.Fatalf("expected write budget to be separate from read budget, got retry %s\n", retry)
+	}
+	chargeRequest("ip:1.2.3.4", 0, dvid.Mega, now)
+	if _, retry, budget := admitRequest("ip:1.2.3.4", "1.2.3.4", "raw", "POST", dvid.Mega/2, now); retry == 0 || budget != "write bytes" {
+		t.Fatalf("expected write rejection after budget used\n")
+	}
+	stats := RateLimitStats()
+	if stats == nil || stats["Rejected requests"].(uint64) != 2 {
+		t.Fatalf("expected 2 rejected requests in stats, got %v\n", stats)
+	}
+}
+
+func TestRateLimitClient(t *testing.T) {
+	r, err := http.NewRequest("GET", WebAPIPath+"node/1234/labels/sparsevol/1?u=alice&app=neutu", nil)
+	if err != nil {
+		t.Fatalf("bad request: %v\n", err)
+	}
+	r.RemoteAddr = "1.2.3.4:5678"
+	if client, name := rateLimitClient(r, false); client != "user:alice" || name != "alice" {
+		t.Fatalf("expected client from user query string, got %q, %q\n", client, name)
+	}
+	if client, name := rateLimitClient(r, true); client != "ip:1.2.3.4" || name != "1.2.3.4" {
+		t.Fatalf("expected client from remote IP when names are ignored, got %q, %q\n", client, name)
+	}
+	r.URL.RawQuery = "app=neutu"
+	if client, name := rateLimitClient(r, false); client != "app:neutu" || name != "neutu" {
+		t.Fatalf("expected client from app query string, got %q, %q\n", client, name)
+	}
+	r.URL.RawQuery = ""
+	if client, name := rateLimitClient(r, false); client != "ip:1.2.3.4" || name != "1.2.3.4" {
+		t.Fatalf("expected client from remote IP without query strings, got %q, %q\n", client, name)
+	}
+
+	r.URL.RawQuery = "u=alice"
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "batch"}}
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if client, _ := rateLimitClient(r, false); client != "user:alice" {
+		t.Fatalf("expected unverified certificate to be ignored, got %q\n", client)
+	}
+	r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	if client, name := rateLimitClient(r, false); client != "cert:batch" || name != "batch" {
+		t.Fatalf("expected client from verified certificate, got %q, %q\n", client, name)
+	}
+}
+
+func TestRateLimitMaxClients(t *testing.T) {
+	SetRateLimits(RateLimits{HeavyOps: 60})
+	defer SetRateLimits(RateLimits{})
+
+	now := time.Now()
+	for i := 0; i < rateLimitMaxClients+10; i++ {
+		client := fmt.Sprintf("ip:%d", i)
+		admitRequest(client, client, "sparsevol", "GET", 0, now.Add(time.Duration(i)*time.Millisecond))
+	}
+	rateLimiter.Lock()
+	numClients := len(rateLimiter.clients)
+	_, oldestFound := rateLimiter.clients["ip:0"]
+	_, newestFound := rateLimiter.clients[fmt.Sprintf("ip:%d", rateLimitMaxClients+9)]
+	rateLimiter.Unlock()
+	if numClients != rateLimitMaxClients {
+		t.Fatalf("expected %d clients tracked, got %d\n", rateLimitMaxClients, numClients)
+	}
+	if oldestFound || !newestFound {
+		t.Fatalf("expected least recently seen clients to be dropped\n")
+	}
+}
@@ -0,0 +1,206 @@
+// +build !clustered,!gcloud
+
+/*
+	This file supports reloading the TOML configuration of a running server.  Settings
+	that can be changed safely are applied immediately while others are reported as
+	requiring a restart.
+*/
+
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+var (
+	// configFile is the TOML file last loaded by LoadConfig.
+	configFile string
+
+	// tcMu guards the sections of the configuration that can change on reload.
+	tcMu sync.RWMutex
+
+	// reloadMu serializes configuration reloads.
+	reloadMu sync.Mutex
+)
+
+// ConfigReload describes the result of reloading the TOML configuration.
+type ConfigReload struct {
+	Applied         []string // settings whose changes were applied to the running server
+	RestartRequired []string // settings whose changes will only be used after a restart
+}
+
+// ReloadConfig re-reads the TOML configuration file given to LoadConfig, validates it,
+// and applies changes to logging, email, mirrors, mutation logging, and rate limits.
+// Changes to any other settings are reported as requiring a restart.  If the new
+// configuration is invalid, an error is returned and no changes are applied.
+func ReloadConfig() (*ConfigReload, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if configFile == "" {
+		return nil, fmt.Errorf("no TOML configuration file was loaded by this server")
+	}
+	var newTC tomlConfig
+	newTC.Server.ShutdownDelay = defaultShutdownDelay
+	if _, err := toml.DecodeFile(configFile, &newTC); err != nil {
+		return nil, fmt.Errorf("could not decode TOML config %q: %v", configFile, err)
+	}
+	if err := newTC.convertPathsToAbsolute(configFile); err != nil {
+		return nil, fmt.Errorf("could not convert relative paths to absolute paths in TOML config: %v", err)
+	}
+	newTC.Server.setDefaults()
+
+	tcMu.RLock()
+	oldTC := tc
+	tcMu.RUnlock()
+
+	if err := validateReload(oldTC, newTC); err != nil {
+		return nil, fmt.Errorf("TOML config %q not reloaded: %v", configFile, err)
+	}
+
+	result := new(ConfigReload)
+	oldVal := reflect.ValueOf(oldTC)
+	newVal := reflect.ValueOf(newTC)
+	for i := 0; i < oldVal.NumField(); i++ {
+		section := strings.ToLower(oldVal.Type().Field(i).Name)
+		if section == "server" {
+			continue
+		}
+		if reflect.DeepEqual(oldVal.Field(i).Interface(), newVal.Field(i).Interface()) {
+			continue
+		}
+		switch section {
+		case "logging", "email", "mirror", "mutations":
+			result.Applied = append(result.Applied, section)
+		default:
+			result.RestartRequired = append(result.RestartRequired, section)
+		}
+	}
+	rateLimitsChanged := false
+	oldVal = reflect.ValueOf(oldTC.Server)
+	newVal = reflect.ValueOf(newTC.Server)
+	for i := 0; i < oldVal.NumField(); i++ {
+		field := oldVal.Type().Field(i)
+		if reflect.DeepEqual(oldVal.Field(i).Interface(), newVal.Field(i).Interface()) {
+			continue
+		}
+		if strings.HasPrefix(field.Name, "RateLimit") {
+			rateLimitsChanged = true
+			continue
+		}
+		result.RestartRequired = append(result.RestartRequired, "server."+tomlKey(field))
+	}
+	if rateLimitsChanged {
+		result.Applied = append(result.Applied, "server.rateLimit")
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.RestartRequired)
+
+	tcMu.Lock()
+	tc.Logging = newTC.Logging
+	tc.Email = newTC.Email
+	tc.Mirror = newTC.Mirror
+	tc.Mutations = newTC.Mutations
+	tc.Server.RateLimitReadMB = newTC.Server.RateLimitReadMB
+	tc.Server.RateLimitWriteMB = newTC.Server.RateLimitWriteMB
+	tc.Server.RateLimitHeavyOps = newTC.Server.RateLimitHeavyOps
+	tc.Server.RateLimitBurst = newTC.Server.RateLimitBurst
+	tc.Server.RateLimitHeavyEndpoints = newTC.Server.RateLimitHeavyEndpoints
+	tc.Server.RateLimitExempt = newTC.Server.RateLimitExempt
+	tc.Server.RateLimitIgnoreNames = newTC.Server.RateLimitIgnoreNames
+	tcMu.Unlock()
+
+	if !reflect.DeepEqual(oldTC.Logging, newTC.Logging) {
+		newTC.Logging.SetLogger()
+	}
+	if !reflect.DeepEqual(oldTC.Email, newTC.Email) {
+		dvid.SetEmailServer(newTC.Email)
+	}
+	if rateLimitsChanged {
+		sc := newTC.Server
+		SetRateLimits(RateLimits{
+			ReadMB:         sc.RateLimitReadMB,
+			WriteMB:        sc.RateLimitWriteMB,
+			HeavyOps:       sc.RateLimitHeavyOps,
+			Burst:          sc.RateLimitBurst,
+			HeavyEndpoints: sc.RateLimitHeavyEndpoints,
+			Exempt:         sc.RateLimitExempt,
+			IgnoreNames:    sc.RateLimitIgnoreNames,
+		})
+	}
+	dvid.Infof("Reloaded TOML config %q: applied %v, restart required for %v\n",
+		configFile, result.Applied, result.RestartRequired)
+	return result, nil
+}
+
+// validateReload checks the settings that would be applied on reload.
+func validateReload(oldTC, newTC tomlConfig) error {
+	if newTC.Mutations != oldTC.Mutations {
+		mc := newTC.Mutations
+		if mc.Logstore != "" {
+			parts := strings.Split(mc.Logstore, ":")
+			if len(parts) != 2 {
+				return fmt.Errorf("bad mutations logstore specification %q", mc.Logstore)
+			}
+			switch parts[0] {
+			case "kafka":
+			case "logstore":
+				store, err := storage.GetStoreByAlias(storage.Alias(parts[1]))
+				if err != nil {
+					return fmt.Errorf("mutations logstore %q is not an open store: %v", parts[1], err)
+				}
+				if _, ok := store.(storage.LogWritable); !ok {
+					return fmt.Errorf("mutations logstore %q is not a valid write log", parts[1])
+				}
+			default:
+				return fmt.Errorf("unknown store %q in mutations logstore specification %q", parts[0], mc.Logstore)
+			}
+		}
+		if mc.Blobstore != "" {
+			store, err := storage.GetStoreByAlias(mc.Blobstore)
+			if err != nil {
+				return fmt.Errorf("mutations blobstore %q is not an open store: %v", mc.Blobstore, err)
+			}
+			if _, ok := store.(storage.BlobStore); !ok {
+				return fmt.Errorf("mutations blobstore %q is not a valid blob store", mc.Blobstore)
+			}
+		}
+	}
+	for dataspec, mirror := range newTC.Mirror {
+		if len(mirror.Servers) == 0 {
+			return fmt.Errorf("mirror %s has no servers", dataspec)
+		}
+		for _, server := range mirror.Servers {
+			if server == "" {
+				return fmt.Errorf("mirror %s has an empty server address", dataspec)
+			}
+		}
+	}
+	sc := newTC.Server
+	if sc.RateLimitReadMB < 0 || sc.RateLimitWriteMB < 0 || sc.RateLimitHeavyOps < 0 || sc.RateLimitBurst < 0 {
+		return fmt.Errorf("rate limits cannot be negative")
+	}
+	return nil
+}
+
+// tomlKey returns the TOML key for a field as it is typically written in config files,
+// e.g., "httpAddress" for HTTPAddress.
+func tomlKey(field reflect.StructField) string {
+	if tag := field.Tag.Get("toml"); tag != "" {
+		return tag
+	}
+	name := field.Name
+	n := 1
+	for n < len(name) && name[n] >= 'A' && name[n] <= 'Z' && (n+1 == len(name) || name[n+1] >= 'A' && name[n+1] <= 'Z') {
+		n++
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
@@ -7,6 +7,7 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -168,7 +169,7 @@ func init() {
 
 // SendRPC sends a request to a remote DVID.
 func SendRPC(addr string, req datastore.Request) error {
-	c := gorpc.NewTCPClient(addr)
+	c := rpc.NewClient(addr)
 	c.Start()
 	defer c.Stop()
 
@@ -187,6 +188,23 @@ func SendRPC(addr string, req datastore.Request) error {
 
 // switchboard for remote command execution
 func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error) {
+	return execCommand(cmd, false)
+}
+
+// runCommandWork runs the long-running part of a command.  If wait is true, it runs
+// before returning, e.g., within an admin job that tracks completion.  Otherwise it runs
+// as a new job and the returned string notes the job ID.
+func runCommandWork(wait bool, cmd *datastore.Request, f func() (string, error)) (string, error) {
+	if wait {
+		return f()
+	}
+	job := StartJob(cmd.String(), f)
+	return fmt.Sprintf(" (job %s)", job), nil
+}
+
+// execCommand executes a command, waiting for any long-running work to complete if
+// wait is true.
+func execCommand(cmd *datastore.Request, wait bool) (reply *datastore.Response, err error) {
 	if cmd.Name() == "" {
 		err = fmt.Errorf("server error: got empty command")
 		return
@@ -423,13 +441,23 @@ func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error
 			datastore.AddToRepoLog(uuid, []string{cmd.String()})
 
 		case "storage-details":
-			go func() {
-				_, err := datastore.GetStorageDetails()
+			var text string
+			text, err = runCommandWork(wait, cmd, func() (string, error) {
+				details, err := datastore.GetStorageDetails()
 				if err != nil {
-					dvid.Errorf("storage-details: %v\n", err)
+					return "", err
 				}
-			}()
-			reply.Text = "Started storage details dump in log..."
+				jsonBytes, err := json.Marshal(details)
+				return string(jsonBytes), err
+			})
+			if err != nil {
+				return
+			}
+			if wait {
+				reply.Text = text
+			} else {
+				reply.Text = "Started storage details dump in log" + text + "..."
+			}
 
 		case "flatten-mutations":
 			var dataStr, filename string
@@ -442,6 +470,7 @@ func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error
 			dumper, ok := d.(datastore.MutationDumper)
 			if !ok {
 				reply.Text = fmt.Sprintf("The data UUID %s (name %q) does not support mutation dumping\n", dataStr, d.DataName())
+				return
 			}
 			reply.Text, err = dumper.DumpMutations(uuid, filename)
 			if err != nil {
@@ -457,34 +486,52 @@ func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error
 				return
 			}
 			config := cmd.Settings()
-			go func() {
-				if err = datastore.MigrateInstance(uuid, dvid.InstanceName(source), store, config); err != nil {
-					dvid.Errorf("migrate error: %v\n", err)
-				}
-			}()
-			reply.Text = fmt.Sprintf("Started migration of uuid %s data instance %q from old store %q...\n", uuid, source, oldStoreName)
+			var text string
+			text, err = runCommandWork(wait, cmd, func() (string, error) {
+				return "", datastore.MigrateInstance(uuid, dvid.InstanceName(source), store, config)
+			})
+			if err != nil {
+				return
+			}
+			if wait {
+				reply.Text = fmt.Sprintf("Migrated uuid %s data instance %q from old store %q\n", uuid, source, oldStoreName)
+			} else {
+				reply.Text = fmt.Sprintf("Started migration of uuid %s data instance %q from old store %q%s...\n", uuid, source, oldStoreName, text)
+			}
 
 		case "copy":
 			var source, target string
 			cmd.CommandArgs(3, &source, &target)
 			config := cmd.Settings()
-			go func() {
-				if err = datastore.CopyInstance(uuid, dvid.InstanceName(source), dvid.InstanceName(target), config); err != nil {
-					dvid.Errorf("copy error: %v\n", err)
-				}
-			}()
-			reply.Text = fmt.Sprintf("Started copy of uuid %s data instance %q to %q...\n", uuid, source, target)
+			var text string
+			text, err = runCommandWork(wait, cmd, func() (string, error) {
+				return "", datastore.CopyInstance(uuid, dvid.InstanceName(source), dvid.InstanceName(target), config)
+			})
+			if err != nil {
+				return
+			}
+			if wait {
+				reply.Text = fmt.Sprintf("Copied uuid %s data instance %q to %q\n", uuid, source, target)
+			} else {
+				reply.Text = fmt.Sprintf("Started copy of uuid %s data instance %q to %q%s...\n", uuid, source, target, text)
+			}
 
 		case "push":
 			var target string
 			cmd.CommandArgs(3, &target)
 			config := cmd.Settings()
-			go func() {
-				if err = datastore.PushRepo(uuid, target, config); err != nil {
-					dvid.Errorf("push error: %v\n", err)
-				}
-			}()
-			reply.Text = fmt.Sprintf("Started push of repo %s to %q...\n", uuid, target)
+			var text string
+			text, err = runCommandWork(wait, cmd, func() (string, error) {
+				return "", datastore.PushRepo(uuid, target, config)
+			})
+			if err != nil {
+				return
+			}
+			if wait {
+				reply.Text = fmt.Sprintf("Pushed repo %s to %q\n", uuid, target)
+			} else {
+				reply.Text = fmt.Sprintf("Started push of repo %s to %q%s...\n", uuid, target, text)
+			}
 
 			/*
 				case "pull":
@@ -521,12 +568,6 @@ func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error
 			reply.Text = fmt.Sprintf("Started deletion of data instance %q from repo with root %s\n", dataname, uuid)
 
 		case "delete-class":
-			// Apply a global lock (if relevant) and reloads meta
-			if err = datastore.MetadataUniversalLock(); err != nil {
-				return
-			}
-			defer datastore.MetadataUniversalUnlock()
-
 			var dataname, classStr, versionsStr string
 			cmd.CommandArgs(3, &dataname, &classStr, &versionsStr)
 			var allVersions bool
@@ -539,8 +580,14 @@ func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error
 			}
 			tkclass := storage.TKeyClass(classUint64)
 
+			// Apply a global lock (if relevant) and reloads meta
+			if err = datastore.MetadataUniversalLock(); err != nil {
+				return
+			}
 			var d datastore.DataService
-			if d, err = datastore.GetDataByUUIDName(uuid, dvid.InstanceName(dataname)); err != nil {
+			d, err = datastore.GetDataByUUIDName(uuid, dvid.InstanceName(dataname))
+			datastore.MetadataUniversalUnlock()
+			if err != nil {
 				err = fmt.Errorf("Error trying to delete class of kv in %q for UUID %s: %v", dataname, uuid, err)
 				return
 			}
@@ -553,13 +600,19 @@ func handleCommand(cmd *datastore.Request) (reply *datastore.Response, err error
 				reply.Text = fmt.Sprintf("The data instance %q does not support type-specific key class deletions\n", dataname)
 				return
 			}
-			go func() {
+			var text string
+			text, err = runCommandWork(wait, cmd, func() (string, error) {
 				ctx := datastore.NewVersionedCtx(d, v)
-				if err = deleter.DeleteTKeyClass(ctx, tkclass, allVersions); err != nil {
-					return
-				}
-			}()
-			reply.Text = fmt.Sprintf("Started deletion of type-specific key class %d for data instance %q, version %s (all versions = %t)\n", tkclass, dataname, uuid, allVersions)
+				return "", deleter.DeleteTKeyClass(ctx, tkclass, allVersions)
+			})
+			if err != nil {
+				return
+			}
+			if wait {
+				reply.Text = fmt.Sprintf("Deleted type-specific key class %d for data instance %q, version %s (all versions = %t)\n", tkclass, dataname, uuid, allVersions)
+			} else {
+				reply.Text = fmt.Sprintf("Started deletion of type-specific key class %d for data instance %q, version %s (all versions = %t)%s\n", tkclass, dataname, uuid, allVersions, text)
+			}
 
 		default:
 			err = fmt.Errorf("Unknown command: %q", cmd)
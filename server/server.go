@@ -113,6 +113,20 @@ var (
 
 const defaultGCPercent = 400
 
+const (
+	// DefaultShutdownHTTPTimeout is the default time to wait for in-flight HTTP requests
+	// to finish on shutdown.
+	DefaultShutdownHTTPTimeout = 60 * time.Second
+
+	// DefaultShutdownMutationTimeout is the default time to wait for in-flight mutations,
+	// syncs and down-resolution computations to finish on shutdown.
+	DefaultShutdownMutationTimeout = 5 * time.Minute
+
+	// DefaultPaintMaxBlocks is the default maximum number of blocks intersected by a
+	// single paint stroke.
+	DefaultPaintMaxBlocks = 4096
+)
+
 func init() {
 	shutdownCh = make(chan struct{})
 
@@ -214,7 +228,7 @@ func SetFullWrite(on bool) {
 
 // AboutJSON returns a JSON string describing the properties of this server.
 func AboutJSON() (jsonStr string, err error) {
-	data := map[string]string{
+	data := map[string]interface{}{
 		"Cores":             fmt.Sprintf("%d", dvid.NumCPU),
 		"Maximum Cores":     fmt.Sprintf("%d", runtime.NumCPU()),
 		"Datastore Version": datastore.Version,
@@ -240,6 +254,30 @@ func AboutJSON() (jsonStr string, err error) {
 	if KafkaPrefixTopic() != "" {
 		data["Kafka Topic Prefix"] = KafkaPrefixTopic()
 	}
+	if storage.EventSinksAvailable() {
+		data["Event Sinks"] = storage.GetEventSinkStats()
+	}
+	if stats := GetMirrorStats(); len(stats) != 0 {
+		data["Mirrors"] = stats
+	}
+	if stats := RateLimitStats(); stats != nil {
+		data["Rate Limits"] = stats
+	}
+	bcStats, err := storage.GetBlockcacheStats()
+	if err == nil && len(bcStats) > 0 {
+		data["Block Cache"] = bcStats
+	}
+	if stores, err := storage.AllStores(); err == nil {
+		encrypted := make(map[storage.Alias]*storage.EncryptionStats)
+		for alias := range stores {
+			if stats, err := storage.GetEncryptionStats(alias); err == nil && stats != nil {
+				encrypted[alias] = stats
+			}
+		}
+		if len(encrypted) > 0 {
+			data["Encrypted Stores"] = encrypted
+		}
+	}
 	m, err := json.Marshal(data)
 	if err != nil {
 		return
@@ -266,12 +304,26 @@ func About() string {
 	return text
 }
 
-// Shutdown handles graceful cleanup of server functions before exiting DVID.
+// Shutdown handles graceful cleanup of server functions before exiting DVID.  New
+// requests are denied, in-flight HTTP requests and mutations are allowed to finish
+// within the configured timeouts, and then logs and stores are flushed and closed.
 // This may not be so graceful if the chunk handler uses cgo since the interrupt
 // may be caught during cgo execution.
 func Shutdown() {
 	// Stop accepting requests.
 	dvid.DenyRequests()
+	if tc.Server.ShutdownDelay > 0 {
+		dvid.Infof("Waiting %d seconds before closing HTTP listener...\n", tc.Server.ShutdownDelay)
+		time.Sleep(time.Duration(tc.Server.ShutdownDelay) * time.Second)
+	}
+
+	// Let in-flight HTTP requests finish.
+	httpTimeout := DefaultShutdownHTTPTimeout
+	if tc.Server.ShutdownHTTPTimeout > 0 {
+		httpTimeout = time.Duration(tc.Server.ShutdownHTTPTimeout) * time.Second
+	}
+	stopHTTP(httpTimeout)
+	stopDataPlane()
 
 	// Wait for chunk handlers.
 	waits := 0
@@ -289,13 +341,25 @@ func Shutdown() {
 		}
 		time.Sleep(1 * time.Second)
 	}
-	if tc.Server.ShutdownDelay > 0 {
-		dvid.Infof("Waiting %d seconds for any HTTP requests to drain...\n", tc.Server.ShutdownDelay)
-		time.Sleep(time.Duration(tc.Server.ShutdownDelay) * time.Second)
+
+	// Wait for mutations, syncs and down-resolution computations that outlive requests.
+	mutTimeout := DefaultShutdownMutationTimeout
+	if tc.Server.ShutdownMutationTimeout > 0 {
+		mutTimeout = time.Duration(tc.Server.ShutdownMutationTimeout) * time.Second
 	}
+	if busy := datastore.WaitForMutations(mutTimeout); len(busy) != 0 {
+		dvid.Criticalf("Shutting down after %s with mutations still in-flight for data %v\n", mutTimeout, busy)
+	} else {
+		dvid.Infof("No mutations in-flight. Proceeding...\n")
+	}
+
 	datastore.Shutdown()
+	storage.ShutdownEventSinks()
+	ShutdownMirrors()
 	dvid.BlockOnActiveCgo()
+	storage.Shutdown() // flushes usage and closes stores, including write logs
 	rpc.Shutdown()
+	dvid.ShutdownTracing()
 	dvid.Shutdown()
 	shutdownCh <- struct{}{}
 }
@@ -37,6 +37,9 @@ const (
 	ErrorLogFilename = "dvid-errors.log"
 )
 
+// default seconds to delay after receiving shutdown request before closing the HTTP listener.
+const defaultShutdownDelay = 5
+
 var (
 	// DefaultHost is the default most understandable alias for this server.
 	DefaultHost = "localhost"
@@ -57,7 +60,7 @@ func init() {
 	}
 	DefaultHost = out.String()
 	DefaultHost = DefaultHost[:len(DefaultHost)-1] // removes EOL
-	tc.Server.ShutdownDelay = 5
+	tc.Server.ShutdownDelay = defaultShutdownDelay
 }
 
 // TestConfig specifies configuration for testing servers.
@@ -116,8 +119,39 @@ func Initialize() error {
 	if err := tc.Kafka.Initialize(WebServer()); err != nil {
 		return err
 	}
+	if err := tc.EventSink.Initialize(); err != nil {
+		return err
+	}
+	if err := tc.Tracing.Initialize(); err != nil {
+		return err
+	}
 
 	sc := tc.Server
+	SetRateLimits(RateLimits{
+		ReadMB:         sc.RateLimitReadMB,
+		WriteMB:        sc.RateLimitWriteMB,
+		HeavyOps:       sc.RateLimitHeavyOps,
+		Burst:          sc.RateLimitBurst,
+		HeavyEndpoints: sc.RateLimitHeavyEndpoints,
+		Exempt:         sc.RateLimitExempt,
+		IgnoreNames:    sc.RateLimitIgnoreNames,
+	})
+
+	if err := initTLS(sc.tlsConfig()); err != nil {
+		return err
+	}
+	outboxDir := sc.MirrorOutbox
+	if outboxDir == "" {
+		if outboxDir = defaultMirrorOutbox(); outboxDir != "" {
+			dvid.Infof("Using mirror outbox directory %s\n", outboxDir)
+		} else {
+			dvid.Infof("No mirrorOutbox set and default store has no local path; undelivered mirror POSTs are kept only in memory\n")
+		}
+	}
+	if err := initMirrors(outboxDir, sc.MirrorMaxBackoff, sc.MirrorMaxAttempts); err != nil {
+		return err
+	}
+
 	if sc.StartWebhook == "" && sc.StartJaneliaConfig == "" {
 		return nil
 	}
@@ -170,10 +204,15 @@ type tomlConfig struct {
 	Logging    dvid.LogConfig
 	Mutations  MutationsConfig
 	Kafka      storage.KafkaConfig
+	EventSink  storage.EventSinkConfig
+	Tracing    dvid.TracingConfig
 	Store      map[storage.Alias]storeConfig
 	Backend    map[dvid.DataSpecifier]backendConfig
 	Cache      map[string]sizeConfig
 	Groupcache storage.GroupcacheConfig
+	Blockcache storage.BlockcacheConfig
+	Usage      storage.UsageConfig
+	Quota      map[dvid.UUID]datastore.RepoQuota
 	Mirror     map[dvid.DataSpecifier]mirrorConfig
 }
 
@@ -197,6 +236,32 @@ func (c *tomlConfig) convertPathsToAbsolute(configPath string) error {
 		return fmt.Errorf("Error converting logfile setting to absolute path")
 	}
 
+	// [tracing].path
+	if c.Tracing.Path != "" {
+		c.Tracing.Path, err = dvid.ConvertToAbsolute(c.Tracing.Path, configDir)
+		if err != nil {
+			return fmt.Errorf("Error converting tracing path setting to absolute path")
+		}
+	}
+
+	// [server].mirrorOutbox
+	if c.Server.MirrorOutbox != "" {
+		c.Server.MirrorOutbox, err = dvid.ConvertToAbsolute(c.Server.MirrorOutbox, configDir)
+		if err != nil {
+			return fmt.Errorf("Error converting mirrorOutbox setting to absolute path")
+		}
+	}
+
+	// [server].tlsCert, tlsKey, tlsClientCA, tlsCA
+	for _, p := range []*string{&c.Server.TLSCert, &c.Server.TLSKey, &c.Server.TLSClientCA, &c.Server.TLSCA} {
+		if *p == "" {
+			continue
+		}
+		if *p, err = dvid.ConvertToAbsolute(*p, configDir); err != nil {
+			return fmt.Errorf("Error converting TLS file setting to absolute path")
+		}
+	}
+
 	// [store.foobar].path
 	for alias, sc := range c.Store {
 		p, ok := sc["path"]
@@ -213,6 +278,40 @@ func (c *tomlConfig) convertPathsToAbsolute(configPath string) error {
 		}
 		sc["path"] = absPath
 	}
+
+	// [store.foobar].keyfile
+	for alias, sc := range c.Store {
+		k, ok := sc["keyfile"]
+		if !ok {
+			continue
+		}
+		keyfile, ok := k.(string)
+		if !ok {
+			return fmt.Errorf("Don't understand keyfile setting for store %q", alias)
+		}
+		absKeyfile, err := dvid.ConvertToAbsolute(keyfile, configDir)
+		if err != nil {
+			return fmt.Errorf("Error converting store.%s.keyfile to absolute path: %q", alias, keyfile)
+		}
+		sc["keyfile"] = absKeyfile
+	}
+
+	// [eventsink].outbox and [eventsink.sinks.foobar].path
+	if c.EventSink.Outbox != "" {
+		c.EventSink.Outbox, err = dvid.ConvertToAbsolute(c.EventSink.Outbox, configDir)
+		if err != nil {
+			return fmt.Errorf("Error converting eventsink.outbox to absolute path: %q", c.EventSink.Outbox)
+		}
+	}
+	for name, sc := range c.EventSink.Sinks {
+		if sc.Path == "" {
+			continue
+		}
+		if sc.Path, err = dvid.ConvertToAbsolute(sc.Path, configDir); err != nil {
+			return fmt.Errorf("Error converting eventsink.sinks.%s.path to absolute path: %q", name, sc.Path)
+		}
+		c.EventSink.Sinks[name] = sc
+	}
 	return nil
 }
 
@@ -291,10 +390,24 @@ func KafkaPrefixTopic() string {
 }
 
 func MutationLogSpec() MutationsConfig {
+	tcMu.RLock()
+	defer tcMu.RUnlock()
 	return tc.Mutations
 }
 
+// PaintMaxBlocks returns the maximum number of blocks intersected by a paint stroke.
+func PaintMaxBlocks() int64 {
+	tcMu.RLock()
+	defer tcMu.RUnlock()
+	if tc.Server.PaintMaxBlocks > 0 {
+		return int64(tc.Server.PaintMaxBlocks)
+	}
+	return DefaultPaintMaxBlocks
+}
+
 func repoMirrors(dataUUID, versionUUID dvid.UUID) []string {
+	tcMu.RLock()
+	defer tcMu.RUnlock()
 	if len(tc.Mirror) == 0 {
 		return nil
 	}
@@ -307,6 +420,8 @@ func repoMirrors(dataUUID, versionUUID dvid.UUID) []string {
 }
 
 func instanceMirrors(dataUUID, versionUUID dvid.UUID) []string {
+	tcMu.RLock()
+	defer tcMu.RUnlock()
 	if len(tc.Mirror) == 0 {
 		return nil
 	}
@@ -322,6 +437,12 @@ func instanceMirrors(dataUUID, versionUUID dvid.UUID) []string {
 	return nil
 }
 
+// AdminCredentials returns the bearer token and TLS client names allowed to use the
+// admin API.
+func AdminCredentials() (token string, clients []string) {
+	return tc.Server.AdminToken, tc.Server.AdminClients
+}
+
 // CacheSize returns the number oF bytes reserved for the given identifier.
 // If unset, will return 0.
 func CacheSize(id string) int {
@@ -352,6 +473,7 @@ type localConfig struct {
 	Host            string
 	HTTPAddress     string
 	RPCAddress      string
+	GRPCAddress     string // if set, the gRPC data plane is served on this address.
 	WebClient       string
 	WebRedirectPath string
 	WebDefaultFile  string
@@ -367,7 +489,57 @@ type localConfig struct {
 	MutIDStart uint64 `toml:"min_mutation_id_start"`
 
 	InteractiveOpsBeforeBlock int // # of interactive ops in 2 min period before batch processing is blocked.  Zero value = no blocking.
-	ShutdownDelay             int // seconds to delay after receiving shutdown request to let HTTP requests drain.
+	ShutdownDelay             int // seconds to delay after receiving shutdown request before closing the HTTP listener.
+	ShutdownHTTPTimeout       int // seconds to wait for in-flight HTTP requests on shutdown.  Zero value = 60 seconds.
+	ShutdownMutationTimeout   int // seconds to wait for in-flight mutations on shutdown.  Zero value = 300 seconds.
+	PaintMaxBlocks            int // blocks intersected by a paint stroke.  Zero value = 4096 blocks.
+
+	// Per-client rate limits for data instance requests.  Zero values = unlimited.
+	RateLimitReadMB         float64  // MB/sec of response data
+	RateLimitWriteMB        float64  // MB/sec of request data
+	RateLimitHeavyOps       float64  // CPU-heavy requests per minute
+	RateLimitBurst          int      // seconds of budget a client can accumulate.  Zero value = 10 seconds.
+	RateLimitHeavyEndpoints []string // endpoint keywords that are CPU-heavy.  Defaults to DefaultHeavyEndpoints.
+	RateLimitExempt         []string // TLS client common names, users, apps or IPs that are not rate limited
+	RateLimitIgnoreNames    bool     // if true, "u" and "app" query strings don't identify rate limited clients
+
+	// Mirroring of data instance POSTs to the servers in [mirror] sections.
+	MirrorOutbox      string // directory for per-mirror outboxes.  Default is "mirror-outbox" under the default store's path.
+	MirrorMaxBackoff  int    // maximum seconds between delivery retries.  Zero value = 300 seconds.
+	MirrorMaxAttempts int    // failed attempts before a POST becomes a dead letter.  Zero value = retry until delivered.
+
+	// Access to the /api/admin endpoints, which are disabled unless one of these is set.
+	AdminToken   string   // secret that must be sent as "Authorization: Bearer <token>"
+	AdminClients []string // common names of TLS client certificates allowed without a token
+
+	// TLS for the HTTP, RPC and gRPC servers.  If a certificate and key are set, servers only
+	// accept encrypted connections.  Changed files are reloaded without a restart.
+	TLSCert     string // PEM certificate chain
+	TLSKey      string // PEM private key
+	TLSClientCA string // PEM CAs for client certificates.  If set, clients must present certificates.
+	TLSCA       string // PEM CAs for verifying remote DVID servers, e.g., on push.  Default is system roots.
+}
+
+// setDefaults sets the host and addresses to defaults if not specified.
+func (c *localConfig) setDefaults() {
+	if c.Host == "" {
+		c.Host = DefaultHost
+	}
+	if c.HTTPAddress == "" {
+		c.HTTPAddress = DefaultWebAddress
+	}
+	if c.RPCAddress == "" {
+		c.RPCAddress = DefaultRPCAddress
+	}
+}
+
+func (c localConfig) tlsConfig() dvid.TLSConfig {
+	return dvid.TLSConfig{
+		CertFile:     c.TLSCert,
+		KeyFile:      c.TLSKey,
+		ClientCAFile: c.TLSClientCA,
+		CAFile:       c.TLSCA,
+	}
 }
 
 // DatastoreConfig returns data instance configuration necessary to
@@ -377,6 +549,7 @@ func DatastoreConfig() datastore.Config {
 		InstanceGen:   tc.Server.IIDGen,
 		InstanceStart: dvid.InstanceID(tc.Server.IIDStart),
 		MutationStart: tc.Server.MutIDStart,
+		Quotas:        tc.Quota,
 	}
 }
 
@@ -409,6 +582,7 @@ func LoadConfig(filename string) error {
 	if err != nil {
 		return fmt.Errorf("could not convert relative paths to absolute paths in TOML config: %v", err)
 	}
+	configFile = filename
 
 	if tc.Email.IsAvailable() {
 		dvid.SetEmailServer(tc.Email)
@@ -416,11 +590,40 @@ func LoadConfig(filename string) error {
 	return nil
 }
 
+// defaultMirrorOutbox returns a mirror outbox directory under the path of the default
+// store, or the empty string if the default store doesn't have a local path.
+func defaultMirrorOutbox() string {
+	var alias storage.Alias
+	for spec, backend := range tc.Backend {
+		if strings.Trim(string(spec), "\"") == "default" {
+			alias = backend.Store
+		}
+	}
+	if alias == "" && len(tc.Store) == 1 {
+		for a := range tc.Store {
+			alias = a
+		}
+	}
+	var config storeConfig
+	for a, c := range tc.Store {
+		if strings.Trim(string(a), "\"") == strings.Trim(string(alias), "\"") {
+			config = c
+		}
+	}
+	path, ok := config["path"].(string)
+	if !ok || path == "" || strings.Contains(path, "://") {
+		return ""
+	}
+	return filepath.Join(path, "mirror-outbox")
+}
+
 // GetBackend returns a backend from current configuration.
 func GetBackend() (backend *storage.Backend, err error) {
 	// Get all defined stores.
 	backend = new(storage.Backend)
 	backend.Groupcache = tc.Groupcache
+	backend.Blockcache = tc.Blockcache
+	backend.Usage = tc.Usage
 	if backend.Stores, err = Stores(); err != nil {
 		return
 	}
@@ -480,32 +683,39 @@ func GetBackend() (backend *storage.Backend, err error) {
 // Serve starts HTTP and RPC servers.
 func Serve() {
 	// Use defaults if not set via TOML config file.
-	if tc.Server.Host == "" {
-		tc.Server.Host = DefaultHost
-	}
-	if tc.Server.HTTPAddress == "" {
-		tc.Server.HTTPAddress = DefaultWebAddress
-	}
-	if tc.Server.RPCAddress == "" {
-		tc.Server.RPCAddress = DefaultRPCAddress
-	}
+	tc.Server.setDefaults()
 
 	dvid.TimeInfof("DVID code version: %s\n", gitVersion)
+	if serverTLS != nil {
+		dvid.TimeInfof("Serving HTTPS, RPC and gRPC with TLS certificate %s\n", tc.Server.TLSCert)
+	}
 	dvid.TimeInfof("Serving HTTP on %s (host alias %q)\n", tc.Server.HTTPAddress, tc.Server.Host)
 	dvid.TimeInfof("Serving command-line use via RPC %s\n", tc.Server.RPCAddress)
+	if tc.Server.GRPCAddress != "" {
+		dvid.TimeInfof("Serving gRPC data plane on %s\n", tc.Server.GRPCAddress)
+	}
 	dvid.TimeInfof("Using web client files from %s\n", tc.Server.WebClient)
 	dvid.TimeInfof("Using %d of %d logical CPUs for DVID.\n", dvid.NumCPU, runtime.NumCPU())
 
 	// Launch the web server
-	go serveHTTP()
+	go serveHTTP(serverTLS)
 
 	// Launch the rpc server
 	go func() {
-		if err := rpc.StartServer(tc.Server.RPCAddress); err != nil {
+		if err := rpc.StartTLSServer(tc.Server.RPCAddress, serverTLS); err != nil {
 			dvid.Criticalf("Could not start RPC server: %v\n", err)
 		}
 	}()
 
+	// Launch the gRPC data plane if configured
+	if tc.Server.GRPCAddress != "" {
+		go func() {
+			if err := serveDataPlane(tc.Server.GRPCAddress, serverTLS); err != nil {
+				dvid.Criticalf("Could not start gRPC data plane: %v\n", err)
+			}
+		}()
+	}
+
 	<-shutdownCh
 }
 
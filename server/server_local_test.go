@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -165,6 +166,9 @@ func TestParseConfig(t *testing.T) {
 	if len(mirrorCfg.Servers) != 2 || mirrorCfg.Servers[0] != "http://mirror1.janelia.org:7000" || mirrorCfg.Servers[1] != "http://mirror2.janelia.org:7000" {
 		t.Fatalf("bad parsed mirror servers: %v\n", mirrorCfg)
 	}
+	if outbox := defaultMirrorOutbox(); outbox != "/data/dbs/basholeveldb/mirror-outbox" {
+		t.Errorf("expected default mirror outbox under default store path, got %q\n", outbox)
+	}
 }
 
 func TestTOMLConfigAbsolutePath(t *testing.T) {
@@ -212,3 +216,69 @@ func TestTOMLConfigAbsolutePath(t *testing.T) {
 		t.Errorf("[store.bar].path was already absolute and should have been left unchanged: %s", path)
 	}
 }
+
+func TestReloadConfig(t *testing.T) {
+	oldTC, oldConfigFile := tc, configFile
+	defer func() {
+		tc, configFile = oldTC, oldConfigFile
+		SetRateLimits(RateLimits{})
+	}()
+
+	f, err := ioutil.TempFile("", "dvid-reload-*.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := f.Name()
+	f.Close()
+	defer os.Remove(filename)
+
+	writeConfig := func(httpAddress, mirror string, readMB float64) {
+		config := fmt.Sprintf(`
+[server]
+httpAddress = %q
+rpcAddress = "localhost:8001"
+rateLimitReadMB = %g
+
+[mirror]
+	[mirror.all]
+	servers = [%q]
+`, httpAddress, readMB, mirror)
+		if err := ioutil.WriteFile(filename, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("localhost:8000", "http://mirror1:7000", 0)
+	tc = tomlConfig{}
+	tc.Server.ShutdownDelay = defaultShutdownDelay
+	if err := LoadConfig(filename); err != nil {
+		t.Fatalf("bad TOML configuration: %v\n", err)
+	}
+	tc.Server.setDefaults()
+
+	writeConfig("localhost:9000", "http://mirror2:7000", 10)
+	result, err := ReloadConfig()
+	if err != nil {
+		t.Fatalf("unable to reload config: %v\n", err)
+	}
+	if len(result.Applied) != 2 || result.Applied[0] != "mirror" || result.Applied[1] != "server.rateLimit" {
+		t.Errorf("bad applied settings after reload: %v\n", result.Applied)
+	}
+	if len(result.RestartRequired) != 1 || result.RestartRequired[0] != "server.httpAddress" {
+		t.Errorf("bad restart required settings after reload: %v\n", result.RestartRequired)
+	}
+	if mirrors := instanceMirrors("a", "b"); len(mirrors) != 1 || mirrors[0] != "http://mirror2:7000" {
+		t.Errorf("expected reloaded mirror, got %v\n", mirrors)
+	}
+	if HTTPAddress() != "localhost:8000" {
+		t.Errorf("expected HTTP address to be unchanged until restart, got %s\n", HTTPAddress())
+	}
+
+	// invalid configurations are not applied
+	writeConfig("localhost:9000", "", 10)
+	if _, err := ReloadConfig(); err == nil {
+		t.Errorf("expected error reloading config with empty mirror server\n")
+	}
+	if mirrors := instanceMirrors("a", "b"); len(mirrors) != 1 || mirrors[0] != "http://mirror2:7000" {
+		t.Errorf("expected mirror to be unchanged after bad reload, got %v\n", mirrors)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/datatype/common/proto"
+	"google.golang.org/grpc"
+)
+
+// OpenTestDataPlane starts a gRPC data plane on a local port, requiring the given admin
+// token, and returns its address and a function to stop it.
+func OpenTestDataPlane(t *testing.T, adminToken string) (addr string, stop func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen for gRPC data plane: %v\n", err)
+	}
+	oldToken := tc.Server.AdminToken
+	tc.Server.AdminToken = adminToken
+	done := make(chan struct{})
+	go func() {
+		serveDataPlaneListener(lis, nil)
+		close(done)
+	}()
+	stop = func() {
+		stopDataPlane()
+		lis.Close()
+		<-done
+		tc.Server.AdminToken = oldToken
+	}
+	return lis.Addr().String(), stop
+}
+
+// DialTestDataPlane returns a client of the gRPC data plane at the given address that
+// sends the given bearer token, if any, and a function to close the connection.
+func DialTestDataPlane(t *testing.T, addr, token string) (proto.DataPlaneClient, func()) {
+	opts := []grpc.DialOption{grpc.WithInsecure(), grpc.WithCodec(dataPlaneCodec{})}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		t.Fatalf("unable to dial gRPC data plane at %s: %v\n", addr, err)
+	}
+	return proto.NewDataPlaneClient(conn), func() { conn.Close() }
+}
+
+// bearerToken sends a bearer token with each RPC.
+type bearerToken string
+
+func (b bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(b)}, nil
+}
+
+func (bearerToken) RequireTransportSecurity() bool { return false }
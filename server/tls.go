@@ -0,0 +1,37 @@
+package server
+
+import (
+	"crypto/tls"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/rpc"
+)
+
+// serverTLS is the TLS configuration for the HTTP, RPC and gRPC servers, or nil if
+// connections are not encrypted.
+var serverTLS *tls.Config
+
+// clientTLS is the TLS configuration for connections to other DVID servers, or nil if
+// the defaults should be used.
+var clientTLS *tls.Config
+
+// initTLS sets up TLS for the servers and for clients connecting to other DVID
+// servers, e.g., on push or mirroring.  The server certificate is also presented as
+// the client certificate, so it should allow client authentication if remotes require it.
+func initTLS(cfg dvid.TLSConfig) error {
+	serverTLS, clientTLS = nil, nil
+	if cfg.Enabled() {
+		var err error
+		if serverTLS, err = cfg.ServerConfig(); err != nil {
+			return err
+		}
+	}
+	if cfg.Enabled() || cfg.CAFile != "" {
+		var err error
+		if clientTLS, err = cfg.ClientConfig(); err != nil {
+			return err
+		}
+		rpc.SetClientTLS(clientTLS)
+	}
+	return nil
+}
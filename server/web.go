@@ -7,6 +7,8 @@ package server
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -85,6 +87,20 @@ const webHelp = `
 
 	Returns help for the given datatype.
 
+ GET  /api/openapi.json[?queryopts]
+
+	Returns an OpenAPI 3 description of a datatype's or data instance's HTTP API, generated
+	from the endpoints registered by the datatype.  Without a query string, returns a JSON
+	object mapping each compiled datatype to the URL of its OpenAPI document.  Datatypes
+	that have not registered their endpoints only describe endpoints common to all data.
+
+	Query-string Options:
+
+	type          Name of a compiled datatype, e.g., "keyvalue".  Paths are templated on
+	                {uuid} and {dataname}.
+	uuid          UUID of a version node.  Must be used with "data".
+	data          Name of a data instance.  Paths are specific to the given instance.
+
  GET  /api/load
 
 	Returns a JSON of server load statistics.
@@ -104,7 +120,18 @@ const webHelp = `
 
  GET  /api/server/info
 
-	Returns JSON for server properties.
+	Returns JSON for server properties.  If the local block cache is configured, the
+	"Block Cache" property holds hit/miss statistics for each cached data instance.
+	If event sinks are configured, the "Event Sinks" property holds the number of
+	messages sent, failed delivery attempts, and messages pending in each outbox.
+	If data instance POSTs are mirrored, the "Mirrors" property holds the delivery
+	statistics described for /api/server/mirrors.
+	If per-client rate limits are configured, the "Rate Limits" property holds the
+	limits and each recent client's remaining budgets.  Data instance requests from
+	clients over budget receive status 429 with a Retry-After header in seconds.
+	If tracing is configured, traced responses include the trace ID in an
+	X-Dvid-Trace-Id header, and requests with a W3C traceparent header continue the
+	client's trace.
 
  GET  /api/server/note 
 
@@ -152,12 +179,116 @@ POST  /api/server/reload-metadata
 	when prompted by an external coordinator, allowing the "slave" DVIDs to see changes made by
 	the master DVID.
 
+POST  /api/server/reload-config
+
+	Re-reads the TOML configuration file given at startup (also done on SIGHUP).  If an
+	adminToken or adminClients is configured, the request requires the same credentials as
+	the /api/admin endpoints.  The reload is done before responding.  If the configuration
+	is valid, changes to logging, email, mirrors, mutation logging, and rate limits are
+	applied immediately.  Returns JSON listing the applied settings and the changed settings
+	that require a server restart:
+
+	{
+		"Applied": ["mirror", "server.rateLimit"],
+		"RestartRequired": ["server.httpAddress"]
+	}
+
+	An invalid configuration returns a 400 status and no settings are changed.
+
+GET  /api/server/mirrors
+
+	Returns JSON with delivery statistics for each server receiving mirrored POSTs.  Mirrored
+	POSTs are persisted in an outbox per mirror before being handled locally, and are delivered
+	to each mirror in order, retrying with backoff until accepted.  POSTs rejected by a mirror
+	(status 4xx) or failing more than the configured maximum attempts become dead letters.
+
+	{
+		"http://mirror1.janelia.org:7000": {
+			"Pending": 12,         // POSTs awaiting delivery
+			"DeadLetters": 1,      // POSTs that failed permanently
+			"Delivered": 1032,     // POSTs delivered since startup
+			"Failures": 17,        // failed delivery attempts since startup
+			"LagSeconds": 63.2,    // age of the oldest POST awaiting delivery
+			"LastDelivered": {
+				"Path": "/api/node/3f8c/segmentation/blocks",
+				"Queued": "2019-03-14T10:31:05.112-04:00",
+				"Delivered": "2019-03-14T10:32:08.304-04:00"
+			},
+			"LastFailure": "..."
+		}
+	}
+
 GET /api/server/blobstore/{reference}
    
 	GETs data with the given reference string from this server's blobstore. The blobstore is
 	populated as part of mutation logging and is read-only.  The reference is a URL-friendly 
 	content hash (FNV-128) of the blob data.
 
+---------------
+Admin endpoints
+---------------
+
+These endpoints require either an "Authorization: Bearer <token>" header matching the server's
+adminToken setting or a TLS client certificate whose common name is in adminClients.
+If neither setting is configured, admin requests receive status 403.  The repo and node
+commands run the same code as the dvid command-line RPC commands (see "dvid help").  Each
+command runs as a job and a 202 status is returned with JSON giving the job ID:
+
+	{"job": "7f3bd1f2a2e14f31b4d4d1c1e0a3a2b9"}
+
+POST /api/admin/repo/{uuid}/{command}
+
+	Runs the "repo" RPC command with positional arguments given by JSON keys.  Any other keys are
+	passed as command settings, e.g., "transmit" or "filter" for copy and push.
+
+	Command             Required JSON keys (optional in brackets)
+	copy                source, target
+	migrate             source, oldstore
+	push                target (remote DVID address), [data]
+	rename              data, newname, [passcode]
+	delete              data, [passcode]
+	delete-class        data, class, allversions
+	delete-repo         [passcode]
+	flatten-mutations   datauuid, filename
+	storage-details
+
+	Example: POST /api/admin/repo/3f8c/copy with {"source": "grayscale", "target": "grayscale-flat", "transmit": "flatten"}
+
+POST /api/admin/node/{uuid}/{data name}/{command}
+
+	Runs a data type-specific RPC command for the data instance.  The optional JSON gives
+	arguments and settings:
+
+	{
+		"Args": ["arg1", "arg2"],
+		"Settings": {"key": "value"}
+	}
+
+GET  /api/admin/jobs
+
+	Returns JSON list of running and recently finished jobs, including ones started through
+	RPC commands that run in the background.
+
+GET  /api/admin/jobs/{job id}
+
+	Returns JSON describing a job:
+
+	{
+		"ID": "7f3bd1f2a2e14f31b4d4d1c1e0a3a2b9",
+		"Command": "repo 3f8c... copy grayscale grayscale-flat transmit=flatten",
+		"Status": "completed",    // "running", "completed", or "failed"
+		"Started": "2019-03-14T10:31:05.112-04:00",
+		"Finished": "2019-03-14T10:48:41.530-04:00",
+		"Result": "...",          // any text output of the command
+		"Error": "..."            // error if the job failed
+	}
+
+POST /api/admin/mirrors/replay[?target=...]
+
+	Moves dead letters back into the outbox of the given mirror, e.g., target=http://mirror1:7000,
+	or of all mirrors if no target is given.  Replayed POSTs are delivered after any already
+	pending.  Returns JSON with the number of replayed POSTs per mirror.
+
 -------------------------
 Memory Profiler endpoints
 -------------------------
@@ -252,6 +383,29 @@ Repo-Level REST endpoints
 	Returns a JSON list of version UUIDs for the given branch name, starting with the
 	current leaf and working back to the root.  Use "master" for the default branch.
 
+  GET /api/repo/{uuid}/usage
+
+	Returns JSON of the storage used by each data instance in the repo, with key and byte
+	counts for each version in which key-value pairs were stored.  If storage quotas have
+	been configured for the repo, the soft and hard limits in bytes are also returned:
+
+	{
+		"Root": "3f8c...",
+		"Keys": 1203,
+		"Bytes": 83201923,
+		"SoftQuotaBytes": 536870912000,
+		"HardQuotaBytes": 644245094400,
+		"SoftQuotaExceeded": false,
+		"Counting": false,
+		"Instances": {
+			"grayscale": { "Keys": 1000, "Bytes": 80000000, "Versions": { "3f8c...": {...} } },
+			...
+		}
+	}
+
+	"Counting" is true while initial counts are computed for stores that had no
+	persisted usage.  Requires [usage] track = true in the server configuration.
+
  POST /api/repo/{uuid}/merge
 
 	Creates a conflict-free merge of a set of committed parent UUIDs into a child.  Note
@@ -457,6 +611,40 @@ Node-Level REST endpoints
 
 	Note that POST /blobstore will not be logged in any associated kafka system.
 
+
+ GET /api/node/{uuid}/{data name}/events[?queryopts]
+
+	Streams changes to the given data instance as Server-Sent Events (content type
+	text/event-stream) until the client disconnects.  Each event has an "id" field with a
+	sequence number unique to the data instance and a "data" field with JSON like:
+
+	{ 
+		"Seq": 1027,
+		"Event": "MERGE_END",
+		"Version": "3f01a8856",
+		"Time": "2018-05-01T11:25:03.1234-04:00",
+		"Labels": [23, 71],
+		"Block": "10,4,23",
+		"Key": "mykey"
+	}
+
+	Event types include MERGE_*, SPLIT_*, CLEAVE_*, SV_SPLIT_*, BLOCK_INGEST, BLOCK_MUTATE,
+	ANNOTATION_MOD_ELEMENTS, ANNOTATION_SET_ELEMENTS, KEYVALUE_PUT and KEYVALUE_DELETE.
+	Labels, Block and Key are only present when relevant to the event type.
+
+	A data instance's feed is started on its first subscription, after which the last
+	4096 events are retained so clients can resume.  Browser EventSource clients resume
+	automatically via the Last-Event-ID header after a dropped connection, including when
+	a client falls too far behind and is disconnected.  If requested events are no longer
+	retained, a "truncated" event is sent first and the client should refresh its state.
+
+	Query-string Options:
+
+	types         Comma-separated event types to receive.  By default, all types are sent.
+	labels        Comma-separated labels.  Only events affecting these labels are sent.
+	since         Resume after the given sequence number, replaying retained events.
+	allversions   If "true", sends events from all versions and not just the given UUID.
+
 		</pre>
 
 		<h4>Data type commands</h4>
@@ -511,6 +699,15 @@ var (
 		routesSetup bool
 	}
 	webMuxMu sync.Mutex // Can Lock() to prevent any kind of web requests from initiating actions.
+
+	httpServer struct {
+		sync.Mutex
+		server *http.Server
+	}
+
+	// drainCh is closed when the server starts shutting down.
+	drainCh   = make(chan struct{})
+	drainOnce sync.Once
 )
 
 func init() {
@@ -555,10 +752,11 @@ func ServeSingleHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // Listen and serve HTTP requests using address and don't let stay-alive
-// connections hog goroutines for more than an hour.
+// connections hog goroutines for more than an hour.  If a TLS configuration is
+// given, only TLS connections are accepted, with HTTP/2 support.
 // See for discussion:
 // http://stackoverflow.com/questions/10971800/golang-http-server-leaving-open-goroutines
-func serveHTTP() {
+func serveHTTP(tlsConfig *tls.Config) {
 	var mode string
 	if readonly {
 		mode = " (read-only mode)"
@@ -574,22 +772,45 @@ func serveHTTP() {
 	// This allows packages like expvar to continue working as expected.  (From goji.go)
 	http.Handle("/", webMux)
 
-	// TODO: Could have used "graceful" goji package but doesn't allow tailoring of timeouts
-	// unless package is modified.  Not sure graceful features needed whereas tailoring
-	// of server is more important.
-
 	s := &http.Server{
 		Addr:         HTTPAddress(),
 		WriteTimeout: WriteTimeout,
 		ReadTimeout:  ReadTimeout,
+		TLSConfig:    tlsConfig,
+	}
+	httpServer.Lock()
+	httpServer.server = s
+	httpServer.Unlock()
+	var err error
+	if tlsConfig != nil {
+		err = s.ListenAndServeTLS("", "") // certificates are supplied by the TLS config
+	} else {
+		err = s.ListenAndServe()
 	}
-	log.Fatal(s.ListenAndServe())
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
 
-	// graceful.HandleSignals()
-	// if err := graceful.ListenAndServe(address, http.DefaultServeMux); err != nil {
-	// 	log.Fatal(err)
-	// }
-	// graceful.Wait()
+// stopHTTP closes the web server's listeners and waits up to the timeout for in-flight
+// requests to finish.  Long-lived streams like change feeds are told to end via drainCh.
+func stopHTTP(timeout time.Duration) {
+	drainOnce.Do(func() { close(drainCh) })
+
+	httpServer.Lock()
+	s := httpServer.server
+	httpServer.server = nil
+	httpServer.Unlock()
+	if s == nil {
+		return
+	}
+	dvid.Infof("Waiting up to %s for in-flight HTTP requests to finish...\n", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		dvid.Errorf("HTTP requests still active after %s, closing connections: %v\n", timeout, err)
+		s.Close()
+	}
 }
 
 // High-level switchboard for DVID HTTP API.
@@ -610,6 +831,7 @@ func initRoutes() {
 	mainMux.Use(middleware.AutomaticOptions)
 	mainMux.Use(httpAvailHandler)
 	mainMux.Use(recoverHandler)
+	mainMux.Use(traceHandler)
 	mainMux.Use(corsHandler)
 
 	mainMux.Get("/interface", interfaceHandler)
@@ -618,11 +840,13 @@ func initRoutes() {
 	mainMux.Get("/api/help", helpHandler)
 	mainMux.Get("/api/help/", helpHandler)
 	mainMux.Get("/api/help/:typename", typehelpHandler)
+	mainMux.Get("/api/openapi.json", openAPIHandler)
 
 	mainMux.Get("/api/storage", serverStorageHandler)
 
 	serverMux := web.New()
 	mainMux.Handle("/api/server/:action", serverMux)
+	mainMux.Handle("/api/server/:action/:name", serverMux)
 	serverMux.Use(activityLogHandler)
 	serverMux.Get("/api/server/info", serverInfoHandler)
 	serverMux.Get("/api/server/info/", serverInfoHandler)
@@ -637,13 +861,29 @@ func initRoutes() {
 	serverMux.Post("/api/server/settings", serverSettingsHandler)
 	serverMux.Post("/api/server/reload-metadata", serverReload)
 	serverMux.Post("/api/server/reload-metadata/", serverReload)
+	serverMux.Post("/api/server/reload-config", configuredAdminAuth(serverReloadConfigHandler))
+	serverMux.Post("/api/server/reload-config/", configuredAdminAuth(serverReloadConfigHandler))
 	serverMux.Get("/api/server/blobstore/:ref", blobstoreHandler)
+	serverMux.Get("/api/server/mirrors", serverMirrorsHandler)
+	serverMux.Get("/api/server/mirrors/", serverMirrorsHandler)
 
 	if !readonly {
 		mainMux.Post("/api/repos", reposPostHandler)
 	}
 	mainMux.Get("/api/repos/info", reposInfoHandler)
 
+	adminMux := web.New()
+	mainMux.Handle("/api/admin/*", adminMux)
+	adminMux.Use(activityLogHandler)
+	adminMux.Use(adminAuthHandler)
+	adminMux.Get("/api/admin/jobs", adminJobsHandler)
+	adminMux.Get("/api/admin/jobs/:id", adminJobHandler)
+	adminMux.Post("/api/admin/mirrors/replay", serverMirrorsReplayHandler)
+	if !readonly {
+		adminMux.Post("/api/admin/repo/:uuid/:command", adminRepoCommandHandler)
+		adminMux.Post("/api/admin/node/:uuid/:dataname/:command", adminNodeCommandHandler)
+	}
+
 	repoRawMux := web.New()
 	mainMux.Handle("/api/repo/:uuid", repoRawMux)
 	repoRawMux.Use(activityLogHandler)
@@ -660,6 +900,7 @@ func initRoutes() {
 	repoMux.Get("/api/repo/:uuid/info", repoInfoHandler)
 	repoMux.Post("/api/repo/:uuid/instance", repoNewDataHandler)
 	repoMux.Get("/api/repo/:uuid/branch-versions/:name", repoBranchVersionsHandler)
+	repoMux.Get("/api/repo/:uuid/usage", repoUsageHandler)
 	repoMux.Get("/api/repo/:uuid/log", getRepoLogHandler)
 	repoMux.Post("/api/repo/:uuid/log", postRepoLogHandler)
 	repoMux.Post("/api/repo/:uuid/merge", repoMergeHandler)
@@ -685,6 +926,7 @@ func initRoutes() {
 	instanceMux := web.New()
 	mainMux.Handle("/api/node/:uuid/:dataname/:keyword", instanceMux)
 	mainMux.Handle("/api/node/:uuid/:dataname/:keyword/*", instanceMux)
+	instanceMux.Use(rateLimitHandler)
 	instanceMux.Use(repoRawSelector)
 	instanceMux.Use(mutationsHandler)
 	instanceMux.Use(instanceSelector)
@@ -727,6 +969,13 @@ func (w *wrappedResponseWriter) Write(buf []byte) (int, error) {
 	return n, err
 }
 
+// Flush allows streaming handlers to flush through the wrapped writer.
+func (w *wrappedResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func wrapResponseWriter(w http.ResponseWriter) *wrappedResponseWriter {
 	wr := wrappedResponseWriter{
 		ResponseWriter: w,
@@ -766,11 +1015,44 @@ func recoverHandler(c *web.C, h http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// Middleware that starts a trace span for each request, continuing any trace given by a
+// W3C traceparent header.  The span is passed to handlers via the request context and
+// its trace ID is returned in the X-Dvid-Trace-Id header.
+func traceHandler(c *web.C, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		span := dvid.StartRemoteSpan(r.Header.Get("traceparent"), "HTTP "+r.Method)
+		if span == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.path", r.URL.Path)
+		span.SetAttribute("request_id", middleware.GetReqID(*c))
+		if r.URL.RawQuery != "" {
+			span.SetAttribute("http.query", r.URL.RawQuery)
+		}
+		w.Header().Set("X-Dvid-Trace-Id", span.TraceID())
+
+		myw := wrapResponseWriter(w)
+		h.ServeHTTP(myw, r.WithContext(dvid.ContextWithSpan(r.Context(), span)))
+		status := myw.status
+		if !myw.wroteHeader {
+			status = http.StatusOK
+		}
+		span.SetAttribute("http.status", status)
+		span.SetAttribute("bytes_out", myw.bytes)
+		if status >= 400 {
+			span.SetError(fmt.Errorf("HTTP status %d", status))
+		}
+		span.End()
+	}
+	return http.HandlerFunc(fn)
+}
+
 // Middleware that logs all mutations to any configured mutation log
 func mutationsHandler(c *web.C, h http.Handler) http.Handler {
-	mutConfig := MutationLogSpec()
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		if mutConfig.Logstore != "" {
+		if mutConfig := MutationLogSpec(); mutConfig.Logstore != "" {
 			buf, err := ioutil.ReadAll(r.Body)
 			if err != nil {
 				BadRequest(w, r, "unable to read POST for mirroring: %v", err)
@@ -816,7 +1098,7 @@ func activityLogHandler(c *web.C, h http.Handler) http.Handler {
 		t0 := time.Now()
 		myw := wrapResponseWriter(w)
 		h.ServeHTTP(myw, r)
-		if KafkaAvailable() {
+		if KafkaAvailable() || storage.EventSinksAvailable() {
 			user := r.URL.Query().Get("u")
 			app := r.URL.Query().Get("app")
 			t := time.Since(t0)
@@ -870,7 +1152,12 @@ func BadRequest(w http.ResponseWriter, r *http.Request, format interface{}, args
 		message = fmt.Sprintf(message, args...)
 	}
 	errorMsg := fmt.Sprintf("%s (%s).", message, r.URL.Path)
-	dvid.Errorf(errorMsg + "\n")
+	if span := dvid.SpanFromContext(r.Context()); span != nil {
+		span.SetError(fmt.Errorf("%s", message))
+		dvid.Errorf("%s [trace %s]\n", errorMsg, span.TraceID())
+	} else {
+		dvid.Errorf(errorMsg + "\n")
+	}
 	http.Error(w, errorMsg, http.StatusBadRequest)
 }
 
@@ -1033,6 +1320,12 @@ func instanceSelector(c *web.C, h http.Handler) http.Handler {
 			return
 		}
 
+		// handle change feed subscriptions
+		if c.URLParams["keyword"] == "events" && method == "get" {
+			serveChangeFeed(w, r, data, uuid)
+			return
+		}
+
 		v, err := datastore.VersionFromUUID(uuid)
 		if err != nil {
 			BadRequest(w, r, err)
@@ -1062,6 +1355,18 @@ func instanceSelector(c *web.C, h http.Handler) http.Handler {
 		// Also set the web request information in case logging needs it downstream.
 		ctx.SetRequestID(middleware.GetReqID(*c))
 
+		// Record handling by the data instance under the request's trace, if any.
+		span := dvid.StartSpan(dvid.SpanFromContext(r.Context()), string(data.TypeName())+" "+c.URLParams["keyword"])
+		if span != nil {
+			span.SetAttribute("data", string(dataname))
+			span.SetAttribute("uuid", string(uuid))
+			span.SetAttribute("version", uint64(v))
+			span.SetAttribute("request_id", ctx.GetRequestID())
+			ctx.SetSpan(span)
+			r = r.WithContext(dvid.ContextWithSpan(r.Context(), span))
+			defer span.End()
+		}
+
 		// Handle DVID-wide query string commands like non-interactive call designations
 		queryStrings := r.URL.Query()
 
@@ -1085,24 +1390,15 @@ func instanceSelector(c *web.C, h http.Handler) http.Handler {
 					return
 				}
 				r.Body = ioutil.NopCloser(bytes.NewBuffer(buf))
-				contentType := r.Header.Get("Content-Type")
-				for _, hostAndPort := range mirrors {
-					url := hostAndPort + r.URL.Path
-					dvid.Infof("echoing POST asynchronously to %s\n", url)
-					go func(url string) {
-						resp, err := http.Post(url, contentType, bytes.NewBuffer(buf))
-						if err != nil {
-							dvid.Errorf("problem echoing POST (%s): %v\n", url, err)
-						} else {
-							dvid.Infof("echoed POST: %s (status %d)\n", url, resp.StatusCode)
-						}
-					}(url)
+				if err := queueMirrors(mirrors, r, buf); err != nil {
+					BadRequest(w, r, "unable to mirror POST: %v", err)
+					return
 				}
 			}
 		}
 		myw := wrapResponseWriter(w)
 		activity := data.ServeHTTP(uuid, ctx, myw, r)
-		if KafkaAvailable() {
+		if KafkaAvailable() || storage.EventSinksAvailable() {
 			user := r.URL.Query().Get("u")
 			app := r.URL.Query().Get("app")
 			t := time.Since(t0)
@@ -1363,6 +1659,46 @@ func serverReload(c web.C, w http.ResponseWriter, r *http.Request) {
 	datastore.MetadataUniversalUnlock()
 }
 
+func serverReloadConfigHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	result, err := ReloadConfig()
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	m, err := json.Marshal(result)
+	if err != nil {
+		BadRequest(w, r, "Cannot marshal JSON for config reload: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(m))
+}
+
+func serverMirrorsHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	m, err := json.Marshal(GetMirrorStats())
+	if err != nil {
+		BadRequest(w, r, "Cannot marshal JSON for mirror stats: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(m))
+}
+
+func serverMirrorsReplayHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	replayed, err := ReplayMirrorDeadLetters(r.URL.Query().Get("target"))
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	m, err := json.Marshal(replayed)
+	if err != nil {
+		BadRequest(w, r, "Cannot marshal JSON for mirror replay: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, string(m))
+}
+
 func blobstoreHandler(c web.C, w http.ResponseWriter, r *http.Request) {
 	method := strings.ToLower(r.Method)
 	if method != "get" {
@@ -1502,6 +1838,17 @@ func repoBranchVersionsHandler(c web.C, w http.ResponseWriter, r *http.Request)
 	fmt.Fprintf(w, jsonStr)
 }
 
+func repoUsageHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	uuid := (c.Env["uuid"]).(dvid.UUID)
+	jsonStr, err := datastore.GetRepoUsageJSON(uuid)
+	if err != nil {
+		BadRequest(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, jsonStr)
+}
+
 func repoNewDataHandler(c web.C, w http.ResponseWriter, r *http.Request) {
 	// Apply a global lock (if relevant) and reloads meta
 	if err := datastore.MetadataUniversalLock(); err != nil {
@@ -0,0 +1,788 @@
+/*
+	This file implements an in-process, size-bounded LRU cache that can be layered over
+	an ordered key-value store for selected data instances.  Unlike groupcache, it is safe
+	for mutable, versioned data since any Put, Delete or batch commit through the wrapped
+	store invalidates the affected keys.
+*/
+
+package storage
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// BlockcacheConfig handles settings for the local block cache.
+type BlockcacheConfig struct {
+	MB        int      // Total size in megabytes shared by all cached data instances.
+	Instances []string // Data instances that use the block cache in form "<name>:<uuid>"
+	Types     []string // Datatypes, e.g., "labelmap", whose instances all use the block cache.
+}
+
+// BlockcacheStats holds the hit/miss stats of the block cache for one data instance.
+type BlockcacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64 // number of cached values removed due to mutations
+	Evictions     uint64 // number of cached values removed due to size limit
+	Entries       int    // current number of cached values
+	Bytes         int64  // current bytes used by cached values
+}
+
+// GetBlockcacheStats returns the block cache stats for each cached data instance,
+// keyed by the "<name>:<uuid>" specification or datatype name used in the configuration.
+func GetBlockcacheStats() (map[string]BlockcacheStats, error) {
+	if !manager.setup {
+		return nil, fmt.Errorf("Storage manager not initialized before requesting block cache stats")
+	}
+	if manager.bcache.cache == nil {
+		return nil, nil
+	}
+	return manager.bcache.cache.getStats(), nil
+}
+
+type blockcacheT struct {
+	cache     *blockcache
+	supported map[dvid.DataSpecifier]string // maps data spec to its configured "<name>:<uuid>" string
+	types     map[dvid.TypeString]string    // maps datatype to its configured name
+}
+
+// cacheName returns the configured name under which the given data instance is cached
+// by instance specification or, failing that, by datatype.
+func (bc blockcacheT) cacheName(dataname dvid.InstanceName, root dvid.UUID, typename dvid.TypeString) (name string, supported bool) {
+	if bc.cache == nil {
+		return "", false
+	}
+	if name, supported = bc.supported[dvid.GetDataSpecifier(dataname, root)]; supported {
+		return
+	}
+	name, supported = bc.types[typename]
+	return
+}
+
+func setupBlockcache(config BlockcacheConfig) error {
+	if config.MB == 0 {
+		return nil
+	}
+	if config.MB < 0 {
+		return fmt.Errorf("bad blockcache size in config: %d MB", config.MB)
+	}
+	dvid.Infof("Initializing block cache with %d MB...\n", config.MB)
+	manager.bcache.cache = newBlockcache(int64(config.MB) * dvid.Mega)
+	manager.bcache.supported = make(map[dvid.DataSpecifier]string)
+	for _, dataspec := range config.Instances {
+		name := strings.Trim(dataspec, "\"")
+		parts := strings.Split(name, ":")
+		switch len(parts) {
+		case 2:
+			dataid := dvid.GetDataSpecifier(dvid.InstanceName(parts[0]), dvid.UUID(parts[1]))
+			manager.bcache.supported[dataid] = name
+		default:
+			dvid.Errorf("bad data instance specification %q given for blockcache support in config file\n", dataspec)
+		}
+	}
+	manager.bcache.types = make(map[dvid.TypeString]string, len(config.Types))
+	for _, typename := range config.Types {
+		name := strings.Trim(typename, "\"")
+		manager.bcache.types[dvid.TypeString(name)] = name
+	}
+	return nil
+}
+
+// cached value for a full key, stored as element of LRU list.
+type bcEntry struct {
+	id    dvid.InstanceID
+	key   string // full key
+	unver string // unversioned key
+	value []byte
+	stats *BlockcacheStats
+}
+
+func (e *bcEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// blockcache is a byte-bounded LRU cache of full keys to values.  Since a mutation to a
+// type-specific key can change the value visible to any version, invalidation is done
+// for all cached versions of the unversioned key.
+type blockcache struct {
+	sync.Mutex
+	capacity int64
+	used     int64
+	lru      *list.List                     // front is most recently used
+	entries  map[string]*list.Element       // full key -> element holding *bcEntry
+	unver    map[string]map[string]struct{} // unversioned key -> set of full keys
+	byData   map[dvid.InstanceID]map[string]struct{}
+
+	// generation per instance is incremented on any invalidation so in-flight
+	// reads from the underlying store won't store stale values.
+	generation map[dvid.InstanceID]uint64
+
+	stats map[string]*BlockcacheStats
+}
+
+func newBlockcache(capacity int64) *blockcache {
+	return &blockcache{
+		capacity:   capacity,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		unver:      make(map[string]map[string]struct{}),
+		byData:     make(map[dvid.InstanceID]map[string]struct{}),
+		generation: make(map[dvid.InstanceID]uint64),
+		stats:      make(map[string]*BlockcacheStats),
+	}
+}
+
+// instanceStats returns the stats for a named data instance, creating it if necessary.
+func (c *blockcache) instanceStats(name string) *BlockcacheStats {
+	c.Lock()
+	defer c.Unlock()
+	stats, found := c.stats[name]
+	if !found {
+		stats = new(BlockcacheStats)
+		c.stats[name] = stats
+	}
+	return stats
+}
+
+func (c *blockcache) getStats() map[string]BlockcacheStats {
+	c.Lock()
+	defer c.Unlock()
+	stats := make(map[string]BlockcacheStats, len(c.stats))
+	for name, s := range c.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// get returns the cached value or if not present, the current generation for the instance
+// that should be passed to a subsequent add.
+func (c *blockcache) get(key string, stats *BlockcacheStats, id dvid.InstanceID) (value []byte, found bool, gen uint64) {
+	c.Lock()
+	defer c.Unlock()
+	elem, found := c.entries[key]
+	if !found {
+		stats.Misses++
+		return nil, false, c.generation[id]
+	}
+	stats.Hits++
+	c.lru.MoveToFront(elem)
+	return copyValue(elem.Value.(*bcEntry).value), true, 0
+}
+
+// getAll returns the cached values for all the given keys or if any key is not present,
+// the current generation for the instance that should be passed to subsequent adds.
+func (c *blockcache) getAll(keys []string, stats *BlockcacheStats, id dvid.InstanceID) (values [][]byte, found bool, gen uint64) {
+	c.Lock()
+	defer c.Unlock()
+	elems := make([]*list.Element, len(keys))
+	for i, key := range keys {
+		elem, found := c.entries[key]
+		if !found {
+			stats.Misses += uint64(len(keys))
+			return nil, false, c.generation[id]
+		}
+		elems[i] = elem
+	}
+	stats.Hits += uint64(len(keys))
+	values = make([][]byte, len(keys))
+	for i, elem := range elems {
+		c.lru.MoveToFront(elem)
+		values[i] = copyValue(elem.Value.(*bcEntry).value)
+	}
+	return values, true, 0
+}
+
+// generationOf returns the current generation for the instance.
+func (c *blockcache) generationOf(id dvid.InstanceID) uint64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.generation[id]
+}
+
+// add caches a value unless there was an invalidation of the data instance since
+// the given generation.
+func (c *blockcache) add(id dvid.InstanceID, gen uint64, key, unver string, value []byte, stats *BlockcacheStats) {
+	c.Lock()
+	defer c.Unlock()
+	if c.generation[id] != gen {
+		return
+	}
+	if elem, found := c.entries[key]; found {
+		c.removeElement(elem)
+	}
+	entry := &bcEntry{id: id, key: key, unver: unver, value: copyValue(value), stats: stats}
+	if entry.size() > c.capacity {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.used += entry.size()
+	stats.Entries++
+	stats.Bytes += entry.size()
+
+	fullkeys, found := c.unver[unver]
+	if !found {
+		fullkeys = make(map[string]struct{})
+		c.unver[unver] = fullkeys
+	}
+	fullkeys[key] = struct{}{}
+	unverkeys, found := c.byData[id]
+	if !found {
+		unverkeys = make(map[string]struct{})
+		c.byData[id] = unverkeys
+	}
+	unverkeys[unver] = struct{}{}
+
+	for c.used > c.capacity {
+		elem := c.lru.Back()
+		if elem == nil {
+			break
+		}
+		elem.Value.(*bcEntry).stats.Evictions++
+		c.removeElement(elem)
+	}
+}
+
+// copyValue returns a copy of a value so callers can't modify cached values.
+func copyValue(value []byte) []byte {
+	if value == nil {
+		return nil
+	}
+	dup := make([]byte, len(value))
+	copy(dup, value)
+	return dup
+}
+
+// removeElement removes an entry from the cache.  Caller must hold lock.
+func (c *blockcache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*bcEntry)
+	delete(c.entries, entry.key)
+	c.used -= entry.size()
+	entry.stats.Entries--
+	entry.stats.Bytes -= entry.size()
+	if fullkeys, found := c.unver[entry.unver]; found {
+		delete(fullkeys, entry.key)
+		if len(fullkeys) == 0 {
+			delete(c.unver, entry.unver)
+			delete(c.byData[entry.id], entry.unver)
+		}
+	}
+}
+
+// invalidate removes all versions of the given unversioned keys for a data instance.
+func (c *blockcache) invalidate(id dvid.InstanceID, unverKeys ...string) {
+	c.Lock()
+	defer c.Unlock()
+	c.generation[id]++
+	for _, unver := range unverKeys {
+		c.invalidateUnversioned(unver)
+	}
+}
+
+// invalidateInstance removes all cached values for a data instance.
+func (c *blockcache) invalidateInstance(id dvid.InstanceID) {
+	c.Lock()
+	defer c.Unlock()
+	c.generation[id]++
+	for unver := range c.byData[id] {
+		c.invalidateUnversioned(unver)
+	}
+}
+
+// invalidateUnversioned removes all versions of an unversioned key.  Caller must hold lock.
+func (c *blockcache) invalidateUnversioned(unver string) {
+	fullkeys, found := c.unver[unver]
+	if !found {
+		return
+	}
+	for key := range fullkeys {
+		if elem, found := c.entries[key]; found {
+			elem.Value.(*bcEntry).stats.Invalidations++
+			c.removeElement(elem)
+		}
+	}
+}
+
+// returns a store that tries the block cache before resorting to passed Store.
+// Mutations through the returned store invalidate cached values.
+func wrapBlockcache(store dvid.Store, cache *blockcache, name string) (dvid.Store, error) {
+	okvstore, ok := store.(OrderedKeyValueDB)
+	if !ok {
+		return store, fmt.Errorf("can't wrap store %s in blockcache: doesn't implement OrderedKeyValueDB", store)
+	}
+	batcher, ok := store.(KeyValueBatcher)
+	if !ok {
+		return store, fmt.Errorf("can't wrap store %s in blockcache: doesn't implement KeyValueBatcher", store)
+	}
+	b := &blockcacheStore{
+		OrderedKeyValueDB: okvstore,
+		batcher:           batcher,
+		cache:             cache,
+		stats:             cache.instanceStats(name),
+	}
+	return exposeOptional(b, store), nil
+}
+
+type blockcacheStore struct {
+	OrderedKeyValueDB
+	batcher KeyValueBatcher
+	cache   *blockcache
+	stats   *BlockcacheStats
+}
+
+func (b *blockcacheStore) String() string {
+	return fmt.Sprintf("blockcache-wrapped %s", b.OrderedKeyValueDB)
+}
+
+// unversionedKey returns the unversioned portion of a full key as a string.
+func unversionedKey(k Key) (string, error) {
+	unver, _, err := SplitKey(k)
+	if err != nil {
+		return "", err
+	}
+	return string(unver), nil
+}
+
+// invalidateTKeys removes any cached values for the type-specific keys in the given context.
+func (b *blockcacheStore) invalidateTKeys(ctx Context, tkeys ...TKey) {
+	ip, ok := ctx.(instanceProvider)
+	if !ok {
+		return
+	}
+	unverKeys := make([]string, 0, len(tkeys))
+	for _, tk := range tkeys {
+		unver, err := unversionedKey(ctx.ConstructKey(tk))
+		if err != nil {
+			dvid.Errorf("unable to invalidate block cache key: %v\n", err)
+			b.cache.invalidateInstance(ip.InstanceID())
+			return
+		}
+		unverKeys = append(unverKeys, unver)
+	}
+	b.cache.invalidate(ip.InstanceID(), unverKeys...)
+}
+
+// invalidateKey removes any cached values for the full key.
+func (b *blockcacheStore) invalidateKey(k Key) {
+	if !k.IsDataKey() {
+		return
+	}
+	id, _, _, err := DataKeyToLocalIDs(k)
+	if err != nil {
+		return
+	}
+	unver, err := unversionedKey(k)
+	if err != nil {
+		b.cache.invalidateInstance(id)
+		return
+	}
+	b.cache.invalidate(id, unver)
+}
+
+// invalidateAll removes any cached values for the data instance in the given context.
+func (b *blockcacheStore) invalidateAll(ctx Context) {
+	if ip, ok := ctx.(instanceProvider); ok {
+		b.cache.invalidateInstance(ip.InstanceID())
+	}
+}
+
+// ---- KeyValueGetter interface
+
+func (b *blockcacheStore) Get(ctx Context, k TKey) ([]byte, error) {
+	// we only cache data contexts that have InstanceID().
+	ip, ok := ctx.(instanceProvider)
+	if !ok {
+		return b.OrderedKeyValueDB.Get(ctx, k)
+	}
+	fullkey := ctx.ConstructKey(k)
+	key := string(fullkey)
+	value, found, gen := b.cache.get(key, b.stats, ip.InstanceID())
+	if found {
+		return value, nil
+	}
+	value, err := b.OrderedKeyValueDB.Get(ctx, k)
+	if err != nil || value == nil {
+		return value, err
+	}
+	unver, err := unversionedKey(fullkey)
+	if err != nil {
+		return value, nil
+	}
+	b.cache.add(ip.InstanceID(), gen, key, unver, value, b.stats)
+	return value, nil
+}
+
+// addTKey caches a value read from the underlying store for a type-specific key.
+func (b *blockcacheStore) addTKey(ctx Context, id dvid.InstanceID, gen uint64, tk TKey, value []byte) {
+	if value == nil {
+		return
+	}
+	fullkey := ctx.ConstructKey(tk)
+	unver, err := unversionedKey(fullkey)
+	if err != nil {
+		return
+	}
+	b.cache.add(id, gen, string(fullkey), unver, value, b.stats)
+}
+
+// getCachedRange returns the key-value pairs in the range if all of them are cached.
+// Otherwise it returns the generation that should be used to cache values read from
+// the underlying store.
+func (b *blockcacheStore) getCachedRange(ctx Context, id dvid.InstanceID, kStart, kEnd TKey) (kvs []*TKeyValue, found bool, gen uint64, err error) {
+	gen = b.cache.generationOf(id)
+	tkeys, err := b.OrderedKeyValueDB.KeysInRange(ctx, kStart, kEnd)
+	if err != nil || len(tkeys) == 0 {
+		return nil, false, gen, err
+	}
+	keys := make([]string, len(tkeys))
+	for i, tk := range tkeys {
+		keys[i] = string(ctx.ConstructKey(tk))
+	}
+	values, found, gen := b.cache.getAll(keys, b.stats, id)
+	if !found {
+		return nil, false, gen, nil
+	}
+	kvs = make([]*TKeyValue, len(tkeys))
+	for i, tk := range tkeys {
+		kvs[i] = &TKeyValue{K: tk, V: values[i]}
+	}
+	return kvs, true, 0, nil
+}
+
+// ---- KeyValueChecker interface
+
+func (b *blockcacheStore) Exists(ctx Context, tk TKey) (bool, error) {
+	checker, ok := b.OrderedKeyValueDB.(KeyValueChecker)
+	if !ok {
+		value, err := b.Get(ctx, tk)
+		return value != nil, err
+	}
+	return checker.Exists(ctx, tk)
+}
+
+// ---- KeyValueTimestampGetter interface
+
+func (b *blockcacheStore) GetWithTimestamp(ctx Context, tk TKey) ([]byte, time.Time, error) {
+	getter, ok := b.OrderedKeyValueDB.(KeyValueTimestampGetter)
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("store %s cannot return timestamps", b.OrderedKeyValueDB)
+	}
+	return getter.GetWithTimestamp(ctx, tk)
+}
+
+// ---- OrderedKeyValueGetter interface
+
+// GetRange returns the cached key-value pairs if every key in the range is cached.
+// Otherwise the range is read from the underlying store and its values are cached.
+func (b *blockcacheStore) GetRange(ctx Context, kStart, kEnd TKey) ([]*TKeyValue, error) {
+	ip, ok := ctx.(instanceProvider)
+	if !ok {
+		return b.OrderedKeyValueDB.GetRange(ctx, kStart, kEnd)
+	}
+	id := ip.InstanceID()
+	kvs, found, gen, err := b.getCachedRange(ctx, id, kStart, kEnd)
+	if err != nil || found {
+		return kvs, err
+	}
+	kvs, err = b.OrderedKeyValueDB.GetRange(ctx, kStart, kEnd)
+	if err != nil {
+		return kvs, err
+	}
+	for _, kv := range kvs {
+		b.addTKey(ctx, id, gen, kv.K, kv.V)
+	}
+	return kvs, nil
+}
+
+// ProcessRange sends cached key-value pairs to the chunk handler if every key in the
+// range is cached.  Otherwise the range is processed from the underlying store and its
+// values are cached.
+func (b *blockcacheStore) ProcessRange(ctx Context, kStart, kEnd TKey, op *ChunkOp, f ChunkFunc) error {
+	ip, ok := ctx.(instanceProvider)
+	if !ok {
+		return b.OrderedKeyValueDB.ProcessRange(ctx, kStart, kEnd, op, f)
+	}
+	id := ip.InstanceID()
+	kvs, found, gen, err := b.getCachedRange(ctx, id, kStart, kEnd)
+	if err != nil {
+		return err
+	}
+	if found {
+		for _, kv := range kvs {
+			if err := f(&Chunk{op, kv}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return b.OrderedKeyValueDB.ProcessRange(ctx, kStart, kEnd, op, func(c *Chunk) error {
+		if c.TKeyValue != nil {
+			b.addTKey(ctx, id, gen, c.K, c.V)
+		}
+		return f(c)
+	})
+}
+
+// ---- KeyValueSetter interface
+
+func (b *blockcacheStore) Put(ctx Context, tk TKey, v []byte) error {
+	err := b.OrderedKeyValueDB.Put(ctx, tk, v)
+	b.invalidateTKeys(ctx, tk)
+	return err
+}
+
+func (b *blockcacheStore) Delete(ctx Context, tk TKey) error {
+	err := b.OrderedKeyValueDB.Delete(ctx, tk)
+	b.invalidateTKeys(ctx, tk)
+	return err
+}
+
+func (b *blockcacheStore) RawPut(k Key, v []byte) error {
+	err := b.OrderedKeyValueDB.RawPut(k, v)
+	b.invalidateKey(k)
+	return err
+}
+
+func (b *blockcacheStore) RawDelete(k Key) error {
+	err := b.OrderedKeyValueDB.RawDelete(k)
+	b.invalidateKey(k)
+	return err
+}
+
+// ---- OrderedKeyValueSetter interface
+
+func (b *blockcacheStore) PutRange(ctx Context, kvs []TKeyValue) error {
+	err := b.OrderedKeyValueDB.PutRange(ctx, kvs)
+	tkeys := make([]TKey, len(kvs))
+	for i, kv := range kvs {
+		tkeys[i] = kv.K
+	}
+	b.invalidateTKeys(ctx, tkeys...)
+	return err
+}
+
+func (b *blockcacheStore) DeleteRange(ctx Context, kStart, kEnd TKey) error {
+	err := b.OrderedKeyValueDB.DeleteRange(ctx, kStart, kEnd)
+	b.invalidateAll(ctx)
+	return err
+}
+
+func (b *blockcacheStore) DeleteAll(ctx Context, allVersions bool) error {
+	err := b.OrderedKeyValueDB.DeleteAll(ctx, allVersions)
+	b.invalidateAll(ctx)
+	return err
+}
+
+// ---- TKeyClassDeleter interface
+
+func (b *blockcacheStore) DeleteTKeyClass(ctx Context, tkc TKeyClass, allVersions bool) error {
+	deleter, ok := b.OrderedKeyValueDB.(TKeyClassDeleter)
+	if !ok {
+		return fmt.Errorf("store %s cannot delete a class of type-specific keys", b.OrderedKeyValueDB)
+	}
+	err := deleter.DeleteTKeyClass(ctx, tkc, allVersions)
+	b.invalidateAll(ctx)
+	return err
+}
+
+// ---- SizeViewer interface
+
+func (b *blockcacheStore) GetApproximateSizes(ranges []KeyRange) ([]uint64, error) {
+	sv, ok := b.OrderedKeyValueDB.(SizeViewer)
+	if !ok {
+		return nil, fmt.Errorf("store %s cannot return approximate sizes", b.OrderedKeyValueDB)
+	}
+	return sv.GetApproximateSizes(ranges)
+}
+
+// ---- BlobStore interface
+
+func (b *blockcacheStore) PutBlob(v []byte) (string, error) {
+	blobstore, ok := b.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return "", fmt.Errorf("store %s is not a blob store", b.OrderedKeyValueDB)
+	}
+	return blobstore.PutBlob(v)
+}
+
+func (b *blockcacheStore) GetBlob(ref string) ([]byte, error) {
+	blobstore, ok := b.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return nil, fmt.Errorf("store %s is not a blob store", b.OrderedKeyValueDB)
+	}
+	return blobstore.GetBlob(ref)
+}
+
+// ---- TransactionDB interface
+
+func (b *blockcacheStore) LockKey(k Key) error {
+	transdb, ok := b.OrderedKeyValueDB.(TransactionDB)
+	if !ok {
+		return fmt.Errorf("store %s does not support transactions", b.OrderedKeyValueDB)
+	}
+	return transdb.LockKey(k)
+}
+
+func (b *blockcacheStore) UnlockKey(k Key) error {
+	transdb, ok := b.OrderedKeyValueDB.(TransactionDB)
+	if !ok {
+		return fmt.Errorf("store %s does not support transactions", b.OrderedKeyValueDB)
+	}
+	return transdb.UnlockKey(k)
+}
+
+func (b *blockcacheStore) Patch(ctx Context, tk TKey, f PatchFunc) error {
+	transdb, ok := b.OrderedKeyValueDB.(TransactionDB)
+	if !ok {
+		return fmt.Errorf("store %s does not support transactions", b.OrderedKeyValueDB)
+	}
+	err := transdb.Patch(ctx, tk, f)
+	b.invalidateTKeys(ctx, tk)
+	return err
+}
+
+// ---- KeyValueRequester interface
+
+func (b *blockcacheStore) NewBuffer(ctx Context) RequestBuffer {
+	requester, ok := b.OrderedKeyValueDB.(KeyValueRequester)
+	if !ok {
+		return nil
+	}
+	return &blockcacheBuffer{
+		RequestBuffer: requester.NewBuffer(ctx),
+		store:         b,
+	}
+}
+
+// blockcacheBuffer invalidates cached values for any mutation queued in the buffer.
+// Since the buffered operations may execute any time up to the flush, the keys are
+// invalidated again after the flush.
+type blockcacheBuffer struct {
+	RequestBuffer
+	store *blockcacheStore
+
+	mu      sync.Mutex
+	ctxs    []Context
+	tkeys   [][]TKey
+	keys    []Key
+	allCtxs []Context
+}
+
+func (buf *blockcacheBuffer) record(ctx Context, tkeys ...TKey) {
+	buf.store.invalidateTKeys(ctx, tkeys...)
+	buf.mu.Lock()
+	buf.ctxs = append(buf.ctxs, ctx)
+	buf.tkeys = append(buf.tkeys, tkeys)
+	buf.mu.Unlock()
+}
+
+func (buf *blockcacheBuffer) recordKey(k Key) {
+	buf.store.invalidateKey(k)
+	buf.mu.Lock()
+	buf.keys = append(buf.keys, k)
+	buf.mu.Unlock()
+}
+
+func (buf *blockcacheBuffer) recordAll(ctx Context) {
+	buf.store.invalidateAll(ctx)
+	buf.mu.Lock()
+	buf.allCtxs = append(buf.allCtxs, ctx)
+	buf.mu.Unlock()
+}
+
+func (buf *blockcacheBuffer) Put(ctx Context, tk TKey, v []byte) error {
+	buf.record(ctx, tk)
+	return buf.RequestBuffer.Put(ctx, tk, v)
+}
+
+func (buf *blockcacheBuffer) Delete(ctx Context, tk TKey) error {
+	buf.record(ctx, tk)
+	return buf.RequestBuffer.Delete(ctx, tk)
+}
+
+func (buf *blockcacheBuffer) RawPut(k Key, v []byte) error {
+	buf.recordKey(k)
+	return buf.RequestBuffer.RawPut(k, v)
+}
+
+func (buf *blockcacheBuffer) RawDelete(k Key) error {
+	buf.recordKey(k)
+	return buf.RequestBuffer.RawDelete(k)
+}
+
+func (buf *blockcacheBuffer) PutRange(ctx Context, kvs []TKeyValue) error {
+	tkeys := make([]TKey, len(kvs))
+	for i, kv := range kvs {
+		tkeys[i] = kv.K
+	}
+	buf.record(ctx, tkeys...)
+	return buf.RequestBuffer.PutRange(ctx, kvs)
+}
+
+func (buf *blockcacheBuffer) DeleteRange(ctx Context, kStart, kEnd TKey) error {
+	buf.recordAll(ctx)
+	return buf.RequestBuffer.DeleteRange(ctx, kStart, kEnd)
+}
+
+func (buf *blockcacheBuffer) PutCallback(ctx Context, tk TKey, v []byte, ready chan error) error {
+	buf.record(ctx, tk)
+	return buf.RequestBuffer.PutCallback(ctx, tk, v, ready)
+}
+
+func (buf *blockcacheBuffer) Flush() error {
+	err := buf.RequestBuffer.Flush()
+	buf.mu.Lock()
+	for i, ctx := range buf.ctxs {
+		buf.store.invalidateTKeys(ctx, buf.tkeys[i]...)
+	}
+	for _, k := range buf.keys {
+		buf.store.invalidateKey(k)
+	}
+	for _, ctx := range buf.allCtxs {
+		buf.store.invalidateAll(ctx)
+	}
+	buf.ctxs, buf.tkeys, buf.keys, buf.allCtxs = nil, nil, nil, nil
+	buf.mu.Unlock()
+	return err
+}
+
+// ---- KeyValueBatcher interface
+
+func (b *blockcacheStore) NewBatch(ctx Context) Batch {
+	return &blockcacheBatch{
+		Batch: b.batcher.NewBatch(ctx),
+		ctx:   ctx,
+		store: b,
+	}
+}
+
+// blockcacheBatch records the keys modified within a batch so they can be invalidated
+// on commit.
+type blockcacheBatch struct {
+	Batch
+	ctx   Context
+	store *blockcacheStore
+	tkeys []TKey
+}
+
+func (batch *blockcacheBatch) Delete(tk TKey) {
+	batch.Batch.Delete(tk)
+	batch.tkeys = append(batch.tkeys, tk)
+}
+
+func (batch *blockcacheBatch) Put(tk TKey, v []byte) {
+	batch.Batch.Put(tk, v)
+	batch.tkeys = append(batch.tkeys, tk)
+}
+
+func (batch *blockcacheBatch) Commit() error {
+	err := batch.Batch.Commit()
+	batch.store.invalidateTKeys(batch.ctx, batch.tkeys...)
+	batch.tkeys = nil
+	return err
+}
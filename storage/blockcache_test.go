@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlockcacheInvalidation(t *testing.T) {
+	cache := newBlockcache(1000)
+	stats := cache.instanceStats("mydata:01")
+
+	tk := TKey([]byte{0x08, 0x33, 0x71})
+	ctx1 := GetTestDataContext(TestUUID1, "mydata", 23)
+	ctx2 := GetTestDataContext(TestUUID2, "mydata", 23)
+	k1 := ctx1.ConstructKey(tk)
+	k2 := ctx2.ConstructKey(tk)
+	unver, err := unversionedKey(k1)
+	if err != nil {
+		t.Fatalf("couldn't get unversioned key: %v\n", err)
+	}
+
+	_, found, gen := cache.get(string(k1), stats, 23)
+	if found {
+		t.Fatalf("expected miss on empty cache\n")
+	}
+	cache.add(23, gen, string(k1), unver, []byte("version 1"), stats)
+	_, _, gen = cache.get(string(k2), stats, 23)
+	cache.add(23, gen, string(k2), unver, []byte("version 2"), stats)
+
+	value, found, _ := cache.get(string(k1), stats, 23)
+	if !found || !bytes.Equal(value, []byte("version 1")) {
+		t.Fatalf("expected cached version 1 value, got %q\n", value)
+	}
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("bad stats after gets: %v\n", *stats)
+	}
+
+	// mutation of the unversioned key should remove all versions.
+	cache.invalidate(23, unver)
+	if _, found, _ = cache.get(string(k1), stats, 23); found {
+		t.Errorf("expected version 1 value to be invalidated\n")
+	}
+	if _, found, _ = cache.get(string(k2), stats, 23); found {
+		t.Errorf("expected version 2 value to be invalidated\n")
+	}
+	if stats.Invalidations != 2 || stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("bad stats after invalidation: %v\n", *stats)
+	}
+
+	// a read that started before an invalidation should not be cached.
+	_, _, gen = cache.get(string(k1), stats, 23)
+	cache.invalidateInstance(23)
+	cache.add(23, gen, string(k1), unver, []byte("stale"), stats)
+	if _, found, _ = cache.get(string(k1), stats, 23); found {
+		t.Errorf("expected stale value to not be cached\n")
+	}
+}
+
+func TestBlockcacheEviction(t *testing.T) {
+	cache := newBlockcache(100)
+	stats := cache.instanceStats("mydata:01")
+	ctx := GetTestDataContext(TestUUID1, "mydata", 23)
+
+	var keys []string
+	for i := byte(0); i < 10; i++ {
+		k := ctx.ConstructKey(TKey([]byte{0x08, i}))
+		unver, err := unversionedKey(k)
+		if err != nil {
+			t.Fatalf("couldn't get unversioned key: %v\n", err)
+		}
+		_, _, gen := cache.get(string(k), stats, 23)
+		cache.add(23, gen, string(k), unver, make([]byte, 20), stats)
+		keys = append(keys, string(k))
+	}
+	if cache.used > cache.capacity {
+		t.Fatalf("cache used %d bytes, exceeding capacity %d\n", cache.used, cache.capacity)
+	}
+	if stats.Evictions == 0 {
+		t.Fatalf("expected evictions after exceeding capacity\n")
+	}
+	if _, found, _ := cache.get(keys[0], stats, 23); found {
+		t.Errorf("expected least recently used key to be evicted\n")
+	}
+	if _, found, _ := cache.get(keys[9], stats, 23); !found {
+		t.Errorf("expected most recently used key to be cached\n")
+	}
+	if stats.Bytes != cache.used || stats.Entries != cache.lru.Len() {
+		t.Errorf("stats (%v) don't match cache contents\n", *stats)
+	}
+}
+
+func TestBlockcacheCopies(t *testing.T) {
+	cache := newBlockcache(1000)
+	stats := cache.instanceStats("mydata:01")
+
+	tk := TKey([]byte{0x08, 0x33, 0x71})
+	ctx := GetTestDataContext(TestUUID1, "mydata", 23)
+	k := ctx.ConstructKey(tk)
+	unver, err := unversionedKey(k)
+	if err != nil {
+		t.Fatalf("couldn't get unversioned key: %v\n", err)
+	}
+	value := []byte("version 1")
+	_, _, gen := cache.get(string(k), stats, 23)
+	cache.add(23, gen, string(k), unver, value, stats)
+	value[0] = 'X'
+
+	got, found, _ := cache.get(string(k), stats, 23)
+	if !found || !bytes.Equal(got, []byte("version 1")) {
+		t.Fatalf("expected cached value to be unaffected by caller, got %q\n", got)
+	}
+	got[0] = 'X'
+	values, found, _ := cache.getAll([]string{string(k)}, stats, 23)
+	if !found || !bytes.Equal(values[0], []byte("version 1")) {
+		t.Fatalf("expected cached value to be unaffected by returned slice, got %q\n", values)
+	}
+	if _, found, _ = cache.getAll([]string{string(k), "missing"}, stats, 23); found {
+		t.Errorf("expected getAll to fail if any key is not cached\n")
+	}
+}
+
+type requesterStore struct {
+	OrderedKeyValueDB
+}
+
+func (s requesterStore) NewBatch(ctx Context) Batch {
+	return nil
+}
+
+func (s requesterStore) NewBuffer(ctx Context) RequestBuffer {
+	return nil
+}
+
+func TestBlockcacheOptionalInterfaces(t *testing.T) {
+	cache := newBlockcache(1000)
+	store, err := wrapBlockcache(requesterStore{}, cache, "mydata:01")
+	if err != nil {
+		t.Fatalf("couldn't wrap store: %v\n", err)
+	}
+	if _, ok := store.(KeyValueRequester); !ok {
+		t.Errorf("expected wrapped store to be a KeyValueRequester\n")
+	}
+	if _, ok := store.(KeyValueTimestampGetter); ok {
+		t.Errorf("expected wrapped store not to be a KeyValueTimestampGetter\n")
+	}
+	if _, ok := store.(TransactionDB); ok {
+		t.Errorf("expected wrapped store not to be a TransactionDB\n")
+	}
+	if _, ok := unwrapOptional(store).(*blockcacheStore); !ok {
+		t.Errorf("expected to recover block cache wrapper, got %T\n", unwrapOptional(store))
+	}
+}
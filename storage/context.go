@@ -114,6 +114,12 @@ type RequestCtx interface {
 	SetRequestID(id string)
 }
 
+// TracedCtx is associated with a trace span that storage operations are recorded under.
+type TracedCtx interface {
+	// Span returns the span of the request or nil if the request is not traced.
+	Span() *dvid.TraceSpan
+}
+
 // DataKeyRange returns the min and max Key across all data keys.
 func DataKeyRange() (minKey, maxKey Key) {
 	var minID, maxID dvid.InstanceID
@@ -313,6 +319,7 @@ type DataContext struct {
 	version dvid.VersionID
 	client  dvid.ClientID
 	reqID   string
+	span    *dvid.TraceSpan
 }
 
 // NewDataContext provides a way for datatypes to create a Context that adheres to DVID
@@ -320,7 +327,7 @@ type DataContext struct {
 // only be implemented within package storage, we force compatible implementations to embed
 // DataContext and initialize it via this function.
 func NewDataContext(data dvid.Data, versionID dvid.VersionID) *DataContext {
-	return &DataContext{data, versionID, 0, ""}
+	return &DataContext{data, versionID, 0, "", nil}
 }
 
 func (ctx *DataContext) UpdateInstance(k Key) error {
@@ -363,6 +370,18 @@ func (ctx *DataContext) SetRequestID(id string) {
 	ctx.reqID = id
 }
 
+// ---- storage.TracedCtx implementation
+
+// Span returns the trace span of the request or nil if the request is not traced.
+func (ctx *DataContext) Span() *dvid.TraceSpan {
+	return ctx.span
+}
+
+// SetSpan sets the trace span under which storage operations are recorded.
+func (ctx *DataContext) SetSpan(span *dvid.TraceSpan) {
+	ctx.span = span
+}
+
 // ---- storage.Context implementation
 
 func (ctx *DataContext) implementsOpaque() {}
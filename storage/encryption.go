@@ -0,0 +1,860 @@
+/*
+	This file implements encryption at rest for any ordered key-value store.  Values are
+	encrypted with AES-GCM before reaching the underlying store and decrypted on reads.
+	Keys are stored in the clear so range queries continue to work.
+
+	Encryption is enabled by adding a "keyfile" setting to a store configuration, e.g.,
+
+	[store.raid6]
+	engine = "basholeveldb"
+	path = "/data/dbs/basholeveldb"
+	keyfile = "/secure/dvid-raid6.keys"
+
+	The keyfile holds one key per line in the form "<key id>:<hex-encoded key>" where the
+	key id is an integer from 1 to 255 and the key is 16, 24, or 32 bytes for AES-128,
+	AES-192, or AES-256.  Blank lines and lines starting with "#" are ignored.  The last
+	key in the file is used for all new writes while earlier keys are only used for reading.
+	To rotate keys, append a new key to the file and restart.  If there is more than one key
+	in the file, all values encrypted with older keys are re-encrypted in the background,
+	after which the older keys can be removed from the file.  Setting "reencrypt = true"
+	in the store configuration forces a background pass that also encrypts any values
+	written before encryption was enabled.
+
+	A legacy plaintext value could happen to start with the encryption header, so values
+	that fail authentication are returned as is until the store is known to be fully
+	encrypted.  A store is marked fully encrypted when it is created with a keyfile or
+	after a background re-encryption pass completes, after which such values are errors.
+*/
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// encryptedMagic prefixes all encrypted values so values written before encryption
+// was enabled can still be read.
+var encryptedMagic = []byte{0xD5, 'E', 'N', 'C'}
+
+const (
+	encryptedHeaderSize = 5 // magic + key id
+
+	// number of key-value pairs read per re-encryption batch.
+	reencryptBatchSize = 1000
+
+	// number of re-encrypted key-value pairs written while holding the write lock.
+	reencryptWriteSize = 100
+
+	// number of consecutive batches interrupted by concurrent writes before
+	// re-encryption reads values while holding the write lock.
+	reencryptMaxConflicts = 3
+)
+
+// encryptionKeyClass is the metadata TKey class under which a store records that all
+// its values are encrypted.  It is well above the classes used by package datastore.
+const encryptionKeyClass TKeyClass = 0xE1
+
+// EncryptionStats describes the state of an encrypted store.
+type EncryptionStats struct {
+	CurrentKeyID   uint8
+	NumKeys        int
+	FullyEncrypted bool // if true, values with a bad encryption header are errors
+	Reencrypting   bool
+	Reencrypted    uint64 // number of values re-encrypted by background rotation
+	ReencryptErrs  uint64
+}
+
+type encryptionKeys struct {
+	current uint8
+	aeads   map[uint8]cipher.AEAD
+}
+
+// loadKeyfile reads encryption keys from a keyfile.
+func loadKeyfile(filename string) (*encryptionKeys, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open encryption keyfile %q: %v", filename, err)
+	}
+	defer f.Close()
+	keys := &encryptionKeys{aeads: make(map[uint8]cipher.AEAD)}
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("keyfile %q line %d: expected <key id>:<hex key>", filename, lineNum)
+		}
+		id, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 8)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("keyfile %q line %d: key id must be integer from 1 to 255", filename, lineNum)
+		}
+		secret, err := hex.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("keyfile %q line %d: bad hex key: %v", filename, lineNum, err)
+		}
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, fmt.Errorf("keyfile %q line %d: %v", filename, lineNum, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("keyfile %q line %d: %v", filename, lineNum, err)
+		}
+		if _, found := keys.aeads[uint8(id)]; found {
+			return nil, fmt.Errorf("keyfile %q line %d: duplicate key id %d", filename, lineNum, id)
+		}
+		keys.aeads[uint8(id)] = aead
+		keys.current = uint8(id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading keyfile %q: %v", filename, err)
+	}
+	if len(keys.aeads) == 0 {
+		return nil, fmt.Errorf("no keys found in keyfile %q", filename)
+	}
+	return keys, nil
+}
+
+// encrypt returns the value encrypted with the current key.
+func (keys *encryptionKeys) encrypt(plaintext []byte) ([]byte, error) {
+	aead := keys.aeads[keys.current]
+	nonceSize := aead.NonceSize()
+	out := make([]byte, encryptedHeaderSize+nonceSize, encryptedHeaderSize+nonceSize+len(plaintext)+aead.Overhead())
+	copy(out, encryptedMagic)
+	out[len(encryptedMagic)] = keys.current
+	nonce := out[encryptedHeaderSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce for encryption: %v", err)
+	}
+	return aead.Seal(out, nonce, plaintext, nil), nil
+}
+
+// keyID returns the key id used to encrypt a value or false if it isn't encrypted.
+func keyID(value []byte) (uint8, bool) {
+	if len(value) < encryptedHeaderSize || !bytes.HasPrefix(value, encryptedMagic) {
+		return 0, false
+	}
+	return value[len(encryptedMagic)], true
+}
+
+// decrypt returns the plaintext of a value.  Values that weren't encrypted, e.g.,
+// tombstones or values written before encryption was enabled, are returned as is.
+func (keys *encryptionKeys) decrypt(value []byte) ([]byte, error) {
+	id, encrypted := keyID(value)
+	if !encrypted {
+		return value, nil
+	}
+	aead, found := keys.aeads[id]
+	if !found {
+		return nil, fmt.Errorf("value encrypted with key id %d not present in keyfile", id)
+	}
+	nonceSize := aead.NonceSize()
+	if len(value) < encryptedHeaderSize+nonceSize {
+		return nil, fmt.Errorf("encrypted value too short (%d bytes)", len(value))
+	}
+	nonce := value[encryptedHeaderSize : encryptedHeaderSize+nonceSize]
+	plaintext, err := aead.Open(nil, nonce, value[encryptedHeaderSize+nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt value with key id %d: %v", id, err)
+	}
+	return plaintext, nil
+}
+
+// wrapEncryption returns an encrypting store if the store configuration has a keyfile.
+// A newly created store is marked as fully encrypted.
+func wrapEncryption(store dvid.Store, config dvid.StoreConfig, created bool) (dvid.Store, error) {
+	keyfile, found, err := config.GetString("keyfile")
+	if err != nil {
+		return nil, err
+	}
+	if !found || keyfile == "" {
+		return store, nil
+	}
+	okvstore, ok := store.(OrderedKeyValueDB)
+	if !ok {
+		return nil, fmt.Errorf("can't encrypt store %s: doesn't implement OrderedKeyValueDB", store)
+	}
+	batcher, ok := store.(KeyValueBatcher)
+	if !ok {
+		return nil, fmt.Errorf("can't encrypt store %s: doesn't implement KeyValueBatcher", store)
+	}
+	keys, err := loadKeyfile(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	reencrypt, _, err := config.GetBool("reencrypt")
+	if err != nil {
+		return nil, err
+	}
+	e := &encryptedStore{
+		OrderedKeyValueDB: okvstore,
+		batcher:           batcher,
+		keys:              keys,
+	}
+	e.stats.CurrentKeyID = keys.current
+	e.stats.NumKeys = len(keys.aeads)
+	if created {
+		err = e.markFullyEncrypted()
+	} else {
+		err = e.loadFullyEncrypted()
+	}
+	if err != nil {
+		return nil, err
+	}
+	dvid.Infof("Encrypting values for store %s using key id %d\n", store, keys.current)
+	if len(keys.aeads) > 1 || reencrypt {
+		e.stats.Reencrypting = true
+		go e.reencryptAll()
+	}
+	return exposeOptional(e, store), nil
+}
+
+// GetEncryptionStats returns the encryption state of the store with the given alias,
+// or nil if the store is not encrypted.
+func GetEncryptionStats(alias Alias) (*EncryptionStats, error) {
+	store, err := GetStoreByAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := unwrapOptional(store).(*encryptedStore)
+	if !ok {
+		return nil, nil
+	}
+	e.statsMu.RLock()
+	stats := e.stats
+	e.statsMu.RUnlock()
+	return &stats, nil
+}
+
+type encryptedStore struct {
+	OrderedKeyValueDB
+	batcher KeyValueBatcher
+	keys    *encryptionKeys
+
+	// writers hold read lock and increment writes while background re-encryption holds
+	// write lock to check for concurrent mutations before writing re-encrypted values.
+	rotateMu sync.RWMutex
+	writes   uint64
+
+	// set to 1 once all values in the store are known to be encrypted.
+	fullyEncrypted uint32
+
+	statsMu sync.RWMutex
+	stats   EncryptionStats
+}
+
+func (e *encryptedStore) String() string {
+	return fmt.Sprintf("encrypted %s", e.OrderedKeyValueDB)
+}
+
+func encryptionMarkerKey() TKey {
+	return NewTKey(encryptionKeyClass, nil)
+}
+
+func (e *encryptedStore) setFullyEncrypted() {
+	atomic.StoreUint32(&e.fullyEncrypted, 1)
+	e.statsMu.Lock()
+	e.stats.FullyEncrypted = true
+	e.statsMu.Unlock()
+}
+
+// loadFullyEncrypted checks whether the store has been marked as fully encrypted.
+func (e *encryptedStore) loadFullyEncrypted() error {
+	marker, err := e.OrderedKeyValueDB.Get(MetadataContext{}, encryptionMarkerKey())
+	if err != nil {
+		return fmt.Errorf("unable to read encryption marker for store %s: %v", e, err)
+	}
+	if marker != nil {
+		e.setFullyEncrypted()
+	}
+	return nil
+}
+
+// markFullyEncrypted persists the fact that all values in the store are encrypted.
+func (e *encryptedStore) markFullyEncrypted() error {
+	marker, err := e.keys.encrypt([]byte("fully encrypted"))
+	if err != nil {
+		return err
+	}
+	if err := e.OrderedKeyValueDB.Put(MetadataContext{}, encryptionMarkerKey(), marker); err != nil {
+		return fmt.Errorf("unable to write encryption marker for store %s: %v", e, err)
+	}
+	e.setFullyEncrypted()
+	return nil
+}
+
+// decrypt returns the plaintext of a value.  Until the store is fully encrypted, a value
+// that fails authentication is assumed to be a legacy plaintext value that happens to
+// start with the encryption header.
+func (e *encryptedStore) decrypt(value []byte) ([]byte, error) {
+	plaintext, err := e.keys.decrypt(value)
+	if err != nil && atomic.LoadUint32(&e.fullyEncrypted) == 0 {
+		return value, nil
+	}
+	return plaintext, err
+}
+
+// startWrite must be called before any write to the underlying store and
+// the returned function called after the write.
+func (e *encryptedStore) startWrite() func() {
+	e.rotateMu.RLock()
+	atomic.AddUint64(&e.writes, 1)
+	return e.rotateMu.RUnlock
+}
+
+// reencryptAll scans the entire store and encrypts any value not using the current key.
+// If the pass completes, the store is marked as fully encrypted.
+func (e *encryptedStore) reencryptAll() {
+	timedLog := dvid.NewTimeLog()
+	dvid.Infof("Starting background re-encryption of store %s with key id %d...\n", e, e.keys.current)
+	begKey := Key{metadataKeyPrefix}
+	endKey := Key(bytes.Repeat([]byte{0xFF}, 128))
+	var conflicts int
+	for {
+		nextKey, done, conflict, err := e.reencryptBatch(begKey, endKey, conflicts >= reencryptMaxConflicts)
+		if err == nil && done {
+			err = e.markFullyEncrypted()
+		}
+		if err != nil {
+			dvid.Errorf("Aborting re-encryption of store %s: %v\n", e, err)
+			e.statsMu.Lock()
+			e.stats.ReencryptErrs++
+			e.stats.Reencrypting = false
+			e.statsMu.Unlock()
+			return
+		}
+		if done {
+			break
+		}
+		if conflict {
+			conflicts++
+		} else {
+			conflicts = 0
+		}
+		begKey = nextKey
+	}
+	e.statsMu.Lock()
+	e.stats.Reencrypting = false
+	numReencrypted := e.stats.Reencrypted
+	e.statsMu.Unlock()
+	timedLog.Infof("Finished re-encryption of %d values in store %s. Keys other than id %d can be removed from keyfile.", numReencrypted, e, e.keys.current)
+}
+
+// readBatch returns up to maxKV key-value pairs starting at begKey and the key that
+// should start the next batch.
+func (e *encryptedStore) readBatch(begKey, endKey Key, maxKV int) (kvs []*KeyValue, nextKey Key, done bool, err error) {
+	ch := make(chan *KeyValue)
+	cancel := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			kv := <-ch
+			if kv == nil {
+				done = true
+				return
+			}
+			if len(kvs) == maxKV {
+				nextKey = kv.K
+				close(cancel)
+				return
+			}
+			kvs = append(kvs, kv)
+		}
+	}()
+	if err = e.OrderedKeyValueDB.RawRangeQuery(begKey, endKey, false, ch, cancel); err != nil {
+		return
+	}
+	wg.Wait()
+	return
+}
+
+// reencryptValue returns the value encrypted with the current key or nil if the value
+// doesn't need to be re-encrypted.
+func (e *encryptedStore) reencryptValue(kv *KeyValue) ([]byte, error) {
+	if kv.K.IsDataKey() && kv.K.IsTombstone() {
+		return nil, nil
+	}
+	// usage counts are written beneath the encryption layer.
+	if bytes.Equal(kv.K, MetadataContext{}.ConstructKey(NewTKey(usageKeyClass, nil))) {
+		return nil, nil
+	}
+	value, err := e.keys.decrypt(kv.V)
+	if err != nil {
+		if atomic.LoadUint32(&e.fullyEncrypted) == 1 {
+			return nil, err
+		}
+		value = kv.V // legacy plaintext value that starts with encryption header.
+	} else if id, encrypted := keyID(kv.V); encrypted && id == e.keys.current {
+		return nil, nil
+	}
+	return e.keys.encrypt(value)
+}
+
+// reencryptBatch re-encrypts up to reencryptBatchSize key-value pairs starting at begKey
+// and returns the key that should start the next batch.  Values are read and encrypted
+// without blocking writers, and the re-encrypted values are written in small chunks while
+// holding the write lock.  If there was a concurrent write since the values were read,
+// the remaining values are not written and conflict is returned so they are read again
+// in the next batch.  If locked is true, a smaller batch is read and written while
+// holding the write lock so re-encryption progresses despite constant writes.
+func (e *encryptedStore) reencryptBatch(begKey, endKey Key, locked bool) (nextKey Key, done, conflict bool, err error) {
+	maxKV := reencryptBatchSize
+	if locked {
+		maxKV = reencryptWriteSize
+		e.rotateMu.Lock()
+		defer e.rotateMu.Unlock()
+	}
+	writes := atomic.LoadUint64(&e.writes)
+	var kvs []*KeyValue
+	if kvs, nextKey, done, err = e.readBatch(begKey, endKey, maxKV); err != nil {
+		return
+	}
+	for start := 0; start < len(kvs); start += reencryptWriteSize {
+		end := start + reencryptWriteSize
+		if end > len(kvs) {
+			end = len(kvs)
+		}
+		var updates []KeyValue
+		for _, kv := range kvs[start:end] {
+			var value []byte
+			if value, err = e.reencryptValue(kv); err != nil {
+				return
+			}
+			if value != nil {
+				updates = append(updates, KeyValue{K: kv.K, V: value})
+			}
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		if !locked {
+			e.rotateMu.Lock()
+			if atomic.LoadUint64(&e.writes) != writes {
+				e.rotateMu.Unlock()
+				return kvs[start].K, false, true, nil
+			}
+		}
+		for _, kv := range updates {
+			if err = e.OrderedKeyValueDB.RawPut(kv.K, kv.V); err != nil {
+				break
+			}
+		}
+		if !locked {
+			e.rotateMu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+		e.statsMu.Lock()
+		e.stats.Reencrypted += uint64(len(updates))
+		e.statsMu.Unlock()
+	}
+	return
+}
+
+// ---- KeyValueGetter interface
+
+func (e *encryptedStore) Get(ctx Context, k TKey) ([]byte, error) {
+	value, err := e.OrderedKeyValueDB.Get(ctx, k)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return e.decrypt(value)
+}
+
+// ---- OrderedKeyValueGetter interface
+
+func (e *encryptedStore) GetRange(ctx Context, kStart, kEnd TKey) ([]*TKeyValue, error) {
+	kvs, err := e.OrderedKeyValueDB.GetRange(ctx, kStart, kEnd)
+	if err != nil {
+		return nil, err
+	}
+	for _, kv := range kvs {
+		if kv.V, err = e.decrypt(kv.V); err != nil {
+			return nil, err
+		}
+	}
+	return kvs, nil
+}
+
+func (e *encryptedStore) ProcessRange(ctx Context, kStart, kEnd TKey, op *ChunkOp, f ChunkFunc) error {
+	return e.OrderedKeyValueDB.ProcessRange(ctx, kStart, kEnd, op, func(c *Chunk) error {
+		if c.TKeyValue != nil && c.TKeyValue.V != nil {
+			value, err := e.decrypt(c.TKeyValue.V)
+			if err != nil {
+				return err
+			}
+			c.TKeyValue.V = value
+		}
+		return f(c)
+	})
+}
+
+func (e *encryptedStore) RawRangeQuery(kStart, kEnd Key, keysOnly bool, out chan *KeyValue, cancel <-chan struct{}) error {
+	if keysOnly {
+		return e.OrderedKeyValueDB.RawRangeQuery(kStart, kEnd, keysOnly, out, cancel)
+	}
+	ch := make(chan *KeyValue)
+	stop := make(chan struct{})
+	var decryptErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			kv := <-ch
+			if kv == nil {
+				out <- nil
+				return
+			}
+			if kv.V != nil {
+				var err error
+				if kv.V, err = e.decrypt(kv.V); err != nil {
+					decryptErr = err
+					close(stop)
+					select {
+					case out <- nil:
+					case <-cancel:
+					}
+					return
+				}
+			}
+			select {
+			case out <- kv:
+			case <-cancel:
+				close(stop)
+				return
+			}
+		}
+	}()
+	err := e.OrderedKeyValueDB.RawRangeQuery(kStart, kEnd, keysOnly, ch, stop)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	return decryptErr
+}
+
+// ---- KeyValueSetter interface
+
+func (e *encryptedStore) Put(ctx Context, tk TKey, v []byte) error {
+	value, err := e.keys.encrypt(v)
+	if err != nil {
+		return err
+	}
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.Put(ctx, tk, value)
+}
+
+func (e *encryptedStore) Delete(ctx Context, tk TKey) error {
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.Delete(ctx, tk)
+}
+
+func (e *encryptedStore) RawPut(k Key, v []byte) error {
+	value, err := e.keys.encrypt(v)
+	if err != nil {
+		return err
+	}
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.RawPut(k, value)
+}
+
+func (e *encryptedStore) RawDelete(k Key) error {
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.RawDelete(k)
+}
+
+// ---- OrderedKeyValueSetter interface
+
+func (e *encryptedStore) PutRange(ctx Context, kvs []TKeyValue) error {
+	encrypted := make([]TKeyValue, len(kvs))
+	for i, kv := range kvs {
+		value, err := e.keys.encrypt(kv.V)
+		if err != nil {
+			return err
+		}
+		encrypted[i] = TKeyValue{K: kv.K, V: value}
+	}
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.PutRange(ctx, encrypted)
+}
+
+func (e *encryptedStore) DeleteRange(ctx Context, kStart, kEnd TKey) error {
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.DeleteRange(ctx, kStart, kEnd)
+}
+
+func (e *encryptedStore) DeleteAll(ctx Context, allVersions bool) error {
+	defer e.startWrite()()
+	return e.OrderedKeyValueDB.DeleteAll(ctx, allVersions)
+}
+
+// ---- KeyValueChecker interface
+
+func (e *encryptedStore) Exists(ctx Context, tk TKey) (bool, error) {
+	checker, ok := e.OrderedKeyValueDB.(KeyValueChecker)
+	if !ok {
+		value, err := e.OrderedKeyValueDB.Get(ctx, tk)
+		return value != nil, err
+	}
+	return checker.Exists(ctx, tk)
+}
+
+// ---- KeyValueTimestampGetter interface
+
+func (e *encryptedStore) GetWithTimestamp(ctx Context, tk TKey) ([]byte, time.Time, error) {
+	getter, ok := e.OrderedKeyValueDB.(KeyValueTimestampGetter)
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("store %s cannot return timestamps", e.OrderedKeyValueDB)
+	}
+	value, modified, err := getter.GetWithTimestamp(ctx, tk)
+	if err != nil || value == nil {
+		return value, modified, err
+	}
+	value, err = e.decrypt(value)
+	return value, modified, err
+}
+
+// ---- TKeyClassDeleter interface
+
+func (e *encryptedStore) DeleteTKeyClass(ctx Context, tkc TKeyClass, allVersions bool) error {
+	deleter, ok := e.OrderedKeyValueDB.(TKeyClassDeleter)
+	if !ok {
+		return fmt.Errorf("store %s cannot delete a class of type-specific keys", e.OrderedKeyValueDB)
+	}
+	defer e.startWrite()()
+	return deleter.DeleteTKeyClass(ctx, tkc, allVersions)
+}
+
+// ---- SizeViewer interface
+
+func (e *encryptedStore) GetApproximateSizes(ranges []KeyRange) ([]uint64, error) {
+	sv, ok := e.OrderedKeyValueDB.(SizeViewer)
+	if !ok {
+		return nil, fmt.Errorf("store %s cannot return approximate sizes", e.OrderedKeyValueDB)
+	}
+	return sv.GetApproximateSizes(ranges)
+}
+
+// ---- BlobStore interface
+
+func (e *encryptedStore) PutBlob(v []byte) (string, error) {
+	blobstore, ok := e.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return "", fmt.Errorf("store %s is not a blob store", e.OrderedKeyValueDB)
+	}
+	value, err := e.keys.encrypt(v)
+	if err != nil {
+		return "", err
+	}
+	return blobstore.PutBlob(value)
+}
+
+func (e *encryptedStore) GetBlob(ref string) ([]byte, error) {
+	blobstore, ok := e.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return nil, fmt.Errorf("store %s is not a blob store", e.OrderedKeyValueDB)
+	}
+	value, err := blobstore.GetBlob(ref)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return e.decrypt(value)
+}
+
+// ---- TransactionDB interface
+
+func (e *encryptedStore) LockKey(k Key) error {
+	transdb, ok := e.OrderedKeyValueDB.(TransactionDB)
+	if !ok {
+		return fmt.Errorf("store %s does not support transactions", e.OrderedKeyValueDB)
+	}
+	return transdb.LockKey(k)
+}
+
+func (e *encryptedStore) UnlockKey(k Key) error {
+	transdb, ok := e.OrderedKeyValueDB.(TransactionDB)
+	if !ok {
+		return fmt.Errorf("store %s does not support transactions", e.OrderedKeyValueDB)
+	}
+	return transdb.UnlockKey(k)
+}
+
+// Patch decrypts the stored value before patching and encrypts the patched value.
+func (e *encryptedStore) Patch(ctx Context, tk TKey, f PatchFunc) error {
+	transdb, ok := e.OrderedKeyValueDB.(TransactionDB)
+	if !ok {
+		return fmt.Errorf("store %s does not support transactions", e.OrderedKeyValueDB)
+	}
+	defer e.startWrite()()
+	return transdb.Patch(ctx, tk, func(value []byte) ([]byte, error) {
+		if value != nil {
+			var err error
+			if value, err = e.decrypt(value); err != nil {
+				return nil, err
+			}
+		}
+		patched, err := f(value)
+		if err != nil || patched == nil {
+			return patched, err
+		}
+		return e.keys.encrypt(patched)
+	})
+}
+
+// ---- KeyValueRequester interface
+
+func (e *encryptedStore) NewBuffer(ctx Context) RequestBuffer {
+	requester, ok := e.OrderedKeyValueDB.(KeyValueRequester)
+	if !ok {
+		return nil
+	}
+	return &encryptedBuffer{
+		RequestBuffer: requester.NewBuffer(ctx),
+		store:         e,
+	}
+}
+
+// encryptedBuffer encrypts values as they are queued and decrypts values passed to
+// chunk handlers.  Since queued writes may execute any time up to the flush, the
+// flush is treated as a write that can't overlap re-encryption.
+type encryptedBuffer struct {
+	RequestBuffer
+	store *encryptedStore
+}
+
+func (buf *encryptedBuffer) decryptChunks(f ChunkFunc) ChunkFunc {
+	return func(c *Chunk) error {
+		if c.TKeyValue != nil && c.TKeyValue.V != nil {
+			value, err := buf.store.decrypt(c.TKeyValue.V)
+			if err != nil {
+				return err
+			}
+			c.TKeyValue.V = value
+		}
+		return f(c)
+	}
+}
+
+func (buf *encryptedBuffer) Put(ctx Context, tk TKey, v []byte) error {
+	value, err := buf.store.keys.encrypt(v)
+	if err != nil {
+		return err
+	}
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.Put(ctx, tk, value)
+}
+
+func (buf *encryptedBuffer) Delete(ctx Context, tk TKey) error {
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.Delete(ctx, tk)
+}
+
+func (buf *encryptedBuffer) RawPut(k Key, v []byte) error {
+	value, err := buf.store.keys.encrypt(v)
+	if err != nil {
+		return err
+	}
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.RawPut(k, value)
+}
+
+func (buf *encryptedBuffer) RawDelete(k Key) error {
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.RawDelete(k)
+}
+
+func (buf *encryptedBuffer) PutRange(ctx Context, kvs []TKeyValue) error {
+	encrypted := make([]TKeyValue, len(kvs))
+	for i, kv := range kvs {
+		value, err := buf.store.keys.encrypt(kv.V)
+		if err != nil {
+			return err
+		}
+		encrypted[i] = TKeyValue{K: kv.K, V: value}
+	}
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.PutRange(ctx, encrypted)
+}
+
+func (buf *encryptedBuffer) DeleteRange(ctx Context, kStart, kEnd TKey) error {
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.DeleteRange(ctx, kStart, kEnd)
+}
+
+func (buf *encryptedBuffer) ProcessRange(ctx Context, kStart, kEnd TKey, op *ChunkOp, f ChunkFunc) error {
+	return buf.RequestBuffer.ProcessRange(ctx, kStart, kEnd, op, buf.decryptChunks(f))
+}
+
+func (buf *encryptedBuffer) ProcessList(ctx Context, tkeys []TKey, op *ChunkOp, f ChunkFunc) error {
+	return buf.RequestBuffer.ProcessList(ctx, tkeys, op, buf.decryptChunks(f))
+}
+
+func (buf *encryptedBuffer) PutCallback(ctx Context, tk TKey, v []byte, ready chan error) error {
+	value, err := buf.store.keys.encrypt(v)
+	if err != nil {
+		return err
+	}
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.PutCallback(ctx, tk, value, ready)
+}
+
+func (buf *encryptedBuffer) Flush() error {
+	defer buf.store.startWrite()()
+	return buf.RequestBuffer.Flush()
+}
+
+// ---- KeyValueBatcher interface
+
+func (e *encryptedStore) NewBatch(ctx Context) Batch {
+	return &encryptedBatch{
+		Batch: e.batcher.NewBatch(ctx),
+		store: e,
+	}
+}
+
+type encryptedBatch struct {
+	Batch
+	store *encryptedStore
+	err   error
+}
+
+func (batch *encryptedBatch) Put(tk TKey, v []byte) {
+	value, err := batch.store.keys.encrypt(v)
+	if err != nil {
+		batch.err = err
+		return
+	}
+	batch.Batch.Put(tk, value)
+}
+
+func (batch *encryptedBatch) Commit() error {
+	if batch.err != nil {
+		return fmt.Errorf("unable to commit batch with encryption error: %v", batch.err)
+	}
+	defer batch.store.startWrite()()
+	return batch.Batch.Commit()
+}
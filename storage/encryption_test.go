@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func writeTestKeyfile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "dvid-test-keyfile")
+	if err != nil {
+		t.Fatalf("unable to create keyfile: %v\n", err)
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatalf("unable to write keyfile: %v\n", err)
+	}
+	f.Close()
+	return f.Name()
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	oldKeyfile := writeTestKeyfile(t, "# test keys\n1:000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f\n")
+	defer os.Remove(oldKeyfile)
+	oldKeys, err := loadKeyfile(oldKeyfile)
+	if err != nil {
+		t.Fatalf("bad keyfile: %v\n", err)
+	}
+
+	plaintext := []byte("some label block data")
+	encrypted, err := oldKeys.encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("unable to encrypt: %v\n", err)
+	}
+	if bytes.Contains(encrypted, plaintext) {
+		t.Fatalf("encrypted value contains plaintext\n")
+	}
+	if id, ok := keyID(encrypted); !ok || id != 1 {
+		t.Fatalf("expected encrypted value with key id 1, got %d\n", id)
+	}
+
+	// add a new key and make sure old values can be read while new values use new key.
+	newKeyfile := writeTestKeyfile(t, "1:000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f\n\n2:ffeeddccbbaa99887766554433221100\n")
+	defer os.Remove(newKeyfile)
+	newKeys, err := loadKeyfile(newKeyfile)
+	if err != nil {
+		t.Fatalf("bad keyfile: %v\n", err)
+	}
+	if newKeys.current != 2 {
+		t.Fatalf("expected last key in keyfile to be current, got key id %d\n", newKeys.current)
+	}
+	decrypted, err := newKeys.decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("unable to decrypt value using old key: %v\n", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("expected %q after decryption, got %q\n", plaintext, decrypted)
+	}
+	reencrypted, err := newKeys.encrypt(decrypted)
+	if err != nil {
+		t.Fatalf("unable to encrypt: %v\n", err)
+	}
+	if id, _ := keyID(reencrypted); id != 2 {
+		t.Fatalf("expected re-encrypted value with key id 2, got %d\n", id)
+	}
+	if _, err := oldKeys.decrypt(reencrypted); err == nil {
+		t.Fatalf("expected error decrypting value with missing key\n")
+	}
+
+	// values written before encryption should pass through.
+	unencrypted := []byte{0x01, 0x02, 0x03}
+	if value, err := newKeys.decrypt(unencrypted); err != nil || !bytes.Equal(value, unencrypted) {
+		t.Fatalf("expected unencrypted value to pass through, got %v (err %v)\n", value, err)
+	}
+
+	// tampering should be detected.
+	reencrypted[len(reencrypted)-1] ^= 0xFF
+	if _, err := newKeys.decrypt(reencrypted); err == nil {
+		t.Fatalf("expected error decrypting tampered value\n")
+	}
+}
+
+func TestBadKeyfile(t *testing.T) {
+	badFiles := []string{
+		"",
+		"1:abcd\n",
+		"0:000102030405060708090a0b0c0d0e0f\n",
+		"1:000102030405060708090a0b0c0d0e0f\n1:000102030405060708090a0b0c0d0e0f\n",
+		"000102030405060708090a0b0c0d0e0f\n",
+	}
+	for _, contents := range badFiles {
+		keyfile := writeTestKeyfile(t, contents)
+		if _, err := loadKeyfile(keyfile); err == nil {
+			t.Errorf("expected error for keyfile contents %q\n", contents)
+		}
+		os.Remove(keyfile)
+	}
+}
+
+func TestEncryptionLegacyHeader(t *testing.T) {
+	keyfile := writeTestKeyfile(t, "1:000102030405060708090a0b0c0d0e0f\n")
+	defer os.Remove(keyfile)
+	keys, err := loadKeyfile(keyfile)
+	if err != nil {
+		t.Fatalf("bad keyfile: %v\n", err)
+	}
+	e := &encryptedStore{keys: keys}
+
+	// plaintext that starts with the magic header and a valid key id.
+	legacy := append(append([]byte{}, encryptedMagic...), 1, 'l', 'e', 'g', 'a', 'c', 'y')
+	if _, err := keys.decrypt(legacy); err == nil {
+		t.Fatalf("expected legacy value to fail authentication\n")
+	}
+	value, err := e.decrypt(legacy)
+	if err != nil || !bytes.Equal(value, legacy) {
+		t.Fatalf("expected legacy value to pass through before store fully encrypted, got %v (err %v)\n", value, err)
+	}
+	reencrypted, err := e.reencryptValue(&KeyValue{K: Key{metadataKeyPrefix, 0x01}, V: legacy})
+	if err != nil || reencrypted == nil {
+		t.Fatalf("expected legacy value to be re-encrypted, got err %v\n", err)
+	}
+	if value, err = e.decrypt(reencrypted); err != nil || !bytes.Equal(value, legacy) {
+		t.Fatalf("expected re-encrypted legacy value to decrypt to original, got %v (err %v)\n", value, err)
+	}
+	if again, err := e.reencryptValue(&KeyValue{K: Key{metadataKeyPrefix, 0x01}, V: reencrypted}); err != nil || again != nil {
+		t.Fatalf("expected value with current key to not be re-encrypted, got err %v\n", err)
+	}
+
+	e.setFullyEncrypted()
+	if _, err := e.decrypt(legacy); err == nil {
+		t.Fatalf("expected error on bad encrypted value in fully encrypted store\n")
+	}
+}
@@ -0,0 +1,398 @@
+/*
+	This file implements pluggable event sinks that receive the same mutation, repo and
+	activity messages sent to Kafka.  Each sink has an outbox: a message is persisted to
+	the outbox directory before delivery is attempted, and is only removed once the sink
+	accepts it.  Failed deliveries are retried in order with exponential backoff, and any
+	messages left in the outbox at shutdown are delivered after restart.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// EventSink receives messages for a topic.  Send is called from a single goroutine per sink
+// and a non-nil error causes the message to be retried later.
+type EventSink interface {
+	fmt.Stringer
+	Send(topic string, msg []byte) error
+	Close() error
+}
+
+// EventSinkConfig describes the event sinks that should receive messages.
+type EventSinkConfig struct {
+	Outbox     string // directory for per-sink outboxes.  If empty, up to MaxMemoryOutbox undelivered messages are kept only in memory.
+	MaxBackoff int    // maximum seconds between delivery retries.  Defaults to 300 seconds.
+	Sinks      map[string]SinkConfig
+}
+
+// SinkConfig describes a single event sink.
+type SinkConfig struct {
+	Type      string   // "webhook" or "file"
+	URL       string   // URL that receives POSTs of each message for a "webhook" sink
+	Path      string   // directory of append-only files, one per topic, for a "file" sink
+	Instances []string // data instances in form "<name>:<uuid>" that send to this sink.  Empty for all.
+	Activity  bool     // if true, the sink also receives activity log messages
+}
+
+// EventSinkStats holds delivery counts for an event sink.
+type EventSinkStats struct {
+	Sent        uint64
+	Failures    uint64 // number of failed delivery attempts
+	Pending     int    // messages in the outbox awaiting delivery
+	LastFailure string `json:",omitempty"`
+}
+
+// initial delay before retrying a failed delivery.
+var sinkInitialBackoff = time.Second
+
+type eventSinksT struct {
+	sync.RWMutex
+	sinks map[string]*sinkT
+}
+
+var eventSinks eventSinksT
+
+// Initialize creates the configured event sinks and starts delivering any messages left
+// in their outboxes.
+func (c EventSinkConfig) Initialize() error {
+	maxBackoff := time.Duration(c.MaxBackoff) * time.Second
+	for name, sc := range c.Sinks {
+		var sink EventSink
+		var err error
+		switch strings.ToLower(sc.Type) {
+		case "webhook":
+			sink, err = NewWebhookSink(sc.URL)
+		case "file":
+			sink, err = NewFileSink(sc.Path)
+		default:
+			err = fmt.Errorf("unknown type %q", sc.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("bad event sink %q configuration: %v", name, err)
+		}
+		instances := make([]dvid.DataSpecifier, 0, len(sc.Instances))
+		for _, spec := range sc.Instances {
+			parts := strings.Split(strings.Trim(spec, "\""), ":")
+			if len(parts) != 2 {
+				return fmt.Errorf("bad data instance specification %q for event sink %q, expected <name>:<uuid>", spec, name)
+			}
+			instances = append(instances, dvid.GetDataSpecifier(dvid.InstanceName(parts[0]), dvid.UUID(parts[1])))
+		}
+		var outboxDir string
+		if c.Outbox != "" {
+			outboxDir = filepath.Join(c.Outbox, name)
+		}
+		if err = AddEventSink(name, sink, instances, sc.Activity, outboxDir, maxBackoff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddEventSink registers a sink that receives messages for the given data instances, or all
+// data instances if none are given.  If outboxDir is non-empty, undelivered messages are
+// persisted there and any previously persisted messages are queued for delivery.
+func AddEventSink(name string, sink EventSink, instances []dvid.DataSpecifier, activity bool, outboxDir string, maxBackoff time.Duration) error {
+	if maxBackoff <= 0 {
+		maxBackoff = 5 * time.Minute
+	}
+	s := &sinkT{
+		name:       name,
+		sink:       sink,
+		activity:   activity,
+		maxBackoff: maxBackoff,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	if len(instances) != 0 {
+		s.instances = make(map[dvid.DataSpecifier]struct{}, len(instances))
+		for _, dataspec := range instances {
+			s.instances[dataspec] = struct{}{}
+		}
+	}
+	var err error
+	if s.outbox, err = NewOutbox(outboxDir); err != nil {
+		return fmt.Errorf("unable to open outbox for event sink %q: %v", name, err)
+	}
+
+	eventSinks.Lock()
+	if eventSinks.sinks == nil {
+		eventSinks.sinks = make(map[string]*sinkT)
+	}
+	if _, found := eventSinks.sinks[name]; found {
+		eventSinks.Unlock()
+		return fmt.Errorf("event sink %q already exists", name)
+	}
+	eventSinks.sinks[name] = s
+	eventSinks.Unlock()
+
+	dvid.Infof("Sending events to sink %q: %s (%d messages pending in outbox)\n", name, sink, s.outbox.NumPending())
+	go s.deliver()
+	return nil
+}
+
+// EventSinksAvailable returns true if any event sink has been registered.
+func EventSinksAvailable() bool {
+	eventSinks.RLock()
+	defer eventSinks.RUnlock()
+	return len(eventSinks.sinks) != 0
+}
+
+// GetEventSinkStats returns delivery stats for each event sink by name.
+func GetEventSinkStats() map[string]EventSinkStats {
+	eventSinks.RLock()
+	defer eventSinks.RUnlock()
+	stats := make(map[string]EventSinkStats, len(eventSinks.sinks))
+	for name, s := range eventSinks.sinks {
+		s.statsMu.RLock()
+		st := s.stats
+		s.statsMu.RUnlock()
+		st.Pending = s.outbox.NumPending()
+		stats[name] = st
+	}
+	return stats
+}
+
+// ShutdownEventSinks stops delivery and closes all event sinks.  Undelivered messages
+// remain in any on-disk outbox.
+func ShutdownEventSinks() {
+	eventSinks.Lock()
+	sinks := eventSinks.sinks
+	eventSinks.sinks = nil
+	eventSinks.Unlock()
+	for name, s := range sinks {
+		close(s.done)
+		<-s.stopped
+		if err := s.sink.Close(); err != nil {
+			dvid.Errorf("error closing event sink %q: %v\n", name, err)
+		}
+	}
+}
+
+// PublishEvent queues a message on the topic for every event sink accepting the data instance.
+// An empty data specifier, e.g., for repo-level events, is only sent to sinks that accept
+// all data instances.
+func PublishEvent(dataspec dvid.DataSpecifier, topic string, msg []byte) error {
+	return publish(func(s *sinkT) bool {
+		if s.instances == nil {
+			return true
+		}
+		_, found := s.instances[dataspec]
+		return found
+	}, topic, msg)
+}
+
+// PublishActivity queues an activity message for every event sink accepting activity.
+func PublishActivity(topic string, msg []byte) error {
+	return publish(func(s *sinkT) bool { return s.activity }, topic, msg)
+}
+
+func publish(accepts func(*sinkT) bool, topic string, msg []byte) error {
+	eventSinks.RLock()
+	defer eventSinks.RUnlock()
+	var errs []string
+	for name, s := range eventSinks.sinks {
+		if !accepts(s) {
+			continue
+		}
+		if err := s.outbox.Add(topic, msg); err != nil {
+			errs = append(errs, fmt.Sprintf("sink %q: %v", name, err))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("unable to queue event for topic %q: %s", topic, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+type sinkT struct {
+	name       string
+	sink       EventSink
+	instances  map[dvid.DataSpecifier]struct{} // nil if all instances accepted
+	activity   bool
+	outbox     *Outbox
+	maxBackoff time.Duration
+
+	done    chan struct{}
+	stopped chan struct{}
+
+	statsMu sync.RWMutex
+	stats   EventSinkStats
+}
+
+// deliver sends outbox messages in order, retrying each with exponential backoff.
+func (s *sinkT) deliver() {
+	defer close(s.stopped)
+	for {
+		entry, ok := s.outbox.Next(s.done)
+		if !ok {
+			return
+		}
+		backoff := sinkInitialBackoff
+		for {
+			err := s.sink.Send(entry.Topic, entry.Msg)
+			if err == nil {
+				break
+			}
+			s.statsMu.Lock()
+			s.stats.Failures++
+			s.stats.LastFailure = err.Error()
+			s.statsMu.Unlock()
+			dvid.Errorf("event sink %q failed delivery to topic %q, retrying in %s: %v\n", s.name, entry.Topic, backoff, err)
+			select {
+			case <-s.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > s.maxBackoff {
+				backoff = s.maxBackoff
+			}
+		}
+		if err := s.outbox.Remove(entry.Seq); err != nil {
+			dvid.Errorf("unable to remove delivered message %d from outbox of event sink %q: %v\n", entry.Seq, s.name, err)
+		}
+		s.statsMu.Lock()
+		s.stats.Sent++
+		s.statsMu.Unlock()
+	}
+}
+
+// ---- sink implementations
+
+// WebhookSink POSTs each message to a URL with the topic in the "X-Dvid-Topic" header.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a sink that POSTs messages to the given URL.
+func NewWebhookSink(url string) (*WebhookSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook sink requires a URL")
+	}
+	return &WebhookSink{url: url, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (w *WebhookSink) String() string {
+	return fmt.Sprintf("webhook %s", w.url)
+}
+
+func (w *WebhookSink) Send(topic string, msg []byte) error {
+	req, err := http.NewRequest("POST", w.url, bytes.NewBuffer(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Dvid-Topic", topic)
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned status %d", w.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *WebhookSink) Close() error {
+	return nil
+}
+
+// FileSink appends each message followed by a newline to a file named for its topic.
+type FileSink struct {
+	dir   string
+	files map[string]*os.File
+}
+
+// NewFileSink returns a sink that appends messages to files within the given directory.
+func NewFileSink(dir string) (*FileSink, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("file sink requires a path")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir, files: make(map[string]*os.File)}, nil
+}
+
+var topicFilenameRegexp = regexp.MustCompile("[^a-zA-Z0-9\\._\\-]+")
+
+func (fs *FileSink) String() string {
+	return fmt.Sprintf("files @ %s", fs.dir)
+}
+
+func (fs *FileSink) Send(topic string, msg []byte) error {
+	f, found := fs.files[topic]
+	if !found {
+		filename := filepath.Join(fs.dir, topicFilenameRegexp.ReplaceAllString(topic, "-"))
+		var err error
+		f, err = os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0644)
+		if err != nil {
+			return err
+		}
+		fs.files[topic] = f
+	}
+	_, err := f.Write(append(append([]byte{}, msg...), '\n'))
+	return err
+}
+
+func (fs *FileSink) Close() error {
+	var err error
+	for topic, f := range fs.files {
+		if err2 := f.Close(); err2 != nil {
+			err = err2
+		}
+		delete(fs.files, topic)
+	}
+	return err
+}
+
+// SinkMessage is a message received by a ChannelSink.
+type SinkMessage struct {
+	Topic string
+	Msg   []byte
+}
+
+// ChannelSink sends messages to an in-process channel and is useful for testing.
+type ChannelSink struct {
+	C chan SinkMessage
+}
+
+// NewChannelSink returns a sink whose channel has the given buffer size.
+func NewChannelSink(bufsize int) *ChannelSink {
+	return &ChannelSink{C: make(chan SinkMessage, bufsize)}
+}
+
+func (c *ChannelSink) String() string {
+	return "in-process channel"
+}
+
+// Send fails if the channel buffer is full, so the message will be retried.
+func (c *ChannelSink) Send(topic string, msg []byte) error {
+	select {
+	case c.C <- SinkMessage{Topic: topic, Msg: msg}:
+		return nil
+	default:
+		return fmt.Errorf("channel sink is full")
+	}
+}
+
+func (c *ChannelSink) Close() error {
+	return nil
+}
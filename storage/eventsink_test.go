@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+type failingSink struct {
+	*ChannelSink
+	failures int
+}
+
+func (s *failingSink) Send(topic string, msg []byte) error {
+	if s.failures > 0 {
+		s.failures--
+		return fmt.Errorf("simulated failure")
+	}
+	return s.ChannelSink.Send(topic, msg)
+}
+
+func receiveSinkMsg(t *testing.T, sink *ChannelSink) SinkMessage {
+	select {
+	case msg := <-sink.C:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for event sink message\n")
+	}
+	return SinkMessage{}
+}
+
+func TestEventSinkRetry(t *testing.T) {
+	defer ShutdownEventSinks()
+	oldBackoff := sinkInitialBackoff
+	sinkInitialBackoff = time.Millisecond
+	defer func() { sinkInitialBackoff = oldBackoff }()
+
+	dataspec := dvid.GetDataSpecifier("mydata", TestUUID1)
+	sink := &failingSink{ChannelSink: NewChannelSink(10), failures: 3}
+	if err := AddEventSink("retrying", sink, []dvid.DataSpecifier{dataspec}, false, "", time.Millisecond); err != nil {
+		t.Fatalf("couldn't add event sink: %v\n", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := PublishEvent(dataspec, "mytopic", []byte(fmt.Sprintf("msg %d", i))); err != nil {
+			t.Fatalf("couldn't publish event: %v\n", err)
+		}
+	}
+	if err := PublishEvent(dvid.GetDataSpecifier("otherdata", TestUUID1), "mytopic", []byte("ignored")); err != nil {
+		t.Fatalf("couldn't publish event: %v\n", err)
+	}
+	for i := 0; i < 3; i++ {
+		msg := receiveSinkMsg(t, sink.ChannelSink)
+		if msg.Topic != "mytopic" || string(msg.Msg) != fmt.Sprintf("msg %d", i) {
+			t.Fatalf("expected msg %d in order, got %q on topic %q\n", i, msg.Msg, msg.Topic)
+		}
+	}
+	select {
+	case msg := <-sink.C:
+		t.Fatalf("received message %q for data not handled by sink\n", msg.Msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+	stats := GetEventSinkStats()["retrying"]
+	if stats.Sent != 3 || stats.Failures != 3 || stats.Pending != 0 {
+		t.Errorf("bad event sink stats: %v\n", stats)
+	}
+}
+
+func TestEventSinkOutbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-outbox")
+	if err != nil {
+		t.Fatalf("couldn't create outbox dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// messages queued for a sink that never succeeds remain in the outbox after shutdown.
+	sink := &failingSink{ChannelSink: NewChannelSink(10), failures: 1000}
+	if err := AddEventSink("durable", sink, nil, false, dir, time.Hour); err != nil {
+		t.Fatalf("couldn't add event sink: %v\n", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := PublishEvent("", "repotopic", []byte(fmt.Sprintf("msg %d", i))); err != nil {
+			t.Fatalf("couldn't publish event: %v\n", err)
+		}
+	}
+	ShutdownEventSinks()
+
+	// a new sink using the same outbox gets the undelivered messages.
+	sink2 := NewChannelSink(10)
+	if err := AddEventSink("durable", sink2, nil, false, dir, time.Hour); err != nil {
+		t.Fatalf("couldn't add event sink: %v\n", err)
+	}
+	defer ShutdownEventSinks()
+	for i := 0; i < 2; i++ {
+		msg := receiveSinkMsg(t, sink2)
+		if string(msg.Msg) != fmt.Sprintf("msg %d", i) {
+			t.Fatalf("expected msg %d from outbox, got %q\n", i, msg.Msg)
+		}
+	}
+}
+
+func TestMemoryOutboxLimit(t *testing.T) {
+	oldMax := MaxMemoryOutbox
+	MaxMemoryOutbox = 3
+	defer func() { MaxMemoryOutbox = oldMax }()
+
+	ob, err := NewOutbox("")
+	if err != nil {
+		t.Fatalf("couldn't create outbox: %v\n", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := ob.Add("topic", []byte(fmt.Sprintf("msg %d", i))); err != nil {
+			t.Fatalf("couldn't add to outbox: %v\n", err)
+		}
+	}
+	if n := ob.NumPending(); n != 3 {
+		t.Fatalf("expected full memory outbox to hold 3 messages, got %d\n", n)
+	}
+	for i := 2; i < 5; i++ {
+		entry, ok := ob.Next(nil)
+		if !ok || string(entry.Msg) != fmt.Sprintf("msg %d", i) {
+			t.Fatalf("expected oldest messages dropped and msg %d next, got %v\n", i, entry)
+		}
+		if err := ob.Remove(entry.Seq); err != nil {
+			t.Fatalf("couldn't remove from outbox: %v\n", err)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package filelog
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/janelia-flyem/dvid/dvid"
 	"github.com/janelia-flyem/dvid/storage"
@@ -92,6 +93,10 @@ func (e Engine) newLogs(config dvid.StoreConfig) (*fileLogs, bool, error) {
 	if err != nil {
 		return nil, false, err
 	}
+	rotation, err := parseRotationConfig(config)
+	if err != nil {
+		return nil, false, err
+	}
 
 	var created bool
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -110,9 +115,15 @@ func (e Engine) newLogs(config dvid.StoreConfig) (*fileLogs, bool, error) {
 	// }
 
 	log := &fileLogs{
-		path:   path,
-		config: config,
-		files:  make(map[string]*fileLog),
+		path:     path,
+		config:   config,
+		rotation: rotation,
+		files:    make(map[string]*fileLog),
+		topics:   make(map[string]*topic),
+	}
+	if rotation.enabled() {
+		log.done = make(chan struct{})
+		go log.maintenanceLoop(log.done)
 	}
 	return log, created, nil
 }
@@ -120,6 +131,11 @@ func (e Engine) newLogs(config dvid.StoreConfig) (*fileLogs, bool, error) {
 type fileLog struct {
 	*os.File
 	sync.RWMutex
+
+	size    int64     // bytes in the active file
+	numMsgs int64     // messages in the active file or -1 if unknown
+	created time.Time // time the active file began receiving messages
+	closed  bool      // true if closed by rotation or CloseLog, so handle should be reacquired
 }
 
 func (f *fileLog) writeHeader(msg storage.LogMessage) error {
@@ -131,73 +147,167 @@ func (f *fileLog) writeHeader(msg storage.LogMessage) error {
 	return err
 }
 
-func (f *fileLog) readAll() ([]storage.LogMessage, error) {
-	msgs := []storage.LogMessage{}
+// readMessages calls fn for each message read from r until EOF.
+func readMessages(r io.Reader, fn func(storage.LogMessage) error) error {
+	hdrbuf := make([]byte, 6)
 	for {
-		hdrbuf := make([]byte, 6)
-		_, err := io.ReadFull(f, hdrbuf)
+		_, err := io.ReadFull(r, hdrbuf)
 		if err == io.EOF {
-			return msgs, nil
+			return nil
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
 		entryType := binary.LittleEndian.Uint16(hdrbuf[0:2])
 		size := binary.LittleEndian.Uint32(hdrbuf[2:])
 		databuf := make([]byte, size)
-		_, err = io.ReadFull(f, databuf)
-		if err != nil {
-			return nil, err
+		if _, err = io.ReadFull(r, databuf); err != nil {
+			return err
+		}
+		if err = fn(storage.LogMessage{EntryType: entryType, Data: databuf}); err != nil {
+			return err
 		}
-		msg := storage.LogMessage{EntryType: entryType, Data: databuf}
-		msgs = append(msgs, msg)
 	}
 }
 
 type fileLogs struct {
-	path   string
-	config dvid.StoreConfig
-	files  map[string]*fileLog // key = data + version UUID
+	path     string
+	config   dvid.StoreConfig
+	rotation rotationConfig
+	files    map[string]*fileLog // key = data + version UUID
+	topics   map[string]*topic   // segment index for each topic, loaded on demand
+	done     chan struct{}       // closed to stop background maintenance
 	sync.RWMutex
 }
 
-func (flogs *fileLogs) ReadAll(dataID, version dvid.UUID) ([]storage.LogMessage, error) {
-	k := string(dataID + "-" + version)
-	filename := filepath.Join(flogs.path, k)
+// topicFile is a segment or active file opened for reading.
+type topicFile struct {
+	f          *os.File
+	size       int64 // bytes to read or -1 to read the whole file
+	compressed bool
+	desc       string
+}
 
-	flogs.RLock()
-	fl, found := flogs.files[k]
-	flogs.RUnlock()
-	if found {
-		// close then reopen later.
-		fl.Lock()
-		fl.Close()
-		flogs.Lock()
-		delete(flogs.files, k)
-		flogs.Unlock()
+// openTopic opens the topic's segments and active file for reading, skipping segments whose
+// messages all precede the given offset, and returns the offset of the first message in the
+// opened files or -1 if unknown.  Appends, rotation and maintenance are blocked while the
+// files are opened, so the files hold a consistent set of messages even if they are later
+// rotated, compacted or removed.
+func (flogs *fileLogs) openTopic(name string, offset uint64) (files []topicFile, first int64, err error) {
+	var fl *fileLog
+	for {
+		flogs.RLock()
+		cur, open := flogs.files[name]
+		flogs.RUnlock()
+		if !open {
+			break
+		}
+		cur.Lock()
+		if !cur.closed {
+			fl = cur
+			defer fl.Unlock()
+			break
+		}
+		cur.Unlock()
+	}
+
+	t, err := flogs.getTopic(name)
+	if err != nil {
+		return nil, -1, err
 	}
+	t.RLock()
+	defer t.RUnlock()
 
-	f, err := os.OpenFile(filename, os.O_RDONLY, 0755)
+	defer func() {
+		if err != nil {
+			for _, tf := range files {
+				tf.f.Close()
+			}
+			files = nil
+		}
+	}()
+	first = t.index.NextMsg
+	for _, seg := range t.index.Segments {
+		if len(files) == 0 {
+			if seg.FirstMsg >= 0 && seg.NumMsgs >= 0 && uint64(seg.FirstMsg+seg.NumMsgs) <= offset {
+				continue
+			}
+			first = seg.FirstMsg
+		}
+		var f *os.File
+		if f, err = os.Open(flogs.segmentFilename(name, seg)); err != nil {
+			return
+		}
+		files = append(files, topicFile{f, -1, seg.Compressed, fmt.Sprintf("segment %d", seg.Seq)})
+	}
+	f, err := os.Open(filepath.Join(flogs.path, name))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			err = nil
 		}
-		return nil, err
+		return
+	}
+	size := int64(-1)
+	if fl != nil {
+		size = fl.size
 	}
-	fl2 := &fileLog{File: f}
-	msgs, err := fl2.readAll()
-	fl2.Close()
+	files = append(files, topicFile{f, size, false, "active file"})
+	return
+}
 
-	if found {
-		f2, err2 := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0755)
-		if err2 != nil {
-			dvid.Errorf("unable to reopen write log %s: %v\n", k, err)
-		} else {
-			flogs.Lock()
-			flogs.files[k] = &fileLog{File: f2}
-			flogs.Unlock()
+// readTopic calls fn for every message in the topic's segments and active file, starting
+// with the message at the given offset.  Messages are read after releasing all locks, so
+// fn can block without stalling writers.  Returns false if the topic has no stored messages.
+func (flogs *fileLogs) readTopic(name string, offset uint64, fn func(storage.LogMessage) error) (found bool, err error) {
+	files, first, err := flogs.openTopic(name, offset)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		for _, tf := range files {
+			tf.f.Close()
 		}
-		fl.Unlock()
+	}()
+	var msgNum uint64
+	if offset > 0 && len(files) > 0 {
+		if first < 0 {
+			return true, fmt.Errorf("message offsets of log %q are unknown until its segments are counted", name)
+		}
+		msgNum = uint64(first)
+		if msgNum > offset {
+			dvid.Infof("Requested offset %d of log %q has been removed by retention, starting at %d\n", offset, name, msgNum)
+		}
+	}
+	readFn := func(msg storage.LogMessage) error {
+		msgNum++
+		if msgNum > offset {
+			return fn(msg)
+		}
+		return nil
+	}
+	for _, tf := range files {
+		found = true
+		var r io.Reader = tf.f
+		if tf.size >= 0 {
+			r = io.LimitReader(r, tf.size)
+		}
+		if err = readStream(r, tf.compressed, readFn); err != nil {
+			return found, fmt.Errorf("error reading %s of log %q: %v", tf.desc, name, err)
+		}
+	}
+	return found, nil
+}
+
+// ReadAll returns all messages for the given data and version across all log segments.
+func (flogs *fileLogs) ReadAll(dataID, version dvid.UUID) ([]storage.LogMessage, error) {
+	k := string(dataID + "-" + version)
+	msgs := []storage.LogMessage{}
+	found, err := flogs.readTopic(k, 0, func(msg storage.LogMessage) error {
+		msgs = append(msgs, msg)
+		return nil
+	})
+	if !found && err == nil {
+		return nil, nil
 	}
 	return msgs, err
 }
@@ -205,77 +315,40 @@ func (flogs *fileLogs) ReadAll(dataID, version dvid.UUID) ([]storage.LogMessage,
 // ReadBinary reads all the data from a given log
 func (flogs *fileLogs) ReadBinary(dataID, version dvid.UUID) ([]byte, error) {
 	k := string(dataID + "-" + version)
-	filename := filepath.Join(flogs.path, k)
-	data, err := ioutil.ReadFile(filename)
-	if os.IsNotExist(err) {
-		return nil, nil
+	var buf bytes.Buffer
+	hdrbuf := make([]byte, 6)
+	found, err := flogs.readTopic(k, 0, func(msg storage.LogMessage) error {
+		binary.LittleEndian.PutUint16(hdrbuf[:2], msg.EntryType)
+		binary.LittleEndian.PutUint32(hdrbuf[2:], uint32(len(msg.Data)))
+		buf.Write(hdrbuf)
+		buf.Write(msg.Data)
+		return nil
+	})
+	if !found || err != nil {
+		return nil, err
 	}
-	return data, err
+	return buf.Bytes(), nil
 }
 
 // StreamAll sends log messages down channel, adding one for each message to wait group if provided.
+// The channel is closed after the last message is sent.
 func (flogs *fileLogs) StreamAll(dataID, version dvid.UUID, ch chan storage.LogMessage, wg *sync.WaitGroup) error {
-	k := string(dataID + "-" + version)
-	filename := filepath.Join(flogs.path, k)
-
-	flogs.RLock()
-	fl, found := flogs.files[k]
-	flogs.RUnlock()
-	if found {
-		// close then reopen later.
-		fl.Lock()
-		fl.Close()
-		flogs.Lock()
-		delete(flogs.files, k)
-		flogs.Unlock()
-	}
+	return flogs.StreamFrom(dataID, version, 0, ch, wg)
+}
 
-	msgNum := 0
-	f, err := os.OpenFile(filename, os.O_RDONLY, 0755)
-	if err != nil {
-		if os.IsNotExist(err) {
-			err = nil
-		}
-		goto restart
-	}
-	for {
-		hdrbuf := make([]byte, 6)
-		_, err = io.ReadFull(f, hdrbuf)
-		if err == io.EOF {
-			err = nil
-			break
-		}
-		if err != nil {
-			break
-		}
-		entryType := binary.LittleEndian.Uint16(hdrbuf[0:2])
-		size := binary.LittleEndian.Uint32(hdrbuf[2:])
-		databuf := make([]byte, size)
-		_, err = io.ReadFull(f, databuf)
-		if err != nil {
-			break
-		}
-		msgNum++
+// StreamFrom sends log messages starting at the given message offset down channel, adding one
+// for each message to wait group if provided.  The segment index is used to skip segments
+// wholly before the offset.  The channel is closed after the last message is sent.
+func (flogs *fileLogs) StreamFrom(dataID, version dvid.UUID, offset uint64, ch chan storage.LogMessage, wg *sync.WaitGroup) error {
+	k := string(dataID + "-" + version)
+	_, err := flogs.readTopic(k, offset, func(msg storage.LogMessage) error {
 		if wg != nil {
 			wg.Add(1)
 		}
-		ch <- storage.LogMessage{EntryType: entryType, Data: databuf}
-	}
+		ch <- msg
+		return nil
+	})
 	close(ch)
-	f.Close()
-
-restart:
-	if found {
-		f2, err2 := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0755)
-		if err2 != nil {
-			dvid.Errorf("unable to reopen write log %s: %v\n", k, err)
-		} else {
-			flogs.Lock()
-			flogs.files[k] = &fileLog{File: f2}
-			flogs.Unlock()
-		}
-		fl.Unlock()
-	}
 	return err
 }
 
@@ -284,18 +357,50 @@ func (flogs *fileLogs) getWriteLog(topic string) (fl *fileLog, err error) {
 	flogs.RLock()
 	fl, found = flogs.files[topic]
 	flogs.RUnlock()
-	if !found {
-		filename := filepath.Join(flogs.path, topic)
-		var f *os.File
-		f, err = os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0755)
-		if err != nil {
-			return
+	if found {
+		return
+	}
+	t, err := flogs.getTopic(topic)
+	if err != nil {
+		return
+	}
+	filename := filepath.Join(flogs.path, topic)
+	var f *os.File
+	f, err = os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0755)
+	if err != nil {
+		return
+	}
+	var fi os.FileInfo
+	if fi, err = f.Stat(); err != nil {
+		f.Close()
+		return
+	}
+	fl = &fileLog{File: f, size: fi.Size(), numMsgs: -1}
+	if fl.size == 0 {
+		fl.numMsgs = 0
+	}
+	t.Lock()
+	if t.index.ActiveCreated.IsZero() {
+		t.index.ActiveCreated = time.Now()
+		if flogs.rotation.enabled() {
+			err = t.save()
 		}
-		fl = &fileLog{File: f}
-		flogs.Lock()
+	}
+	fl.created = t.index.ActiveCreated
+	t.Unlock()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	flogs.Lock()
+	if existing, found := flogs.files[topic]; found {
+		f.Close()
+		fl = existing
+	} else {
 		flogs.files[topic] = fl
-		flogs.Unlock()
 	}
+	flogs.Unlock()
 	return
 }
 
@@ -306,6 +411,7 @@ func (flogs *fileLogs) closeWriteLog(topic string) error {
 	if found {
 		fl.Lock()
 		err := fl.Close()
+		fl.closed = true
 		flogs.Lock()
 		delete(flogs.files, topic)
 		flogs.Unlock()
@@ -315,23 +421,44 @@ func (flogs *fileLogs) closeWriteLog(topic string) error {
 	return nil
 }
 
-func (flogs *fileLogs) Append(dataID, version dvid.UUID, msg storage.LogMessage) error {
-	topic := string(dataID + "-" + version)
-	fl, err := flogs.getWriteLog(topic)
-	if err != nil {
-		return fmt.Errorf("append log %q: %v", flogs, err)
-	}
-	fl.Lock()
-	if err = fl.writeHeader(msg); err != nil {
+// appendMsg writes a message to the topic's active file, rotating it into a segment if needed.
+func (flogs *fileLogs) appendMsg(topic string, msg storage.LogMessage) error {
+	for {
+		fl, err := flogs.getWriteLog(topic)
+		if err != nil {
+			return err
+		}
+		fl.Lock()
+		if fl.closed {
+			fl.Unlock()
+			continue
+		}
+		if err = fl.writeHeader(msg); err != nil {
+			fl.Unlock()
+			return fmt.Errorf("bad write of log header: %v", err)
+		}
+		if _, err = fl.Write(msg.Data); err != nil {
+			fl.Unlock()
+			return err
+		}
+		fl.size += int64(6 + len(msg.Data))
+		if fl.numMsgs >= 0 {
+			fl.numMsgs++
+		}
+		if flogs.rotation.needsRotation(fl) {
+			err = flogs.rotate(topic, fl)
+		}
 		fl.Unlock()
-		return fmt.Errorf("bad write of log header to data %s, uuid %s: %v", dataID, version, err)
+		return err
 	}
-	_, err = fl.Write(msg.Data)
-	fl.Unlock()
-	if err != nil {
-		err = fmt.Errorf("append log %q: %v", flogs, err)
+}
+
+func (flogs *fileLogs) Append(dataID, version dvid.UUID, msg storage.LogMessage) error {
+	topic := string(dataID + "-" + version)
+	if err := flogs.appendMsg(topic, msg); err != nil {
+		return fmt.Errorf("append log %q, data %s, uuid %s: %v", flogs, dataID, version, err)
 	}
-	return err
+	return nil
 }
 
 func (flogs *fileLogs) CloseLog(dataID, version dvid.UUID) error {
@@ -340,21 +467,10 @@ func (flogs *fileLogs) CloseLog(dataID, version dvid.UUID) error {
 }
 
 func (flogs *fileLogs) TopicAppend(topic string, msg storage.LogMessage) error {
-	fl, err := flogs.getWriteLog(topic)
-	if err != nil {
-		return fmt.Errorf("append log %q: %v", flogs, err)
+	if err := flogs.appendMsg(topic, msg); err != nil {
+		return fmt.Errorf("append log %q, topic %q: %v", flogs, topic, err)
 	}
-	fl.Lock()
-	if err = fl.writeHeader(msg); err != nil {
-		fl.Unlock()
-		return fmt.Errorf("bad write of log header to topic %q: %v", topic, err)
-	}
-	_, err = fl.Write(msg.Data)
-	fl.Unlock()
-	if err != nil {
-		err = fmt.Errorf("append log %q: %v", flogs, err)
-	}
-	return err
+	return nil
 }
 
 func (flogs *fileLogs) TopicClose(topic string) error {
@@ -363,13 +479,22 @@ func (flogs *fileLogs) TopicClose(topic string) error {
 
 func (flogs *fileLogs) Close() {
 	flogs.Lock()
-	for _, flogs := range flogs.files {
-		err := flogs.Close()
+	if flogs.done != nil {
+		close(flogs.done)
+		flogs.done = nil
+	}
+	files := flogs.files
+	flogs.files = make(map[string]*fileLog)
+	flogs.Unlock()
+	for _, fl := range files {
+		fl.Lock()
+		err := fl.Close()
+		fl.closed = true
+		fl.Unlock()
 		if err != nil {
-			dvid.Errorf("closing log file %q: %v\n", flogs.Name(), err)
+			dvid.Errorf("closing log file %q: %v\n", fl.Name(), err)
 		}
 	}
-	flogs.Unlock()
 }
 
 func (flogs *fileLogs) String() string {
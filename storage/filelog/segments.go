@@ -0,0 +1,529 @@
+/*
+	This file implements rotation, retention and compaction of file logs.  Each topic has
+	an active file, named for the topic, that receives appends.  When the active file
+	exceeds a configured size or age, it is renamed into a numbered segment and a new
+	active file is started.  Segments are compacted in the background into gzip-compressed
+	files and removed once they fall outside the retention limits.
+
+	Each topic with segments has a "<topic>.index" JSON file that lists its segments with
+	their sizes, message counts and the offset of their first message, so reads starting
+	from a message offset can skip whole segments.  Message offsets are absolute, i.e.,
+	they count messages removed by retention.  If the index is missing or corrupt, it is
+	rebuilt from the segment files found in the log directory.
+
+	Store configuration settings:
+
+	rotate_mb      Rotate active file once it reaches this size.
+	rotate_hours   Rotate active file once it has received messages for this long.
+	retain_mb      Remove oldest segments while total segment size exceeds this.
+	retain_hours   Remove segments rotated longer ago than this.
+	compress       If true, compress rotated segments with gzip.
+*/
+
+package filelog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+// how often the background maintenance checks for aged active files and segments.
+const maintenanceInterval = time.Minute
+
+type rotationConfig struct {
+	maxBytes    int64
+	maxAge      time.Duration
+	retainBytes int64
+	retainAge   time.Duration
+	compress    bool
+}
+
+// enabled returns true if active files will be rotated into segments.
+func (rc rotationConfig) enabled() bool {
+	return rc.maxBytes > 0 || rc.maxAge > 0
+}
+
+func (rc rotationConfig) needsRotation(fl *fileLog) bool {
+	if fl.size == 0 {
+		return false
+	}
+	if rc.maxBytes > 0 && fl.size >= rc.maxBytes {
+		return true
+	}
+	return rc.maxAge > 0 && time.Since(fl.created) >= rc.maxAge
+}
+
+func getNumber(c map[string]interface{}, name string) (float64, error) {
+	v, found := c[name]
+	if !found {
+		return 0, nil
+	}
+	var num float64
+	switch x := v.(type) {
+	case int:
+		num = float64(x)
+	case int64:
+		num = float64(x)
+	case float64:
+		num = x
+	default:
+		return 0, fmt.Errorf("%q setting must be a number (%v)", name, v)
+	}
+	if num < 0 {
+		return 0, fmt.Errorf("%q setting must be non-negative (%v)", name, v)
+	}
+	return num, nil
+}
+
+func parseRotationConfig(config dvid.StoreConfig) (rc rotationConfig, err error) {
+	c := config.GetAll()
+	var num float64
+	if num, err = getNumber(c, "rotate_mb"); err != nil {
+		return
+	}
+	rc.maxBytes = int64(num * dvid.Mega)
+	if num, err = getNumber(c, "rotate_hours"); err != nil {
+		return
+	}
+	rc.maxAge = time.Duration(num * float64(time.Hour))
+	if num, err = getNumber(c, "retain_mb"); err != nil {
+		return
+	}
+	rc.retainBytes = int64(num * dvid.Mega)
+	if num, err = getNumber(c, "retain_hours"); err != nil {
+		return
+	}
+	rc.retainAge = time.Duration(num * float64(time.Hour))
+	if v, found := c["compress"]; found {
+		var ok bool
+		if rc.compress, ok = v.(bool); !ok {
+			err = fmt.Errorf("%q setting must be a bool (%v)", "compress", v)
+			return
+		}
+	}
+	if !rc.enabled() && (rc.retainBytes > 0 || rc.retainAge > 0 || rc.compress) {
+		dvid.Errorf("filelog retention and compression settings ignored since rotate_mb or rotate_hours not set\n")
+	}
+	return
+}
+
+// segment describes a rotated portion of a topic's log.
+type segment struct {
+	Seq        uint64
+	Created    time.Time // time the segment began receiving messages
+	Rotated    time.Time
+	Bytes      int64 // size of segment file
+	NumMsgs    int64 // -1 until counted
+	FirstMsg   int64 // offset of the first message in the segment or -1 if unknown
+	Compressed bool
+}
+
+type logIndex struct {
+	NextSeq       uint64
+	NextMsg       int64     // offset of the first message in the active file or -1 if unknown
+	ActiveCreated time.Time // time the active file began receiving messages
+	Segments      []segment
+}
+
+// fillOffsets sets any unknown message offsets that follow from the counts of earlier
+// segments.
+func (idx *logIndex) fillOffsets() {
+	for i := 1; i < len(idx.Segments); i++ {
+		cur, prev := &(idx.Segments[i]), idx.Segments[i-1]
+		if cur.FirstMsg < 0 && prev.FirstMsg >= 0 && prev.NumMsgs >= 0 {
+			cur.FirstMsg = prev.FirstMsg + prev.NumMsgs
+		}
+	}
+	if n := len(idx.Segments); n > 0 && idx.NextMsg < 0 {
+		last := idx.Segments[n-1]
+		if last.FirstMsg >= 0 && last.NumMsgs >= 0 {
+			idx.NextMsg = last.FirstMsg + last.NumMsgs
+		}
+	}
+}
+
+type topic struct {
+	sync.RWMutex
+	filename string
+	index    logIndex
+
+	// serializes background compaction and retention for the topic.
+	maintMu sync.Mutex
+}
+
+// save persists the index and should be called with the topic locked.
+func (t *topic) save() error {
+	data, err := json.Marshal(t.index)
+	if err != nil {
+		return err
+	}
+	tmpname := t.filename + ".tmp"
+	if err = ioutil.WriteFile(tmpname, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpname, t.filename)
+}
+
+func (flogs *fileLogs) segmentFilename(name string, seg segment) string {
+	filename := filepath.Join(flogs.path, fmt.Sprintf("%s.%08d", name, seg.Seq))
+	if seg.Compressed {
+		filename += ".gz"
+	}
+	return filename
+}
+
+// getTopic returns the segment index for the topic, loading it from disk if necessary.
+func (flogs *fileLogs) getTopic(name string) (*topic, error) {
+	flogs.RLock()
+	t, found := flogs.topics[name]
+	flogs.RUnlock()
+	if found {
+		return t, nil
+	}
+	t = &topic{filename: filepath.Join(flogs.path, name+".index")}
+	data, err := ioutil.ReadFile(t.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &(t.index)); err != nil {
+			dvid.Errorf("bad index file %q, rebuilding from segments: %v\n", t.filename, err)
+			t.index = logIndex{}
+		}
+	}
+	if err != nil {
+		if err = flogs.rebuildIndex(name, t); err != nil {
+			return nil, err
+		}
+	}
+	flogs.Lock()
+	if existing, found := flogs.topics[name]; found {
+		t = existing
+	} else {
+		flogs.topics[name] = t
+	}
+	flogs.Unlock()
+	return t, nil
+}
+
+// rebuildIndex recreates a topic's index from the segment files in the log directory.
+// Message counts and offsets are left for maintenance to recount.
+func (flogs *fileLogs) rebuildIndex(name string, t *topic) error {
+	fis, err := ioutil.ReadDir(flogs.path)
+	if err != nil {
+		return err
+	}
+	segs := make(map[uint64]segment)
+	prefix := name + "."
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix) {
+			continue
+		}
+		suffix := strings.TrimPrefix(fi.Name(), prefix)
+		if strings.HasSuffix(suffix, ".gz.tmp") {
+			os.Remove(filepath.Join(flogs.path, fi.Name())) // incomplete compaction
+			continue
+		}
+		compressed := strings.HasSuffix(suffix, ".gz")
+		seq, err := strconv.ParseUint(strings.TrimSuffix(suffix, ".gz"), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg := segment{
+			Seq:        seq,
+			Created:    fi.ModTime(),
+			Rotated:    fi.ModTime(),
+			Bytes:      fi.Size(),
+			NumMsgs:    -1,
+			FirstMsg:   -1,
+			Compressed: compressed,
+		}
+		if seq == 0 {
+			seg.FirstMsg = 0
+		}
+		if prev, found := segs[seq]; found {
+			// compressed copy is only renamed into place once complete, so drop the raw one.
+			raw := prev
+			if !compressed {
+				raw, seg = seg, prev
+			}
+			if err := os.Remove(flogs.segmentFilename(name, raw)); err != nil {
+				return err
+			}
+		}
+		segs[seq] = seg
+	}
+	if len(segs) == 0 {
+		return nil
+	}
+	t.index.NextMsg = -1
+	for _, seg := range segs {
+		t.index.Segments = append(t.index.Segments, seg)
+		if seg.Seq >= t.index.NextSeq {
+			t.index.NextSeq = seg.Seq + 1
+		}
+	}
+	sort.Slice(t.index.Segments, func(i, j int) bool {
+		return t.index.Segments[i].Seq < t.index.Segments[j].Seq
+	})
+	dvid.Infof("Rebuilt index of log %q from %d segments\n", name, len(segs))
+	return t.save()
+}
+
+// rotate closes the active file and renames it into the next segment.  The fileLog must
+// be locked by the caller.
+func (flogs *fileLogs) rotate(name string, fl *fileLog) error {
+	t, err := flogs.getTopic(name)
+	if err != nil {
+		return err
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	err = fl.Close()
+	fl.closed = true
+	flogs.Lock()
+	if flogs.files[name] == fl {
+		delete(flogs.files, name)
+	}
+	flogs.Unlock()
+	if err != nil {
+		return err
+	}
+
+	seg := segment{
+		Seq:      t.index.NextSeq,
+		Created:  fl.created,
+		Rotated:  time.Now(),
+		Bytes:    fl.size,
+		NumMsgs:  fl.numMsgs,
+		FirstMsg: t.index.NextMsg,
+	}
+	if err = os.Rename(filepath.Join(flogs.path, name), flogs.segmentFilename(name, seg)); err != nil {
+		return fmt.Errorf("unable to rotate log %q: %v", name, err)
+	}
+	t.index.NextSeq++
+	if seg.FirstMsg >= 0 && seg.NumMsgs >= 0 {
+		t.index.NextMsg = seg.FirstMsg + seg.NumMsgs
+	} else {
+		t.index.NextMsg = -1
+	}
+	t.index.Segments = append(t.index.Segments, seg)
+	t.index.ActiveCreated = time.Time{}
+	if err = t.save(); err != nil {
+		return fmt.Errorf("unable to save index after rotating log %q: %v", name, err)
+	}
+	dvid.Infof("Rotated log %q into segment %d (%d bytes)\n", name, seg.Seq, seg.Bytes)
+	go flogs.maintain(name)
+	return nil
+}
+
+// readSegment calls fn for each message in a segment file.
+func readSegment(filename string, compressed bool, fn func(storage.LogMessage) error) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readStream(f, compressed, fn)
+}
+
+// readStream calls fn for each message in a possibly compressed stream.
+func readStream(in io.Reader, compressed bool, fn func(storage.LogMessage) error) error {
+	var r io.Reader = bufio.NewReader(in)
+	if compressed {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return readMessages(r, fn)
+}
+
+// compactSegment counts the messages in a segment and, if compression is on, writes a
+// compressed copy to a temporary file that should be renamed into place.
+func (flogs *fileLogs) compactSegment(name string, seg segment) (numMsgs int64, tmpname string, err error) {
+	filename := flogs.segmentFilename(name, seg)
+	countFn := func(storage.LogMessage) error {
+		numMsgs++
+		return nil
+	}
+	if !flogs.rotation.compress || seg.Compressed {
+		err = readSegment(filename, seg.Compressed, countFn)
+		return
+	}
+
+	tmpname = filename + ".gz.tmp"
+	var out *os.File
+	if out, err = os.Create(tmpname); err != nil {
+		return
+	}
+	zw := gzip.NewWriter(out)
+	in, err := os.Open(filename)
+	if err != nil {
+		out.Close()
+		os.Remove(tmpname)
+		return
+	}
+	defer in.Close()
+	err = readMessages(bufio.NewReader(io.TeeReader(in, zw)), countFn)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err2 := out.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmpname)
+	}
+	return
+}
+
+// maintain compacts and counts any new segments of a topic, then applies retention limits.
+func (flogs *fileLogs) maintain(name string) {
+	t, err := flogs.getTopic(name)
+	if err != nil {
+		dvid.Errorf("unable to maintain log %q: %v\n", name, err)
+		return
+	}
+	t.maintMu.Lock()
+	defer t.maintMu.Unlock()
+
+	t.RLock()
+	segs := make([]segment, len(t.index.Segments))
+	copy(segs, t.index.Segments)
+	t.RUnlock()
+
+	for _, seg := range segs {
+		if seg.NumMsgs >= 0 && (seg.Compressed || !flogs.rotation.compress) {
+			continue
+		}
+		numMsgs, tmpname, err := flogs.compactSegment(name, seg)
+		if err != nil {
+			dvid.Errorf("unable to compact segment %d of log %q: %v\n", seg.Seq, name, err)
+			return
+		}
+		t.Lock()
+		for i := range t.index.Segments {
+			cur := &(t.index.Segments[i])
+			if cur.Seq != seg.Seq {
+				continue
+			}
+			cur.NumMsgs = numMsgs
+			t.index.fillOffsets()
+			if tmpname != "" {
+				rawname := flogs.segmentFilename(name, *cur)
+				cur.Compressed = true
+				if err = os.Rename(tmpname, flogs.segmentFilename(name, *cur)); err != nil {
+					cur.Compressed = false
+					break
+				}
+				if fi, err := os.Stat(flogs.segmentFilename(name, *cur)); err == nil {
+					cur.Bytes = fi.Size()
+				}
+				if err = os.Remove(rawname); err != nil {
+					break
+				}
+			}
+			break
+		}
+		if err == nil {
+			err = t.save()
+		}
+		t.Unlock()
+		if err != nil {
+			dvid.Errorf("unable to update segment %d of log %q: %v\n", seg.Seq, name, err)
+			return
+		}
+	}
+	flogs.applyRetention(name, t)
+}
+
+// applyRetention removes the oldest segments of a topic that fall outside retention limits.
+func (flogs *fileLogs) applyRetention(name string, t *topic) {
+	rc := flogs.rotation
+	if rc.retainBytes == 0 && rc.retainAge == 0 {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+	var total int64
+	for _, seg := range t.index.Segments {
+		total += seg.Bytes
+	}
+	var removed int
+	for _, seg := range t.index.Segments {
+		expired := rc.retainAge > 0 && time.Since(seg.Rotated) > rc.retainAge
+		oversize := rc.retainBytes > 0 && total > rc.retainBytes
+		if (!expired && !oversize) || seg.NumMsgs < 0 {
+			break
+		}
+		if err := os.Remove(flogs.segmentFilename(name, seg)); err != nil && !os.IsNotExist(err) {
+			dvid.Errorf("unable to remove segment %d of log %q: %v\n", seg.Seq, name, err)
+			break
+		}
+		total -= seg.Bytes
+		removed++
+	}
+	if removed == 0 {
+		return
+	}
+	t.index.Segments = t.index.Segments[removed:]
+	if err := t.save(); err != nil {
+		dvid.Errorf("unable to save index after retention for log %q: %v\n", name, err)
+	}
+	dvid.Infof("Removed %d segments of log %q due to retention limits\n", removed, name)
+}
+
+// maintenanceLoop periodically rotates aged active files and maintains all topics with segments.
+func (flogs *fileLogs) maintenanceLoop(done chan struct{}) {
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	for {
+		flogs.RLock()
+		files := make(map[string]*fileLog, len(flogs.files))
+		for name, fl := range flogs.files {
+			files[name] = fl
+		}
+		flogs.RUnlock()
+		for name, fl := range files {
+			fl.Lock()
+			if !fl.closed && flogs.rotation.needsRotation(fl) {
+				if err := flogs.rotate(name, fl); err != nil {
+					dvid.Errorf("unable to rotate log %q: %v\n", name, err)
+				}
+			}
+			fl.Unlock()
+		}
+
+		indexFiles, err := filepath.Glob(filepath.Join(flogs.path, "*.index"))
+		if err != nil {
+			dvid.Errorf("unable to find log indices in %s: %v\n", flogs.path, err)
+		}
+		for _, indexFile := range indexFiles {
+			flogs.maintain(strings.TrimSuffix(filepath.Base(indexFile), ".index"))
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package filelog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+	"github.com/janelia-flyem/dvid/storage"
+)
+
+const (
+	testDataID  = dvid.UUID("data")
+	testVersion = dvid.UUID("version")
+	testTopic   = "data-version"
+)
+
+func openTestLogs(t *testing.T, path string, settings map[string]interface{}) *fileLogs {
+	tc := map[string]interface{}{"path": path}
+	for k, v := range settings {
+		tc[k] = v
+	}
+	var c dvid.Config
+	c.SetAll(tc)
+	flogs, _, err := Engine{}.newLogs(dvid.StoreConfig{Config: c, Engine: "filelog"})
+	if err != nil {
+		t.Fatalf("can't open logs: %v\n", err)
+	}
+	return flogs
+}
+
+func appendTestMsgs(t *testing.T, flogs *fileLogs, beg, end int) {
+	for i := beg; i < end; i++ {
+		msg := storage.LogMessage{EntryType: uint16(i), Data: []byte(fmt.Sprintf("message %04d of test log", i))}
+		if err := flogs.Append(testDataID, testVersion, msg); err != nil {
+			t.Fatalf("can't append message %d: %v\n", i, err)
+		}
+	}
+}
+
+// checkTestMsgs verifies the log holds messages beg up to end in order.
+func checkTestMsgs(t *testing.T, flogs *fileLogs, beg, end int) {
+	msgs, err := flogs.ReadAll(testDataID, testVersion)
+	if err != nil {
+		t.Fatalf("can't read log: %v\n", err)
+	}
+	if len(msgs) != end-beg {
+		t.Fatalf("expected %d messages, got %d\n", end-beg, len(msgs))
+	}
+	for i, msg := range msgs {
+		expected := fmt.Sprintf("message %04d of test log", beg+i)
+		if int(msg.EntryType) != beg+i || string(msg.Data) != expected {
+			t.Fatalf("expected message %d to be %q, got type %d: %q\n", i, expected, msg.EntryType, string(msg.Data))
+		}
+	}
+}
+
+func testSegments(t *testing.T, flogs *fileLogs) []segment {
+	tp, err := flogs.getTopic(testTopic)
+	if err != nil {
+		t.Fatalf("can't get topic: %v\n", err)
+	}
+	tp.RLock()
+	defer tp.RUnlock()
+	return append([]segment{}, tp.index.Segments...)
+}
+
+func TestRotationCompaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-filelog")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// each message is 30 bytes so active file rotates every 4 messages.
+	flogs := openTestLogs(t, dir, map[string]interface{}{"rotate_mb": 100.0 / dvid.Mega, "compress": true})
+	defer flogs.Close()
+	appendTestMsgs(t, flogs, 0, 22)
+	flogs.maintain(testTopic)
+
+	segs := testSegments(t, flogs)
+	if len(segs) != 5 {
+		t.Fatalf("expected 5 segments after rotation, got %d\n", len(segs))
+	}
+	for i, seg := range segs {
+		if seg.Seq != uint64(i) || !seg.Compressed || seg.NumMsgs != 4 {
+			t.Errorf("bad segment %d after compaction: %v\n", i, seg)
+		}
+		if _, err := os.Stat(flogs.segmentFilename(testTopic, seg)); err != nil {
+			t.Errorf("compressed segment %d not found: %v\n", i, err)
+		}
+		raw := seg
+		raw.Compressed = false
+		if _, err := os.Stat(flogs.segmentFilename(testTopic, raw)); !os.IsNotExist(err) {
+			t.Errorf("uncompressed segment %d not removed after compaction\n", i)
+		}
+	}
+	checkTestMsgs(t, flogs, 0, 22)
+}
+
+func TestRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-filelog")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// keep at most 2 uncompressed segments of 120 bytes each.
+	flogs := openTestLogs(t, dir, map[string]interface{}{"rotate_mb": 100.0 / dvid.Mega, "retain_mb": 250.0 / dvid.Mega})
+	defer flogs.Close()
+	appendTestMsgs(t, flogs, 0, 22)
+	flogs.maintain(testTopic)
+
+	segs := testSegments(t, flogs)
+	if len(segs) != 2 || segs[0].Seq != 3 || segs[1].Seq != 4 {
+		t.Fatalf("expected segments 3 and 4 after retention, got %v\n", segs)
+	}
+	for seq := uint64(0); seq < 3; seq++ {
+		if _, err := os.Stat(flogs.segmentFilename(testTopic, segment{Seq: seq})); !os.IsNotExist(err) {
+			t.Errorf("segment %d not removed by retention\n", seq)
+		}
+	}
+	checkTestMsgs(t, flogs, 12, 22)
+}
+
+func TestIndexRebuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-filelog")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings := map[string]interface{}{"rotate_mb": 100.0 / dvid.Mega, "compress": true}
+	flogs := openTestLogs(t, dir, settings)
+	appendTestMsgs(t, flogs, 0, 10)
+	flogs.maintain(testTopic)
+	flogs.Close()
+
+	// simulate a crash during compaction of a further raw segment and loss of the index.
+	if err := os.Remove(filepath.Join(dir, testTopic+".index")); err != nil {
+		t.Fatalf("can't remove index: %v\n", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, testTopic+".00000001.gz.tmp"), []byte("partial"), 0644); err != nil {
+		t.Fatalf("can't write partial compaction: %v\n", err)
+	}
+
+	flogs = openTestLogs(t, dir, settings)
+	defer flogs.Close()
+	segs := testSegments(t, flogs)
+	if len(segs) != 2 || segs[0].Seq != 0 || segs[1].Seq != 1 || !segs[0].Compressed {
+		t.Fatalf("bad segments after index rebuild: %v\n", segs)
+	}
+	if _, err := os.Stat(filepath.Join(dir, testTopic+".00000001.gz.tmp")); !os.IsNotExist(err) {
+		t.Errorf("partial compaction not removed on index rebuild\n")
+	}
+	checkTestMsgs(t, flogs, 0, 10)
+
+	// new messages go into new segments after the rebuilt ones.
+	appendTestMsgs(t, flogs, 10, 14)
+	segs = testSegments(t, flogs)
+	if len(segs) != 3 || segs[2].Seq != 2 {
+		t.Fatalf("expected new segment 2 after rebuilt index, got %v\n", segs)
+	}
+	flogs.maintain(testTopic)
+	for i, seg := range testSegments(t, flogs) {
+		if seg.NumMsgs != 4 {
+			t.Errorf("expected segment %d to be recounted with 4 messages, got %d\n", i, seg.NumMsgs)
+		}
+	}
+	checkTestMsgs(t, flogs, 0, 14)
+}
+
+func TestActiveCreatedPersisted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-filelog")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// start with a non-empty active file written before rotation was configured.
+	flogs := openTestLogs(t, dir, nil)
+	appendTestMsgs(t, flogs, 0, 1)
+	flogs.Close()
+
+	settings := map[string]interface{}{"rotate_hours": 1}
+	flogs = openTestLogs(t, dir, settings)
+	appendTestMsgs(t, flogs, 1, 2)
+	tp, err := flogs.getTopic(testTopic)
+	if err != nil {
+		t.Fatalf("can't get topic: %v\n", err)
+	}
+	tp.RLock()
+	created := tp.index.ActiveCreated
+	tp.RUnlock()
+	if created.IsZero() {
+		t.Fatalf("active file creation time not set\n")
+	}
+	flogs.Close()
+	time.Sleep(10 * time.Millisecond)
+
+	flogs = openTestLogs(t, dir, settings)
+	defer flogs.Close()
+	if tp, err = flogs.getTopic(testTopic); err != nil {
+		t.Fatalf("can't get topic: %v\n", err)
+	}
+	if !tp.index.ActiveCreated.Equal(created) {
+		t.Errorf("expected active file creation time %s, got %s\n", created, tp.index.ActiveCreated)
+	}
+	checkTestMsgs(t, flogs, 0, 2)
+}
+
+func TestStreamWithoutLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-filelog")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	flogs := openTestLogs(t, dir, map[string]interface{}{"rotate_mb": 100.0 / dvid.Mega})
+	defer flogs.Close()
+	appendTestMsgs(t, flogs, 0, 6)
+
+	// the consumer appends to the same log while the stream is blocked on it.
+	ch := make(chan storage.LogMessage)
+	var wg sync.WaitGroup
+	done := make(chan error, 1)
+	go func() {
+		done <- flogs.StreamAll(testDataID, testVersion, ch, &wg)
+	}()
+	var numMsgs int
+	for msg := range ch {
+		if numMsgs == 0 {
+			appended := make(chan error, 1)
+			go func() {
+				var err error
+				for i := 6; i < 10 && err == nil; i++ {
+					msg := storage.LogMessage{EntryType: uint16(i), Data: []byte(fmt.Sprintf("message %04d of test log", i))}
+					err = flogs.Append(testDataID, testVersion, msg)
+				}
+				appended <- err
+			}()
+			select {
+			case err := <-appended:
+				if err != nil {
+					t.Fatalf("can't append while streaming: %v\n", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("append blocked while streaming log\n")
+			}
+		}
+		if int(msg.EntryType) != numMsgs {
+			t.Errorf("expected message %d, got %d\n", numMsgs, msg.EntryType)
+		}
+		numMsgs++
+		wg.Done()
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("error streaming log: %v\n", err)
+	}
+	if numMsgs != 6 {
+		t.Errorf("expected stream of the 6 messages present at start, got %d\n", numMsgs)
+	}
+	checkTestMsgs(t, flogs, 0, 10)
+}
+
+// streamTestMsgs verifies that streaming from an offset gives messages offset up to end.
+func streamTestMsgs(t *testing.T, flogs *fileLogs, offset uint64, end int) {
+	ch := make(chan storage.LogMessage, end)
+	if err := flogs.StreamFrom(testDataID, testVersion, offset, ch, nil); err != nil {
+		t.Fatalf("can't stream log from offset %d: %v\n", offset, err)
+	}
+	i := int(offset)
+	for msg := range ch {
+		if int(msg.EntryType) != i {
+			t.Fatalf("expected message %d streaming from offset %d, got %d\n", i, offset, msg.EntryType)
+		}
+		i++
+	}
+	if i != end {
+		t.Fatalf("expected stream from offset %d to end at message %d, got %d\n", offset, end, i)
+	}
+}
+
+func TestStreamFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvid-test-filelog")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	flogs := openTestLogs(t, dir, map[string]interface{}{"rotate_mb": 100.0 / dvid.Mega, "compress": true})
+	defer flogs.Close()
+	appendTestMsgs(t, flogs, 0, 22)
+	flogs.maintain(testTopic)
+
+	segs := testSegments(t, flogs)
+	for i, seg := range segs {
+		if !seg.Compressed || seg.FirstMsg != int64(4*i) {
+			t.Fatalf("expected compressed segment %d to start at message %d, got %v\n", i, 4*i, seg)
+		}
+	}
+
+	// offset 6 is within the second segment, so the first segment is never read.
+	if err := os.Remove(flogs.segmentFilename(testTopic, segs[0])); err != nil {
+		t.Fatalf("can't remove first segment: %v\n", err)
+	}
+	streamTestMsgs(t, flogs, 6, 22)
+	streamTestMsgs(t, flogs, 8, 22)
+	streamTestMsgs(t, flogs, 21, 22)
+	streamTestMsgs(t, flogs, 22, 22)
+	if _, err := flogs.ReadAll(testDataID, testVersion); err == nil {
+		t.Fatalf("expected read of all messages to fail without first segment\n")
+	}
+}
@@ -101,19 +101,30 @@ func (kc KafkaConfig) Initialize(hostID string) error {
 	return nil
 }
 
-// LogActivityToKafka publishes activity
+// LogActivityToKafka publishes activity to kafka and any event sinks accepting activity.
 func LogActivityToKafka(activity map[string]interface{}) {
-	if kafkaActivityTopic != "" {
-		go func() {
-			jsonmsg, err := json.Marshal(activity)
-			if err != nil {
-				dvid.Errorf("unable to marshal activity for kafka logging: %v\n", err)
-			}
+	if kafkaActivityTopic == "" && !EventSinksAvailable() {
+		return
+	}
+	go func() {
+		jsonmsg, err := json.Marshal(activity)
+		if err != nil {
+			dvid.Errorf("unable to marshal activity for kafka logging: %v\n", err)
+			return
+		}
+		if kafkaActivityTopic != "" {
 			if err := KafkaProduceMsg(jsonmsg, kafkaActivityTopic); err != nil {
 				dvid.Errorf("unable to publish activity to kafka activity topic: %v\n", err)
 			}
-		}()
-	}
+		}
+		topic := kafkaActivityTopic
+		if topic == "" {
+			topic = "dvidactivity"
+		}
+		if err := PublishActivity(topic, jsonmsg); err != nil {
+			dvid.Errorf("unable to publish activity to event sinks: %v\n", err)
+		}
+	}()
 }
 
 // KafkaProduceMsg sends a message to kafka
@@ -32,6 +32,13 @@ type ReadLog interface {
 	StreamAll(dataID, version dvid.UUID, ch chan LogMessage, wg *sync.WaitGroup) error
 }
 
+// OffsetReadLog is a ReadLog that can stream messages starting from a given message offset,
+// where offset 0 is the first message ever appended to the log.
+type OffsetReadLog interface {
+	ReadLog
+	StreamFrom(dataID, version dvid.UUID, offset uint64, ch chan LogMessage, wg *sync.WaitGroup) error
+}
+
 type LogReadable interface {
 	GetReadLog() ReadLog
 }
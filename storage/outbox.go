@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// OutboxEntry is a message queued in an Outbox.
+type OutboxEntry struct {
+	Seq    uint64 `json:"-"`
+	Topic  string
+	Msg    []byte
+	Queued int64 `json:",omitempty"` // Unix time in nanoseconds when the message was added
+}
+
+// MaxMemoryOutbox is the maximum number of messages held by an outbox without a directory.
+// Once full, the oldest messages are dropped to make room for new ones.
+var MaxMemoryOutbox = 100000
+
+// Outbox is an ordered queue of messages, persisted as one file per message if it has a
+// directory.  Messages stay in the outbox until removed, so a persisted outbox holds any
+// undelivered messages across restarts.
+type Outbox struct {
+	dir string
+
+	mu      sync.Mutex
+	pending []uint64                // sequence numbers in delivery order
+	entries map[uint64]*OutboxEntry // messages for memory-only outbox
+	nextSeq uint64
+	dropped uint64 // messages dropped from a full memory-only outbox
+	notify  chan struct{}
+}
+
+// NewOutbox returns an outbox persisted in the given directory, which is created if
+// necessary.  Any messages already in the directory are pending in their original order.
+// If dir is empty, messages are kept only in memory, up to MaxMemoryOutbox messages.
+func NewOutbox(dir string) (*Outbox, error) {
+	ob := &Outbox{
+		dir:     dir,
+		entries: make(map[uint64]*OutboxEntry),
+		notify:  make(chan struct{}, 1),
+	}
+	if dir == "" {
+		return ob, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// ReadDir sorts by filename and zero-padded sequence numbers keep it in delivery order.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range files {
+		name := fi.Name()
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		ob.pending = append(ob.pending, seq)
+		if seq >= ob.nextSeq {
+			ob.nextSeq = seq + 1
+		}
+	}
+	return ob, nil
+}
+
+func (ob *Outbox) filename(seq uint64) string {
+	return filepath.Join(ob.dir, fmt.Sprintf("%020d.json", seq))
+}
+
+// writeFileSync writes a file and flushes it to disk so a rename of the file can't
+// expose partially written data after a crash.
+func writeFileSync(filename string, data []byte) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir flushes a directory to disk so renames within it are durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Add appends a message to the outbox, returning only after it has been persisted.
+func (ob *Outbox) Add(topic string, msg []byte) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	entry := &OutboxEntry{Seq: ob.nextSeq, Topic: topic, Msg: msg, Queued: time.Now().UnixNano()}
+	if ob.dir == "" {
+		for len(ob.pending) >= MaxMemoryOutbox && len(ob.pending) > 0 {
+			delete(ob.entries, ob.pending[0])
+			ob.pending = ob.pending[1:]
+			ob.dropped++
+			if ob.dropped == 1 || ob.dropped%1000 == 0 {
+				dvid.Errorf("memory-only outbox full with %d messages, dropped %d oldest messages so far\n", MaxMemoryOutbox, ob.dropped)
+			}
+		}
+		ob.entries[entry.Seq] = entry
+	} else {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		filename := ob.filename(entry.Seq)
+		if err = writeFileSync(filename+".tmp", data); err != nil {
+			return err
+		}
+		if err = os.Rename(filename+".tmp", filename); err != nil {
+			return err
+		}
+		if err = syncDir(ob.dir); err != nil {
+			return err
+		}
+	}
+	ob.nextSeq++
+	ob.pending = append(ob.pending, entry.Seq)
+	select {
+	case ob.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Next returns the oldest message without removing it, blocking until a message is
+// available or done is closed.
+func (ob *Outbox) Next(done chan struct{}) (*OutboxEntry, bool) {
+	for {
+		ob.mu.Lock()
+		if len(ob.pending) != 0 {
+			seq := ob.pending[0]
+			ob.mu.Unlock()
+			entry, err := ob.get(seq)
+			if err != nil {
+				dvid.Errorf("dropping unreadable outbox message %s: %v\n", ob.filename(seq), err)
+				ob.Remove(seq)
+				continue
+			}
+			return entry, true
+		}
+		ob.mu.Unlock()
+		select {
+		case <-done:
+			return nil, false
+		case <-ob.notify:
+		}
+	}
+}
+
+func (ob *Outbox) get(seq uint64) (*OutboxEntry, error) {
+	if ob.dir == "" {
+		ob.mu.Lock()
+		defer ob.mu.Unlock()
+		return ob.entries[seq], nil
+	}
+	data, err := ioutil.ReadFile(ob.filename(seq))
+	if err != nil {
+		return nil, err
+	}
+	entry := &OutboxEntry{Seq: seq}
+	if err = json.Unmarshal(data, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Remove deletes the oldest pending message, which must have the given sequence number.
+func (ob *Outbox) Remove(seq uint64) error {
+	ob.mu.Lock()
+	if len(ob.pending) != 0 && ob.pending[0] == seq {
+		ob.pending = ob.pending[1:]
+	}
+	delete(ob.entries, seq)
+	ob.mu.Unlock()
+	if ob.dir == "" {
+		return nil
+	}
+	return os.Remove(ob.filename(seq))
+}
+
+// NumPending returns the number of messages in the outbox.
+func (ob *Outbox) NumPending() int {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	return len(ob.pending)
+}
@@ -28,6 +28,8 @@ type Backend struct {
 	KVStore     DataMap
 	LogStore    DataMap
 	Groupcache  GroupcacheConfig
+	Blockcache  BlockcacheConfig
+	Usage       UsageConfig
 }
 
 // StoreConfig returns a data specifier's assigned store configuration.
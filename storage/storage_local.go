@@ -36,6 +36,9 @@ type managerT struct {
 
 	// groupcache support
 	gcache groupcacheT
+
+	// local block cache support
+	bcache blockcacheT
 }
 
 func AllStores() (map[Alias]dvid.Store, error) {
@@ -119,7 +122,7 @@ func GetStoreByAlias(alias Alias) (dvid.Store, error) {
 
 // GetAssignedStore returns the store assigned based on (instance name, root uuid), tag, or type,
 // in that order.  In some cases, this store may include a caching wrapper if the data instance has
-// been configured to use groupcache.
+// been configured to use groupcache or the local block cache.
 func GetAssignedStore(dataname dvid.InstanceName, root dvid.UUID, tags map[string]string, typename dvid.TypeString) (dvid.Store, error) {
 	if !manager.setup {
 		return nil, fmt.Errorf("Storage manager not initialized before requesting store for %s/%s", dataname, root)
@@ -154,6 +157,18 @@ func GetAssignedStore(dataname dvid.InstanceName, root dvid.UUID, tags map[strin
 		} else {
 			dvid.Infof("Returning groupcache-wrapped store %s for data instance %q @ %s\n", store, dataname, root)
 		}
+	} else if name, supported := manager.bcache.cacheName(dataname, root, typename); supported {
+		store, err = wrapBlockcache(store, manager.bcache.cache, name)
+		if err != nil {
+			dvid.Errorf("Unable to wrap block cache around store %s for data instance %q (uuid %s): %v\n", store, dataname, root, err)
+		} else {
+			dvid.Infof("Returning blockcache-wrapped store %s for data instance %q @ %s\n", store, dataname, root)
+		}
+	}
+
+	// Record storage spans for traced requests.
+	if dvid.TracingEnabled() {
+		store = wrapTracing(store)
 	}
 	return store, nil
 }
@@ -216,6 +231,7 @@ func assignedLogByType(typename dvid.TypeString) (dvid.Store, error) {
 // Shutdown handles any storage-specific shutdown procedures.
 func Shutdown() {
 	if manager.setup {
+		FlushUsage()
 		for alias, store := range manager.stores {
 			dvid.Infof("Closing store %q: %s...\n", alias, store)
 			store.Close()
@@ -246,6 +262,12 @@ func Initialize(cmdline dvid.Config, backend *Backend) (createdMetadata bool, er
 			dvid.TimeErrorf("dbconfig: %v\n", dbconfig)
 			return false, fmt.Errorf("bad store %q: %v", alias, err)
 		}
+		if store, err = wrapUsage(store, backend.Usage); err != nil {
+			return false, fmt.Errorf("bad usage tracking setup for store %q: %v", alias, err)
+		}
+		if store, err = wrapEncryption(store, dbconfig, created); err != nil {
+			return false, fmt.Errorf("bad encryption setup for store %q: %v", alias, err)
+		}
 		if alias == backend.Metadata {
 			gotMetadata = true
 			createdMetadata = created
@@ -288,6 +310,12 @@ func Initialize(cmdline dvid.Config, backend *Backend) (createdMetadata bool, er
 		return
 	}
 
+	// Setup the local block cache if specified.
+	err = setupBlockcache(backend.Blockcache)
+	if err != nil {
+		return
+	}
+
 	// Make all data instance, tag-specific, or datatype-specific store assignments.
 	manager.instanceStore = make(map[dvid.DataSpecifier]dvid.Store)
 	manager.datatypeStore = make(map[dvid.TypeString]dvid.Store)
@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// startSpan starts a child span for a storage operation if the context carries a span.
+func startSpan(ctx Context, store dvid.Store, op string) *dvid.TraceSpan {
+	tc, ok := ctx.(TracedCtx)
+	if !ok || tc.Span() == nil {
+		return nil
+	}
+	span := dvid.StartSpan(tc.Span(), "storage."+op)
+	span.SetAttribute("store", store.String())
+	span.SetAttribute("version", uint64(ctx.VersionID()))
+	if rc, ok := ctx.(RequestCtx); ok && rc.GetRequestID() != "" {
+		span.SetAttribute("request_id", rc.GetRequestID())
+	}
+	if vctx, ok := ctx.(VersionedCtx); ok && vctx.Data() != nil {
+		span.SetAttribute("data", string(vctx.Data().DataName()))
+	}
+	return span
+}
+
+func endSpan(span *dvid.TraceSpan, err error) {
+	span.SetError(err)
+	span.End()
+}
+
+// wrapTracing returns a store that records spans for operations whose context carries a
+// trace span.  The wrapper exposes the optional interfaces of the wrapped store, like
+// KeyValueRequester, so traced requests take the same code paths.  Stores that implement
+// TransactionDB or that can't batch are returned unwrapped since the wrapper would hide
+// required interfaces.
+func wrapTracing(store dvid.Store) dvid.Store {
+	if _, ok := store.(TransactionDB); ok {
+		return store
+	}
+	okvstore, ok := store.(OrderedKeyValueDB)
+	if !ok {
+		return store
+	}
+	batcher, ok := store.(KeyValueBatcher)
+	if !ok {
+		return store
+	}
+	return exposeOptional(&tracingStore{OrderedKeyValueDB: okvstore, batcher: batcher}, store)
+}
+
+type tracingStore struct {
+	OrderedKeyValueDB
+	batcher KeyValueBatcher
+}
+
+func (t *tracingStore) String() string {
+	return fmt.Sprintf("traced %s", t.OrderedKeyValueDB)
+}
+
+// ---- KeyValueGetter interface
+
+func (t *tracingStore) Get(ctx Context, k TKey) ([]byte, error) {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "Get")
+	value, err := t.OrderedKeyValueDB.Get(ctx, k)
+	span.SetAttribute("bytes", len(value))
+	endSpan(span, err)
+	return value, err
+}
+
+// ---- KeyValueTimestampGetter interface
+
+func (t *tracingStore) GetWithTimestamp(ctx Context, k TKey) ([]byte, time.Time, error) {
+	getter, ok := t.OrderedKeyValueDB.(KeyValueTimestampGetter)
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("store %s cannot return timestamps", t.OrderedKeyValueDB)
+	}
+	span := startSpan(ctx, t.OrderedKeyValueDB, "GetWithTimestamp")
+	value, modified, err := getter.GetWithTimestamp(ctx, k)
+	span.SetAttribute("bytes", len(value))
+	endSpan(span, err)
+	return value, modified, err
+}
+
+// ---- OrderedKeyValueGetter interface
+
+func (t *tracingStore) GetRange(ctx Context, kStart, kEnd TKey) ([]*TKeyValue, error) {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "GetRange")
+	kvs, err := t.OrderedKeyValueDB.GetRange(ctx, kStart, kEnd)
+	span.SetAttribute("keys", len(kvs))
+	endSpan(span, err)
+	return kvs, err
+}
+
+func (t *tracingStore) KeysInRange(ctx Context, kStart, kEnd TKey) ([]TKey, error) {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "KeysInRange")
+	keys, err := t.OrderedKeyValueDB.KeysInRange(ctx, kStart, kEnd)
+	span.SetAttribute("keys", len(keys))
+	endSpan(span, err)
+	return keys, err
+}
+
+func (t *tracingStore) SendKeysInRange(ctx Context, kStart, kEnd TKey, ch KeyChan) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "SendKeysInRange")
+	err := t.OrderedKeyValueDB.SendKeysInRange(ctx, kStart, kEnd, ch)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingStore) ProcessRange(ctx Context, kStart, kEnd TKey, op *ChunkOp, f ChunkFunc) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "ProcessRange")
+	if span == nil {
+		return t.OrderedKeyValueDB.ProcessRange(ctx, kStart, kEnd, op, f)
+	}
+	var chunks int
+	var processing time.Duration
+	err := t.OrderedKeyValueDB.ProcessRange(ctx, kStart, kEnd, op, func(c *Chunk) error {
+		chunks++
+		t0 := time.Now()
+		err := f(c)
+		processing += time.Since(t0)
+		return err
+	})
+	span.SetAttribute("chunks", chunks)
+	span.SetAttribute("processing_ms", processing.Seconds()*1000)
+	endSpan(span, err)
+	return err
+}
+
+// ---- KeyValueSetter interface
+
+func (t *tracingStore) Put(ctx Context, tk TKey, v []byte) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "Put")
+	span.SetAttribute("bytes", len(v))
+	err := t.OrderedKeyValueDB.Put(ctx, tk, v)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingStore) Delete(ctx Context, tk TKey) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "Delete")
+	err := t.OrderedKeyValueDB.Delete(ctx, tk)
+	endSpan(span, err)
+	return err
+}
+
+// ---- OrderedKeyValueSetter interface
+
+func (t *tracingStore) PutRange(ctx Context, kvs []TKeyValue) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "PutRange")
+	span.SetAttribute("keys", len(kvs))
+	err := t.OrderedKeyValueDB.PutRange(ctx, kvs)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingStore) DeleteRange(ctx Context, kStart, kEnd TKey) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "DeleteRange")
+	err := t.OrderedKeyValueDB.DeleteRange(ctx, kStart, kEnd)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingStore) DeleteAll(ctx Context, allVersions bool) error {
+	span := startSpan(ctx, t.OrderedKeyValueDB, "DeleteAll")
+	err := t.OrderedKeyValueDB.DeleteAll(ctx, allVersions)
+	endSpan(span, err)
+	return err
+}
+
+// ---- TKeyClassDeleter interface
+
+func (t *tracingStore) DeleteTKeyClass(ctx Context, tkc TKeyClass, allVersions bool) error {
+	deleter, ok := t.OrderedKeyValueDB.(TKeyClassDeleter)
+	if !ok {
+		return fmt.Errorf("store %s cannot delete a class of type-specific keys", t.OrderedKeyValueDB)
+	}
+	return deleter.DeleteTKeyClass(ctx, tkc, allVersions)
+}
+
+// ---- KeyValueChecker interface
+
+func (t *tracingStore) Exists(ctx Context, tk TKey) (bool, error) {
+	checker, ok := t.OrderedKeyValueDB.(KeyValueChecker)
+	if !ok {
+		value, err := t.Get(ctx, tk)
+		return value != nil, err
+	}
+	return checker.Exists(ctx, tk)
+}
+
+// ---- SizeViewer interface
+
+func (t *tracingStore) GetApproximateSizes(ranges []KeyRange) ([]uint64, error) {
+	sv, ok := t.OrderedKeyValueDB.(SizeViewer)
+	if !ok {
+		return nil, fmt.Errorf("store %s cannot return approximate sizes", t.OrderedKeyValueDB)
+	}
+	return sv.GetApproximateSizes(ranges)
+}
+
+// ---- BlobStore interface
+
+func (t *tracingStore) PutBlob(v []byte) (string, error) {
+	blobstore, ok := t.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return "", fmt.Errorf("store %s is not a blob store", t.OrderedKeyValueDB)
+	}
+	return blobstore.PutBlob(v)
+}
+
+func (t *tracingStore) GetBlob(ref string) ([]byte, error) {
+	blobstore, ok := t.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return nil, fmt.Errorf("store %s is not a blob store", t.OrderedKeyValueDB)
+	}
+	return blobstore.GetBlob(ref)
+}
+
+// ---- KeyValueRequester interface
+
+func (t *tracingStore) NewBuffer(ctx Context) RequestBuffer {
+	requester, ok := t.OrderedKeyValueDB.(KeyValueRequester)
+	if !ok {
+		return nil
+	}
+	buffer := requester.NewBuffer(ctx)
+	if buffer == nil {
+		return nil
+	}
+	return &tracingBuffer{RequestBuffer: buffer, ctx: ctx, store: t}
+}
+
+// tracingBuffer records a span for the flush, which executes all queued operations.
+type tracingBuffer struct {
+	RequestBuffer
+	ctx   Context
+	store *tracingStore
+}
+
+func (buf *tracingBuffer) Flush() error {
+	span := startSpan(buf.ctx, buf.store.OrderedKeyValueDB, "BufferFlush")
+	err := buf.RequestBuffer.Flush()
+	endSpan(span, err)
+	return err
+}
+
+// ---- KeyValueBatcher interface
+
+func (t *tracingStore) NewBatch(ctx Context) Batch {
+	return &tracingBatch{
+		Batch: t.batcher.NewBatch(ctx),
+		ctx:   ctx,
+		store: t,
+	}
+}
+
+type tracingBatch struct {
+	Batch
+	ctx   Context
+	store *tracingStore
+	puts  int
+	bytes int
+}
+
+func (batch *tracingBatch) Put(tk TKey, v []byte) {
+	batch.puts++
+	batch.bytes += len(v)
+	batch.Batch.Put(tk, v)
+}
+
+func (batch *tracingBatch) Commit() error {
+	span := startSpan(batch.ctx, batch.store.OrderedKeyValueDB, "BatchCommit")
+	span.SetAttribute("puts", batch.puts)
+	span.SetAttribute("bytes", batch.bytes)
+	err := batch.Batch.Commit()
+	endSpan(span, err)
+	return err
+}
@@ -0,0 +1,22 @@
+package storage
+
+import "testing"
+
+func TestTracingOptionalInterfaces(t *testing.T) {
+	store := wrapTracing(requesterStore{})
+	if _, ok := store.(KeyValueRequester); !ok {
+		t.Errorf("expected traced store to be a KeyValueRequester\n")
+	}
+	if _, ok := store.(BufferableOps); !ok {
+		t.Errorf("expected traced store to implement BufferableOps\n")
+	}
+	if _, ok := store.(KeyValueTimestampGetter); ok {
+		t.Errorf("expected traced store not to be a KeyValueTimestampGetter\n")
+	}
+	if _, ok := store.(BlobStore); ok {
+		t.Errorf("expected traced store not to be a BlobStore\n")
+	}
+	if _, ok := unwrapOptional(store).(*tracingStore); !ok {
+		t.Errorf("expected to recover tracing wrapper, got %T\n", unwrapOptional(store))
+	}
+}
@@ -0,0 +1,807 @@
+/*
+	This file implements incrementally maintained storage usage accounting.  When enabled,
+	each ordered key-value store is wrapped so every Put, Delete, and batch commit updates
+	per-instance, per-version key and byte counters.  Counts refer to the key-value pairs
+	stored under each version, not the data visible at that version, so the total for an
+	instance is the sum of its version counts.
+
+	Determining the change in bytes for a write requires knowing the size of any value
+	already stored at the exact key, so tracked writes incur an additional read.  Counters
+	are persisted periodically within each store.  If a store has no persisted counters,
+	e.g., the first time accounting is enabled, a background scan computes them in chunks.
+	Writes during the scan only update counts for keys the scan has already passed, so
+	counts are partial but not lost or double-counted until the scan completes.
+
+	Writes are not serialized by the wrapper, so concurrent writes to the same key could
+	skew counts.  Datatypes already serialize mutations of a given block or label, so this
+	is rare in practice.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+// UsageConfig handles settings for storage usage accounting.
+type UsageConfig struct {
+	Track     bool // If true, maintain per-instance and per-version key and byte counts.
+	FlushSecs int  // Seconds between persisting counts.  Defaults to 60 seconds.
+}
+
+// UsageCounts holds the number of key-value pairs and total bytes of keys and values.
+type UsageCounts struct {
+	Keys  int64
+	Bytes int64
+}
+
+func (c *UsageCounts) add(delta UsageCounts) {
+	c.Keys += delta.Keys
+	c.Bytes += delta.Bytes
+}
+
+// InstanceUsage holds the usage counts for a data instance across all stores.
+type InstanceUsage struct {
+	UsageCounts
+	Versions map[dvid.VersionID]UsageCounts
+	Counting bool // true if a store holding this instance is still computing its initial counts
+}
+
+// UsageObserver is called with the change in bytes used by a data instance whenever
+// tracked usage changes.  It is called while holding a store lock so it must be fast
+// and must not access storage.
+type UsageObserver func(id dvid.InstanceID, deltaBytes int64)
+
+var (
+	usageObserverMu sync.RWMutex
+	usageObserver   UsageObserver
+)
+
+// ObserveUsage installs a function that is notified of all subsequent usage changes and
+// returns the bytes used by each data instance just before the function was installed.
+func ObserveUsage(f UsageObserver) map[dvid.InstanceID]int64 {
+	stores := usageStores()
+	for _, u := range stores {
+		u.mu.Lock()
+	}
+	baseline := make(map[dvid.InstanceID]int64)
+	for _, u := range stores {
+		for id, versions := range u.counts {
+			for _, c := range versions {
+				baseline[id] += c.Bytes
+			}
+		}
+	}
+	usageObserverMu.Lock()
+	usageObserver = f
+	usageObserverMu.Unlock()
+	for _, u := range stores {
+		u.mu.Unlock()
+	}
+	return baseline
+}
+
+// notifyUsage calls any usage observer with the change in bytes for each instance.
+// Caller should hold the lock of the store whose counts changed.
+func notifyUsage(deltas map[dvid.InstanceID]int64) {
+	usageObserverMu.RLock()
+	f := usageObserver
+	usageObserverMu.RUnlock()
+	if f == nil {
+		return
+	}
+	for id, deltaBytes := range deltas {
+		if deltaBytes != 0 {
+			f(id, deltaBytes)
+		}
+	}
+}
+
+// UsageLimiter is called before a tracked write that would increase the bytes used by
+// a data instance.  A non-nil error aborts the write and is returned to the caller.
+type UsageLimiter func(id dvid.InstanceID, addBytes int64) error
+
+var (
+	usageLimiterMu sync.RWMutex
+	usageLimiter   UsageLimiter
+)
+
+// SetUsageLimiter sets the function used to check quotas before writes that increase usage.
+func SetUsageLimiter(f UsageLimiter) {
+	usageLimiterMu.Lock()
+	usageLimiter = f
+	usageLimiterMu.Unlock()
+}
+
+func checkUsageLimit(id dvid.InstanceID, addBytes int64) error {
+	if addBytes <= 0 {
+		return nil
+	}
+	usageLimiterMu.RLock()
+	f := usageLimiter
+	usageLimiterMu.RUnlock()
+	if f == nil {
+		return nil
+	}
+	return f(id, addBytes)
+}
+
+// UsageTracked returns true if storage usage accounting is enabled.
+func UsageTracked() bool {
+	if !manager.setup {
+		return false
+	}
+	return len(usageStores()) != 0
+}
+
+// GetInstanceUsage returns the usage counts for the given data instances, summed across all
+// stores.  Instances without any stored data are not included in the returned map.
+func GetInstanceUsage(ids ...dvid.InstanceID) (map[dvid.InstanceID]*InstanceUsage, error) {
+	if !manager.setup {
+		return nil, fmt.Errorf("Storage manager not initialized before requesting storage usage")
+	}
+	usage := make(map[dvid.InstanceID]*InstanceUsage, len(ids))
+	for _, u := range usageStores() {
+		u.mu.RLock()
+		for _, id := range ids {
+			versions, found := u.counts[id]
+			if !found && !u.counting {
+				continue
+			}
+			iu, found := usage[id]
+			if !found {
+				iu = &InstanceUsage{Versions: make(map[dvid.VersionID]UsageCounts, len(versions))}
+				usage[id] = iu
+			}
+			iu.Counting = iu.Counting || u.counting
+			for v, counts := range versions {
+				vc := iu.Versions[v]
+				vc.add(*counts)
+				iu.Versions[v] = vc
+				iu.add(*counts)
+			}
+		}
+		u.mu.RUnlock()
+	}
+	return usage, nil
+}
+
+// FlushUsage persists the usage counts of all tracked stores.
+func FlushUsage() {
+	for _, u := range usageStores() {
+		if err := u.flush(); err != nil {
+			dvid.Errorf("Unable to persist usage counts for store %s: %v\n", u, err)
+		}
+	}
+}
+
+func usageStores() []*usageStore {
+	var stores []*usageStore
+	for _, store := range manager.stores {
+		if e, ok := unwrapOptional(store).(*encryptedStore); ok {
+			store = e.OrderedKeyValueDB
+		}
+		if u, ok := unwrapOptional(store).(*usageStore); ok {
+			stores = append(stores, u)
+		}
+	}
+	return stores
+}
+
+// usageKeyClass is the metadata TKey class under which a store persists its usage counts.
+// It is well above the classes used by package datastore for metadata.
+const usageKeyClass TKeyClass = 0xE0
+
+// wrapUsage returns a usage-tracking store if accounting is enabled.  Stores that implement
+// TransactionDB or that can't batch are returned unwrapped since the wrapper would hide
+// required interfaces.
+func wrapUsage(store dvid.Store, config UsageConfig) (dvid.Store, error) {
+	if !config.Track {
+		return store, nil
+	}
+	if _, ok := store.(TransactionDB); ok {
+		dvid.Infof("Storage usage not tracked for transactional store %s\n", store)
+		return store, nil
+	}
+	okvstore, ok := store.(OrderedKeyValueDB)
+	if !ok {
+		dvid.Infof("Storage usage not tracked for store %s: doesn't implement OrderedKeyValueDB\n", store)
+		return store, nil
+	}
+	batcher, ok := store.(KeyValueBatcher)
+	if !ok {
+		dvid.Infof("Storage usage not tracked for store %s: doesn't implement KeyValueBatcher\n", store)
+		return store, nil
+	}
+	u := &usageStore{
+		OrderedKeyValueDB: okvstore,
+		batcher:           batcher,
+		counts:            make(map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts),
+	}
+	found, err := u.load()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		u.counting = true
+		u.scanning = true
+		go u.countAll()
+	}
+	flushSecs := config.FlushSecs
+	if flushSecs <= 0 {
+		flushSecs = 60
+	}
+	go u.flushLoop(time.Duration(flushSecs) * time.Second)
+	return exposeOptional(u, store), nil
+}
+
+// number of key-value pairs counted per chunk of the initial usage scan.
+const usageScanChunk = 1000
+
+type usageStore struct {
+	OrderedKeyValueDB
+	batcher KeyValueBatcher
+
+	// Writers hold the read lock from computing usage changes through applying them
+	// while the initial scan holds the write lock to count each chunk, so writers see
+	// a fixed scan position.
+	writeMu  sync.RWMutex
+	scanning bool
+	scanned  Key // while scanning, keys before this have been counted by the scan.
+
+	mu       sync.RWMutex
+	counts   map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts
+	dirty    bool
+	counting bool // true while initial counts are computed via full scan
+}
+
+func (u *usageStore) String() string {
+	return fmt.Sprintf("usage-tracked %s", u.OrderedKeyValueDB)
+}
+
+func (u *usageStore) load() (found bool, err error) {
+	var data []byte
+	if data, err = u.OrderedKeyValueDB.Get(MetadataContext{}, NewTKey(usageKeyClass, nil)); err != nil {
+		return
+	}
+	if data == nil {
+		return
+	}
+	var persisted map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts
+	if err = json.Unmarshal(data, &persisted); err != nil {
+		return false, fmt.Errorf("unable to decode persisted usage counts for store %s: %v", u, err)
+	}
+	if persisted != nil {
+		u.counts = persisted
+	}
+	return true, nil
+}
+
+func (u *usageStore) flush() error {
+	u.mu.Lock()
+	if !u.dirty || u.counting {
+		u.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(u.counts)
+	u.dirty = false
+	u.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return u.OrderedKeyValueDB.Put(MetadataContext{}, NewTKey(usageKeyClass, nil), data)
+}
+
+func (u *usageStore) flushLoop(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := u.flush(); err != nil {
+			dvid.Errorf("Unable to persist usage counts for store %s: %v\n", u, err)
+		}
+	}
+}
+
+// countAll computes the usage counts by scanning all data keys in the store in chunks.
+func (u *usageStore) countAll() {
+	timedLog := dvid.NewTimeLog()
+	dvid.Infof("Computing initial storage usage for store %s...\n", u)
+	begKey, endKey := DataKeyRange()
+	for {
+		nextKey, done, err := u.countChunk(begKey, endKey)
+		if err != nil {
+			u.writeMu.Lock()
+			u.scanning = false
+			u.writeMu.Unlock()
+			u.mu.Lock()
+			u.counting = false
+			u.mu.Unlock()
+			dvid.Errorf("Unable to compute storage usage for store %s: %v\n", u, err)
+			return
+		}
+		if done {
+			break
+		}
+		begKey = nextKey
+	}
+	if err := u.flush(); err != nil {
+		dvid.Errorf("Unable to persist usage counts for store %s: %v\n", u, err)
+	}
+	timedLog.Infof("Finished computing storage usage for store %s", u)
+}
+
+// countChunk adds the usage of up to usageScanChunk key-value pairs starting at begKey
+// and returns the key that should start the next chunk.
+func (u *usageStore) countChunk(begKey, endKey Key) (nextKey Key, done bool, err error) {
+	u.writeMu.Lock()
+	defer u.writeMu.Unlock()
+
+	ch := make(chan *KeyValue)
+	cancel := make(chan struct{})
+	counts := make(map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var n int
+		for {
+			kv := <-ch
+			if kv == nil {
+				done = true
+				return
+			}
+			if n == usageScanChunk {
+				nextKey = kv.K
+				close(cancel)
+				return
+			}
+			n++
+			if id, v, tracked := trackedKey(kv.K); tracked {
+				addUsage(counts, id, v, UsageCounts{Keys: 1, Bytes: int64(len(kv.K) + len(kv.V))})
+			}
+		}
+	}()
+	if err = u.OrderedKeyValueDB.RawRangeQuery(begKey, endKey, false, ch, cancel); err != nil {
+		return
+	}
+	wg.Wait()
+
+	added := make(map[dvid.InstanceID]int64, len(counts))
+	u.mu.Lock()
+	for id, versions := range counts {
+		for v, c := range versions {
+			addUsage(u.counts, id, v, *c)
+			added[id] += c.Bytes
+		}
+	}
+	u.dirty = true
+	if done {
+		u.counting = false
+	}
+	notifyUsage(added)
+	u.mu.Unlock()
+
+	u.scanned = nextKey
+	if done {
+		u.scanning = false
+	}
+	return
+}
+
+// counted returns true if usage changes for the key should be applied, i.e., the
+// initial scan isn't running or has already counted the key.  Caller must hold writeMu.
+func (u *usageStore) counted(k Key) bool {
+	return !u.scanning || bytes.Compare(k, u.scanned) < 0
+}
+
+// startWrite must be called before computing usage changes for a write and the
+// returned function called after the changes are applied.
+func (u *usageStore) startWrite() func() {
+	u.writeMu.RLock()
+	return u.writeMu.RUnlock
+}
+
+// scan calls f for each non-tombstone data key-value pair between begKey and endKey, inclusive.
+func (u *usageStore) scan(begKey, endKey Key, f func(Key, dvid.InstanceID, dvid.VersionID, int64)) error {
+	ch := make(chan *KeyValue, 1000)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for kv := range ch {
+			if kv == nil {
+				return
+			}
+			if !kv.K.IsDataKey() || kv.K.IsTombstone() {
+				continue
+			}
+			id, v, _, err := DataKeyToLocalIDs(kv.K)
+			if err != nil {
+				continue
+			}
+			f(kv.K, id, v, int64(len(kv.K)+len(kv.V)))
+		}
+	}()
+	err := u.OrderedKeyValueDB.RawRangeQuery(begKey, endKey, false, ch, nil)
+	if err != nil {
+		close(ch)
+	}
+	wg.Wait()
+	return err
+}
+
+func addUsage(counts map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts, id dvid.InstanceID, v dvid.VersionID, delta UsageCounts) {
+	versions, found := counts[id]
+	if !found {
+		versions = make(map[dvid.VersionID]*UsageCounts)
+		counts[id] = versions
+	}
+	c, found := versions[v]
+	if !found {
+		c = new(UsageCounts)
+		versions[v] = c
+	}
+	c.add(delta)
+}
+
+func (u *usageStore) apply(id dvid.InstanceID, v dvid.VersionID, delta UsageCounts) {
+	if delta.Keys == 0 && delta.Bytes == 0 {
+		return
+	}
+	u.mu.Lock()
+	addUsage(u.counts, id, v, delta)
+	u.dirty = true
+	notifyUsage(map[dvid.InstanceID]int64{id: delta.Bytes})
+	u.mu.Unlock()
+}
+
+// storedSize returns the size of the key-value pair stored at the exact key.
+func (u *usageStore) storedSize(k Key) (size int64, found bool, err error) {
+	// A single key range returns at most the key-value pair and the terminating nil.
+	ch := make(chan *KeyValue, 2)
+	if err = u.OrderedKeyValueDB.RawRangeQuery(k, k, false, ch, nil); err != nil {
+		return
+	}
+	kv := <-ch
+	if kv == nil || !bytes.Equal(kv.K, k) {
+		return 0, false, nil
+	}
+	return int64(len(kv.K) + len(kv.V)), true, nil
+}
+
+// writeDelta returns the change in usage when the value at key k is replaced by v,
+// where a nil v signals deletion.
+func (u *usageStore) writeDelta(k Key, v []byte, deleted bool) (delta UsageCounts, err error) {
+	oldSize, found, err := u.storedSize(k)
+	if err != nil {
+		return
+	}
+	if found {
+		delta.Keys--
+		delta.Bytes -= oldSize
+	}
+	if !deleted {
+		delta.Keys++
+		delta.Bytes += int64(len(k) + len(v))
+	}
+	return
+}
+
+// trackedKey returns the instance and version of a full key if its usage should be tracked.
+func trackedKey(k Key) (id dvid.InstanceID, v dvid.VersionID, tracked bool) {
+	if !k.IsDataKey() || k.IsTombstone() {
+		return
+	}
+	var err error
+	if id, v, _, err = DataKeyToLocalIDs(k); err != nil {
+		return
+	}
+	return id, v, true
+}
+
+type keyDelta struct {
+	id    dvid.InstanceID
+	v     dvid.VersionID
+	delta UsageCounts
+}
+
+// putsDelta computes usage changes and checks quotas for a set of puts and deletes
+// given by full keys, where deletions have nil values.  Later writes to the same key
+// supersede earlier ones.  Changes for keys not yet counted by the initial scan are
+// checked against quotas but not returned.  Caller must hold writeMu.
+func (u *usageStore) putsDelta(keys []Key, values [][]byte, deleted []bool) ([]keyDelta, error) {
+	final := make(map[string]int, len(keys))
+	for i, k := range keys {
+		final[string(k)] = i
+	}
+	var deltas []keyDelta
+	added := make(map[dvid.InstanceID]int64)
+	for i, k := range keys {
+		if final[string(k)] != i {
+			continue
+		}
+		id, v, tracked := trackedKey(k)
+		if !tracked {
+			continue
+		}
+		delta, err := u.writeDelta(k, values[i], deleted[i])
+		if err != nil {
+			return nil, err
+		}
+		added[id] += delta.Bytes
+		if u.counted(k) {
+			deltas = append(deltas, keyDelta{id, v, delta})
+		}
+	}
+	for id, addBytes := range added {
+		if err := checkUsageLimit(id, addBytes); err != nil {
+			return nil, err
+		}
+	}
+	return deltas, nil
+}
+
+func (u *usageStore) applyDeltas(deltas []keyDelta) {
+	for _, d := range deltas {
+		u.apply(d.id, d.v, d.delta)
+	}
+}
+
+// removeRange subtracts the usage of all keys for the context's instance with type-specific
+// keys between kStart and kEnd, inclusive.  If allVersions is false, only keys of the context's
+// version are removed.  Caller must hold writeMu.
+func (u *usageStore) removeRange(ctx Context, kStart, kEnd TKey, allVersions bool) ([]keyDelta, error) {
+	if _, tracked := ctx.(instanceProvider); !tracked {
+		return nil, nil
+	}
+	begKey := ctx.ConstructKeyVersion(kStart, 0)
+	endKey := ctx.ConstructKeyVersion(kEnd, dvid.MaxVersionID)
+	removed := make(map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts)
+	err := u.scan(begKey, endKey, func(k Key, id dvid.InstanceID, v dvid.VersionID, size int64) {
+		if (allVersions || v == ctx.VersionID()) && u.counted(k) {
+			addUsage(removed, id, v, UsageCounts{Keys: -1, Bytes: -size})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	var deltas []keyDelta
+	for id, versions := range removed {
+		for v, c := range versions {
+			deltas = append(deltas, keyDelta{id, v, *c})
+		}
+	}
+	return deltas, nil
+}
+
+// ---- KeyValueSetter interface
+
+func (u *usageStore) Put(ctx Context, tk TKey, v []byte) error {
+	defer u.startWrite()()
+	deltas, err := u.putsDelta([]Key{ctx.ConstructKey(tk)}, [][]byte{v}, []bool{false})
+	if err != nil {
+		return err
+	}
+	if err := u.OrderedKeyValueDB.Put(ctx, tk, v); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+func (u *usageStore) Delete(ctx Context, tk TKey) error {
+	defer u.startWrite()()
+	deltas, err := u.putsDelta([]Key{ctx.ConstructKey(tk)}, [][]byte{nil}, []bool{true})
+	if err != nil {
+		return err
+	}
+	if err := u.OrderedKeyValueDB.Delete(ctx, tk); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+func (u *usageStore) RawPut(k Key, v []byte) error {
+	defer u.startWrite()()
+	deltas, err := u.putsDelta([]Key{k}, [][]byte{v}, []bool{false})
+	if err != nil {
+		return err
+	}
+	if err := u.OrderedKeyValueDB.RawPut(k, v); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+func (u *usageStore) RawDelete(k Key) error {
+	defer u.startWrite()()
+	deltas, err := u.putsDelta([]Key{k}, [][]byte{nil}, []bool{true})
+	if err != nil {
+		return err
+	}
+	if err := u.OrderedKeyValueDB.RawDelete(k); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+// ---- OrderedKeyValueSetter interface
+
+func (u *usageStore) PutRange(ctx Context, kvs []TKeyValue) error {
+	defer u.startWrite()()
+	keys := make([]Key, len(kvs))
+	values := make([][]byte, len(kvs))
+	deleted := make([]bool, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = ctx.ConstructKey(kv.K)
+		values[i] = kv.V
+	}
+	deltas, err := u.putsDelta(keys, values, deleted)
+	if err != nil {
+		return err
+	}
+	if err := u.OrderedKeyValueDB.PutRange(ctx, kvs); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+func (u *usageStore) DeleteRange(ctx Context, kStart, kEnd TKey) error {
+	defer u.startWrite()()
+	deltas, err := u.removeRange(ctx, kStart, kEnd, !ctx.Versioned())
+	if err != nil {
+		return err
+	}
+	if err := u.OrderedKeyValueDB.DeleteRange(ctx, kStart, kEnd); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+func (u *usageStore) DeleteAll(ctx Context, allVersions bool) error {
+	defer u.startWrite()()
+	if err := u.OrderedKeyValueDB.DeleteAll(ctx, allVersions); err != nil {
+		return err
+	}
+	if p, tracked := ctx.(instanceProvider); tracked {
+		id := p.InstanceID()
+		var removed int64
+		u.mu.Lock()
+		for v, c := range u.counts[id] {
+			if allVersions || v == ctx.VersionID() {
+				removed += c.Bytes
+				delete(u.counts[id], v)
+			}
+		}
+		if len(u.counts[id]) == 0 {
+			delete(u.counts, id)
+		}
+		u.dirty = true
+		notifyUsage(map[dvid.InstanceID]int64{id: -removed})
+		u.mu.Unlock()
+	}
+	return nil
+}
+
+// ---- KeyValueChecker interface
+
+func (u *usageStore) Exists(ctx Context, tk TKey) (bool, error) {
+	checker, ok := u.OrderedKeyValueDB.(KeyValueChecker)
+	if !ok {
+		value, err := u.OrderedKeyValueDB.Get(ctx, tk)
+		return value != nil, err
+	}
+	return checker.Exists(ctx, tk)
+}
+
+// ---- KeyValueTimestampGetter interface
+
+func (u *usageStore) GetWithTimestamp(ctx Context, tk TKey) ([]byte, time.Time, error) {
+	getter, ok := u.OrderedKeyValueDB.(KeyValueTimestampGetter)
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("store %s cannot return timestamps", u.OrderedKeyValueDB)
+	}
+	return getter.GetWithTimestamp(ctx, tk)
+}
+
+// ---- TKeyClassDeleter interface
+
+func (u *usageStore) DeleteTKeyClass(ctx Context, tkc TKeyClass, allVersions bool) error {
+	deleter, ok := u.OrderedKeyValueDB.(TKeyClassDeleter)
+	if !ok {
+		return fmt.Errorf("store %s cannot delete a class of type-specific keys", u.OrderedKeyValueDB)
+	}
+	defer u.startWrite()()
+	deltas, err := u.removeRange(ctx, MinTKey(tkc), MaxTKey(tkc), allVersions || !ctx.Versioned())
+	if err != nil {
+		return err
+	}
+	if err := deleter.DeleteTKeyClass(ctx, tkc, allVersions); err != nil {
+		return err
+	}
+	u.applyDeltas(deltas)
+	return nil
+}
+
+// ---- SizeViewer interface
+
+func (u *usageStore) GetApproximateSizes(ranges []KeyRange) ([]uint64, error) {
+	sv, ok := u.OrderedKeyValueDB.(SizeViewer)
+	if !ok {
+		return nil, fmt.Errorf("store %s cannot return approximate sizes", u.OrderedKeyValueDB)
+	}
+	return sv.GetApproximateSizes(ranges)
+}
+
+// ---- BlobStore interface
+
+func (u *usageStore) PutBlob(v []byte) (string, error) {
+	blobstore, ok := u.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return "", fmt.Errorf("store %s is not a blob store", u.OrderedKeyValueDB)
+	}
+	return blobstore.PutBlob(v)
+}
+
+func (u *usageStore) GetBlob(ref string) ([]byte, error) {
+	blobstore, ok := u.OrderedKeyValueDB.(BlobStore)
+	if !ok {
+		return nil, fmt.Errorf("store %s is not a blob store", u.OrderedKeyValueDB)
+	}
+	return blobstore.GetBlob(ref)
+}
+
+// ---- KeyValueBatcher interface
+
+func (u *usageStore) NewBatch(ctx Context) Batch {
+	return &usageBatch{
+		Batch: u.batcher.NewBatch(ctx),
+		store: u,
+		ctx:   ctx,
+	}
+}
+
+type usageBatch struct {
+	Batch
+	store   *usageStore
+	ctx     Context
+	keys    []Key
+	values  [][]byte
+	deleted []bool
+}
+
+func (batch *usageBatch) Delete(tk TKey) {
+	batch.keys = append(batch.keys, batch.ctx.ConstructKey(tk))
+	batch.values = append(batch.values, nil)
+	batch.deleted = append(batch.deleted, true)
+	batch.Batch.Delete(tk)
+}
+
+func (batch *usageBatch) Put(tk TKey, v []byte) {
+	batch.keys = append(batch.keys, batch.ctx.ConstructKey(tk))
+	batch.values = append(batch.values, v)
+	batch.deleted = append(batch.deleted, false)
+	batch.Batch.Put(tk, v)
+}
+
+func (batch *usageBatch) Commit() error {
+	defer batch.store.startWrite()()
+	deltas, err := batch.store.putsDelta(batch.keys, batch.values, batch.deleted)
+	if err != nil {
+		return err
+	}
+	if err := batch.Batch.Commit(); err != nil {
+		return err
+	}
+	batch.store.applyDeltas(deltas)
+	return nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/janelia-flyem/dvid/dvid"
+)
+
+func TestUsageTrackedKeys(t *testing.T) {
+	tk := TKey([]byte{0x08, 0x33, 0x71})
+	ctx := GetTestDataContext(TestUUID1, "mydata", 23)
+	id, v, tracked := trackedKey(ctx.ConstructKey(tk))
+	if !tracked {
+		t.Fatalf("expected data key to be tracked\n")
+	}
+	if id != 23 || v != ctx.VersionID() {
+		t.Errorf("expected instance 23, version %d, got instance %d, version %d\n", ctx.VersionID(), id, v)
+	}
+	if _, _, tracked = trackedKey(ctx.TombstoneKey(tk)); tracked {
+		t.Errorf("expected tombstone key to not be tracked\n")
+	}
+	if _, _, tracked = trackedKey(MetadataContext{}.ConstructKey(NewTKey(usageKeyClass, nil))); tracked {
+		t.Errorf("expected metadata key to not be tracked\n")
+	}
+}
+
+func TestUsageCountsAndLimit(t *testing.T) {
+	counts := make(map[dvid.InstanceID]map[dvid.VersionID]*UsageCounts)
+	addUsage(counts, 23, 1, UsageCounts{Keys: 1, Bytes: 100})
+	addUsage(counts, 23, 1, UsageCounts{Keys: 1, Bytes: 50})
+	addUsage(counts, 23, 2, UsageCounts{Keys: 1, Bytes: 10})
+	addUsage(counts, 23, 1, UsageCounts{Keys: -1, Bytes: -100})
+	if c := counts[23][1]; c.Keys != 1 || c.Bytes != 50 {
+		t.Errorf("bad counts for version 1: %v\n", *c)
+	}
+	if c := counts[23][2]; c.Keys != 1 || c.Bytes != 10 {
+		t.Errorf("bad counts for version 2: %v\n", *c)
+	}
+
+	SetUsageLimiter(func(id dvid.InstanceID, addBytes int64) error {
+		if addBytes > 100 {
+			return fmt.Errorf("instance %d over quota", id)
+		}
+		return nil
+	})
+	defer SetUsageLimiter(nil)
+	if err := checkUsageLimit(23, 101); err == nil {
+		t.Errorf("expected write over limit to fail\n")
+	}
+	if err := checkUsageLimit(23, 100); err != nil {
+		t.Errorf("expected write within limit to succeed: %v\n", err)
+	}
+	if err := checkUsageLimit(23, -1000); err != nil {
+		t.Errorf("expected write decreasing usage to succeed: %v\n", err)
+	}
+}
@@ -0,0 +1,193 @@
+package storage
+
+import "github.com/janelia-flyem/dvid/dvid"
+
+// Store wrappers like the block cache, encryption and tracing layer their own handling
+// over an underlying store.  Callers choose code paths by type-asserting optional store
+// interfaces, so a wrapper must only expose the optional interfaces the wrapped store
+// also implements.  Otherwise a wrapper either hides a fast path (e.g., KeyValueRequester)
+// or advertises one that only returns errors.
+
+// storeWrapper is the set of interfaces every store wrapper implements.
+type storeWrapper interface {
+	OrderedKeyValueDB
+	KeyValueBatcher
+	KeyValueChecker
+	TKeyClassDeleter
+	SizeViewer
+}
+
+// wrappedBase allows recovery of the wrapper from any of the combinations below.
+type wrappedBase struct {
+	storeWrapper
+}
+
+func (w wrappedBase) wrapper() storeWrapper {
+	return w.storeWrapper
+}
+
+// exposeOptional returns the wrapper w with each of the optional interfaces
+// KeyValueTimestampGetter, KeyValueRequester, TransactionDB, and BlobStore that are
+// implemented by both w and the wrapped store.
+func exposeOptional(w storeWrapper, wrapped dvid.Store) dvid.Store {
+	var mask int
+	if _, ok := wrapped.(KeyValueTimestampGetter); ok {
+		if _, ok := w.(KeyValueTimestampGetter); ok {
+			mask |= 1
+		}
+	}
+	if _, ok := wrapped.(KeyValueRequester); ok {
+		if _, ok := w.(KeyValueRequester); ok {
+			mask |= 2
+		}
+	}
+	if _, ok := wrapped.(TransactionDB); ok {
+		if _, ok := w.(TransactionDB); ok {
+			mask |= 4
+		}
+	}
+	if _, ok := wrapped.(BlobStore); ok {
+		if _, ok := w.(BlobStore); ok {
+			mask |= 8
+		}
+	}
+	base := wrappedBase{w}
+	switch mask {
+	case 1:
+		return wrappedT{base, w.(KeyValueTimestampGetter)}
+	case 2:
+		return wrappedR{base, w.(KeyValueRequester)}
+	case 3:
+		return wrappedTR{base, w.(KeyValueTimestampGetter), w.(KeyValueRequester)}
+	case 4:
+		return wrappedX{base, w.(TransactionDB)}
+	case 5:
+		return wrappedTX{base, w.(KeyValueTimestampGetter), w.(TransactionDB)}
+	case 6:
+		return wrappedRX{base, w.(KeyValueRequester), w.(TransactionDB)}
+	case 7:
+		return wrappedTRX{base, w.(KeyValueTimestampGetter), w.(KeyValueRequester), w.(TransactionDB)}
+	case 8:
+		return wrappedB{base, w.(BlobStore)}
+	case 9:
+		return wrappedTB{base, w.(KeyValueTimestampGetter), w.(BlobStore)}
+	case 10:
+		return wrappedRB{base, w.(KeyValueRequester), w.(BlobStore)}
+	case 11:
+		return wrappedTRB{base, w.(KeyValueTimestampGetter), w.(KeyValueRequester), w.(BlobStore)}
+	case 12:
+		return wrappedXB{base, w.(TransactionDB), w.(BlobStore)}
+	case 13:
+		return wrappedTXB{base, w.(KeyValueTimestampGetter), w.(TransactionDB), w.(BlobStore)}
+	case 14:
+		return wrappedRXB{base, w.(KeyValueRequester), w.(TransactionDB), w.(BlobStore)}
+	case 15:
+		return wrappedTRXB{base, w.(KeyValueTimestampGetter), w.(KeyValueRequester), w.(TransactionDB), w.(BlobStore)}
+	default:
+		return w
+	}
+}
+
+// unwrapOptional returns the wrapper underlying a store returned by exposeOptional.
+func unwrapOptional(store dvid.Store) dvid.Store {
+	if w, ok := store.(interface {
+		wrapper() storeWrapper
+	}); ok {
+		return w.wrapper()
+	}
+	return store
+}
+
+// Combinations of optional interfaces, named by T (KeyValueTimestampGetter),
+// R (KeyValueRequester), X (TransactionDB), and B (BlobStore).
+type wrappedT struct {
+	wrappedBase
+	KeyValueTimestampGetter
+}
+
+type wrappedR struct {
+	wrappedBase
+	KeyValueRequester
+}
+
+type wrappedTR struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	KeyValueRequester
+}
+
+type wrappedX struct {
+	wrappedBase
+	TransactionDB
+}
+
+type wrappedTX struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	TransactionDB
+}
+
+type wrappedRX struct {
+	wrappedBase
+	KeyValueRequester
+	TransactionDB
+}
+
+type wrappedTRX struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	KeyValueRequester
+	TransactionDB
+}
+
+type wrappedB struct {
+	wrappedBase
+	BlobStore
+}
+
+type wrappedTB struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	BlobStore
+}
+
+type wrappedRB struct {
+	wrappedBase
+	KeyValueRequester
+	BlobStore
+}
+
+type wrappedTRB struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	KeyValueRequester
+	BlobStore
+}
+
+type wrappedXB struct {
+	wrappedBase
+	TransactionDB
+	BlobStore
+}
+
+type wrappedTXB struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	TransactionDB
+	BlobStore
+}
+
+type wrappedRXB struct {
+	wrappedBase
+	KeyValueRequester
+	TransactionDB
+	BlobStore
+}
+
+type wrappedTRXB struct {
+	wrappedBase
+	KeyValueTimestampGetter
+	KeyValueRequester
+	TransactionDB
+	BlobStore
+}